@@ -0,0 +1,164 @@
+package logx
+
+// attrs.go exposes logx's structured error treatment as slog.Attr helpers,
+// so it is available to plain slog loggers and With chains, and typed
+// field builders that replace alternating key/value arguments.
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+// Err returns an "error" attr whose value is a group with "message",
+// "type", "chain" and "origin" (see ErrorErr) plus any Loggable attrs:
+//
+//	logger.With(logx.Err(err)).Warn("retrying")
+//
+// The group is built lazily, only when a record is written. A nil err
+// yields an empty attr, which handlers ignore.
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+	return slog.Any("error", errValue{err})
+}
+
+type errValue struct{ err error }
+
+func (v errValue) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("message", v.err.Error()),
+		slog.String("type", fmt.Sprintf("%T", v.err)),
+	}
+	if chain := errorChain(v.err); len(chain) > 0 {
+		attrs = append(attrs, slog.Any("chain", chain))
+	}
+	if origin := errorOrigin(v.err); origin != "" {
+		attrs = append(attrs, slog.String("origin", origin))
+	}
+	if le, ok := v.err.(Loggable); ok {
+		attrs = append(attrs, le.LogAttrs()...)
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Dur returns key with d rendered as a duration string ("1.5s") by every
+// handler; slog's JSON handler would otherwise write integer nanoseconds.
+func Dur(key string, d time.Duration) slog.Attr {
+	return slog.String(key, d.String())
+}
+
+// Any is slog.Any with logx formatting: errors become an Err group and
+// Loggable values a group of their attrs. Other values, including
+// slog.LogValuers, are passed to slog.Any unchanged.
+func Any(key string, v any) slog.Attr {
+	switch x := v.(type) {
+	case error:
+		a := Err(x)
+		a.Key = key
+		return a
+	case Loggable:
+		return slog.Attr{Key: key, Value: slog.GroupValue(x.LogAttrs()...)}
+	case time.Duration:
+		return Dur(key, x)
+	default:
+		return slog.Any(key, v)
+	}
+}
+
+// String is slog.String. With Int, Int64, Bool, Float64, Time and Group
+// it builds typed attrs without importing log/slog:
+//
+//	logx.InfoAttrs(ctx, "login", logx.String("user", u), logx.Int("attempt", n))
+func String(key, value string) slog.Attr { return slog.String(key, value) }
+
+// Int is slog.Int.
+func Int(key string, value int) slog.Attr { return slog.Int(key, value) }
+
+// Int64 is slog.Int64.
+func Int64(key string, value int64) slog.Attr { return slog.Int64(key, value) }
+
+// Bool is slog.Bool.
+func Bool(key string, value bool) slog.Attr { return slog.Bool(key, value) }
+
+// Float64 is slog.Float64.
+func Float64(key string, value float64) slog.Attr { return slog.Float64(key, value) }
+
+// Time is slog.Time.
+func Time(key string, value time.Time) slog.Attr { return slog.Time(key, value) }
+
+// Group returns a group attr of typed attrs. Unlike slog.Group it does not
+// accept key/value pairs, so a missing value is a compile error.
+func Group(key string, attrs ...slog.Attr) slog.Attr {
+	return slog.Attr{Key: key, Value: slog.GroupValue(attrs...)}
+}
+
+// FieldList is a list of attrs built with typed methods:
+//
+//	f := logx.Fields().Str("user", u).Int("n", 3).Err(err)
+//	logx.InfoAttrs(ctx, "sync done", f...)
+//	logx.Info("sync done", f.Args()...)
+//
+// Each method returns a new list, so a shared base can be extended
+// independently.
+type FieldList []slog.Attr
+
+// Fields starts an empty FieldList.
+func Fields() FieldList {
+	return nil
+}
+
+func (f FieldList) add(a slog.Attr) FieldList {
+	return append(slices.Clip(f), a)
+}
+
+// Str adds a string field.
+func (f FieldList) Str(key, value string) FieldList { return f.add(slog.String(key, value)) }
+
+// Int adds an int field.
+func (f FieldList) Int(key string, value int) FieldList { return f.add(slog.Int(key, value)) }
+
+// Int64 adds an int64 field.
+func (f FieldList) Int64(key string, value int64) FieldList { return f.add(slog.Int64(key, value)) }
+
+// Bool adds a bool field.
+func (f FieldList) Bool(key string, value bool) FieldList { return f.add(slog.Bool(key, value)) }
+
+// Float64 adds a float64 field.
+func (f FieldList) Float64(key string, value float64) FieldList {
+	return f.add(slog.Float64(key, value))
+}
+
+// Time adds a time field.
+func (f FieldList) Time(key string, value time.Time) FieldList { return f.add(slog.Time(key, value)) }
+
+// Dur adds a duration field (see Dur).
+func (f FieldList) Dur(key string, d time.Duration) FieldList { return f.add(Dur(key, d)) }
+
+// Any adds a field formatted by Any.
+func (f FieldList) Any(key string, value any) FieldList { return f.add(Any(key, value)) }
+
+// Err adds the "error" group of Err; a nil err adds nothing.
+func (f FieldList) Err(err error) FieldList {
+	if err == nil {
+		return f
+	}
+	return f.add(Err(err))
+}
+
+// Group adds fields as a group under key.
+func (f FieldList) Group(key string, fields FieldList) FieldList {
+	return f.add(Group(key, fields...))
+}
+
+// Args returns the fields as arguments for Info, With and the other
+// helpers that take ...any.
+func (f FieldList) Args() []any {
+	args := make([]any, len(f))
+	for i, a := range f {
+		args[i] = a
+	}
+	return args
+}
@@ -0,0 +1,81 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+type codedErr struct{ code int }
+
+func (e codedErr) Error() string { return fmt.Sprintf("code %d", e.code) }
+
+func (e codedErr) LogAttrs() []slog.Attr { return []slog.Attr{slog.Int("code", e.code)} }
+
+func TestErr_GroupWithPlainSlog(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	err := fmt.Errorf("save: %w", codedErr{code: 7})
+	l.With(Err(err)).Warn("retrying", Err(nil))
+
+	var rec struct {
+		Error struct {
+			Message string      `json:"message"`
+			Type    string      `json:"type"`
+			Chain   []ErrorLink `json:"chain"`
+			Code    int         `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("invalid JSON: %v: %s", err, buf.String())
+	}
+	if rec.Error.Message != "save: code 7" || rec.Error.Type != "*fmt.wrapError" {
+		t.Fatalf("unexpected error group: %+v", rec.Error)
+	}
+	if len(rec.Error.Chain) != 1 || rec.Error.Chain[0].Type != "logx.codedErr" {
+		t.Fatalf("unexpected chain: %+v", rec.Error.Chain)
+	}
+	if rec.Error.Code != 0 {
+		t.Fatalf("Loggable attrs only apply to the top error: %+v", rec.Error)
+	}
+}
+
+func TestAny_FormatsKnownTypes(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	l.Info("done",
+		Any("cause", codedErr{code: 3}),
+		Any("took", 1500*time.Millisecond),
+		Dur("timeout", 2*time.Second),
+		Any("n", 1),
+	)
+
+	assertContains(t, buf.String(), `"cause":{"message":"code 3","type":"logx.codedErr","code":3}`)
+	assertContains(t, buf.String(), `"took":"1.5s","timeout":"2s","n":1`)
+}
+
+func TestFields_BuildsTypedAttrs(t *testing.T) {
+	base := Fields().Str("user", "ada").Int("n", 3)
+	a := base.Bool("retry", true)
+	b := base.Err(fmt.Errorf("timeout")).Err(nil).Group("req", Fields().Dur("took", 1500*time.Millisecond))
+	if len(base) != 2 || len(a) != 3 || len(b) != 4 || a[2].Key != "retry" || b[2].Key != "error" {
+		t.Fatalf("expected independent lists, got %v, %v, %v", base, a, b)
+	}
+
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, nil))
+	l.LogAttrs(t.Context(), slog.LevelInfo, "attrs", b...)
+	l.Info("args", a.Args()...)
+	l.Info("helpers", Group("g", String("s", "x"), Int64("i", 9), Float64("f", 1.5), Bool("b", false)))
+
+	out := buf.String()
+	assertContains(t, out, "msg=attrs user=ada n=3 error.message=timeout")
+	assertContains(t, out, "req.took=1.5s")
+	assertContains(t, out, "msg=args user=ada n=3 retry=true")
+	assertContains(t, out, "msg=helpers g.s=x g.i=9 g.f=1.5 g.b=false")
+}
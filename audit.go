@@ -0,0 +1,240 @@
+package logx
+
+// audit.go routes audit events to their own output, isolated from
+// application logs: always JSON, never level-filtered or sampled, and
+// optionally hash-chained so that edits and deletions are detectable.
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rannday/logx/internal/backups"
+)
+
+// ErrNoAuditSink is returned by Audit when neither Config.AuditPath nor
+// Config.AuditWriter is set.
+var ErrNoAuditSink = errors.New("logx: no audit sink configured")
+
+// ErrAuditChainBroken is returned by VerifyAuditChain when a record's
+// "prev_hash" does not match the record before it.
+var ErrAuditChainBroken = errors.New("logx: audit chain broken")
+
+// auditHashKey holds the SHA-256 of the previous audit line.
+const auditHashKey = "prev_hash"
+
+var auditOut atomic.Pointer[auditSink]
+
+// Audit writes an audit event (the record message) with args to the audit
+// output, bypassing levels, sampling and the application outputs. Unlike
+// the other helpers it reports write failures, since a lost audit event
+// usually matters:
+//
+//	if err := logx.Audit("user.role_changed", "actor", admin, "user", id, "role", role); err != nil {
+//		return err
+//	}
+func Audit(event string, args ...any) error {
+	return AuditContext(context.Background(), event, args...)
+}
+
+// AuditContext is Audit with a context for context-aware handlers.
+func AuditContext(ctx context.Context, event string, args ...any) error {
+	a := auditOut.Load()
+	if a == nil {
+		return ErrNoAuditSink
+	}
+	r := slog.NewRecord(Now(), slog.LevelInfo, event, 0)
+	r.Add(args...)
+	return a.write(ctx, r)
+}
+
+type auditSink struct {
+	mu    sync.Mutex
+	h     slog.Handler
+	hw    *hashWriter // nil unless hash-chained
+	prev  string
+	owned io.Closer
+}
+
+func (a *auditSink) write(ctx context.Context, r slog.Record) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.hw == nil {
+		return a.h.Handle(ctx, r)
+	}
+	r.AddAttrs(slog.String(auditHashKey, a.prev))
+	if err := a.h.Handle(ctx, r); err != nil {
+		return err
+	}
+	a.prev = a.hw.last
+	return nil
+}
+
+// hashWriter remembers the hash of the last line written; slog's JSON
+// handler writes each record with a single Write call.
+type hashWriter struct {
+	w    io.Writer
+	last string
+}
+
+func (h *hashWriter) Write(p []byte) (int, error) {
+	n, err := h.w.Write(p)
+	if err == nil {
+		h.last = lineHash(p)
+	}
+	return n, err
+}
+
+func lineHash(line []byte) string {
+	sum := sha256.Sum256(line)
+	return hex.EncodeToString(sum[:])
+}
+
+// buildAudit opens the audit output described by cfg, if any.
+func buildAudit(cfg Config) (*auditSink, io.Closer, *OutputDescription, error) {
+	var w io.WriteCloser
+	out := &OutputDescription{Kind: "audit", Format: "json", HashChain: cfg.AuditHashChain}
+	var prev string
+	switch {
+	case cfg.AuditWriter != nil:
+		w = cfg.AuditWriter
+		out.Target = "writer"
+	case cfg.AuditPath != "" && cfg.AuditRotation.active():
+		r, err := openRotator(cfg.AuditPath, cfg.AuditRotation, 0o600)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: %w", ErrRotatorInit, err)
+		}
+		w = r
+		out.Target = cfg.AuditPath
+		out.Rotation = r.describe()
+		if cfg.AuditHashChain {
+			// continue the chain across restarts and rotations
+			prev = lastLineHash(cfg.AuditPath)
+			if prev == "" {
+				prev = lastBackupLineHash(cfg.AuditPath)
+			}
+		}
+	case cfg.AuditPath != "":
+		f, err := os.OpenFile(cfg.AuditPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("%w: %w", ErrFileOpen, err)
+		}
+		w = f
+		out.Target = cfg.AuditPath
+		if cfg.AuditHashChain {
+			// continue the chain across restarts
+			prev = lastLineHash(cfg.AuditPath)
+		}
+	default:
+		return nil, nil, nil, nil
+	}
+
+	opts := &slog.HandlerOptions{Level: slog.Level(math.MinInt)}
+	a := &auditSink{prev: prev}
+	var dst io.Writer = w
+	if cfg.AuditHashChain {
+		a.hw = &hashWriter{w: w}
+		dst = a.hw
+	}
+	a.h = newRedactionHandler(slog.NewJSONHandler(dst, opts))
+	return a, w, out, nil
+}
+
+// lastLineHash returns the hash of the last line of path, or "" if the
+// file is empty or unreadable.
+func lastLineHash(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return ""
+	}
+	off := max(info.Size()-maxTailBytes, 0)
+	tail := make([]byte, info.Size()-off)
+	if _, err := f.ReadAt(tail, off); err != nil && !errors.Is(err, io.EOF) {
+		return ""
+	}
+	tail = bytes.TrimRight(tail, "\n")
+	if i := bytes.LastIndexByte(tail, '\n'); i >= 0 {
+		tail = tail[i+1:]
+	}
+	return lineHash(append(tail, '\n'))
+}
+
+// lastBackupLineHash returns the hash of the last line of the newest backup
+// of path, reading gzipped backups in full.
+func lastBackupLineHash(path string) string {
+	files, _ := backups.List(path)
+	if len(files) == 0 {
+		return ""
+	}
+	newest := files[len(files)-1]
+	if !strings.HasSuffix(newest, ".gz") {
+		return lastLineHash(newest)
+	}
+
+	f, err := os.Open(newest)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return ""
+	}
+	sc := bufio.NewScanner(zr)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var last []byte
+	for sc.Scan() {
+		if len(sc.Bytes()) > 0 {
+			last = append(last[:0], sc.Bytes()...)
+		}
+	}
+	if sc.Err() != nil || last == nil {
+		return ""
+	}
+	return lineHash(append(last, '\n'))
+}
+
+// VerifyAuditChain checks a hash-chained audit log: every record's
+// "prev_hash" must be the SHA-256 of the line before it. The first
+// record's prev_hash is not checked, so a log can be verified from any
+// starting point.
+func VerifyAuditChain(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var prev string
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Bytes()
+		var rec map[string]any
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("%w: line %d: %w", ErrAuditChainBroken, n, err)
+		}
+		if n > 1 && rec[auditHashKey] != prev {
+			return fmt.Errorf("%w: line %d", ErrAuditChainBroken, n)
+		}
+		h := sha256.New()
+		h.Write(line)
+		h.Write([]byte{'\n'})
+		prev = hex.EncodeToString(h.Sum(nil))
+	}
+	return sc.Err()
+}
@@ -0,0 +1,135 @@
+package logx
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rannday/logx/internal/backups"
+)
+
+func TestAudit_SeparateOutputIgnoresLevel(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var app, audit bytes.Buffer
+	err := Configure(Config{
+		Level:       slog.LevelError,
+		FileWriter:  nopWriteCloser{&app},
+		AuditWriter: nopWriteCloser{&audit},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Audit("user.login", "user", "ann"); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Len() != 0 {
+		t.Fatalf("expected nothing in the application log: %s", app.String())
+	}
+	assertContains(t, audit.String(), `"msg":"user.login","user":"ann"}`)
+	if strings.Contains(audit.String(), auditHashKey) {
+		t.Fatalf("expected no hash without AuditHashChain: %s", audit.String())
+	}
+}
+
+func TestAudit_NoSink(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	if err := Audit("x"); !errors.Is(err, ErrNoAuditSink) {
+		t.Fatalf("expected ErrNoAuditSink, got %v", err)
+	}
+}
+
+func TestAudit_HashChainAcrossRestarts(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	cfg := Config{Level: slog.LevelInfo, FileWriter: nopWriteCloser{&bytes.Buffer{}}, AuditPath: path, AuditHashChain: true}
+	if err := Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	_ = Audit("a", "n", 1)
+	_ = Audit("b", "n", 2)
+	if err := Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	_ = Audit("c", "n", 3)
+	Reset()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyAuditChain(bytes.NewReader(data)); err != nil {
+		t.Fatalf("expected valid chain: %v\n%s", err, data)
+	}
+
+	tampered := bytes.Replace(data, []byte(`"n":2`), []byte(`"n":5`), 1)
+	if err := VerifyAuditChain(bytes.NewReader(tampered)); !errors.Is(err, ErrAuditChainBroken) {
+		t.Fatalf("expected tampering to be detected, got %v", err)
+	}
+}
+
+func TestAudit_HashChainAcrossRotatedFiles(t *testing.T) {
+	Reset()
+	defer Reset()
+	day := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	now := day
+	SetClock(func() time.Time { return now })
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	cfg := Config{
+		Level:          slog.LevelInfo,
+		FileWriter:     nopWriteCloser{&bytes.Buffer{}},
+		AuditPath:      path,
+		AuditHashChain: true,
+		AuditRotation:  Rotation{Every: 24 * time.Hour, MaxBackups: 365},
+	}
+	if err := Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	_ = Audit("a")
+	now = day.Add(24 * time.Hour)
+	_ = Audit("b")
+	if d := Describe(); d.Outputs[len(d.Outputs)-1].Rotation == nil {
+		t.Fatalf("expected audit rotation to be described: %+v", d.Outputs)
+	}
+	Reset()
+
+	// an external rotation leaves no active file to continue from
+	if err := os.Rename(path, path+".20240103T235959"); err != nil {
+		t.Fatal(err)
+	}
+	if err := Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	_ = Audit("c")
+	Reset()
+
+	files, _ := backups.List(path)
+	if len(files) != 2 {
+		t.Fatalf("expected two backups, got %v", files)
+	}
+	var all []byte
+	for _, p := range append(files, path) {
+		data, _ := os.ReadFile(p)
+		all = append(all, data...)
+	}
+	if err := VerifyAuditChain(bytes.NewReader(all)); err != nil || bytes.Count(all, []byte("\n")) != 3 {
+		t.Fatalf("expected the chain to continue across files: %v\n%s", err, all)
+	}
+}
@@ -0,0 +1,66 @@
+package logx
+
+// caller.go builds records with the caller's program counter so that
+// AddSource reports application code instead of logx's wrapper functions.
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+// LogDepth logs at level with the source location depth frames above its
+// caller: 0 is the caller itself, 1 its caller, and so on. Application
+// logging helpers use it to keep AddSource pointing at their callers:
+//
+//	func logFailure(msg string, args ...any) {
+//		logx.LogDepth(context.Background(), 1, slog.LevelWarn, msg, args...)
+//	}
+func LogDepth(ctx context.Context, depth int, level slog.Level, msg string, args ...any) {
+	logDepth(Logger(), ctx, depth, level, msg, args...)
+}
+
+// logDepth logs through l with the source set skip frames above the
+// caller of logDepth's caller, mirroring slog.Logger.Log.
+func logDepth(l *slog.Logger, ctx context.Context, skip int, level slog.Level, msg string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	// skip runtime.Callers, logDepth and the exported wrapper
+	runtime.Callers(skip+3, pcs[:])
+	r := slog.NewRecord(Now(), level, msg, pcs[0])
+	r.Add(args...)
+	_ = l.Handler().Handle(ctx, r)
+}
+
+// logAttrsDepth is logDepth for typed attrs.
+func logAttrsDepth(l *slog.Logger, ctx context.Context, skip int, level slog.Level, msg string, attrs ...slog.Attr) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(skip+3, pcs[:])
+	r := slog.NewRecord(Now(), level, msg, pcs[0])
+	r.AddAttrs(attrs...)
+	_ = l.Handler().Handle(ctx, r)
+}
+
+// logPC logs through l with an explicit source pc.
+func logPC(l *slog.Logger, ctx context.Context, pc uintptr, level slog.Level, msg string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	r := slog.NewRecord(Now(), level, msg, pc)
+	r.Add(args...)
+	_ = l.Handler().Handle(ctx, r)
+}
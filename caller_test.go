@@ -0,0 +1,102 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// sources configures a JSON logger with AddSource, runs fn and returns the
+// source function of each record.
+func sources(t *testing.T, fn func()) []string {
+	t.Helper()
+	Reset()
+	t.Cleanup(Reset)
+
+	var buf bytes.Buffer
+	err := Configure(Config{
+		Level:      slog.LevelDebug,
+		FileWriter: nopWriteCloser{&buf},
+		JSONFile:   true,
+		AddSource:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure error: %v", err)
+	}
+
+	fn()
+
+	var out []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var rec struct {
+			Source slog.Source `json:"source"`
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid JSON: %v: %s", err, line)
+		}
+		out = append(out, rec.Source.Function)
+	}
+	return out
+}
+
+func assertSources(t *testing.T, got []string, want string, n int) {
+	t.Helper()
+	if len(got) != n {
+		t.Fatalf("expected %d records, got %d", n, len(got))
+	}
+	for _, fn := range got {
+		if !strings.Contains(fn, want) {
+			t.Fatalf("expected source in %s, got %s", want, fn)
+		}
+	}
+}
+
+func TestSource_Helpers(t *testing.T) {
+	ctx := context.Background()
+	got := sources(t, func() {
+		DebugContext(ctx, "d")
+		InfoContext(ctx, "i")
+		WarnContext(ctx, "w")
+		ErrorContext(ctx, "e")
+		InfoAttrs(ctx, "a")
+		ErrorErr("err", errors.New("x"))
+		ErrorErrContext(ctx, "err", nil)
+		Sampled(1).Info("s")
+	})
+	assertSources(t, got, "TestSource_Helpers", 8)
+}
+
+func TestSource_Timed(t *testing.T) {
+	got := sources(t, func() {
+		done := Timed(context.Background(), "job")
+		done()
+		done = TimedLevel(Logger(), slog.LevelWarn, context.Background(), "job")
+		done()
+	})
+	assertSources(t, got, "TestSource_Timed", 4)
+}
+
+func warnHelper(msg string) {
+	LogDepth(context.Background(), 1, slog.LevelWarn, msg)
+}
+
+func TestLogDepth(t *testing.T) {
+	got := sources(t, func() {
+		warnHelper("via helper")
+	})
+	assertSources(t, got, "TestLogDepth", 1)
+}
+
+func TestSource_RecoverAndLog(t *testing.T) {
+	got := sources(t, func() {
+		func() {
+			defer RecoverAndLog(context.Background())
+			panic("boom")
+		}()
+	})
+	assertSources(t, got, "TestSource_RecoverAndLog", 1)
+}
@@ -0,0 +1,111 @@
+package logx
+
+// chaos.go provides a failure-injecting writer for integration tests of
+// logging resilience (file failover, error callbacks, slow outputs).
+
+import (
+	"io"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ChaosSink is an io.WriteCloser that injects failures and latency. It can
+// be used as Config.FileWriter or returned from a RegisterSink factory.
+// Intended for tests only.
+type ChaosSink struct {
+	mu      sync.Mutex
+	dst     io.Writer
+	rate    float64
+	err     error
+	down    error
+	latency time.Duration
+	rand    func() float64
+
+	writes   atomic.Uint64
+	failures atomic.Uint64
+}
+
+// FailingSink returns a ChaosSink that fails a fraction rate (0..1) of
+// writes with err and discards the rest. Use To to keep successful writes.
+//
+//	sink := logx.FailingSink(0.1, logx.ErrSimulatedDiskFull).To(&buf)
+//	logx.Configure(logx.Config{FileWriter: sink, FileFallback: true})
+func FailingSink(rate float64, err error) *ChaosSink {
+	return &ChaosSink{dst: io.Discard, rate: rate, err: err, rand: rand.Float64}
+}
+
+// To sets the destination for writes that are not failed.
+func (c *ChaosSink) To(w io.Writer) *ChaosSink {
+	c.mu.Lock()
+	c.dst = w
+	c.mu.Unlock()
+	return c
+}
+
+// SetFailRate changes the random failure rate and error.
+func (c *ChaosSink) SetFailRate(rate float64, err error) {
+	c.mu.Lock()
+	c.rate, c.err = rate, err
+	c.mu.Unlock()
+}
+
+// SetDown fails every write with err until SetUp, simulating an outage
+// such as a network flap or a removed file.
+func (c *ChaosSink) SetDown(err error) {
+	c.mu.Lock()
+	c.down = err
+	c.mu.Unlock()
+}
+
+// SetUp ends an outage started with SetDown.
+func (c *ChaosSink) SetUp() {
+	c.SetDown(nil)
+}
+
+// SetLatency delays every write by d, simulating a slow disk or network.
+func (c *ChaosSink) SetLatency(d time.Duration) {
+	c.mu.Lock()
+	c.latency = d
+	c.mu.Unlock()
+}
+
+// SetRand replaces the random source (values in [0, 1)) so tests can make
+// failures deterministic.
+func (c *ChaosSink) SetRand(fn func() float64) {
+	c.mu.Lock()
+	c.rand = fn
+	c.mu.Unlock()
+}
+
+// Writes returns the number of Write calls.
+func (c *ChaosSink) Writes() uint64 { return c.writes.Load() }
+
+// Failures returns the number of writes that were failed.
+func (c *ChaosSink) Failures() uint64 { return c.failures.Load() }
+
+func (c *ChaosSink) Write(p []byte) (int, error) {
+	c.writes.Add(1)
+
+	c.mu.Lock()
+	dst, latency, err := c.dst, c.latency, c.down
+	if err == nil && c.rate > 0 && c.rand() < c.rate {
+		err = c.err
+	}
+	c.mu.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+	if err != nil {
+		c.failures.Add(1)
+		return 0, err
+	}
+	return dst.Write(p)
+}
+
+// Close is a no-op; the destination is owned by the caller.
+func (c *ChaosSink) Close() error {
+	return nil
+}
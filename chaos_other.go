@@ -0,0 +1,13 @@
+//go:build !plan9
+
+package logx
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// ErrSimulatedDiskFull is a ready-made disk-full error for FailingSink; it
+// matches syscall.ENOSPC with errors.Is (except on plan9, which has no
+// ENOSPC).
+var ErrSimulatedDiskFull = fmt.Errorf("logx chaos: %w", syscall.ENOSPC)
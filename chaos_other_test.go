@@ -0,0 +1,15 @@
+//go:build !plan9
+
+package logx
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+)
+
+func TestErrSimulatedDiskFull_IsENOSPC(t *testing.T) {
+	if _, err := FailingSink(1, ErrSimulatedDiskFull).Write([]byte("x")); !errors.Is(err, syscall.ENOSPC) {
+		t.Fatalf("expected ENOSPC, got %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+package logx
+
+import "errors"
+
+// ErrSimulatedDiskFull is a ready-made disk-full error for FailingSink.
+// plan9 has no syscall.ENOSPC, so it wraps nothing.
+var ErrSimulatedDiskFull = errors.New("logx chaos: no space left on device")
@@ -0,0 +1,75 @@
+package logx
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestFailingSink_DeterministicRate(t *testing.T) {
+	var buf bytes.Buffer
+	sink := FailingSink(0.5, ErrSimulatedDiskFull).To(&buf)
+	vals := []float64{0.1, 0.9, 0.4, 0.6}
+	i := 0
+	sink.SetRand(func() float64 { v := vals[i%len(vals)]; i++; return v })
+
+	var failed int
+	for range vals {
+		if _, err := sink.Write([]byte("x\n")); err != nil {
+			if !errors.Is(err, ErrSimulatedDiskFull) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			failed++
+		}
+	}
+
+	if failed != 2 || sink.Failures() != 2 || sink.Writes() != 4 {
+		t.Fatalf("expected 2/4 failures, got %d (%d/%d)", failed, sink.Failures(), sink.Writes())
+	}
+	if buf.String() != "x\nx\n" {
+		t.Fatalf("unexpected destination contents: %q", buf.String())
+	}
+}
+
+func TestFailingSink_OutageDrivesFailover(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	sink := FailingSink(0, nil).To(&buf)
+	var reported int
+	SetErrorHandler(func(error) { reported++ })
+
+	if err := Configure(Config{
+		Level:             slog.LevelInfo,
+		FileWriter:        sink,
+		FileFallback:      true,
+		FileRetryInterval: time.Hour,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	sink.SetDown(errors.New("connection reset"))
+	Info("during outage")
+	sink.SetUp()
+
+	if reported != 1 || sink.Failures() != 1 {
+		t.Fatalf("expected one reported failure, got %d (sink %d)", reported, sink.Failures())
+	}
+	if bytes.Contains(buf.Bytes(), []byte("during outage")) {
+		t.Fatalf("record should not reach the failed sink")
+	}
+}
+
+func TestFailingSink_Latency(t *testing.T) {
+	sink := FailingSink(0, nil)
+	sink.SetLatency(20 * time.Millisecond)
+
+	start := time.Now()
+	_, _ = sink.Write([]byte("x"))
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatalf("expected write to be delayed")
+	}
+}
@@ -0,0 +1,79 @@
+package logx
+
+// clock.go makes output reproducible for golden-file tests: SetClock fixes
+// record times and measured durations, SetRandSource fixes request IDs and
+// sampling decisions.
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	clock   atomic.Pointer[func() time.Time]
+	randSrc atomic.Pointer[lockedSource]
+)
+
+// SetClock makes logx use now for the time of every record, for durations
+// measured by the timing helpers and by httpx and sqlx, for NewRequestID
+// fallbacks, and for file rotation times and backup names (nil = time.Now).
+// A clock that advances by a fixed step per call
+// gives stable durations:
+//
+//	t := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+//	logx.SetClock(func() time.Time { t = t.Add(time.Millisecond); return t })
+func SetClock(now func() time.Time) {
+	if now == nil {
+		clock.Store(nil)
+		return
+	}
+	clock.Store(&now)
+}
+
+// Now returns the time of the clock set with SetClock.
+func Now() time.Time {
+	if c := clock.Load(); c != nil {
+		return (*c)()
+	}
+	return time.Now()
+}
+
+// Since returns the time elapsed since t on the clock set with SetClock.
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
+
+// lockedSource serializes a rand.Source, which need not be safe for
+// concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Uint64() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Uint64()
+}
+
+// SetRandSource makes NewRequestID, Sampled and the httpx middleware's
+// SampleRate draw from src, e.g. rand.NewPCG(1, 2) for the same sequence on
+// every run (nil = crypto/rand for IDs and math/rand/v2 for sampling).
+func SetRandSource(src rand.Source) {
+	if src == nil {
+		randSrc.Store(nil)
+		return
+	}
+	randSrc.Store(&lockedSource{src: src})
+}
+
+// RandFloat64 returns a value in [0, 1) from the source set with
+// SetRandSource.
+func RandFloat64() float64 {
+	if s := randSrc.Load(); s != nil {
+		return rand.New(s).Float64()
+	}
+	return rand.Float64()
+}
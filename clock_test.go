@@ -0,0 +1,57 @@
+package logx
+
+import (
+	"context"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func deterministicRun(t *testing.T) string {
+	t.Helper()
+	Reset()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time {
+		now = now.Add(time.Millisecond)
+		return now
+	})
+	SetRandSource(rand.NewPCG(1, 2))
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w, JSONFile: true}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+
+	done := Timed(context.Background(), "job", "request_id", NewRequestID())
+	done()
+	Sampled(0.5).Info("sampled")
+	Sampled(0.5).Info("sampled")
+	Sampled(0.5).Info("sampled")
+	return w.String()
+}
+
+func TestSetClockAndRandSource_Reproducible(t *testing.T) {
+	defer Reset()
+
+	first := deterministicRun(t)
+	second := deterministicRun(t)
+	if first != second {
+		t.Fatalf("output differs between runs:\n%s\n%s", first, second)
+	}
+	assertContains(t, first, `"time":"2024-01-01T00:00:00.`)
+	assertContains(t, first, `"duration":`)
+}
+
+func TestSetClock_NilRestoresTimeNow(t *testing.T) {
+	defer Reset()
+
+	fixed := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return fixed })
+	if !Now().Equal(fixed) || Since(fixed) != 0 {
+		t.Fatalf("expected the fixed clock")
+	}
+	SetClock(nil)
+	if Now().Year() == 2000 {
+		t.Fatalf("expected time.Now after SetClock(nil)")
+	}
+}
@@ -0,0 +1,163 @@
+package logx
+
+// cloudwatch.go implements SchemaCloudWatch: AWS Lambda's JSON log format
+// and CloudWatch Embedded Metric Format (EMF) blocks, so metrics can be
+// published by logging them.
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// metricValue is the value of an attr created with Metric.
+type metricValue struct {
+	value float64
+	unit  string
+}
+
+// LogValue writes the plain number outside SchemaCloudWatch.
+func (m metricValue) LogValue() slog.Value { return slog.Float64Value(m.value) }
+
+// Metric returns an attr that SchemaCloudWatch outputs publish as a
+// CloudWatch metric named key through an EMF block; unit is a CloudWatch
+// unit such as "Milliseconds", "Count" or "Bytes" ("" = "None"). Other
+// outputs write the number. Only top-level metric attrs are published.
+func Metric(key string, value float64, unit string) slog.Attr {
+	if unit == "" {
+		unit = "None"
+	}
+	return slog.Any(key, metricValue{value: value, unit: unit})
+}
+
+func cloudWatchReplacer(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) != 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		a.Key = "timestamp"
+		if a.Value.Kind() == slog.KindTime {
+			a.Value = slog.TimeValue(a.Value.Time().UTC())
+		}
+	case slog.LevelKey:
+		if l, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(cloudWatchLevel(l))
+		}
+	case slog.MessageKey:
+		a.Key = "message"
+	case "request_id":
+		a.Key = "requestId"
+	}
+	return a
+}
+
+// cloudWatchLevel maps levels to Lambda's log level keywords.
+func cloudWatchLevel(l slog.Level) string {
+	switch {
+	case l >= slog.LevelError+4:
+		return "FATAL"
+	case l >= slog.LevelError:
+		return "ERROR"
+	case l >= slog.LevelWarn:
+		return "WARN"
+	case l >= slog.LevelInfo:
+		return "INFO"
+	case l >= slog.LevelDebug:
+		return "DEBUG"
+	default:
+		return "TRACE"
+	}
+}
+
+// emfDirective is one entry of "_aws.CloudWatchMetrics".
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+// emfHandler adds an "_aws" EMF block to records with top-level Metric
+// attrs. Dimensions are the configured keys present as top-level attrs.
+type emfHandler struct {
+	next       slog.Handler
+	namespace  string
+	dimensions []string
+	// present lists top-level keys added with WithAttrs
+	present []string
+	grouped bool
+}
+
+func newEMFHandler(next slog.Handler, cfg Config) slog.Handler {
+	ns := cfg.EMFNamespace
+	if ns == "" {
+		ns = "logx"
+	}
+	return &emfHandler{next: next, namespace: ns, dimensions: cfg.EMFDimensions}
+}
+
+func (h *emfHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *emfHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.grouped {
+		return h.next.Handle(ctx, r)
+	}
+	var metrics []emfMetric
+	keys := slices.Clip(h.present)
+	r.Attrs(func(a slog.Attr) bool {
+		keys = append(keys, a.Key)
+		if m, ok := a.Value.Any().(metricValue); ok && a.Value.Kind() == slog.KindLogValuer {
+			metrics = append(metrics, emfMetric{Name: a.Key, Unit: m.unit})
+		}
+		return true
+	})
+	if len(metrics) == 0 {
+		return h.next.Handle(ctx, r)
+	}
+
+	var dims []string
+	for _, d := range h.dimensions {
+		if slices.Contains(keys, d) {
+			dims = append(dims, d)
+		}
+	}
+	nr := r.Clone()
+	nr.AddAttrs(slog.Group("_aws",
+		slog.Int64("Timestamp", r.Time.UnixMilli()),
+		slog.Any("CloudWatchMetrics", []emfDirective{{
+			Namespace:  h.namespace,
+			Dimensions: [][]string{append([]string{}, dims...)},
+			Metrics:    metrics,
+		}}),
+	))
+	return h.next.Handle(ctx, nr)
+}
+
+func (h *emfHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.next = h.next.WithAttrs(attrs)
+	if !h.grouped {
+		c.present = slices.Clip(h.present)
+		for _, a := range attrs {
+			c.present = append(c.present, a.Key)
+		}
+	}
+	return &c
+}
+
+func (h *emfHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.next = h.next.WithGroup(name)
+	c.grouped = true
+	return &c
+}
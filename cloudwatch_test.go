@@ -0,0 +1,91 @@
+package logx
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSchemaCloudWatch_LambdaFields(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w, JSONFile: true, Level: slog.LevelDebug - 4, Schema: SchemaCloudWatch}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Logger().Log(t.Context(), slog.LevelDebug-4, "trace")
+	Logger().With("request_id", "r1").Warn("slow")
+	Logger().Log(t.Context(), slog.LevelError+4, "fatal")
+
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	want := []string{"TRACE", "WARN", "FATAL"}
+	for i, line := range lines {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if m["level"] != want[i] || m["message"] == nil || m["timestamp"] == nil {
+			t.Fatalf("unexpected entry: %s", line)
+		}
+		if _, ok := m["_aws"]; ok {
+			t.Fatalf("unexpected EMF block without metrics: %s", line)
+		}
+	}
+	assertContains(t, lines[1], `"requestId":"r1"`)
+}
+
+func TestSchemaCloudWatch_EMFBlock(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	err := Configure(Config{
+		FileWriter:    w,
+		JSONFile:      true,
+		Schema:        SchemaCloudWatch,
+		EMFNamespace:  "checkout",
+		EMFDimensions: []string{"service", "region"},
+	})
+	if err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Logger().With("service", "api").Info("charged", Metric("latency", 12, "Milliseconds"), Metric("items", 3, ""))
+
+	var m struct {
+		Latency float64 `json:"latency"`
+		AWS     struct {
+			Timestamp         int64
+			CloudWatchMetrics []emfDirective
+		} `json:"_aws"`
+	}
+	if err := json.Unmarshal([]byte(w.String()), &m); err != nil {
+		t.Fatalf("unmarshal %s: %v", w.String(), err)
+	}
+	if m.Latency != 12 || m.AWS.Timestamp == 0 || len(m.AWS.CloudWatchMetrics) != 1 {
+		t.Fatalf("unexpected entry: %s", w.String())
+	}
+	d := m.AWS.CloudWatchMetrics[0]
+	if d.Namespace != "checkout" || len(d.Dimensions) != 1 || len(d.Dimensions[0]) != 1 || d.Dimensions[0][0] != "service" {
+		t.Fatalf("unexpected directive: %+v", d)
+	}
+	if len(d.Metrics) != 2 || d.Metrics[0] != (emfMetric{"latency", "Milliseconds"}) || d.Metrics[1].Unit != "None" {
+		t.Fatalf("unexpected metrics: %+v", d.Metrics)
+	}
+}
+
+func TestMetric_PlainNumberElsewhere(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w, JSONFile: true}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Info("charged", Metric("latency", 12.5, "Milliseconds"))
+	assertContains(t, w.String(), `"latency":12.5`)
+	if strings.Contains(w.String(), "_aws") {
+		t.Fatalf("unexpected EMF block: %s", w.String())
+	}
+}
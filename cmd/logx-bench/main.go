@@ -0,0 +1,210 @@
+// Command logx-bench drives a logx pipeline at a target rate and reports the
+// sustained records/sec, call latency percentiles and failed writes, to help
+// size outputs and rotation settings for a given machine.
+//
+//	logx-bench -file /var/tmp/bench.log -json -rate 50000 -duration 10s
+//	logx-bench -file /var/tmp/bench.log -max-size 10485760 -backups 3
+//	logx-bench -latency 2ms -fail-rate 0.01   # simulated slow, flaky sink
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rannday/logx"
+)
+
+// maxSamples bounds the latencies kept per worker for percentiles.
+const maxSamples = 100_000
+
+type options struct {
+	rate     int
+	duration time.Duration
+	workers  int
+	attrs    int
+	console  bool
+	file     string
+	json     bool
+	maxSize  int
+	backups  int
+	latency  time.Duration
+	failRate float64
+	keep     bool
+}
+
+func main() {
+	var o options
+	flag.IntVar(&o.rate, "rate", 0, "target records/sec across all workers (0 = as fast as possible)")
+	flag.DurationVar(&o.duration, "duration", 5*time.Second, "how long to run")
+	flag.IntVar(&o.workers, "workers", 4, "concurrent logging goroutines")
+	flag.IntVar(&o.attrs, "attrs", 5, "attrs per record")
+	flag.BoolVar(&o.console, "console", false, "also log to stderr")
+	flag.StringVar(&o.file, "file", "", "log file path (default: a temporary file)")
+	flag.BoolVar(&o.json, "json", false, "write the file as JSON")
+	flag.IntVar(&o.maxSize, "max-size", 0, "rotate the file at this many bytes (0 = disabled)")
+	flag.IntVar(&o.backups, "backups", 3, "rotated files to keep")
+	flag.DurationVar(&o.latency, "latency", 0, "simulated latency per file write")
+	flag.Float64Var(&o.failRate, "fail-rate", 0, "simulated fraction of failed file writes (0..1)")
+	flag.BoolVar(&o.keep, "keep", false, "keep the temporary log file")
+	flag.Parse()
+
+	if err := run(o, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "logx-bench:", err)
+		os.Exit(1)
+	}
+}
+
+func run(o options, out io.Writer) error {
+	if o.workers < 1 {
+		o.workers = 1
+	}
+
+	cfg := logx.Config{
+		Level:            slog.LevelInfo,
+		Console:          o.console,
+		JSONFile:         o.json,
+		FileMaxSizeBytes: o.maxSize,
+		FileMaxBackups:   o.backups,
+	}
+
+	path := o.file
+	if path == "" {
+		dir, err := os.MkdirTemp("", "logx-bench-")
+		if err != nil {
+			return err
+		}
+		if !o.keep {
+			defer os.RemoveAll(dir)
+		}
+		path = filepath.Join(dir, "bench.log")
+	}
+
+	if o.latency > 0 || o.failRate > 0 {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		sink := logx.FailingSink(o.failRate, logx.ErrSimulatedDiskFull).To(f)
+		sink.SetLatency(o.latency)
+		cfg.FileWriter = sink
+	} else {
+		cfg.FilePath = path
+	}
+
+	if err := logx.Configure(cfg); err != nil {
+		return err
+	}
+	defer logx.Shutdown()
+
+	errorsBefore := logx.WriteErrors()
+	res := drive(o)
+	res.failed = logx.WriteErrors() - errorsBefore
+
+	res.report(out, o)
+	return nil
+}
+
+type result struct {
+	records   int
+	elapsed   time.Duration
+	latencies []time.Duration
+	failed    uint64
+}
+
+// drive runs the workers and merges their results.
+func drive(o options) result {
+	args := make([]any, 0, 2*o.attrs)
+	for i := range o.attrs {
+		args = append(args, fmt.Sprintf("k%d", i), i)
+	}
+
+	var interval time.Duration
+	if o.rate > 0 {
+		interval = time.Duration(int64(time.Second) * int64(o.workers) / int64(o.rate))
+	}
+
+	var (
+		mu  sync.Mutex
+		res result
+		wg  sync.WaitGroup
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), o.duration)
+	defer cancel()
+
+	start := time.Now()
+	for w := range o.workers {
+		wg.Go(func() {
+			r := worker(ctx, w, interval, args)
+			mu.Lock()
+			res.records += r.records
+			res.latencies = append(res.latencies, r.latencies...)
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	res.elapsed = time.Since(start)
+	return res
+}
+
+func worker(ctx context.Context, id int, interval time.Duration, args []any) result {
+	var r result
+	next := time.Now()
+	for ctx.Err() == nil {
+		if interval > 0 {
+			if d := time.Until(next); d > 0 {
+				time.Sleep(d)
+			}
+			next = next.Add(interval)
+		}
+
+		t := time.Now()
+		logx.Info("bench record", append(args, "worker", id, "seq", r.records)...)
+		lat := time.Since(t)
+
+		r.records++
+		// reservoir sampling keeps percentiles bounded in memory
+		if len(r.latencies) < maxSamples {
+			r.latencies = append(r.latencies, lat)
+		} else if j := rand.IntN(r.records); j < maxSamples {
+			r.latencies[j] = lat
+		}
+	}
+	return r
+}
+
+func (r result) report(w io.Writer, o options) {
+	slices.Sort(r.latencies)
+	rate := float64(r.records) / r.elapsed.Seconds()
+
+	fmt.Fprintf(w, "records:     %d in %s\n", r.records, r.elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "throughput:  %.0f records/sec", rate)
+	if o.rate > 0 {
+		fmt.Fprintf(w, " (target %d)", o.rate)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "latency:     p50 %s  p99 %s  max %s\n",
+		percentile(r.latencies, 0.50), percentile(r.latencies, 0.99), percentile(r.latencies, 1))
+	fmt.Fprintf(w, "failed:      %d writes\n", r.failed)
+	if o.rate > 0 && rate < 0.95*float64(o.rate) {
+		fmt.Fprintln(w, "target rate not sustained")
+	}
+}
+
+// percentile returns the q-th (0..1) value of sorted.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(q * float64(len(sorted)-1))
+	return sorted[i]
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	lat := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if got := percentile(lat, 0.5); got != 5 {
+		t.Fatalf("p50: expected 5, got %d", got)
+	}
+	if got := percentile(lat, 1); got != 10 {
+		t.Fatalf("max: expected 10, got %d", got)
+	}
+	if got := percentile(nil, 0.99); got != 0 {
+		t.Fatalf("empty: expected 0, got %d", got)
+	}
+}
+
+func TestRun_Report(t *testing.T) {
+	var out bytes.Buffer
+	err := run(options{
+		rate:     2000,
+		duration: 100 * time.Millisecond,
+		workers:  2,
+		attrs:    2,
+		file:     filepath.Join(t.TempDir(), "bench.log"),
+		failRate: 1,
+	}, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"records:", "throughput:", "(target 2000)", "p99"} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("expected %q in report:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "failed:      0 writes") {
+		t.Fatalf("expected failed writes to be counted:\n%s", out.String())
+	}
+}
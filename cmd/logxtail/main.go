@@ -0,0 +1,369 @@
+// Command logxtail follows logx log files (JSON or text) and prints records
+// one per line with colored levels, or pretty-printed with one attr per
+// line. Records can be filtered by level, attr value and message, and the
+// filters can be changed while tailing by typing commands:
+//
+//	logxtail -level warn -where request_id=4bf92f app.log
+//	logxtail -pretty -n 100 app.log worker.log
+//	kubectl logs -f pod | logxtail -level error
+//
+// Commands (while following files, one per line on stdin):
+//
+//	level warn           show WARN and above
+//	where user=42        also require an attr value (repeatable)
+//	grep timeout         require a message substring
+//	pretty on|off        toggle pretty-printing
+//	clear                remove where and grep filters
+//	help                 list the commands
+//
+// Records are parsed and formatted by package pretty.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rannday/logx"
+	"github.com/rannday/logx/pretty"
+)
+
+// pollInterval is how often followed files are checked for new lines.
+var pollInterval = 250 * time.Millisecond
+
+// maxLineBytes bounds a single log line (stack traces included).
+const maxLineBytes = 1024 * 1024
+
+type options struct {
+	lines  int
+	follow bool
+	level  string
+	where  []string
+	grep   string
+	pretty bool
+	color  string
+	files  []string
+}
+
+type stringsFlag []string
+
+func (s *stringsFlag) String() string     { return strings.Join(*s, ",") }
+func (s *stringsFlag) Set(v string) error { *s = append(*s, v); return nil }
+
+func main() {
+	var o options
+	flag.IntVar(&o.lines, "n", 10, "print the last n records of each file first (-1 = all)")
+	flag.BoolVar(&o.follow, "f", true, "keep following the files for new records")
+	flag.StringVar(&o.level, "level", "", "minimum level (trace, debug, info, warn, error)")
+	flag.Var((*stringsFlag)(&o.where), "where", "require an attr value, key=value (repeatable)")
+	flag.StringVar(&o.grep, "grep", "", "require a message substring")
+	flag.BoolVar(&o.pretty, "pretty", false, "print each attr on its own line")
+	flag.StringVar(&o.color, "color", "auto", "color output: auto, always or never")
+	flag.Parse()
+	o.files = flag.Args()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, o, os.Stdout, os.Stdin); err != nil {
+		fmt.Fprintln(os.Stderr, "logxtail:", err)
+		os.Exit(1)
+	}
+}
+
+// run prints the files of o, or stdin when there are none, to out. While
+// following files it reads commands from stdin until ctx is done.
+func run(ctx context.Context, o options, out io.Writer, stdin io.Reader) error {
+	f := &filter{multiline: o.pretty}
+	if o.level != "" {
+		if err := f.apply("level " + o.level); err != nil {
+			return err
+		}
+	}
+	for _, w := range o.where {
+		if err := f.apply("where " + w); err != nil {
+			return err
+		}
+	}
+	f.grep = o.grep
+
+	p := &printer{out: out, filter: f, color: useColor(o.color, out), names: len(o.files) > 1}
+	if len(o.files) == 0 {
+		return p.copy(stdin)
+	}
+
+	if !o.follow {
+		for _, name := range o.files {
+			if err := tailFile(ctx, p, name, o.lines, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	go readCommands(stdin, p)
+	var wg sync.WaitGroup
+	errs := make([]error, len(o.files))
+	for i, name := range o.files {
+		wg.Go(func() { errs[i] = tailFile(ctx, p, name, o.lines, true) })
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// useColor resolves the -color flag for out.
+func useColor(mode string, out io.Writer) bool {
+	switch mode {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// filter holds the live filters; commands change it while files are tailed.
+type filter struct {
+	mu        sync.Mutex
+	minLevel  *slog.Level
+	where     []pretty.Field
+	grep      string
+	multiline bool
+}
+
+const help = `commands: level <lvl|all>, where <key=value>, grep <text>, pretty on|off, clear, help`
+
+// apply runs a command such as "level warn".
+func (f *filter) apply(cmd string) error {
+	name, arg, _ := strings.Cut(strings.TrimSpace(cmd), " ")
+	arg = strings.TrimSpace(arg)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch name {
+	case "level":
+		if arg == "all" || arg == "" {
+			f.minLevel = nil
+			return nil
+		}
+		l, err := logx.ParseLevel(arg)
+		if err != nil {
+			return err
+		}
+		f.minLevel = &l
+	case "where":
+		k, v, ok := strings.Cut(arg, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("where: expected key=value, got %q", arg)
+		}
+		f.where = append(f.where, pretty.Field{Key: k, Value: v})
+	case "grep":
+		f.grep = arg
+	case "pretty":
+		f.multiline = arg != "off"
+	case "clear":
+		f.where, f.grep = nil, ""
+	default:
+		return fmt.Errorf("unknown command %q; %s", name, help)
+	}
+	return nil
+}
+
+// match reports whether r passes the filters and whether to print it with
+// one attr per line.
+func (f *filter) match(r pretty.Record) (ok, multiline bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.minLevel != nil && r.HasLevel && r.LevelValue < *f.minLevel {
+		return false, false
+	}
+	if f.grep != "" && !strings.Contains(r.Message, f.grep) {
+		return false, false
+	}
+	for _, w := range f.where {
+		if v, ok := r.Attr(w.Key); !ok || v != w.Value {
+			return false, false
+		}
+	}
+	return true, f.multiline
+}
+
+// readCommands applies the commands read from r until it is closed.
+func readCommands(r io.Reader, p *printer) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		cmd := strings.TrimSpace(sc.Text())
+		if cmd == "" {
+			continue
+		}
+		msg := "ok: " + cmd
+		if cmd == "help" {
+			msg = help
+		} else if err := p.filter.apply(cmd); err != nil {
+			msg = err.Error()
+		}
+		p.note(msg)
+	}
+}
+
+// tailFile prints the last n records of the file and, with follow, the
+// records appended later, reopening the file when it is rotated or
+// truncated.
+func tailFile(ctx context.Context, p *printer, name string, n int, follow bool) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	rd := bufio.NewReaderSize(f, 64*1024)
+	last, partial := lastLines(rd, n)
+	for _, line := range last {
+		p.print(name, line)
+	}
+	if !follow {
+		if len(partial) > 0 && n != 0 {
+			p.print(name, string(partial))
+		}
+		return nil
+	}
+
+	for {
+		line, err := rd.ReadBytes('\n')
+		partial = append(partial, line...)
+		if err == nil {
+			if len(partial) <= maxLineBytes {
+				p.print(name, strings.TrimRight(string(partial), "\r\n"))
+			}
+			partial = partial[:0]
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(pollInterval):
+		}
+		if replaced(f, name) {
+			next, err := os.Open(name)
+			if err != nil {
+				continue // between rename and create
+			}
+			// records written to the old file before it was rotated
+			for {
+				line, err := rd.ReadBytes('\n')
+				partial = append(partial, line...)
+				if err != nil {
+					break
+				}
+				p.print(name, strings.TrimRight(string(partial), "\r\n"))
+				partial = partial[:0]
+			}
+			_ = f.Close()
+			f, partial = next, partial[:0]
+			rd.Reset(f)
+		}
+	}
+}
+
+// lastLines reads rd to the end and returns its last n complete lines (all
+// with n < 0) and the partial line after them, which is still being written.
+func lastLines(rd *bufio.Reader, n int) ([]string, []byte) {
+	var ring []string
+	for {
+		line, err := rd.ReadBytes('\n')
+		if err != nil {
+			return ring, line
+		}
+		if n != 0 {
+			ring = append(ring, strings.TrimRight(string(line), "\r\n"))
+			if n > 0 && len(ring) > n {
+				ring = slices.Delete(ring, 0, 1)
+			}
+		}
+	}
+}
+
+// replaced reports whether name now refers to another file than f, or f
+// was truncated.
+func replaced(f *os.File, name string) bool {
+	cur, err := f.Stat()
+	if err != nil {
+		return true
+	}
+	fi, err := os.Stat(name)
+	if err != nil {
+		return false
+	}
+	if !os.SameFile(cur, fi) {
+		return true
+	}
+	pos, err := f.Seek(0, io.SeekCurrent)
+	return err == nil && fi.Size() < pos
+}
+
+// printer writes records and command replies without interleaving them.
+type printer struct {
+	mu     sync.Mutex
+	out    io.Writer
+	filter *filter
+	color  bool
+	// names prefixes records with their file name
+	names bool
+}
+
+// copy prints every line of r.
+func (p *printer) copy(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	for sc.Scan() {
+		p.print("", sc.Text())
+	}
+	return sc.Err()
+}
+
+func (p *printer) print(name, line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	r := pretty.Parse(line)
+	ok, multiline := p.filter.match(r)
+	if !ok {
+		return
+	}
+	prefix := ""
+	if p.names && name != "" {
+		prefix = filepath.Base(name) + ": "
+	}
+	s := pretty.Format(r, pretty.Options{Color: p.color, Multiline: multiline})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintln(p.out, prefix+s)
+}
+
+func (p *printer) note(msg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintln(p.out, "-- "+msg)
+}
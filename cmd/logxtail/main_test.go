@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe for the tail goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func waitFor(t *testing.T, out *syncBuffer, want string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(out.String(), want) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %q in output:\n%s", want, out.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRun_FollowsRotationWithLiveFilters(t *testing.T) {
+	pollInterval = 5 * time.Millisecond
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendLine := func(line string) {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = f.WriteString(line + "\n")
+		_ = f.Close()
+	}
+	appendLine(`level=INFO msg=old`)
+	appendLine(`level=DEBUG msg=skipped`)
+	appendLine(`level=WARN msg=kept user=1`)
+
+	cmdR, cmdW := io.Pipe()
+	defer cmdW.Close()
+	out := &syncBuffer{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx, options{lines: 2, follow: true, level: "info", color: "never", files: []string{path}}, out, cmdR)
+	}()
+
+	waitFor(t, out, "WARN  kept user=1")
+	if s := out.String(); strings.Contains(s, "old") || strings.Contains(s, "skipped") {
+		t.Fatalf("expected only the last 2 lines, filtered by level:\n%s", s)
+	}
+
+	_, _ = io.WriteString(cmdW, "where user=2\n")
+	waitFor(t, out, "-- ok: where user=2")
+	appendLine(`level=ERROR msg=other user=1`)
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendLine(`level=ERROR msg=rotated user=2`)
+	waitFor(t, out, "ERROR rotated user=2")
+	if strings.Contains(out.String(), "other") {
+		t.Fatalf("where filter not applied:\n%s", out.String())
+	}
+
+	_, _ = io.WriteString(cmdW, "level loud\n")
+	waitFor(t, out, `-- logx: invalid level: "loud"`)
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+func TestRun_Stdin(t *testing.T) {
+	var out bytes.Buffer
+	in := strings.NewReader("{\"level\":\"INFO\",\"msg\":\"a\"}\n{\"level\":\"ERROR\",\"msg\":\"timeout talking to db\"}\n")
+	if err := run(context.Background(), options{grep: "timeout", color: "never"}, &out, in); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "ERROR timeout talking to db\n" {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+}
@@ -0,0 +1,388 @@
+package logx
+
+// color.go colors console output by record level. The handler that writes a
+// record tells the writer its level and the level field as rendered after
+// ReplaceAttr, level mappers and schemas, so coloring works for text and
+// JSON and is not fooled by messages that contain "level=ERROR".
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorGreen  = "\033[32m"
+	colorGray   = "\033[90m"
+	colorDim    = "\033[2m"
+	// colorHighlight marks Config.ConsoleHighlightKeys.
+	colorHighlight = "\033[1;36m"
+)
+
+// ColorMode selects what a color console colors by severity.
+type ColorMode int
+
+const (
+	// ColorLevel colors the level only.
+	ColorLevel ColorMode = iota
+	// ColorMessage colors the level and the message.
+	ColorMessage
+	// ColorLine colors the whole line.
+	ColorLine
+)
+
+// String returns "level", "message" or "line".
+func (m ColorMode) String() string {
+	switch m {
+	case ColorMessage:
+		return "message"
+	case ColorLine:
+		return "line"
+	default:
+		return "level"
+	}
+}
+
+// consoleStyle is how a color console renders records.
+type consoleStyle struct {
+	icons     bool
+	mode      ColorMode
+	highlight []string
+}
+
+// levelStyle returns the color and icon glyph for level.
+func levelStyle(level slog.Level) (color, glyph string) {
+	switch {
+	case level >= slog.LevelError:
+		return colorRed, "✖"
+	case level >= slog.LevelWarn:
+		return colorYellow, "⚠"
+	case level >= slog.LevelInfo:
+		return colorGreen, "ℹ"
+	default:
+		return colorGray, "·"
+	}
+}
+
+// newConsoleHandler returns a text or JSON handler writing to w that colors
+// each record by its level when style is not nil. Icons apply to text only.
+func newConsoleHandler(w io.Writer, jsonFormat bool, style *consoleStyle, opts *slog.HandlerOptions) slog.Handler {
+	if style == nil {
+		if jsonFormat {
+			return slog.NewJSONHandler(w, opts)
+		}
+		return slog.NewTextHandler(w, opts)
+	}
+	cw := &colorWriter{w: w, json: jsonFormat, style: *style}
+	cw.style.icons = style.icons && !jsonFormat
+	o := *opts
+	o.ReplaceAttr = cw.replaceAttr(o.ReplaceAttr)
+	return &colorHandler{next: newConsoleHandler(cw, jsonFormat, nil, &o), cw: cw}
+}
+
+// colorWriter colors the record being written. A colorHandler holds mu for
+// the whole record, so level, field and msg describe the bytes passed to
+// Write.
+type colorWriter struct {
+	w io.Writer
+	// json renders fields as JSON instead of key=value.
+	json bool
+	// style.icons replaces the level field with a compact glyph and dims
+	// what precedes it (the timestamp).
+	style consoleStyle
+
+	mu    sync.Mutex
+	level slog.Level
+	// field and msg are the level and message fields as the handler
+	// rendered them, set by the ReplaceAttr of replaceAttr for the first
+	// such attr of the record
+	field atomic.Pointer[[]byte]
+	msg   atomic.Pointer[[]byte]
+}
+
+// replaceAttr wraps next so that the rendered level field is recorded.
+func (cw *colorWriter) replaceAttr(next func([]string, slog.Attr) slog.Attr) func([]string, slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		var field *atomic.Pointer[[]byte]
+		if len(groups) == 0 {
+			switch a.Key {
+			case slog.LevelKey:
+				field = &cw.field
+			case slog.MessageKey:
+				field = &cw.msg
+			}
+		}
+		if next != nil {
+			a = next(groups, a)
+		}
+		if field != nil && a.Key != "" {
+			// built-ins come before any attr with the same key
+			f := cw.render(a)
+			field.CompareAndSwap(nil, &f)
+		}
+		return a
+	}
+}
+
+// render formats a the way the text or JSON handler writes it.
+func (cw *colorWriter) render(a slog.Attr) []byte {
+	v := a.Value.Resolve()
+	if cw.json {
+		k, _ := json.Marshal(a.Key)
+		val, err := json.Marshal(v.Any())
+		if err != nil {
+			return nil
+		}
+		return append(append(k, ':'), val...)
+	}
+	key, val := a.Key, v.String()
+	if needsQuoting(key) {
+		key = strconv.Quote(key)
+	}
+	if needsQuoting(val) {
+		val = strconv.Quote(val)
+	}
+	return []byte(key + "=" + val)
+}
+
+// needsQuoting mirrors slog.TextHandler's quoting rule.
+func needsQuoting(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r == '=' || r == '"' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// colorEdit replaces p[start:end] with repl.
+type colorEdit struct {
+	start, end int
+	repl       []byte
+}
+
+func (cw *colorWriter) Write(p []byte) (int, error) {
+	line := bytes.TrimSuffix(p, []byte("\n"))
+	color, glyph := levelStyle(cw.level)
+	lineMode := cw.style.mode == ColorLine
+	// text after a highlight goes back to the line color
+	after := colorReset
+	if lineMode {
+		after += color
+	}
+
+	var edits []colorEdit
+	level := -1
+	if f := cw.field.Load(); f != nil && len(*f) > 0 {
+		if i := bytes.Index(line, *f); i >= 0 {
+			level = i
+			switch {
+			case cw.style.icons && lineMode:
+				edits = append(edits, colorEdit{i, i + len(*f), []byte(glyph)})
+			case cw.style.icons:
+				edits = append(edits, colorEdit{i, i + len(*f), []byte(color + glyph + colorReset)})
+			case !lineMode:
+				edits = append(edits, colorEdit{i, i + len(*f), []byte(color + string(*f) + colorReset)})
+			}
+		}
+	}
+	if f := cw.msg.Load(); cw.style.mode == ColorMessage && f != nil && len(*f) > 0 {
+		if i := bytes.Index(line[max(level, 0):], *f); i >= 0 {
+			i += max(level, 0)
+			edits = append(edits, colorEdit{i, i + len(*f), []byte(color + string(*f) + colorReset)})
+		}
+	}
+	if len(cw.style.highlight) > 0 {
+		scan := scanTextFields
+		if cw.json {
+			scan = scanJSONFields
+		}
+		scan(line, func(key string, start, end int) {
+			if !cw.highlighted(key) || overlaps(edits, start, end) {
+				return
+			}
+			edits = append(edits, colorEdit{start, end, []byte(colorHighlight + string(line[start:end]) + after)})
+		})
+	}
+	dim := cw.style.icons && !lineMode && level > 0
+	if len(edits) == 0 && !dim && !lineMode {
+		return cw.w.Write(p)
+	}
+	slices.SortFunc(edits, func(a, b colorEdit) int { return a.start - b.start })
+
+	out := make([]byte, 0, len(p)+64)
+	if lineMode {
+		out = append(out, color...)
+	}
+	pos := 0
+	if dim {
+		// dim the leading "time=... " metadata
+		head := line[:level]
+		if ts := bytes.TrimRight(head, " "); len(ts) > 0 {
+			out = append(out, colorDim...)
+			out = append(out, ts...)
+			out = append(out, colorReset...)
+			pos = len(ts)
+		}
+	}
+	for _, e := range edits {
+		out = append(out, line[pos:e.start]...)
+		out = append(out, e.repl...)
+		pos = e.end
+	}
+	out = append(out, line[pos:]...)
+	if lineMode {
+		out = append(out, colorReset...)
+	}
+	out = append(out, p[len(line):]...)
+	if _, err := cw.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// highlighted reports whether key, or its last dotted segment for grouped
+// text keys, is one of the highlighted keys.
+func (cw *colorWriter) highlighted(key string) bool {
+	for _, h := range cw.style.highlight {
+		if key == h || strings.HasSuffix(key, "."+h) {
+			return true
+		}
+	}
+	return false
+}
+
+func overlaps(edits []colorEdit, start, end int) bool {
+	for _, e := range edits {
+		if start < e.end && e.start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// scanTextFields calls fn with the key and bounds of every key=value field
+// of a slog text line.
+func scanTextFields(line []byte, fn func(key string, start, end int)) {
+	i := 0
+	for i < len(line) {
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+		start := i
+		i = textToken(line, i, '=')
+		key := string(line[start:i])
+		if k, err := strconv.Unquote(key); err == nil {
+			key = k
+		}
+		if i >= len(line) || line[i] != '=' {
+			return
+		}
+		i = textToken(line, i+1, ' ')
+		fn(key, start, i)
+	}
+}
+
+// textToken returns the end of the token starting at i: a quoted string,
+// or the bytes up to stop or a space.
+func textToken(line []byte, i int, stop byte) int {
+	if i < len(line) && line[i] == '"' {
+		for i++; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return len(line)
+	}
+	for i < len(line) && line[i] != stop && line[i] != ' ' {
+		i++
+	}
+	return i
+}
+
+// scanJSONFields calls fn with the key and bounds of every field with a
+// scalar value in a JSON object, at any depth.
+func scanJSONFields(line []byte, fn func(key string, start, end int)) {
+	type frame struct{ object, keyNext bool }
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var stack []frame
+	key, keyStart := "", 0
+	for {
+		before := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return
+		}
+		if len(stack) > 0 && stack[len(stack)-1].keyNext {
+			if k, ok := tok.(string); ok {
+				key, keyStart = k, before+bytes.IndexByte(line[before:], '"')
+				stack[len(stack)-1].keyNext = false
+				continue
+			}
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			// nested values are scanned on their own
+			key = ""
+			stack = append(stack, frame{object: tok == json.Delim('{'), keyNext: tok == json.Delim('{')})
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+		default:
+			if key != "" {
+				fn(key, keyStart, int(dec.InputOffset()))
+				key = ""
+			}
+		}
+		if n := len(stack); n > 0 && stack[n-1].object {
+			stack[n-1].keyNext = true
+		}
+	}
+}
+
+// colorHandler tells its colorWriter which record the handler below is
+// writing.
+type colorHandler struct {
+	next slog.Handler
+	cw   *colorWriter
+}
+
+func (h *colorHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *colorHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.cw.mu.Lock()
+	defer h.cw.mu.Unlock()
+	h.cw.level = r.Level
+	h.cw.field.Store(nil)
+	h.cw.msg.Store(nil)
+	return h.next.Handle(ctx, r)
+}
+
+func (h *colorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &colorHandler{next: h.next.WithAttrs(attrs), cw: h.cw}
+}
+
+func (h *colorHandler) WithGroup(name string) slog.Handler {
+	return &colorHandler{next: h.next.WithGroup(name), cw: h.cw}
+}
@@ -0,0 +1,112 @@
+package logx
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+// noTime drops the time so outputs are stable.
+func noTime(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
+
+func TestConsoleColor_TextUsesRecordLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(newConsoleHandler(&buf, false, &consoleStyle{}, &slog.HandlerOptions{ReplaceAttr: noTime}))
+
+	l.Info("saw level=ERROR upstream")
+	want := colorGreen + "level=INFO" + colorReset + ` msg="saw level=ERROR upstream"` + "\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n got %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestConsoleColor_JSONAndRenamedLevels(t *testing.T) {
+	var buf bytes.Buffer
+	opts := withLevelMapper(&slog.HandlerOptions{ReplaceAttr: chainReplaceAttr(noTime, func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.LevelKey {
+			a.Key = "severity"
+		}
+		return a
+	})}, GCPSeverity)
+	l := slog.New(newConsoleHandler(&buf, true, &consoleStyle{icons: true}, opts))
+
+	l.Warn("careful", "note", `"severity":"WARNING"`)
+	want := `{` + colorYellow + `"severity":"WARNING"` + colorReset + `,"msg":"careful","note":"\"severity\":\"WARNING\""}` + "\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n got %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestConsoleColor_IconsReplaceLevelAndDimTime(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(newConsoleHandler(&buf, false, &consoleStyle{icons: true}, &slog.HandlerOptions{ReplaceAttr: timeReplacer(time.RFC3339, true)}))
+
+	r := slog.NewRecord(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), slog.LevelError, "boom", 0)
+	_ = l.Handler().Handle(t.Context(), r)
+	want := colorDim + "time=2024-01-02T03:04:05Z" + colorReset + " " + colorRed + "✖" + colorReset + " msg=boom\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected output:\n got %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestConsoleColor_DroppedLevelIsLeftAlone(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(newConsoleHandler(&buf, false, &consoleStyle{}, &slog.HandlerOptions{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && (a.Key == slog.LevelKey || a.Key == slog.TimeKey) {
+			return slog.Attr{}
+		}
+		return a
+	}}))
+
+	l.With("level", "x").Error("no level")
+	if strings.Contains(buf.String(), "\033") {
+		t.Fatalf("expected no color without a level field: %q", buf.String())
+	}
+}
+
+func TestConsoleColor_MessageAndLineModes(t *testing.T) {
+	var buf bytes.Buffer
+	opts := &slog.HandlerOptions{ReplaceAttr: noTime}
+	slog.New(newConsoleHandler(&buf, false, &consoleStyle{mode: ColorMessage}, opts)).Error("failed", "k", 1)
+	want := colorRed + "level=ERROR" + colorReset + " " + colorRed + "msg=failed" + colorReset + " k=1\n"
+	if buf.String() != want {
+		t.Fatalf("message mode:\n got %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	slog.New(newConsoleHandler(&buf, false, &consoleStyle{mode: ColorLine, highlight: []string{"error"}}, opts)).
+		Warn("retrying", "error", "timeout", "attempt", 2)
+	want = colorYellow + "level=WARN msg=retrying " + colorHighlight + "error=timeout" + colorReset + colorYellow + " attempt=2" + colorReset + "\n"
+	if buf.String() != want {
+		t.Fatalf("line mode:\n got %q\nwant %q", buf.String(), want)
+	}
+}
+
+func TestConsoleColor_HighlightsKeysNotQuotedText(t *testing.T) {
+	var buf bytes.Buffer
+	style := &consoleStyle{highlight: []string{"duration", "error"}}
+	l := slog.New(newConsoleHandler(&buf, false, style, &slog.HandlerOptions{ReplaceAttr: noTime}))
+	l.WithGroup("req").Info("done error=none", "duration", "12ms", "error", "bad \"input\"")
+	want := colorGreen + "level=INFO" + colorReset + ` msg="done error=none" ` +
+		colorHighlight + "req.duration=12ms" + colorReset + " " +
+		colorHighlight + `req.error="bad \"input\""` + colorReset + "\n"
+	if buf.String() != want {
+		t.Fatalf("text:\n got %q\nwant %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	l = slog.New(newConsoleHandler(&buf, true, style, &slog.HandlerOptions{ReplaceAttr: noTime}))
+	l.Info("done", "list", []int{1, 2}, slog.Group("req", "duration", 12, "tags", []string{"error"}))
+	want = `{` + colorGreen + `"level":"INFO"` + colorReset + `,"msg":"done","list":[1,2],"req":{` +
+		colorHighlight + `"duration":12` + colorReset + `,"tags":["error"]}}` + "\n"
+	if buf.String() != want {
+		t.Fatalf("json:\n got %q\nwant %q", buf.String(), want)
+	}
+}
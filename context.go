@@ -12,6 +12,8 @@ const (
 	requestIDKey ctxKey = "logx_request_id"
 	// loggerKey stores a request-scoped *slog.Logger in the context.
 	loggerKey ctxKey = "logx_logger"
+	// levelKey stores a request-scoped minimum level override.
+	levelKey ctxKey = "logx_level"
 )
 
 // WithRequestID returns a new context containing a request ID.
@@ -64,3 +66,100 @@ func LoggerFromContext(ctx context.Context) *slog.Logger {
 	}
 	return Logger()
 }
+
+// WithLevel returns a new context that lowers (or raises) the minimum enabled
+// level for records logged with it through a Configure-built logger,
+// overriding the global level for that context only.
+func WithLevel(ctx context.Context, level slog.Level) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, levelKey, level)
+}
+
+// LevelFromContext returns the level override stored in the context, if any.
+func LevelFromContext(ctx context.Context) (slog.Level, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	level, ok := ctx.Value(levelKey).(slog.Level)
+	return level, ok
+}
+
+// DetachContext returns a new context carrying ctx's logger, request ID and
+// level override but none of its other values, cancellation or deadline.
+// Use it for goroutines that outlive the request that started them:
+//
+//	go sendReceipt(logx.DetachContext(r.Context()), order)
+func DetachContext(ctx context.Context) context.Context {
+	out := context.Background()
+	if ctx == nil {
+		return out
+	}
+	for _, key := range []ctxKey{requestIDKey, loggerKey, levelKey} {
+		if v := ctx.Value(key); v != nil {
+			out = context.WithValue(out, key, v)
+		}
+	}
+	return out
+}
+
+// CtxLogger logs through the logger stored in a context, passing the
+// context to the handler so its level override and trace IDs apply. Get
+// one with FromCtx.
+type CtxLogger struct {
+	ctx context.Context
+	l   *slog.Logger
+}
+
+// FromCtx returns a CtxLogger for ctx's logger (see WithLogger), or the
+// package logger if ctx carries none:
+//
+//	logx.FromCtx(ctx).Info("order placed", "id", id)
+func FromCtx(ctx context.Context) CtxLogger {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return CtxLogger{ctx: ctx, l: LoggerFromContext(ctx)}
+}
+
+// Logger returns the logger c writes to.
+func (c CtxLogger) Logger() *slog.Logger {
+	return c.l
+}
+
+// With returns a CtxLogger whose records carry args.
+func (c CtxLogger) With(args ...any) CtxLogger {
+	return CtxLogger{ctx: c.ctx, l: c.l.With(args...)}
+}
+
+// Debug logs a message at debug level.
+func (c CtxLogger) Debug(msg string, args ...any) {
+	logDepth(c.l, c.ctx, 0, slog.LevelDebug, msg, args...)
+}
+
+// Info logs a message at info level.
+func (c CtxLogger) Info(msg string, args ...any) {
+	logDepth(c.l, c.ctx, 0, slog.LevelInfo, msg, args...)
+}
+
+// Warn logs a message at warn level.
+func (c CtxLogger) Warn(msg string, args ...any) {
+	logDepth(c.l, c.ctx, 0, slog.LevelWarn, msg, args...)
+}
+
+// Error logs a message at error level.
+func (c CtxLogger) Error(msg string, args ...any) {
+	logDepth(c.l, c.ctx, 0, slog.LevelError, msg, args...)
+}
+
+// ErrorErr logs at error level with the error fields of the package
+// ErrorErr.
+func (c CtxLogger) ErrorErr(msg string, err error, args ...any) {
+	logDepth(c.l, c.ctx, 0, slog.LevelError, msg, errFields(err, args)...)
+}
+
+// Timed is the package Timed with c's context and logger.
+func (c CtxLogger) Timed(msg string, args ...any) func(extra ...any) {
+	return timed(c.l, slog.LevelInfo, 0, c.ctx, msg, args, nil)
+}
@@ -1,7 +1,10 @@
 package logx
 
 import (
+	"context"
+	"errors"
 	"log/slog"
+	"strings"
 	"testing"
 )
 
@@ -18,3 +21,79 @@ func TestWithLogger_And_LoggerFromContext(t *testing.T) {
 type nopWriter struct{}
 
 func (n *nopWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+func TestWithLevel_OverridesConfiguredLevel(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: w}); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	ctx := WithLevel(nil, slog.LevelDebug)
+	DebugContext(ctx, "ctx-debug")
+	Debug("global-debug")
+
+	assertContains(t, w.String(), "ctx-debug")
+	if strings.Contains(w.String(), "global-debug") {
+		t.Fatalf("expected override to apply only to its context")
+	}
+}
+
+func TestDetachContext_KeepsLoggingValuesOnly(t *testing.T) {
+	type otherKey struct{}
+	l := slog.New(slog.NewTextHandler(&nopWriter{}, nil))
+
+	parent, cancel := context.WithCancel(context.Background())
+	parent = WithRequestID(parent, "rid-1")
+	parent = WithLogger(parent, l)
+	parent = WithLevel(parent, slog.LevelDebug)
+	parent = context.WithValue(parent, otherKey{}, "x")
+
+	ctx := DetachContext(parent)
+	cancel()
+
+	if ctx.Err() != nil {
+		t.Fatalf("expected detached context to survive cancellation")
+	}
+	if id, _ := RequestID(ctx); id != "rid-1" {
+		t.Fatalf("expected request id, got %q", id)
+	}
+	if LoggerFromContext(ctx) != l {
+		t.Fatalf("expected logger to be carried over")
+	}
+	if lvl, ok := LevelFromContext(ctx); !ok || lvl != slog.LevelDebug {
+		t.Fatalf("expected level override, got %v %v", lvl, ok)
+	}
+	if ctx.Value(otherKey{}) != nil {
+		t.Fatalf("expected unrelated values to be dropped")
+	}
+}
+
+func TestFromCtx_UsesTheContextLoggerAndLevel(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: w, AddSource: true}); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	FromCtx(nil).Info("no-ctx")
+	ctx := WithLogger(context.Background(), With("request_id", "r1"))
+	ctx = WithLevel(ctx, slog.LevelDebug)
+	FromCtx(ctx).Debug("ctx-debug")
+	FromCtx(ctx).With("step", 2).ErrorErr("ctx-failed", errors.New("boom"))
+	FromCtx(ctx).Timed("ctx-timed")()
+
+	out := w.String()
+	assertContains(t, out, "msg=no-ctx")
+	assertContains(t, out, "msg=ctx-debug request_id=r1")
+	assertContains(t, out, "msg=ctx-failed request_id=r1 step=2 error=boom")
+	assertContains(t, out, `msg="ctx-timed completed" request_id=r1`)
+	assertContains(t, out, "context_test.go")
+	if strings.Contains(out, "/context.go:") {
+		t.Fatalf("expected the caller's source:\n%s", out)
+	}
+}
@@ -0,0 +1,125 @@
+package logx
+
+// crash.go writes a small marker file next to the log file when the process
+// ends fatally and reports it on the next start, so restart loops leave a
+// trail even when the fatal line itself is hard to find.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/rannday/logx/internal/backups"
+)
+
+// crashMarkerSuffix is appended to Config.FilePath to name the marker file.
+const crashMarkerSuffix = backups.CrashSuffix
+
+var (
+	crashMarkerPath string // guarded by loggerMu
+	crashOnPanic    bool   // guarded by loggerMu
+)
+
+// crashMarker is the JSON content of the marker file.
+type crashMarker struct {
+	Time        time.Time `json:"time"`
+	Message     string    `json:"message"`
+	Fingerprint string    `json:"fingerprint"`
+	RequestID   string    `json:"request_id,omitempty"`
+	Cause       string    `json:"cause"` // "fatal" or "panic"
+}
+
+// writeCrashMarker records a fatal exit or panic if markers are enabled.
+// skip is the number of frames above writeCrashMarker's caller to attribute
+// the crash to.
+func writeCrashMarker(cause, msg string, args []any, skip int) {
+	loggerMu.RLock()
+	path := crashMarkerPath
+	enabled := path != "" && (cause != "panic" || crashOnPanic)
+	loggerMu.RUnlock()
+	if !enabled {
+		return
+	}
+
+	m := crashMarker{
+		Time:        time.Now(),
+		Message:     msg,
+		Fingerprint: crashFingerprint(msg, skip+2),
+		RequestID:   requestIDFromArgs(args),
+		Cause:       cause,
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0o644)
+}
+
+// crashFingerprint identifies a crash site by message and calling function,
+// so repeated crashes in a restart loop share a fingerprint. skip is passed
+// to runtime.Caller.
+func crashFingerprint(msg string, skip int) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(msg))
+	if pc, _, _, ok := runtime.Caller(skip); ok {
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			_, _ = h.Write([]byte(fn.Name()))
+		}
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// requestIDFromArgs returns a "request_id" value from key/value args.
+func requestIDFromArgs(args []any) string {
+	for i := 0; i < len(args); i++ {
+		switch a := args[i].(type) {
+		case slog.Attr:
+			if a.Key == "request_id" {
+				return a.Value.String()
+			}
+		case string:
+			if i+1 >= len(args) {
+				return ""
+			}
+			if a == "request_id" {
+				return fmt.Sprint(args[i+1])
+			}
+			i++
+		}
+	}
+	return ""
+}
+
+// reportCrashMarker logs and removes a marker left by a previous run and
+// reports whether one was found.
+func reportCrashMarker(l *slog.Logger, path string) bool {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	defer os.Remove(path)
+
+	var m crashMarker
+	if err != nil || json.Unmarshal(data, &m) != nil {
+		l.Warn("previous run ended fatally (unreadable crash marker)", "marker", path)
+		return true
+	}
+
+	fields := []any{
+		"at", m.Time,
+		"cause", m.Cause,
+		"message", m.Message,
+		"fingerprint", m.Fingerprint,
+	}
+	if m.RequestID != "" {
+		fields = append(fields, "request_id", m.RequestID)
+	}
+	l.Warn("previous run ended fatally", fields...)
+	return true
+}
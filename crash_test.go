@@ -0,0 +1,114 @@
+package logx
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestCrashMarker_WrittenOnFatalAndReportedOnNextStart(t *testing.T) {
+	if os.Getenv("LOGX_CRASH_CHILD") == "1" {
+		Reset()
+		if err := Configure(Config{
+			Level:       slog.LevelInfo,
+			FilePath:    os.Getenv("LOGX_CRASH_FILE"),
+			CrashMarker: true,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		Fatal("db unreachable", "request_id", "rid-1")
+		return
+	}
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	cmd := exec.Command(os.Args[0], "-test.run=TestCrashMarker_WrittenOnFatalAndReportedOnNextStart")
+	cmd.Env = append(os.Environ(), "LOGX_CRASH_CHILD=1", "LOGX_CRASH_FILE="+path)
+	if err := cmd.Run(); err == nil {
+		t.Fatalf("expected non-zero exit")
+	}
+
+	data, err := os.ReadFile(path + crashMarkerSuffix)
+	if err != nil {
+		t.Fatalf("expected crash marker: %v", err)
+	}
+	var m crashMarker
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Message != "db unreachable" || m.RequestID != "rid-1" || m.Cause != "fatal" || m.Fingerprint == "" {
+		t.Fatalf("unexpected marker: %+v", m)
+	}
+
+	Reset()
+	defer Reset()
+	if err := Configure(Config{Level: slog.LevelInfo, FilePath: path, CrashMarker: true}); err != nil {
+		t.Fatal(err)
+	}
+	Reset()
+
+	log, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, string(log), `msg="previous run ended fatally"`)
+	assertContains(t, string(log), "fingerprint="+m.Fingerprint)
+	assertContains(t, string(log), "request_id=rid-1")
+
+	if _, err := os.Stat(path + crashMarkerSuffix); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected marker to be removed, got %v", err)
+	}
+}
+
+func TestCrashMarker_PanicOnlyWhenEnabled(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	panicNow := func() {
+		defer func() { _ = recover() }()
+		Panic("bad state")
+	}
+
+	if err := Configure(Config{FilePath: path, CrashMarker: true}); err != nil {
+		t.Fatal(err)
+	}
+	panicNow()
+	if _, err := os.Stat(path + crashMarkerSuffix); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("marker written without CrashMarkerOnPanic: %v", err)
+	}
+
+	if err := Configure(Config{FilePath: path, CrashMarker: true, CrashMarkerOnPanic: true}); err != nil {
+		t.Fatal(err)
+	}
+	panicNow()
+	if _, err := os.Stat(path + crashMarkerSuffix); err != nil {
+		t.Fatalf("expected marker after panic: %v", err)
+	}
+}
+
+func TestCrashFingerprint_StablePerSite(t *testing.T) {
+	fp := func() string { return crashFingerprint("boom", 1) }
+	if a, b := fp(), fp(); a != b {
+		t.Fatalf("fingerprint not stable: %s vs %s", a, b)
+	}
+	if crashFingerprint("boom", 1) == crashFingerprint("other", 1) {
+		t.Fatalf("expected different fingerprints for different messages")
+	}
+}
+
+func TestRequestIDFromArgs(t *testing.T) {
+	if got := requestIDFromArgs([]any{"a", 1, "request_id", "r1"}); got != "r1" {
+		t.Fatalf("got %q", got)
+	}
+	if got := requestIDFromArgs([]any{slog.String("request_id", "r2")}); got != "r2" {
+		t.Fatalf("got %q", got)
+	}
+	if got := requestIDFromArgs([]any{"n", "request_id"}); got != "" {
+		t.Fatalf("got %q", got)
+	}
+}
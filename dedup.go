@@ -0,0 +1,218 @@
+package logx
+
+// dedup.go collapses runs of identical records, so a flapping dependency
+// logs one error and a periodic "last message repeated N times" summary
+// instead of the same line thousands of times.
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Keys of the summary record written for suppressed duplicates.
+const (
+	RepeatedKey    = "repeated"
+	RepeatedMsgKey = "repeated_msg"
+)
+
+var (
+	dedupSeed       = maphash.MakeSeed()
+	dedupSuppressed atomic.Uint64
+	dedupSummaries  atomic.Uint64
+)
+
+// DedupSuppressed returns the number of duplicate records suppressed by
+// Config.DedupWindow.
+func DedupSuppressed() uint64 {
+	return dedupSuppressed.Load()
+}
+
+// DedupSummaries returns the number of "last message repeated" summaries
+// written by Config.DedupWindow.
+func DedupSummaries() uint64 {
+	return dedupSummaries.Load()
+}
+
+// stopper is the part of *time.Timer the dedup window uses.
+type stopper interface {
+	Stop() bool
+}
+
+// dedupState is shared by a dedupHandler and the handlers derived from it.
+type dedupState struct {
+	window time.Duration
+
+	mu    sync.Mutex
+	last  uint64
+	count int // duplicates suppressed since the last record written
+	first time.Time
+	prev  time.Time
+	// rec and next write the summary: the last record written and the
+	// handler it went to, so the summary carries the same With attrs
+	rec    slog.Record
+	next   slog.Handler
+	timer  stopper
+	closed bool
+	// afterFunc starts the window timer (time.AfterFunc); tests replace it
+	afterFunc func(time.Duration, func()) stopper
+	// flushing tracks summaries being written by the window timer, so
+	// Close does not return while one may still reach an output
+	flushing sync.WaitGroup
+}
+
+// dedupHandler drops a record identical to the previous one (level,
+// message and attrs, including those added with With) and writes a summary
+// once the next different record arrives or window has passed since the
+// first suppressed duplicate, by the SetClock clock or the window timer.
+type dedupHandler struct {
+	next  slog.Handler
+	state *dedupState
+	scope attrScope
+}
+
+func newDedupHandler(next slog.Handler, window time.Duration) *dedupHandler {
+	return &dedupHandler{next: next, state: &dedupState{
+		window: window,
+		afterFunc: func(d time.Duration, f func()) stopper {
+			return time.AfterFunc(d, f)
+		},
+	}}
+}
+
+func (h *dedupHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *dedupHandler) Handle(ctx context.Context, r slog.Record) error {
+	// records only the recent ring sees must not break a run
+	if ringOnly(ctx) {
+		return h.next.Handle(ctx, r)
+	}
+	key := h.key(r)
+	s := h.state
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return h.next.Handle(ctx, r)
+	}
+	if s.next != nil && key == s.last {
+		// the window may have ended without the timer firing yet
+		var summary slog.Record
+		var next slog.Handler
+		if s.count > 0 && Now().Sub(s.first) >= s.window {
+			summary, next = s.takeSummary()
+		}
+		if s.count == 0 {
+			s.first = r.Time
+			s.timer = s.afterFunc(s.window, s.flush)
+		}
+		s.count++
+		s.prev = r.Time
+		s.mu.Unlock()
+		dedupSuppressed.Add(1)
+		if next != nil {
+			_ = next.Handle(ctx, summary)
+		}
+		return nil
+	}
+	summary, next := s.takeSummary()
+	s.last = key
+	s.rec = r.Clone()
+	s.next = h.next
+	s.mu.Unlock()
+
+	if next != nil {
+		_ = next.Handle(ctx, summary)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// flush writes the summary when the window ends; the next duplicate starts
+// a new window.
+func (s *dedupState) flush() {
+	s.mu.Lock()
+	summary, next := s.takeSummary()
+	if next != nil {
+		s.flushing.Add(1)
+		defer s.flushing.Done()
+	}
+	s.mu.Unlock()
+	if next != nil {
+		_ = next.Handle(context.Background(), summary)
+	}
+}
+
+// Close writes the pending summary, stops the window timer and waits for a
+// summary the timer is already writing. Later records pass through
+// unchanged. buildLogger closes it before the outputs.
+func (s *dedupState) Close() error {
+	s.mu.Lock()
+	summary, next := s.takeSummary()
+	s.closed = true
+	s.mu.Unlock()
+	if next != nil {
+		_ = next.Handle(context.Background(), summary)
+	}
+	s.flushing.Wait()
+	return nil
+}
+
+// takeSummary returns the summary record for the suppressed duplicates and
+// the handler to write it to, or a nil handler when nothing was suppressed.
+// The caller holds s.mu.
+func (s *dedupState) takeSummary() (slog.Record, slog.Handler) {
+	if s.count == 0 {
+		return slog.Record{}, nil
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	msg := fmt.Sprintf("last message repeated %d times in %s", s.count, s.prev.Sub(s.first).Round(time.Millisecond))
+	r := slog.NewRecord(Now(), s.rec.Level, msg, 0)
+	r.AddAttrs(slog.Int(RepeatedKey, s.count), slog.String(RepeatedMsgKey, s.rec.Message))
+	s.count = 0
+	dedupSummaries.Add(1)
+	return r, s.next
+}
+
+// key hashes the level, message and attrs of r within h's scope.
+func (h *dedupHandler) key(r slog.Record) uint64 {
+	var m maphash.Hash
+	m.SetSeed(dedupSeed)
+	fmt.Fprintf(&m, "%d\x00%s\x00", r.Level, r.Message)
+	for _, g := range h.scope.groups {
+		fmt.Fprintf(&m, "g%s\x00", g)
+	}
+	for _, p := range h.scope.pre {
+		fmt.Fprintf(&m, "%d%s=%s\x00", p.depth, p.attr.Key, p.attr.Value)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		fmt.Fprintf(&m, "%s=%s\x00", a.Key, a.Value)
+		return true
+	})
+	return m.Sum64()
+}
+
+func (h *dedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.next = h.next.WithAttrs(attrs)
+	c.scope = h.scope.withAttrs(attrs)
+	return &c
+}
+
+func (h *dedupHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.next = h.next.WithGroup(name)
+	c.scope = h.scope.withGroup(name)
+	return &c
+}
@@ -0,0 +1,250 @@
+package logx
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingHandler keeps the messages of the records it handles.
+type recordingHandler struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *recordingHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	h.msgs = append(h.msgs, r.Message)
+	h.mu.Unlock()
+	return nil
+}
+
+func (h *recordingHandler) messages() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.msgs...)
+}
+
+func TestDedup_CollapsesConsecutiveDuplicates(t *testing.T) {
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w, DedupWindow: time.Hour}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	db := With("dep", "db")
+	for range 5 {
+		db.Error("connection refused", "port", 5432)
+	}
+	db.Error("connection refused", "port", 5433)
+	Error("connection refused", "port", 5433)
+	Info("recovered")
+	Shutdown()
+
+	out := w.String()
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 lines, got %d: %q", len(lines), out)
+	}
+	if !strings.Contains(lines[1], "last message repeated 4 times") ||
+		!strings.Contains(lines[1], "repeated=4") ||
+		!strings.Contains(lines[1], `repeated_msg="connection refused"`) ||
+		!strings.Contains(lines[1], "dep=db") {
+		t.Fatalf("unexpected summary: %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "port=5433") || !strings.Contains(lines[3], "port=5433") || strings.Contains(lines[3], "dep=db") {
+		t.Fatalf("records with different attrs were collapsed: %q", out)
+	}
+	if !strings.Contains(lines[4], "recovered") {
+		t.Fatalf("unexpected last line: %q", lines[4])
+	}
+}
+
+// fakeTimers replaces the window timer of h; fire runs the last one.
+type fakeTimers struct {
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	f       func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	was := !t.stopped
+	t.stopped = true
+	return was
+}
+
+func (ft *fakeTimers) install(h *dedupHandler) {
+	h.state.afterFunc = func(_ time.Duration, f func()) stopper {
+		t := &fakeTimer{f: f}
+		ft.timers = append(ft.timers, t)
+		return t
+	}
+}
+
+func (ft *fakeTimers) fire() {
+	if t := ft.timers[len(ft.timers)-1]; !t.stopped {
+		t.f()
+	}
+}
+
+func TestDedup_WindowFlushesSummary(t *testing.T) {
+	defer Reset()
+
+	sink := &recordingHandler{}
+	h := newDedupHandler(sink, time.Minute)
+	var timers fakeTimers
+	timers.install(h)
+	ctx := context.Background()
+	for range 3 {
+		_ = h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelWarn, "flap", 0))
+	}
+	timers.fire()
+
+	if msgs := sink.messages(); len(msgs) != 2 || msgs[0] != "flap" || !strings.HasPrefix(msgs[1], "last message repeated 2 times") {
+		t.Fatalf("unexpected records: %q", msgs)
+	}
+	if DedupSuppressed() != 2 || DedupSummaries() != 1 {
+		t.Fatalf("expected 2 suppressed and 1 summary, got %d and %d", DedupSuppressed(), DedupSummaries())
+	}
+
+	// a duplicate after the summary starts a new window
+	_ = h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelWarn, "flap", 0))
+	_ = h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelWarn, "other", 0))
+	if msgs := sink.messages(); len(msgs) != 4 || !strings.HasPrefix(msgs[2], "last message repeated 1 times") || msgs[3] != "other" {
+		t.Fatalf("unexpected records: %q", msgs)
+	}
+	if len(timers.timers) != 2 || !timers.timers[1].stopped {
+		t.Fatalf("expected the second window timer to be stopped")
+	}
+}
+
+func TestDedup_WindowEndsOnTheClock(t *testing.T) {
+	defer Reset()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	sink := &recordingHandler{}
+	h := newDedupHandler(sink, time.Minute)
+	var timers fakeTimers
+	timers.install(h)
+	ctx := context.Background()
+	for range 3 {
+		_ = h.Handle(ctx, slog.NewRecord(now, slog.LevelWarn, "flap", 0))
+	}
+	now = now.Add(time.Minute)
+	_ = h.Handle(ctx, slog.NewRecord(now, slog.LevelWarn, "flap", 0))
+
+	if msgs := sink.messages(); len(msgs) != 2 || !strings.HasPrefix(msgs[1], "last message repeated 2 times") {
+		t.Fatalf("expected a summary once the window passed, got %q", msgs)
+	}
+	if !timers.timers[0].stopped || DedupSuppressed() != 3 {
+		t.Fatalf("expected the duplicate to start a new window (suppressed %d)", DedupSuppressed())
+	}
+}
+
+func TestDedup_IgnoresRecordsOnlyTheRecentRingSees(t *testing.T) {
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: w, DedupWindow: time.Hour, RecentRecords: 8}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	for range 3 {
+		Error("boom")
+		Debug("noise")
+	}
+	if err := Shutdown(); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	out := w.String()
+	if n := strings.Count(out, "level=ERROR msg=boom"); n != 1 {
+		t.Fatalf("expected one boom record, got %d: %q", n, out)
+	}
+	assertContains(t, out, "last message repeated 2 times")
+	if strings.Contains(out, "noise") {
+		t.Fatalf("below-level record reached the output: %q", out)
+	}
+}
+
+func TestDedup_AppliesToRecordsEnabledByOverrides(t *testing.T) {
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: w, DedupWindow: time.Hour, RecentRecords: 8}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	SetNamedLevel("dedup-db", slog.LevelDebug)
+	ctx := WithLevel(context.Background(), slog.LevelDebug)
+	for range 3 {
+		DebugContext(ctx, "session")
+	}
+	for range 3 {
+		Named("dedup-db").Debug("named")
+	}
+	if err := Shutdown(); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	out := w.String()
+	for _, msg := range []string{"session", "named"} {
+		if n := strings.Count(out, "level=DEBUG msg="+msg); n != 1 {
+			t.Fatalf("expected one %s record, got %d: %q", msg, n, out)
+		}
+	}
+	if n := strings.Count(out, "last message repeated 2 times"); n != 2 {
+		t.Fatalf("expected a summary for each run, got %d: %q", n, out)
+	}
+}
+
+func TestDedup_ShutdownFlushesPendingSummary(t *testing.T) {
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w, DedupWindow: time.Hour}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	for range 3 {
+		Error("connection refused")
+	}
+	if err := Shutdown(); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	out := w.String()
+	if !strings.Contains(out, "last message repeated 2 times") {
+		t.Fatalf("expected the pending summary on shutdown: %q", out)
+	}
+	if !w.closed {
+		t.Fatal("expected the writer to be closed")
+	}
+}
+
+func TestDedup_CloseStopsWindowTimer(t *testing.T) {
+	sink := &recordingHandler{}
+	h := newDedupHandler(sink, time.Minute)
+	var timers fakeTimers
+	timers.install(h)
+	ctx := context.Background()
+	for range 3 {
+		_ = h.Handle(ctx, slog.NewRecord(time.Now(), slog.LevelWarn, "flap", 0))
+	}
+	_ = h.state.Close()
+
+	if msgs := sink.messages(); len(msgs) != 2 || !strings.HasPrefix(msgs[1], "last message repeated 2 times") {
+		t.Fatalf("expected one summary written on close, got %q", msgs)
+	}
+	if !timers.timers[0].stopped {
+		t.Fatal("expected the window timer to be stopped")
+	}
+}
@@ -0,0 +1,250 @@
+package logx
+
+// describe.go reports how the active logger was built so that a running
+// process can be asked how it is logging (admin endpoints, startup summaries).
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Description is a structured summary of the active logging pipeline.
+type Description struct {
+	// Configured is false until Configure or SetLogger has installed a logger.
+	Configured bool `json:"configured"`
+	// Custom is true when the logger was installed with SetLogger and its
+	// handlers are unknown to logx.
+	Custom bool `json:"custom,omitempty"`
+	// Level is the current global minimum level.
+	Level string `json:"level"`
+	// AddSource reports whether source annotation is enabled.
+	AddSource bool `json:"add_source"`
+	// StacktraceLevel is the level at/above which stacks are attached, if enabled.
+	StacktraceLevel string `json:"stacktrace_level,omitempty"`
+	// Schema is the output schema, if not the default (see Config.Schema).
+	Schema string `json:"schema,omitempty"`
+	// ParallelOutputs is how many outputs a record is written to at once,
+	// if more than one (see Config.ParallelOutputs).
+	ParallelOutputs int `json:"parallel_outputs,omitempty"`
+	// Outputs lists the sinks records are written to.
+	Outputs []OutputDescription `json:"outputs,omitempty"`
+	// Decorators lists wrapping handlers from outermost to innermost.
+	Decorators []string `json:"decorators,omitempty"`
+	// RedactedKeys is the number of configured redacted keys.
+	RedactedKeys int `json:"redacted_keys"`
+}
+
+// OutputDescription describes a single sink in the pipeline.
+type OutputDescription struct {
+	// Kind is "console", "file", "writer" (Config.FileWriter), "sink"
+	// (Config.Sinks), "gelf", "journald", "discard", "counter" or "audit".
+	Kind string `json:"kind"`
+	// Target is "stderr" or "stdout" for console output (a file name or
+	// "writer" with Config.ConsoleWriter), the file path, the sink name,
+	// the GELF network and address, the journal socket, or "writer" for
+	// Config.AuditWriter.
+	Target string `json:"target,omitempty"`
+	// Format is "text", "json", "gelf", "journald" or "none".
+	Format string `json:"format"`
+	// Color reports whether ANSI level colors are applied.
+	Color bool `json:"color,omitempty"`
+	// Icons reports whether level glyphs replace level names.
+	Icons bool `json:"icons,omitempty"`
+	// Fallback is true when no output was configured (or file setup failed)
+	// and logx fell back to stderr, or to the journal under systemd.
+	Fallback bool `json:"fallback,omitempty"`
+	// Rotation holds rotation settings, if enabled.
+	Rotation *RotationDescription `json:"rotation,omitempty"`
+	// Locked reports whether multi-process advisory locking is enabled.
+	Locked bool `json:"locked,omitempty"`
+	// ReopenInterval is how often the file path is checked for external
+	// rotation, if at all.
+	ReopenInterval string `json:"reopen_interval,omitempty"`
+	// WriteTimeout is how long the output may block a record, if limited.
+	WriteTimeout string `json:"write_timeout,omitempty"`
+	// QueueSize is the number of records queued for the output, if it has
+	// a queue (SinkConfig.QueueSize, GELFConfig.QueueSize,
+	// JournaldConfig.QueueSize).
+	QueueSize int `json:"queue_size,omitempty"`
+	// MinLevel is the lowest level the output writes, if it has its own
+	// floor (Config.ConsoleMinLevel, FileMinLevel, ErrorFilePath,
+	// FileConfig.MinLevel, SinkConfig.MinLevel, GELFConfig.MinLevel,
+	// JournaldConfig.MinLevel).
+	MinLevel string `json:"min_level,omitempty"`
+	// minLevel is the floor itself, read again by Describe since it may be
+	// a *slog.LevelVar
+	minLevel slog.Leveler
+	// Failover reports whether records fall back to stderr while the file fails.
+	Failover bool `json:"failover,omitempty"`
+	// AttrFilter lists the attr keys kept or dropped for this output, if any.
+	AttrFilter *AttrFilter `json:"attr_filter,omitempty"`
+	// Keys is "flatten" or "nest" when the output rewrites key structure.
+	Keys string `json:"keys,omitempty"`
+	// HashChain reports whether audit events are hash-chained.
+	HashChain bool `json:"hash_chain,omitempty"`
+	// LevelMapper reports whether levels are rewritten by a LevelMapper.
+	LevelMapper bool `json:"level_mapper,omitempty"`
+	// MaxAttrValueBytes is the string attr value limit for this output (0 = unlimited).
+	MaxAttrValueBytes int `json:"max_attr_value_bytes,omitempty"`
+}
+
+// RotationDescription describes file rotation settings.
+type RotationDescription struct {
+	MaxSizeBytes int `json:"max_size_bytes"`
+	MaxBackups   int `json:"max_backups"`
+	// MaxTotalBytes is the disk quota of the file and its backups.
+	MaxTotalBytes int `json:"max_total_bytes,omitempty"`
+	// Every is the rotation period, if any.
+	Every string `json:"every,omitempty"`
+	// Compress reports whether rotated files are gzipped.
+	Compress bool `json:"compress,omitempty"`
+	// Manifest reports whether rotated files are recorded in a manifest.
+	Manifest bool `json:"manifest,omitempty"`
+}
+
+// Describe returns a description of the active logging pipeline.
+func Describe() Description {
+	loggerMu.RLock()
+	d := currentDesc
+	loggerMu.RUnlock()
+
+	d.Outputs = append([]OutputDescription(nil), d.Outputs...)
+	d.Decorators = append([]string(nil), d.Decorators...)
+	d.Level = levelVar.Level().String()
+	for i, o := range d.Outputs {
+		if o.minLevel != nil {
+			d.Outputs[i].MinLevel = o.minLevel.Level().String()
+		}
+	}
+	d.RedactedKeys = len(ListRedactedKeys())
+	return d
+}
+
+// LogValue implements slog.LogValuer so a Description can be logged directly,
+// e.g. logx.Info("logging configured", "pipeline", logx.Describe()).
+func (d Description) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.Bool("configured", d.Configured),
+		slog.String("level", d.Level),
+		slog.Int("redacted_keys", d.RedactedKeys),
+	}
+	if d.Custom {
+		attrs = append(attrs, slog.Bool("custom", true))
+	}
+	if d.StacktraceLevel != "" {
+		attrs = append(attrs, slog.String("stacktrace_level", d.StacktraceLevel))
+	}
+	if d.ParallelOutputs > 0 {
+		attrs = append(attrs, slog.Int("parallel_outputs", d.ParallelOutputs))
+	}
+	// the kind is the group key; registered sinks and the outputs of
+	// Config.Files are keyed by name and path too
+	seen := map[string]bool{}
+	for _, o := range d.Outputs {
+		out := []any{"format", o.Format}
+		if o.Target != "" {
+			out = append(out, "target", o.Target)
+		}
+		if o.MinLevel != "" {
+			out = append(out, "min_level", o.MinLevel)
+		}
+		if o.Rotation != nil {
+			out = append(out,
+				"max_size_bytes", o.Rotation.MaxSizeBytes,
+				"max_backups", o.Rotation.MaxBackups,
+			)
+			if o.Rotation.Every != "" {
+				out = append(out, "every", o.Rotation.Every)
+			}
+			if o.Rotation.MaxTotalBytes > 0 {
+				out = append(out, "max_total_bytes", o.Rotation.MaxTotalBytes)
+			}
+		}
+		key := o.Kind
+		if o.Kind == "sink" || seen[key] {
+			key = o.Kind + "." + o.Target
+		}
+		seen[key] = true
+		attrs = append(attrs, slog.Group(key, out...))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// describe returns the rotation settings of r, or nil if it never rotates.
+func (r *fileRotator) describe() *RotationDescription {
+	if r.maxSize == 0 && r.every == 0 {
+		return nil
+	}
+	d := &RotationDescription{
+		MaxSizeBytes:  r.maxSize,
+		MaxBackups:    r.backups,
+		MaxTotalBytes: r.maxTotal,
+		Compress:      r.compress,
+		Manifest:      r.manifest,
+	}
+	if r.every > 0 {
+		d.Every = r.every.String()
+	}
+	return d
+}
+
+// describeTimeout formats a write timeout for OutputDescription.
+func describeTimeout(d time.Duration) string {
+	if d <= 0 {
+		return ""
+	}
+	return d.String()
+}
+
+func describeFile(cfg Config, w io.WriteCloser) OutputDescription {
+	out := OutputDescription{Kind: "file", Format: formatName(cfg.JSONFile)}
+
+	switch f := w.(type) {
+	case *fileRotator:
+		out.Target = f.path
+		out.Locked = f.lock != nil
+		out.Rotation = f.describe()
+		if f.reopen > 0 {
+			out.ReopenInterval = f.reopen.String()
+		}
+	case *os.File:
+		out.Target = f.Name()
+	default:
+		out.Kind = "writer"
+	}
+	return out
+}
+
+func describeDecorators(cfg Config) []string {
+	decorators := []string{"context_level", "filter", "rate_limit"}
+	if cfg.DedupWindow > 0 {
+		decorators = append(decorators, "dedup")
+	}
+	decorators = append(decorators, "redaction")
+	if cfg.SanitizeUTF8 {
+		decorators = append(decorators, "sanitize")
+	}
+	if cfg.RecentRecords > 0 {
+		decorators = append(decorators, "recent")
+	}
+	if cfg.stacktraceEnabled() {
+		decorators = append(decorators, "stacktrace")
+	}
+	return append(decorators, "hooks")
+}
+
+func describeKeys(l KeyLayout) string {
+	if l == KeysAsIs {
+		return ""
+	}
+	return l.String()
+}
+
+func formatName(json bool) string {
+	if json {
+		return "json"
+	}
+	return "text"
+}
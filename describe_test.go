@@ -0,0 +1,59 @@
+package logx
+
+import (
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDescribe_ReportsConfiguredPipeline(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	if d := Describe(); d.Configured {
+		t.Fatalf("expected unconfigured description after Reset")
+	}
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	SetRedactedKeys("password", "token")
+	if err := Configure(Config{
+		Level:            slog.LevelWarn,
+		FilePath:         path,
+		JSONFile:         true,
+		FileMaxSizeBytes: 1024,
+		FileMaxBackups:   3,
+		StacktraceLevel:  slog.LevelError,
+	}); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	d := Describe()
+	if d.Level != "WARN" || d.StacktraceLevel != "ERROR" || d.RedactedKeys != 2 {
+		t.Fatalf("unexpected description: %+v", d)
+	}
+	if len(d.Outputs) != 1 {
+		t.Fatalf("expected one output, got %+v", d.Outputs)
+	}
+	o := d.Outputs[0]
+	if o.Kind != "file" || o.Format != "json" || o.Target != path {
+		t.Fatalf("unexpected output: %+v", o)
+	}
+	if o.Rotation == nil || o.Rotation.MaxSizeBytes != 1024 || o.Rotation.MaxBackups != 3 {
+		t.Fatalf("expected rotation settings, got %+v", o.Rotation)
+	}
+}
+
+func TestDescribe_LogValue(t *testing.T) {
+	out := capture(t, slog.LevelInfo, func() {
+		Info("logging configured", "pipeline", Description{
+			Configured: true,
+			Level:      "INFO",
+			Outputs:    []OutputDescription{{Kind: "console", Target: "stderr", Format: "text"}},
+		})
+	})
+
+	if !strings.Contains(out, "pipeline.console.format=text") {
+		t.Fatalf("expected grouped output description, got: %s", out)
+	}
+}
@@ -0,0 +1,26 @@
+package logx
+
+// disable.go is a kill switch for all logging, for phases such as batch
+// imports where even the level check of a high SetLevel is too much.
+
+import "sync/atomic"
+
+var disabled atomic.Bool
+
+// Disable drops every record logged through logx until Enable, including
+// records from loggers derived earlier and from the slog default logx
+// installed. The configuration, level and open outputs are kept. Audit
+// events are still written.
+func Disable() {
+	disabled.Store(true)
+}
+
+// Enable undoes Disable.
+func Enable() {
+	disabled.Store(false)
+}
+
+// Disabled reports whether logging is disabled with Disable.
+func Disabled() bool {
+	return disabled.Load()
+}
@@ -0,0 +1,48 @@
+package logx
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestDisable_SilencesDerivedLoggersUntilEnable(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	derived := Logger().With("phase", "import")
+
+	Disable()
+	if !Disabled() || Logger().Enabled(t.Context(), slog.LevelError) {
+		t.Fatalf("expected logging to be disabled")
+	}
+	Error("dropped")
+	derived.Error("dropped too")
+	slog.Error("dropped via slog")
+	Enable()
+	derived.Info("back")
+
+	out := w.String()
+	if strings.Contains(out, "dropped") {
+		t.Fatalf("expected no records while disabled, got %q", out)
+	}
+	assertContains(t, out, "phase=import")
+}
+
+func BenchmarkDisabled(b *testing.B) {
+	Reset()
+	defer Reset()
+	if err := Configure(Config{Discard: true}); err != nil {
+		b.Fatalf("configure: %v", err)
+	}
+	Disable()
+	l := Logger()
+	b.ReportAllocs()
+	for b.Loop() {
+		l.Info("skipped", "n", 1)
+	}
+}
@@ -0,0 +1,114 @@
+package logx
+
+// discard.go provides outputs that write nothing, so benchmarks and load
+// tests can measure the decorator pipeline (redaction, stack traces, hooks,
+// ...) without I/O.
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// DiscardHandler returns a handler that is enabled for every level and
+// drops all records. Unlike slog.DiscardHandler, Config.Discard keeps the
+// logx decorators in front of it.
+func DiscardHandler() slog.Handler {
+	return discardHandler{}
+}
+
+// discardHandler drops records; with a level it is enabled like the
+// configured outputs.
+type discardHandler struct {
+	level slog.Leveler
+}
+
+func (h discardHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.level == nil || level >= h.level.Level()
+}
+
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// CountingHandler counts the records it handles by level and passes them
+// to another handler. Handlers derived with WithAttrs and WithGroup share
+// the counts.
+type CountingHandler struct {
+	next   slog.Handler
+	level  slog.Leveler
+	counts *levelCounts
+}
+
+// levelCounts holds one counter per standard level; other levels are
+// counted with the standard level below them.
+type levelCounts struct {
+	debug, info, warn, error atomic.Uint64
+}
+
+// NewCountingHandler returns a CountingHandler writing to next
+// (nil = DiscardHandler). Set it as Config.Counter to count the records
+// reaching the outputs.
+func NewCountingHandler(next slog.Handler) *CountingHandler {
+	if next == nil {
+		next = DiscardHandler()
+	}
+	return &CountingHandler{next: next, counts: &levelCounts{}}
+}
+
+// Count returns the number of records handled at level, where DEBUG counts
+// every level below INFO, INFO those below WARN, WARN those below ERROR and
+// ERROR everything above.
+func (h *CountingHandler) Count(level slog.Level) uint64 {
+	return h.counts.bucket(level).Load()
+}
+
+// Total returns the number of records handled.
+func (h *CountingHandler) Total() uint64 {
+	c := h.counts
+	return c.debug.Load() + c.info.Load() + c.warn.Load() + c.error.Load()
+}
+
+func (c *levelCounts) bucket(level slog.Level) *atomic.Uint64 {
+	switch {
+	case level >= slog.LevelError:
+		return &c.error
+	case level >= slog.LevelWarn:
+		return &c.warn
+	case level >= slog.LevelInfo:
+		return &c.info
+	default:
+		return &c.debug
+	}
+}
+
+func (h *CountingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.level != nil && level < h.level.Level() {
+		return false
+	}
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *CountingHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.counts.bucket(r.Level).Add(1)
+	return h.next.Handle(ctx, r)
+}
+
+func (h *CountingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.next = h.next.WithAttrs(attrs)
+	return &c
+}
+
+func (h *CountingHandler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.next = h.next.WithGroup(name)
+	return &c
+}
+
+// withLevel returns a copy of h that is disabled below level.
+func (h *CountingHandler) withLevel(level slog.Leveler) *CountingHandler {
+	c := *h
+	c.level = level
+	return &c
+}
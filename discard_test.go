@@ -0,0 +1,79 @@
+package logx
+
+import (
+	"log/slog"
+	"testing"
+)
+
+func TestConfigDiscard_RunsDecoratorsWithoutOutput(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	hook := &recordingHook{}
+	counter := NewCountingHandler(nil)
+	err := Configure(Config{
+		FileWriter: w,
+		Discard:    true,
+		Counter:    counter,
+		Hooks:      []Hook{hook},
+	})
+	if err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	SetRedactedKeys("password")
+
+	Debug("below level")
+	Info("login", "password", "s3cret")
+	Logger().With("k", "v").WithGroup("g").Warn("slow")
+	Error("failed")
+	Logger().Log(t.Context(), slog.LevelError+4, "fatal")
+
+	if w.String() != "" {
+		t.Fatalf("expected no output, got %q", w.String())
+	}
+	if counter.Total() != 4 || counter.Count(slog.LevelDebug) != 0 || counter.Count(slog.LevelError) != 2 {
+		t.Fatalf("unexpected counts: total %d, error %d", counter.Total(), counter.Count(slog.LevelError))
+	}
+	if len(hook.records) != 4 || attrMap(hook.records[0])["password"].String() == "s3cret" {
+		t.Fatalf("expected hooks to see redacted records")
+	}
+	out := Describe().Outputs
+	if len(out) != 2 || out[0].Kind != "discard" || out[1].Kind != "counter" {
+		t.Fatalf("unexpected outputs: %+v", out)
+	}
+}
+
+func TestCountingHandler_WrapsNext(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	counter := NewCountingHandler(nil)
+	if err := Configure(Config{FileWriter: w, Counter: counter}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Info("hello")
+	assertContains(t, w.String(), "hello")
+	if counter.Count(slog.LevelInfo) != 1 {
+		t.Fatalf("expected one counted record, got %d", counter.Total())
+	}
+
+	if DiscardHandler().Enabled(t.Context(), slog.LevelDebug-8) != true {
+		t.Fatalf("expected DiscardHandler to be enabled at every level")
+	}
+}
+
+func BenchmarkConfigDiscard(b *testing.B) {
+	Reset()
+	defer Reset()
+	if err := Configure(Config{Discard: true}); err != nil {
+		b.Fatalf("configure: %v", err)
+	}
+	SetRedactedKeys("password")
+	l := Logger()
+	b.ReportAllocs()
+	for b.Loop() {
+		l.Info("login", "user", "bob", "password", "x")
+	}
+}
@@ -0,0 +1,108 @@
+package logx
+
+// errchain.go expands wrapped errors for ErrorErr so the causal chain and,
+// where an error carries one, the origin of the failure survive as
+// structured attrs instead of one flattened message.
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// maxErrorChain caps the number of links walked, guarding against cycles.
+const maxErrorChain = 32
+
+// ErrorLink is one wrapped error in an "error_chain" attr.
+type ErrorLink struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// errorChain returns the errors wrapped by err, depth first, following both
+// Unwrap() error and Unwrap() []error. err itself is not included.
+func errorChain(err error) []ErrorLink {
+	var links []ErrorLink
+	var walk func(error)
+	walk = func(e error) {
+		for _, w := range unwrapAll(e) {
+			if len(links) == maxErrorChain {
+				return
+			}
+			links = append(links, ErrorLink{Message: w.Error(), Type: fmt.Sprintf("%T", w)})
+			walk(w)
+		}
+	}
+	walk(err)
+	return links
+}
+
+func unwrapAll(err error) []error {
+	switch u := err.(type) {
+	case interface{ Unwrap() []error }:
+		return nonNil(u.Unwrap())
+	case interface{ Unwrap() error }:
+		if w := u.Unwrap(); w != nil {
+			return []error{w}
+		}
+	}
+	return nil
+}
+
+func nonNil(errs []error) []error {
+	out := errs[:0:0]
+	for _, e := range errs {
+		if e != nil {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// errorOrigin returns "function file:line" for the innermost error in the
+// chain that records a stack: either a Callers() []uintptr method or a
+// pkg/errors style StackTrace() whose result is a slice of uintptr-based
+// frames. It returns "" when no error carries a stack.
+func errorOrigin(err error) string {
+	var pcs []uintptr
+	for depth := 0; err != nil && depth <= maxErrorChain; depth++ {
+		if p := stackPCs(err); len(p) > 0 {
+			pcs = p
+		}
+		// for multi-errors, follow the first branch
+		next := unwrapAll(err)
+		if len(next) == 0 {
+			break
+		}
+		err = next[0]
+	}
+	if len(pcs) == 0 {
+		return ""
+	}
+	f, _ := runtime.CallersFrames(pcs[:1]).Next()
+	if f.Function == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s %s:%d", f.Function, f.File, f.Line)
+}
+
+func stackPCs(err error) []uintptr {
+	if c, ok := err.(interface{ Callers() []uintptr }); ok {
+		return c.Callers()
+	}
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return nil
+	}
+	t := m.Type().Out(0)
+	if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	st := m.Call(nil)[0]
+	pcs := make([]uintptr, st.Len())
+	for i := range pcs {
+		// pkg/errors frames hold the return address, like runtime.Callers
+		pcs[i] = uintptr(st.Index(i).Uint())
+	}
+	return pcs
+}
@@ -0,0 +1,66 @@
+package logx
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestErrorChain_WrapAndJoin(t *testing.T) {
+	root := fs.ErrNotExist
+	err := fmt.Errorf("load config: %w", errors.Join(root, errors.New("fallback failed")))
+
+	chain := errorChain(err)
+	if len(chain) != 3 {
+		t.Fatalf("expected 3 links, got %+v", chain)
+	}
+	if chain[1].Message != root.Error() || chain[2].Message != "fallback failed" {
+		t.Fatalf("unexpected chain order: %+v", chain)
+	}
+	if chain[2].Type != "*errors.errorString" {
+		t.Fatalf("unexpected type: %s", chain[2].Type)
+	}
+	if errorChain(errors.New("plain")) != nil {
+		t.Fatalf("expected no chain for an unwrapped error")
+	}
+}
+
+// stackFrame and stackErr mimic github.com/pkg/errors.
+type stackFrame uintptr
+
+type stackTrace []stackFrame
+
+type stackErr struct {
+	msg string
+	st  stackTrace
+}
+
+func (e *stackErr) Error() string          { return e.msg }
+func (e *stackErr) StackTrace() stackTrace { return e.st }
+
+func newStackErr(msg string) error {
+	pcs := make([]uintptr, 8)
+	n := runtime.Callers(2, pcs)
+	st := make(stackTrace, n)
+	for i, pc := range pcs[:n] {
+		st[i] = stackFrame(pc)
+	}
+	return &stackErr{msg: msg, st: st}
+}
+
+func TestErrorErr_ChainAndOrigin(t *testing.T) {
+	out := capture(t, 0, func() {
+		err := fmt.Errorf("handler: %w", newStackErr("disk read"))
+		ErrorErr("request failed", err)
+	})
+
+	assertContains(t, out, "error_chain=")
+	assertContains(t, out, "disk read")
+	assertContains(t, out, "error_origin=")
+	if !strings.Contains(out, "TestErrorErr_ChainAndOrigin") {
+		t.Fatalf("expected origin to name the creating function: %s", out)
+	}
+}
@@ -0,0 +1,110 @@
+package logx
+
+// fallback.go reroutes file records to stderr while the file output is
+// failing (disk full, file removed) and periodically retries the file.
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// defaultFileRetryInterval is used when Config.FileRetryInterval is zero.
+const defaultFileRetryInterval = 30 * time.Second
+
+// fallbackState is shared by a fallbackHandler and every handler derived
+// from it with WithAttrs/WithGroup.
+type fallbackState struct {
+	degraded  atomic.Bool
+	nextRetry atomic.Int64 // unix nanos
+	interval  time.Duration
+	notice    slog.Handler // plain stderr handler for degraded/restored notices
+}
+
+type fallbackHandler struct {
+	primary   slog.Handler
+	secondary slog.Handler // nil when stderr already receives every record
+	state     *fallbackState
+}
+
+// newFallbackHandler wraps the file output primary. While it fails, records
+// go to secondary (if non-nil) and primary is retried every interval.
+func newFallbackHandler(primary, secondary slog.Handler, interval time.Duration) slog.Handler {
+	if interval <= 0 {
+		interval = defaultFileRetryInterval
+	}
+	return &fallbackHandler{
+		primary:   primary,
+		secondary: secondary,
+		state: &fallbackState{
+			interval: interval,
+			notice:   slog.NewTextHandler(os.Stderr, nil),
+		},
+	}
+}
+
+func (h *fallbackHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.primary.Enabled(ctx, level)
+}
+
+func (h *fallbackHandler) Handle(ctx context.Context, r slog.Record) error {
+	s := h.state
+	if s.degraded.Load() {
+		now := time.Now().UnixNano()
+		next := s.nextRetry.Load()
+		if now < next || !s.nextRetry.CompareAndSwap(next, now+int64(s.interval)) {
+			return h.handleSecondary(ctx, r)
+		}
+		if err := h.primary.Handle(ctx, r); err != nil {
+			return h.handleSecondary(ctx, r)
+		}
+		if s.degraded.CompareAndSwap(true, false) {
+			h.notify(ctx, slog.LevelInfo, "file sink restored")
+		}
+		return nil
+	}
+
+	err := h.primary.Handle(ctx, r)
+	if err == nil {
+		return nil
+	}
+	if s.degraded.CompareAndSwap(false, true) {
+		s.nextRetry.Store(time.Now().Add(s.interval).UnixNano())
+		h.notify(ctx, slog.LevelWarn, "file sink degraded", slog.Any("error", err))
+	}
+	return h.handleSecondary(ctx, r)
+}
+
+func (h *fallbackHandler) handleSecondary(ctx context.Context, r slog.Record) error {
+	if h.secondary == nil {
+		return nil
+	}
+	return h.secondary.Handle(ctx, r)
+}
+
+func (h *fallbackHandler) notify(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	r := slog.NewRecord(Now(), level, msg, 0)
+	r.AddAttrs(attrs...)
+	if h.state.degraded.Load() {
+		r.AddAttrs(slog.Duration("retry_interval", h.state.interval))
+	}
+	_ = h.state.notice.Handle(ctx, r)
+}
+
+func (h *fallbackHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := &fallbackHandler{primary: h.primary.WithAttrs(attrs), state: h.state}
+	if h.secondary != nil {
+		out.secondary = h.secondary.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (h *fallbackHandler) WithGroup(name string) slog.Handler {
+	out := &fallbackHandler{primary: h.primary.WithGroup(name), state: h.state}
+	if h.secondary != nil {
+		out.secondary = h.secondary.WithGroup(name)
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyWriter fails while broken is set.
+type flakyWriter struct {
+	mu     sync.Mutex
+	broken bool
+	buf    bytes.Buffer
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.broken {
+		return 0, errors.New("no space left on device")
+	}
+	return w.buf.Write(p)
+}
+
+func (w *flakyWriter) setBroken(b bool) {
+	w.mu.Lock()
+	w.broken = b
+	w.mu.Unlock()
+}
+
+func TestFallbackHandler_DegradesAndRestores(t *testing.T) {
+	file := &flakyWriter{}
+	var stderr, notices bytes.Buffer
+
+	h := newFallbackHandler(
+		slog.NewTextHandler(file, nil),
+		slog.NewTextHandler(&stderr, nil),
+		time.Hour,
+	).(*fallbackHandler)
+	h.state.notice = slog.NewTextHandler(&notices, nil)
+	l := slog.New(h).With("svc", "api")
+
+	file.setBroken(true)
+	l.Info("first")
+	l.Info("second")
+
+	assertContains(t, stderr.String(), "msg=first svc=api")
+	assertContains(t, stderr.String(), "msg=second")
+	if n := bytes.Count(notices.Bytes(), []byte("file sink degraded")); n != 1 {
+		t.Fatalf("expected one degraded notice, got %d: %s", n, notices.String())
+	}
+
+	// force the retry window open
+	file.setBroken(false)
+	h.state.nextRetry.Store(0)
+	l.Info("third")
+
+	assertContains(t, file.buf.String(), "msg=third svc=api")
+	assertContains(t, notices.String(), "file sink restored")
+	if bytes.Contains(stderr.Bytes(), []byte("third")) {
+		t.Fatalf("restored record should not go to stderr")
+	}
+}
+
+func TestFallbackHandler_NilSecondaryDrops(t *testing.T) {
+	var notices bytes.Buffer
+	h := newFallbackHandler(&errHandler{err: errors.New("closed")}, nil, time.Hour).(*fallbackHandler)
+	h.state.notice = slog.NewTextHandler(&notices, nil)
+
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "x", 0)); err != nil {
+		t.Fatalf("expected nil error with fallback, got %v", err)
+	}
+	assertContains(t, notices.String(), "file sink degraded")
+}
+
+func TestConfigure_FileFallbackDescribed(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	if err := Configure(Config{FileWriter: nopWriteCloser{&bytes.Buffer{}}, FileFallback: true}); err != nil {
+		t.Fatal(err)
+	}
+	d := Describe()
+	if len(d.Outputs) != 1 || !d.Outputs[0].Failover {
+		t.Fatalf("expected failover output, got %+v", d.Outputs)
+	}
+}
+
+func TestConfigure_FileFallbackWithFilteredConsole(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var console bytes.Buffer
+	sink := FailingSink(0, nil)
+	sink.SetDown(errors.New("disk full"))
+	out := withStderr(t, func() {
+		if err := Configure(Config{
+			Level:             slog.LevelInfo,
+			FileWriter:        sink,
+			FileFallback:      true,
+			FileRetryInterval: time.Hour,
+			Console:           true,
+			ConsoleWriter:     &console,
+			ConsoleMinLevel:   slog.LevelError,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		Info("during outage")
+	})
+
+	if console.Len() != 0 {
+		t.Fatalf("expected the console to filter the record: %s", console.String())
+	}
+	assertContains(t, out, `msg="during outage"`)
+}
@@ -0,0 +1,188 @@
+package logx
+
+// filter.go drops or keeps attr keys per output, e.g. to strip bulky attrs
+// from a network sink while keeping them in the local file.
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// AttrFilter selects the attrs an output writes. Keys are dotted paths
+// from the record root ("user_agent", "req.headers"); groups opened with
+// WithGroup count as path segments. Deny is applied after Allow.
+type AttrFilter struct {
+	// Allow, if non-empty, keeps only these keys (and the groups leading
+	// to them).
+	Allow []string `json:"allow,omitempty"`
+	// Deny drops these keys.
+	Deny []string `json:"deny,omitempty"`
+}
+
+func (f AttrFilter) empty() bool {
+	return len(f.Allow) == 0 && len(f.Deny) == 0
+}
+
+// describe returns f for OutputDescription, or nil when it is empty.
+func (f AttrFilter) describe() *AttrFilter {
+	if f.empty() {
+		return nil
+	}
+	return &f
+}
+
+type attrFilterHandler struct {
+	next   slog.Handler
+	allow  map[string]struct{}
+	deny   map[string]struct{}
+	prefix string
+}
+
+func newAttrFilterHandler(next slog.Handler, f AttrFilter) slog.Handler {
+	if f.empty() {
+		return next
+	}
+	h := &attrFilterHandler{next: next, deny: keySet(f.Deny)}
+	if len(f.Allow) > 0 {
+		h.allow = keySet(f.Allow)
+	}
+	return h
+}
+
+func keySet(keys []string) map[string]struct{} {
+	m := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		m[k] = struct{}{}
+	}
+	return m
+}
+
+func (h *attrFilterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *attrFilterHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := getAttrs()
+	defer putAttrs(attrs)
+
+	changed := false
+	r.Attrs(func(a slog.Attr) bool {
+		fa, keep, mod := h.filter(a, h.prefix)
+		if keep {
+			*attrs = append(*attrs, fa)
+		}
+		changed = changed || !keep || mod
+		return true
+	})
+	if !changed {
+		return h.next.Handle(ctx, r)
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(*attrs...)
+	return h.next.Handle(ctx, nr)
+}
+
+func (h *attrFilterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		if fa, keep, _ := h.filter(a, h.prefix); keep {
+			out = append(out, fa)
+		}
+	}
+	return &attrFilterHandler{next: h.next.WithAttrs(out), allow: h.allow, deny: h.deny, prefix: h.prefix}
+}
+
+func (h *attrFilterHandler) WithGroup(name string) slog.Handler {
+	return &attrFilterHandler{next: h.next.WithGroup(name), allow: h.allow, deny: h.deny, prefix: h.prefix + name + "."}
+}
+
+// filter applies the lists to a at path prefix+a.Key, descending into
+// groups. mod reports whether a kept group lost members.
+func (h *attrFilterHandler) filter(a slog.Attr, prefix string) (out slog.Attr, keep, mod bool) {
+	path := prefix + a.Key
+	if _, ok := h.deny[path]; ok {
+		return a, false, false
+	}
+
+	_, allowed := h.allow[path]
+	if h.allow != nil && !allowed && !h.allowsBelow(path) {
+		return a, false, false
+	}
+
+	v := a.Value
+	if v.Kind() == slog.KindLogValuer {
+		v = v.Resolve()
+	}
+	if v.Kind() != slog.KindGroup {
+		return a, true, false
+	}
+
+	group := v.Group()
+	members := make([]slog.Attr, 0, len(group))
+	for _, ga := range group {
+		var (
+			fa     slog.Attr
+			gk, gm bool
+		)
+		if allowed {
+			// members of an allowed group are only subject to Deny
+			fa, gk, gm = h.filterDeny(ga, path+".")
+		} else {
+			fa, gk, gm = h.filter(ga, path+".")
+		}
+		if gk {
+			members = append(members, fa)
+		}
+		mod = mod || !gk || gm
+	}
+	if len(members) == 0 {
+		return a, false, false
+	}
+	if mod {
+		a.Value = slog.GroupValue(members...)
+	}
+	return a, true, mod
+}
+
+// filterDeny applies only Deny.
+func (h *attrFilterHandler) filterDeny(a slog.Attr, prefix string) (out slog.Attr, keep, mod bool) {
+	path := prefix + a.Key
+	if _, ok := h.deny[path]; ok {
+		return a, false, false
+	}
+	if len(h.deny) == 0 {
+		return a, true, false
+	}
+	v := a.Value
+	if v.Kind() == slog.KindLogValuer {
+		v = v.Resolve()
+	}
+	if v.Kind() != slog.KindGroup {
+		return a, true, false
+	}
+	group := v.Group()
+	members := make([]slog.Attr, 0, len(group))
+	for _, ga := range group {
+		fa, gk, gm := h.filterDeny(ga, path+".")
+		if gk {
+			members = append(members, fa)
+		}
+		mod = mod || !gk || gm
+	}
+	if mod {
+		a.Value = slog.GroupValue(members...)
+	}
+	return a, true, mod
+}
+
+// allowsBelow reports whether an allowed key lies inside the group at path.
+func (h *attrFilterHandler) allowsBelow(path string) bool {
+	for k := range h.allow {
+		if strings.HasPrefix(k, path+".") {
+			return true
+		}
+	}
+	return false
+}
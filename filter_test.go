@@ -0,0 +1,80 @@
+package logx
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestAttrFilterHandler_Deny(t *testing.T) {
+	var buf bytes.Buffer
+	h := newAttrFilterHandler(slog.NewJSONHandler(&buf, nil), AttrFilter{Deny: []string{"user_agent", "req.headers"}})
+	l := slog.New(h).With("user_agent", "curl")
+
+	l.Info("call", "status", 200, slog.Group("req", "method", "GET", "headers", "big"))
+
+	out := buf.String()
+	if strings.Contains(out, "curl") || strings.Contains(out, "big") {
+		t.Fatalf("denied keys leaked: %s", out)
+	}
+	assertContains(t, out, `"status":200,"req":{"method":"GET"}`)
+}
+
+func TestAttrFilterHandler_Allow(t *testing.T) {
+	var buf bytes.Buffer
+	h := newAttrFilterHandler(slog.NewJSONHandler(&buf, nil), AttrFilter{
+		Allow: []string{"status", "req.method", "db"},
+		Deny:  []string{"db.query"},
+	})
+
+	slog.New(h).Info("call",
+		"status", 200,
+		"stack", "...",
+		slog.Group("req", "method", "GET", "url", "/x"),
+		slog.Group("db", "rows", 3, "query", "select"),
+	)
+
+	out := buf.String()
+	assertContains(t, out, `"status":200,"req":{"method":"GET"},"db":{"rows":3}`)
+	if strings.Contains(out, "stack") || strings.Contains(out, "url") || strings.Contains(out, "select") {
+		t.Fatalf("unexpected keys: %s", out)
+	}
+}
+
+func TestAttrFilterHandler_WithGroupPrefix(t *testing.T) {
+	var buf bytes.Buffer
+	h := newAttrFilterHandler(slog.NewTextHandler(&buf, nil), AttrFilter{Deny: []string{"req.ip"}})
+
+	slog.New(h).WithGroup("req").Info("call", "ip", "10.0.0.1", "id", 7)
+
+	if strings.Contains(buf.String(), "10.0.0.1") {
+		t.Fatalf("denied key under group leaked: %s", buf.String())
+	}
+	assertContains(t, buf.String(), "req.id=7")
+}
+
+func TestConfigure_PerOutputAttrFilter(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var file bytes.Buffer
+	err := Configure(Config{
+		Level:      slog.LevelInfo,
+		FileWriter: nopWriteCloser{&file},
+		FileAttrs:  AttrFilter{Deny: []string{"user_agent"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Info("req", "user_agent", "curl", "path", "/")
+
+	if strings.Contains(file.String(), "curl") {
+		t.Fatalf("user_agent should be dropped from file: %s", file.String())
+	}
+	assertContains(t, file.String(), "path=/")
+	if f := Describe().Outputs[0].AttrFilter; f == nil || f.Deny[0] != "user_agent" {
+		t.Fatalf("expected attr filter in description, got %+v", f)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package logx
+
+import (
+	"errors"
+	"os"
+)
+
+var errLockUnsupported = errors.New("logx: file locking is not supported on this platform")
+
+func lockFile(f *os.File) error {
+	return errLockUnsupported
+}
+
+func unlockFile(f *os.File) error {
+	return errLockUnsupported
+}
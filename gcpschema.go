@@ -0,0 +1,209 @@
+package logx
+
+// gcpschema.go implements SchemaGCP: the special JSON fields Cloud Run and
+// GKE's logging agents parse into LogEntry severity, trace and httpRequest.
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+)
+
+const (
+	gcpTraceKey  = "logging.googleapis.com/trace"
+	gcpSpanKey   = "logging.googleapis.com/spanId"
+	gcpSourceKey = "logging.googleapis.com/sourceLocation"
+)
+
+// gcpProject returns Config.GCPProjectID or $GOOGLE_CLOUD_PROJECT.
+func (cfg Config) gcpProject() string {
+	if cfg.GCPProjectID != "" {
+		return cfg.GCPProjectID
+	}
+	return os.Getenv("GOOGLE_CLOUD_PROJECT")
+}
+
+func gcpReplacer(project string) func([]string, slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) != 0 {
+			return a
+		}
+		switch a.Key {
+		case slog.LevelKey:
+			a.Key = "severity"
+			if l, ok := a.Value.Any().(slog.Level); ok {
+				a.Value = GCPSeverity(l)
+			}
+		case slog.MessageKey:
+			a.Key = "message"
+		case slog.SourceKey:
+			if src, ok := a.Value.Any().(*slog.Source); ok {
+				a.Value = slog.GroupValue(
+					slog.String("file", src.File),
+					slog.String("line", strconv.Itoa(src.Line)),
+					slog.String("function", src.Function),
+				)
+			}
+			a.Key = gcpSourceKey
+		case "trace_id":
+			a.Key = gcpTraceKey
+			if project != "" {
+				a.Value = slog.StringValue("projects/" + project + "/traces/" + a.Value.String())
+			}
+		case "span_id":
+			a.Key = gcpSpanKey
+		}
+		return a
+	}
+}
+
+// gcpHTTPKeys maps the middleware's request attrs to HttpRequest fields.
+var gcpHTTPKeys = map[string]string{
+	"method":      "requestMethod",
+	"url":         "requestUrl",
+	"status":      "status",
+	"user_agent":  "userAgent",
+	"remote_addr": "remoteIp",
+	"duration":    "latency",
+	"bytes":       "responseSize",
+	"referer":     "referer",
+	"proto":       "protocol",
+}
+
+// gcpHandler moves top-level request attrs into an "httpRequest" object
+// and adds the trace of the context's span (see SetTracer) when the record
+// has none. Like stackHandler it writes grouped records to the ungrouped
+// handler so that these fields stay at the top level.
+type gcpHandler struct {
+	next   slog.Handler
+	base   slog.Handler
+	groups []string
+	pre    []depthAttr
+	// http holds request attrs added with WithAttrs at the top level
+	http   []slog.Attr
+	traced bool
+}
+
+func (h *gcpHandler) ungrouped() slog.Handler {
+	if h.base != nil {
+		return h.base
+	}
+	return h.next
+}
+
+func (h *gcpHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *gcpHandler) Handle(ctx context.Context, r slog.Record) error {
+	http := slices.Clip(h.http)
+	traced := h.traced
+	var rest []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		if len(h.groups) == 0 {
+			if _, ok := gcpHTTPKeys[a.Key]; ok {
+				http = setAttr(http, a)
+				return true
+			}
+			traced = traced || a.Key == "trace_id"
+		}
+		rest = append(rest, a)
+		return true
+	})
+
+	var top []slog.Attr
+	if !traced {
+		args := traceArgs(ctx, nil)
+		for i := 0; i+1 < len(args); i += 2 {
+			top = append(top, slog.Any(args[i].(string), args[i+1]))
+		}
+	}
+	if len(http) > 0 {
+		top = append(top, slog.Attr{Key: "httpRequest", Value: slog.GroupValue(gcpHTTPRequest(http)...)})
+	}
+	if len(top) == 0 && len(rest) == r.NumAttrs() {
+		return h.next.Handle(ctx, r)
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	if len(h.groups) == 0 {
+		nr.AddAttrs(rest...)
+		nr.AddAttrs(top...)
+		return h.next.Handle(ctx, nr)
+	}
+	nr.AddAttrs(nestAttrs(h.groups, h.pre, 1, rest)...)
+	nr.AddAttrs(top...)
+	return h.ungrouped().Handle(ctx, nr)
+}
+
+// setAttr replaces the attr with a's key in attrs or appends a.
+func setAttr(attrs []slog.Attr, a slog.Attr) []slog.Attr {
+	for i, x := range attrs {
+		if x.Key == a.Key {
+			attrs = slices.Clone(attrs)
+			attrs[i] = a
+			return attrs
+		}
+	}
+	return append(attrs, a)
+}
+
+// gcpHTTPRequest converts request attrs to HttpRequest fields.
+func gcpHTTPRequest(attrs []slog.Attr) []slog.Attr {
+	out := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		switch a.Key {
+		case "remote_addr":
+			if host, _, err := net.SplitHostPort(v.String()); err == nil {
+				v = slog.StringValue(host)
+			}
+		case "duration":
+			if v.Kind() == slog.KindDuration {
+				v = slog.StringValue(strconv.FormatFloat(v.Duration().Seconds(), 'f', -1, 64) + "s")
+			}
+		case "bytes":
+			// int64 fields are strings in LogEntry JSON
+			v = slog.StringValue(v.String())
+		}
+		out = append(out, slog.Attr{Key: gcpHTTPKeys[a.Key], Value: v})
+	}
+	return out
+}
+
+func (h *gcpHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	if len(h.groups) > 0 {
+		c.next = h.next.WithAttrs(attrs)
+		c.pre = slices.Clip(h.pre)
+		for _, a := range attrs {
+			c.pre = append(c.pre, depthAttr{depth: len(h.groups), attr: a})
+		}
+		return &c
+	}
+	var others []slog.Attr
+	for _, a := range attrs {
+		if _, ok := gcpHTTPKeys[a.Key]; ok {
+			c.http = setAttr(slices.Clip(c.http), a)
+			continue
+		}
+		c.traced = c.traced || a.Key == "trace_id"
+		others = append(others, a)
+	}
+	c.next = h.next.WithAttrs(others)
+	return &c
+}
+
+func (h *gcpHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.base = h.ungrouped()
+	c.next = h.next.WithGroup(name)
+	c.groups = append(slices.Clip(h.groups), name)
+	return &c
+}
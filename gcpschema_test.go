@@ -0,0 +1,64 @@
+package logx
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchemaGCP_SpecialFields(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	SetTracer(&Tracer{IDs: func(ctx context.Context) (string, string) { return "abc", "def" }})
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w, JSONFile: true, Schema: SchemaGCP, GCPProjectID: "p1"}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	ctx := context.Background()
+	Logger().With("method", "GET", "url", "/x", "remote_addr", "10.0.0.1:5123").
+		InfoContext(ctx, "http request completed", "status", 200, "duration", 1500*time.Millisecond, "bytes", 12)
+	Logger().WithGroup("job").ErrorContext(ctx, "failed", "id", 7)
+
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	var req, job map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &job); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	if req["severity"] != "INFO" || req["message"] != "http request completed" {
+		t.Fatalf("unexpected entry: %s", lines[0])
+	}
+	if req[gcpTraceKey] != "projects/p1/traces/abc" || req[gcpSpanKey] != "def" {
+		t.Fatalf("unexpected trace fields: %s", lines[0])
+	}
+	hr, _ := req["httpRequest"].(map[string]any)
+	want := map[string]any{
+		"requestMethod": "GET",
+		"requestUrl":    "/x",
+		"remoteIp":      "10.0.0.1",
+		"status":        float64(200),
+		"latency":       "1.5s",
+		"responseSize":  "12",
+	}
+	for k, v := range want {
+		if hr[k] != v {
+			t.Errorf("httpRequest.%s = %v, want %v", k, hr[k], v)
+		}
+	}
+	if _, ok := req["method"]; ok {
+		t.Errorf("expected method to move into httpRequest: %s", lines[0])
+	}
+
+	if job["severity"] != "ERROR" || job[gcpTraceKey] != "projects/p1/traces/abc" {
+		t.Fatalf("expected top-level severity and trace on grouped record: %s", lines[1])
+	}
+	if g, _ := job["job"].(map[string]any); g["id"] != float64(7) {
+		t.Fatalf("expected grouped attrs kept: %s", lines[1])
+	}
+}
@@ -0,0 +1,391 @@
+package logx
+
+// gelf.go ships records to Graylog in GELF 1.1 over UDP (chunked, optionally
+// gzipped), TCP or TLS, so no file tailer is needed next to the process.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GELFConfig configures the Graylog output (Config.GELF).
+type GELFConfig struct {
+	// Addr is the GELF input's host:port.
+	Addr string
+	// Network is "udp" (default), "tcp" or "tls".
+	Network string
+	// TLSConfig is used with Network "tls" (nil = system defaults).
+	TLSConfig *tls.Config
+	// Host is the GELF "host" field (default os.Hostname).
+	Host string
+	// ChunkSize is the maximum UDP datagram payload; larger messages are
+	// split into at most 128 chunks (default 1420).
+	ChunkSize int
+	// Compress gzips UDP messages before chunking.
+	Compress bool
+	// WriteTimeout bounds how long the output may block a record (see
+	// Config.ConsoleWriteTimeout).
+	WriteTimeout time.Duration
+	// QueueSize queues records for the output (see SinkConfig.QueueSize).
+	QueueSize int
+	// MinLevel drops records below this level from the output (see
+	// Config.ConsoleMinLevel).
+	MinLevel slog.Leveler
+}
+
+// ErrGELFDial reports that the GELF output could not connect. The output
+// keeps reconnecting with backoff on later records.
+var ErrGELFDial = errors.New("logx: dial gelf")
+
+// ErrGELFDisconnected reports a record dropped because the GELF output is
+// not connected: a reconnect is in progress or backing off after a failed
+// dial.
+var ErrGELFDisconnected = errors.New("logx: gelf disconnected")
+
+// ErrGELFTooLarge reports a UDP message that needs more than 128 chunks.
+var ErrGELFTooLarge = errors.New("logx: gelf message too large")
+
+const (
+	defaultGELFChunkSize = 1420
+	maxGELFChunks        = 128
+	gelfChunkHeaderLen   = 12
+
+	// gelfTimeout bounds a dial and a single write, so a stalled Graylog
+	// input cannot hold records behind it indefinitely.
+	gelfTimeout = 5 * time.Second
+	// gelfMinBackoff and gelfMaxBackoff bound the wait between reconnects;
+	// it doubles after each failed dial.
+	gelfMinBackoff = 100 * time.Millisecond
+	gelfMaxBackoff = 30 * time.Second
+)
+
+// buildGELF creates the GELF handler and its connection. A failed dial is
+// returned as an error together with a usable handler.
+func buildGELF(gc *GELFConfig, opts *slog.HandlerOptions) (slog.Handler, *gelfWriter, error) {
+	host := gc.Host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	w := &gelfWriter{
+		network:   gc.Network,
+		addr:      gc.Addr,
+		tlsConfig: gc.TLSConfig,
+		chunkSize: gc.ChunkSize,
+		compress:  gc.Compress,
+	}
+	if w.network == "" {
+		w.network = "udp"
+	}
+	if w.chunkSize <= gelfChunkHeaderLen {
+		w.chunkSize = defaultGELFChunkSize
+	}
+	h := &gelfHandler{w: w, opts: opts, host: host}
+
+	switch w.network {
+	case "udp", "tcp", "tls":
+	default:
+		return h, w, fmt.Errorf("%w %s: unknown network %q", ErrGELFDial, gc.Addr, w.network)
+	}
+	w.mu.Lock()
+	err := w.connect()
+	w.mu.Unlock()
+	if err != nil {
+		return h, w, fmt.Errorf("%w %s: %w", ErrGELFDial, gc.Addr, err)
+	}
+	return h, w, nil
+}
+
+// gelfHandler formats records as GELF messages. Attrs become additional
+// fields ("_key").
+type gelfHandler struct {
+	w      *gelfWriter
+	opts   *slog.HandlerOptions
+	host   string
+	groups []string
+	fields []gelfField
+}
+
+type gelfField struct {
+	key   string
+	value any
+}
+
+func (h *gelfHandler) Enabled(_ context.Context, level slog.Level) bool {
+	min := slog.LevelInfo
+	if h.opts != nil && h.opts.Level != nil {
+		min = h.opts.Level.Level()
+	}
+	return level >= min
+}
+
+func (h *gelfHandler) Handle(_ context.Context, r slog.Record) error {
+	short, full := r.Message, ""
+	if i := strings.IndexByte(r.Message, '\n'); i >= 0 {
+		short, full = r.Message[:i], r.Message
+	}
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	msg := map[string]any{
+		"version":       "1.1",
+		"host":          h.host,
+		"short_message": short,
+		"timestamp":     float64(t.UnixMicro()) / 1e6,
+		"level":         SyslogSeverity(r.Level).Int64(),
+		"_level_name":   r.Level.String(),
+	}
+	if full != "" {
+		msg["full_message"] = full
+	}
+	if h.opts != nil && h.opts.AddSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		msg["_source"] = f.File + ":" + strconv.Itoa(f.Line)
+	}
+	for _, f := range h.fields {
+		msg[f.key] = f.value
+	}
+	var fields []gelfField
+	r.Attrs(func(a slog.Attr) bool {
+		fields = h.appendField(fields, h.groups, a)
+		return true
+	})
+	for _, f := range fields {
+		msg[f.key] = f.value
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return h.w.send(b)
+}
+
+func (h *gelfHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.fields = append([]gelfField(nil), h.fields...)
+	for _, a := range attrs {
+		c.fields = h.appendField(c.fields, h.groups, a)
+	}
+	return &c
+}
+
+func (h *gelfHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.groups = append(slices.Clip(h.groups), name)
+	return &c
+}
+
+// appendField flattens a into additional fields after applying
+// opts.ReplaceAttr. GELF only allows string and number values, and field
+// names matching [\w.-]; groups are joined with "_" and "_id" is reserved
+// and written as "_id_".
+func (h *gelfHandler) appendField(fields []gelfField, groups []string, a slog.Attr) []gelfField {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(slices.Clip(groups), a.Key)
+		}
+		for _, ga := range v.Group() {
+			fields = h.appendField(fields, groups, ga)
+		}
+		return fields
+	}
+	if h.opts != nil && h.opts.ReplaceAttr != nil {
+		a.Value = v
+		a = h.opts.ReplaceAttr(groups, a)
+		v = a.Value.Resolve()
+	}
+	if a.Key == "" {
+		return fields
+	}
+
+	key := "_" + gelfKey(strings.Join(append(slices.Clip(groups), a.Key), "_"))
+	if key == "_id" {
+		key = "_id_"
+	}
+	var value any
+	switch v.Kind() {
+	case slog.KindInt64:
+		value = v.Int64()
+	case slog.KindUint64:
+		value = v.Uint64()
+	case slog.KindFloat64:
+		value = v.Float64()
+	case slog.KindTime:
+		value = v.Time().Format(time.RFC3339Nano)
+	default:
+		value = v.String()
+	}
+	return append(fields, gelfField{key: key, value: value})
+}
+
+func gelfKey(k string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9',
+			r == '_', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, k)
+}
+
+// gelfWriter sends one GELF message per send call. Stream connections are
+// null-byte delimited and redialed once after a failed write. Dials happen
+// outside mu and back off after a failure; records sent meanwhile are
+// dropped with ErrGELFDisconnected.
+type gelfWriter struct {
+	mu        sync.Mutex
+	network   string
+	addr      string
+	tlsConfig *tls.Config
+	chunkSize int
+	compress  bool
+	conn      net.Conn
+	closed    bool
+	dialing   bool
+	backoff   time.Duration
+	retryAt   time.Time
+}
+
+func (w *gelfWriter) dial() (net.Conn, error) {
+	d := net.Dialer{Timeout: gelfTimeout}
+	if w.network == "tls" {
+		return tls.DialWithDialer(&d, "tcp", w.addr, w.tlsConfig)
+	}
+	return d.Dial(w.network, w.addr)
+}
+
+// connect dials when there is no connection. It is called with w.mu held
+// and releases it for the dial.
+func (w *gelfWriter) connect() error {
+	if w.conn != nil {
+		return nil
+	}
+	if w.dialing || time.Now().Before(w.retryAt) {
+		return ErrGELFDisconnected
+	}
+	w.dialing = true
+	w.mu.Unlock()
+	c, err := w.dial()
+	w.mu.Lock()
+	w.dialing = false
+	if err != nil {
+		w.backoff = min(max(2*w.backoff, gelfMinBackoff), gelfMaxBackoff)
+		w.retryAt = time.Now().Add(w.backoff)
+		return err
+	}
+	if w.closed {
+		_ = c.Close()
+		return net.ErrClosed
+	}
+	w.conn, w.backoff, w.retryAt = c, 0, time.Time{}
+	return nil
+}
+
+func (w *gelfWriter) send(msg []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return net.ErrClosed
+	}
+
+	if w.network == "udp" {
+		if err := w.connect(); err != nil {
+			return err
+		}
+		return w.sendUDP(msg)
+	}
+
+	frame := append(msg, 0)
+	for attempt := 0; ; attempt++ {
+		if err := w.connect(); err != nil {
+			return err
+		}
+		_ = w.conn.SetWriteDeadline(time.Now().Add(gelfTimeout))
+		_, err := w.conn.Write(frame)
+		if err == nil || attempt == 1 {
+			if err != nil {
+				w.drop()
+			}
+			return err
+		}
+		w.drop()
+	}
+}
+
+// drop closes a connection that failed a write so the next send redials.
+func (w *gelfWriter) drop() {
+	_ = w.conn.Close()
+	w.conn = nil
+}
+
+func (w *gelfWriter) sendUDP(msg []byte) error {
+	if w.compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, _ = zw.Write(msg)
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		msg = buf.Bytes()
+	}
+	_ = w.conn.SetWriteDeadline(time.Now().Add(gelfTimeout))
+	if len(msg) <= w.chunkSize {
+		_, err := w.conn.Write(msg)
+		return err
+	}
+
+	size := w.chunkSize - gelfChunkHeaderLen
+	count := (len(msg) + size - 1) / size
+	if count > maxGELFChunks {
+		return fmt.Errorf("%w: %d bytes", ErrGELFTooLarge, len(msg))
+	}
+	id := rand.Uint64()
+	chunk := make([]byte, 0, w.chunkSize)
+	for seq := 0; seq < count; seq++ {
+		end := min((seq+1)*size, len(msg))
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = append(chunk,
+			byte(id>>56), byte(id>>48), byte(id>>40), byte(id>>32),
+			byte(id>>24), byte(id>>16), byte(id>>8), byte(id))
+		chunk = append(chunk, byte(seq), byte(count))
+		chunk = append(chunk, msg[seq*size:end]...)
+		if _, err := w.conn.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *gelfWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,259 @@
+package logx
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listenGELFUDP(t *testing.T) *net.UDPConn {
+	t.Helper()
+	c, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	_ = c.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return c
+}
+
+func TestGELF_UDPMessage(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	c := listenGELFUDP(t)
+	if err := Configure(Config{GELF: &GELFConfig{Addr: c.LocalAddr().String(), Host: "web-1"}}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Logger().WithGroup("req").Warn("slow request\ndetails", "id", "r1", "ms", 250)
+
+	buf := make([]byte, 64*1024)
+	n, err := c.Read(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(buf[:n], &m); err != nil {
+		t.Fatalf("unmarshal %q: %v", buf[:n], err)
+	}
+	want := map[string]any{
+		"version":       "1.1",
+		"host":          "web-1",
+		"short_message": "slow request",
+		"full_message":  "slow request\ndetails",
+		"level":         float64(4),
+		"_req_id":       "r1",
+		"_req_ms":       float64(250),
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Fatalf("%s = %v, want %v (message %s)", k, m[k], v, buf[:n])
+		}
+	}
+	if out := Describe().Outputs; len(out) != 1 || out[0].Kind != "gelf" {
+		t.Fatalf("unexpected outputs: %+v", out)
+	}
+}
+
+func TestGELF_UDPChunking(t *testing.T) {
+	c := listenGELFUDP(t)
+	h, w, err := buildGELF(&GELFConfig{Addr: c.LocalAddr().String(), ChunkSize: 100}, &slog.HandlerOptions{})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	defer w.Close()
+
+	big := strings.Repeat("x", 1000)
+	slog.New(h).Info("big", "payload", big)
+
+	var parts [][]byte
+	buf := make([]byte, 2048)
+	for {
+		n, err := c.Read(buf)
+		if err != nil {
+			t.Fatalf("read: %v", err)
+		}
+		chunk := append([]byte(nil), buf[:n]...)
+		if n > 100 || chunk[0] != 0x1e || chunk[1] != 0x0f {
+			t.Fatalf("bad chunk header or size %d", n)
+		}
+		if parts == nil {
+			parts = make([][]byte, chunk[11])
+		}
+		parts[chunk[10]] = chunk[12:]
+		if int(chunk[10]) == len(parts)-1 {
+			break
+		}
+	}
+	var m map[string]any
+	if err := json.Unmarshal(bytes.Join(parts, nil), &m); err != nil {
+		t.Fatalf("reassembled message: %v", err)
+	}
+	if m["_payload"] != big {
+		t.Fatalf("payload lost in chunking")
+	}
+}
+
+func TestGELF_TCPNullDelimited(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	got := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		var msgs []string
+		for range 2 {
+			b, err := r.ReadBytes(0)
+			if err != nil {
+				break
+			}
+			msgs = append(msgs, string(b[:len(b)-1]))
+		}
+		got <- msgs
+	}()
+
+	h, w, err := buildGELF(&GELFConfig{Addr: ln.Addr().String(), Network: "tcp"}, &slog.HandlerOptions{})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	l := slog.New(h)
+	l.Info("one")
+	l.Error("two")
+	msgs := <-got
+	_ = w.Close()
+
+	if len(msgs) != 2 || !strings.Contains(msgs[0], `"short_message":"one"`) || !strings.Contains(msgs[1], `"level":3`) {
+		t.Fatalf("unexpected frames: %q", msgs)
+	}
+	if err := w.send([]byte("{}")); !errors.Is(err, net.ErrClosed) {
+		t.Fatalf("expected ErrClosed after Close, got %v", err)
+	}
+}
+
+func TestGELF_DialFailureReported(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	err := Configure(Config{GELF: &GELFConfig{Addr: addr, Network: "tcp"}})
+	if !errors.Is(err, ErrGELFDial) {
+		t.Fatalf("expected ErrGELFDial, got %v", err)
+	}
+}
+
+func TestGELF_ReconnectBacksOff(t *testing.T) {
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	_, w, err := buildGELF(&GELFConfig{Addr: addr, Network: "tcp"}, &slog.HandlerOptions{})
+	if !errors.Is(err, ErrGELFDial) {
+		t.Fatalf("expected ErrGELFDial, got %v", err)
+	}
+	defer w.Close()
+	if err := w.send([]byte("{}")); !errors.Is(err, ErrGELFDisconnected) {
+		t.Fatalf("expected the record to be dropped while backing off, got %v", err)
+	}
+	if w.backoff != gelfMinBackoff {
+		t.Fatalf("unexpected backoff %v", w.backoff)
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("cannot listen on %s again: %v", addr, err)
+	}
+	defer ln.Close()
+	got := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		b, _ := bufio.NewReader(conn).ReadBytes(0)
+		got <- string(b)
+	}()
+
+	w.mu.Lock()
+	w.retryAt = time.Time{}
+	w.mu.Unlock()
+	if err := w.send([]byte(`{"n":1}`)); err != nil {
+		t.Fatalf("expected reconnect after the backoff, got %v", err)
+	}
+	if msg := <-got; msg != "{\"n\":1}\x00" {
+		t.Fatalf("unexpected frame %q", msg)
+	}
+	if w.backoff != 0 {
+		t.Fatalf("expected backoff reset after a dial, got %v", w.backoff)
+	}
+}
+
+func TestGELF_DropsRecordsWhileDialing(t *testing.T) {
+	w := &gelfWriter{network: "tcp", addr: "127.0.0.1:1", dialing: true}
+	if err := w.send([]byte("{}")); !errors.Is(err, ErrGELFDisconnected) {
+		t.Fatalf("expected ErrGELFDisconnected while another send dials, got %v", err)
+	}
+}
+
+func TestGELF_ReplaceAttrAppliesToFields(t *testing.T) {
+	c := listenGELFUDP(t)
+	opts := &slog.HandlerOptions{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 1 && a.Key == "secret" {
+			return slog.Attr{}
+		}
+		if a.Key == "user" {
+			a.Key = "user_name"
+		}
+		return a
+	}}
+	h, w, err := buildGELF(&GELFConfig{Addr: c.LocalAddr().String()}, opts)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	defer w.Close()
+	slog.New(h).Info("login", "user", "ann", slog.Group("auth", "secret", "s3", "method", "otp"))
+
+	buf := make([]byte, 4096)
+	n, err := c.Read(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	msg := string(buf[:n])
+	if strings.Contains(msg, "s3") || !strings.Contains(msg, `"_user_name":"ann"`) || !strings.Contains(msg, `"_auth_method":"otp"`) {
+		t.Fatalf("unexpected message: %s", msg)
+	}
+}
+
+func TestGELF_WriteTimeoutDescribed(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	c := listenGELFUDP(t)
+	err := Configure(Config{GELF: &GELFConfig{Addr: c.LocalAddr().String(), WriteTimeout: 10 * time.Millisecond}})
+	if err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	if out := Describe().Outputs; len(out) != 1 || out[0].WriteTimeout != "10ms" {
+		t.Fatalf("expected the timeout to be described, got %+v", out)
+	}
+	if err := (Config{GELF: &GELFConfig{Addr: "x:1", WriteTimeout: -1}}).Validate(); err == nil {
+		t.Fatal("expected a negative WriteTimeout to be rejected")
+	}
+}
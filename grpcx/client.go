@@ -0,0 +1,100 @@
+package grpcx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/rannday/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryClientInterceptor returns an interceptor that logs outbound unary RPCs
+// and propagates the context request id in the x-request-id metadata key.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := logx.Now()
+		ctx = outgoingContext(ctx)
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		logClient(ctx, "grpc client request completed", method, cc.Target(), start, err)
+		return err
+	}
+}
+
+// StreamClientInterceptor returns the streaming counterpart of
+// UnaryClientInterceptor. The record is logged when the stream ends
+// (RecvMsg returns an error, io.EOF included) or fails to open.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := logx.Now()
+		ctx = outgoingContext(ctx)
+
+		cs, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			logClient(ctx, "grpc client stream completed", method, cc.Target(), start, err)
+			return cs, err
+		}
+
+		return &clientStream{
+			ClientStream: cs,
+			done: func(err error) {
+				logClient(ctx, "grpc client stream completed", method, cc.Target(), start, err)
+			},
+		}, nil
+	}
+}
+
+func outgoingContext(ctx context.Context) context.Context {
+	id, ok := logx.RequestID(ctx)
+	if !ok {
+		return ctx
+	}
+	if md, ok := metadata.FromOutgoingContext(ctx); ok && len(md.Get(RequestIDKey)) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, RequestIDKey, id)
+}
+
+func logClient(ctx context.Context, msg, method, target string, start time.Time, err error) {
+	code := status.Code(err)
+
+	fields := []any{
+		"method", method,
+		"target", target,
+		"code", code.String(),
+		"duration", logx.Now().Sub(start),
+	}
+	if id, ok := logx.RequestID(ctx); ok {
+		fields = append(fields, "request_id", id)
+	}
+	if err != nil {
+		fields = append(fields, "error", err)
+	}
+
+	logx.LoggerFromContext(ctx).Log(ctx, codeLevel(code), msg, fields...)
+}
+
+type clientStream struct {
+	grpc.ClientStream
+	once sync.Once
+	done func(error)
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.once.Do(func() {
+			if errors.Is(err, io.EOF) {
+				s.done(nil)
+				return
+			}
+			s.done(err)
+		})
+	}
+	return err
+}
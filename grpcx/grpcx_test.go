@@ -0,0 +1,122 @@
+package grpcx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rannday/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func captureGRPC(t *testing.T) *syncBuffer {
+	t.Helper()
+
+	logx.Reset()
+	t.Cleanup(logx.Reset)
+
+	buf := &syncBuffer{}
+	logx.SetLogger(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	return buf
+}
+
+func TestInterceptors_LogAndPropagateRequestID(t *testing.T) {
+	out := captureGRPC(t)
+	// Reset restores the clock
+	logx.SetClock(func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) })
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(UnaryServerInterceptor()))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	ctx := logx.WithRequestID(context.Background(), "rid-grpc")
+	if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+
+	got := out.String()
+	if !strings.Contains(got, "grpc request completed") || !strings.Contains(got, "grpc client request completed") {
+		t.Fatalf("expected server and client logs, got: %s", got)
+	}
+	if strings.Count(got, "request_id=rid-grpc") < 2 {
+		t.Fatalf("expected request id propagated to server, got: %s", got)
+	}
+	if !strings.Contains(got, "code=OK") || !strings.Contains(got, "method=/grpc.health.v1.Health/Check") {
+		t.Fatalf("expected method and code fields, got: %s", got)
+	}
+	if strings.Count(got, "duration=0s") != 2 {
+		t.Fatalf("expected durations from the logx clock, got: %s", got)
+	}
+}
+
+func TestUnaryServerInterceptor_RecoversPanic(t *testing.T) {
+	out := captureGRPC(t)
+
+	_, err := UnaryServerInterceptor()(context.Background(), nil,
+		&grpc.UnaryServerInfo{FullMethod: "/svc/Panic"},
+		func(ctx context.Context, req any) (any, error) { panic("boom") },
+	)
+
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expected Internal, got %v", err)
+	}
+	if !strings.Contains(out.String(), "grpc handler panic") || !strings.Contains(out.String(), "level=ERROR") {
+		t.Fatalf("expected panic log, got: %s", out.String())
+	}
+}
+
+func TestCodeLevel(t *testing.T) {
+	cases := map[codes.Code]slog.Level{
+		codes.OK:          slog.LevelInfo,
+		codes.NotFound:    slog.LevelWarn,
+		codes.Internal:    slog.LevelError,
+		codes.Unavailable: slog.LevelError,
+	}
+	for code, want := range cases {
+		if got := codeLevel(code); got != want {
+			t.Fatalf("%s: expected %s, got %s", code, want, got)
+		}
+	}
+}
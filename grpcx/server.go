@@ -0,0 +1,144 @@
+// Package grpcx provides gRPC server and client interceptors integrated with
+// github.com/rannday/logx, mirroring the behavior of the httpx package.
+// It lives in its own module so the gRPC dependency is only pulled in by
+// programs that import it.
+package grpcx
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/rannday/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDKey is the metadata key used to propagate request IDs.
+// gRPC metadata keys are lowercase; this matches httpx's X-Request-ID header.
+const RequestIDKey = "x-request-id"
+
+// UnaryServerInterceptor returns an interceptor that instruments unary RPCs
+// with timing, status-level mapping, panic recovery, and a request-scoped
+// logger stored in the context (accessible via logx.LoggerFromContext).
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		start := logx.Now()
+		ctx = serverContext(ctx, info.FullMethod)
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = recovered(ctx, rec)
+			}
+			logCompleted(ctx, "grpc request completed", info.FullMethod, start, err)
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the streaming counterpart of
+// UnaryServerInterceptor. The completion record is logged when the
+// handler returns.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := logx.Now()
+		ctx := serverContext(ss.Context(), info.FullMethod)
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = recovered(ctx, rec)
+			}
+			logCompleted(ctx, "grpc stream completed", info.FullMethod, start, err)
+		}()
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// serverContext ensures a request id and stores a request-scoped logger.
+func serverContext(ctx context.Context, method string) context.Context {
+	var reqID string
+	if id, ok := logx.RequestID(ctx); ok {
+		reqID = id
+	} else if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(RequestIDKey)) > 0 && md.Get(RequestIDKey)[0] != "" {
+		reqID = md.Get(RequestIDKey)[0]
+	} else {
+		reqID = logx.NewRequestID()
+	}
+	ctx = logx.WithRequestID(ctx, reqID)
+
+	// expose request id to clients
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, reqID))
+
+	l := logx.Logger().With(
+		"method", method,
+		"peer", peerAddr(ctx),
+		"request_id", reqID,
+	)
+	return logx.WithLogger(ctx, l)
+}
+
+func recovered(ctx context.Context, rec any) error {
+	logx.LoggerFromContext(ctx).ErrorContext(ctx,
+		"grpc handler panic",
+		"panic", rec,
+		"stack", string(debug.Stack()),
+	)
+	return status.Error(codes.Internal, codes.Internal.String())
+}
+
+func logCompleted(ctx context.Context, msg, method string, start time.Time, err error) {
+	code := status.Code(err)
+
+	fields := []any{
+		"method", method,
+		"peer", peerAddr(ctx),
+		"code", code.String(),
+		"duration", logx.Now().Sub(start),
+	}
+	if id, ok := logx.RequestID(ctx); ok {
+		fields = append(fields, "request_id", id)
+	}
+	if err != nil {
+		fields = append(fields, "error", err)
+	}
+
+	// use request-scoped logger
+	logx.LoggerFromContext(ctx).Log(ctx, codeLevel(code), msg, fields...)
+}
+
+// codeLevel maps gRPC status codes to log levels the way httpx maps HTTP
+// statuses: caller errors are warnings and server errors are errors.
+func codeLevel(code codes.Code) slog.Level {
+	switch code {
+	case codes.OK:
+		return slog.LevelInfo
+	case codes.Canceled, codes.InvalidArgument, codes.NotFound, codes.AlreadyExists,
+		codes.PermissionDenied, codes.Unauthenticated, codes.FailedPrecondition,
+		codes.OutOfRange, codes.ResourceExhausted, codes.Aborted:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
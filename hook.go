@@ -0,0 +1,176 @@
+package logx
+
+// hook.go notifies application hooks (error trackers, metrics, alerts) of
+// records after they were written, without a full slog.Handler.
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Hook is notified of records that pass the level check, after they were
+// written to the outputs. r carries the attrs added with Logger.With and
+// open groups as group attrs, so hooks see what the outputs saw. Hooks run
+// on the logging goroutine; slow work belongs in a queue. A panicking hook
+// is ignored.
+type Hook interface {
+	Fire(ctx context.Context, r slog.Record)
+}
+
+// HookFunc adapts a function to Hook.
+type HookFunc func(ctx context.Context, r slog.Record)
+
+// Fire calls f(ctx, r).
+func (f HookFunc) Fire(ctx context.Context, r slog.Record) { f(ctx, r) }
+
+// levelHook is a hook added with AddHook.
+type levelHook struct {
+	level slog.Level
+	hook  Hook
+}
+
+var (
+	hooksMu      sync.Mutex
+	runtimeHooks atomic.Pointer[[]*levelHook]
+)
+
+// AddHook fires fn for records at or above level until the returned
+// function is called. Unlike Config.Hooks, hooks added at runtime are kept
+// across Configure calls; Reset and ClearHooks remove them. AddHook and the
+// returned function are safe to call while logging.
+func AddHook(level slog.Level, fn func(ctx context.Context, r slog.Record)) (remove func()) {
+	lh := &levelHook{level: level, hook: HookFunc(fn)}
+	hooksMu.Lock()
+	var hooks []*levelHook
+	if p := runtimeHooks.Load(); p != nil {
+		hooks = slices.Clone(*p)
+	}
+	hooks = append(hooks, lh)
+	runtimeHooks.Store(&hooks)
+	hooksMu.Unlock()
+
+	return func() {
+		hooksMu.Lock()
+		defer hooksMu.Unlock()
+		p := runtimeHooks.Load()
+		if p == nil {
+			return
+		}
+		hooks := slices.DeleteFunc(slices.Clone(*p), func(h *levelHook) bool { return h == lh })
+		runtimeHooks.Store(&hooks)
+	}
+}
+
+// ClearHooks removes all hooks added with AddHook.
+func ClearHooks() {
+	hooksMu.Lock()
+	runtimeHooks.Store(nil)
+	hooksMu.Unlock()
+}
+
+// hookHandler fires Config.Hooks and runtime hooks after next handled a
+// record.
+type hookHandler struct {
+	next  slog.Handler
+	hooks []Hook
+	scope attrScope
+}
+
+func newHookHandler(next slog.Handler, hooks []Hook) slog.Handler {
+	return &hookHandler{next: next, hooks: hooks}
+}
+
+func (h *hookHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *hookHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := h.next.Handle(ctx, r)
+
+	hooks := slices.Clip(h.hooks)
+	if p := runtimeHooks.Load(); p != nil {
+		for _, lh := range *p {
+			if r.Level >= lh.level {
+				hooks = append(hooks, lh.hook)
+			}
+		}
+	}
+	if len(hooks) == 0 {
+		return err
+	}
+
+	full := h.scope.full(r)
+	for _, hook := range hooks {
+		fireHook(ctx, hook, full)
+	}
+	return err
+}
+
+func fireHook(ctx context.Context, hook Hook, r slog.Record) {
+	defer func() { _ = recover() }()
+	hook.Fire(ctx, r.Clone())
+}
+
+func (h *hookHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.next = h.next.WithAttrs(attrs)
+	c.scope = h.scope.withAttrs(attrs)
+	return &c
+}
+
+func (h *hookHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.next = h.next.WithGroup(name)
+	c.scope = h.scope.withGroup(name)
+	return &c
+}
+
+// attrScope tracks the attrs and groups added to a handler, so that it can
+// hand a record with all of them to application code.
+type attrScope struct {
+	groups []string
+	pre    []depthAttr
+}
+
+func (s attrScope) withAttrs(attrs []slog.Attr) attrScope {
+	s.pre = slices.Clip(s.pre)
+	for _, a := range attrs {
+		s.pre = append(s.pre, depthAttr{depth: len(s.groups), attr: a})
+	}
+	return s
+}
+
+func (s attrScope) withGroup(name string) attrScope {
+	s.groups = append(slices.Clip(s.groups), name)
+	return s
+}
+
+// full returns r with the scope's attrs, with r's own attrs placed in the
+// innermost open group.
+func (s attrScope) full(r slog.Record) slog.Record {
+	if len(s.pre) == 0 && len(s.groups) == 0 {
+		return r
+	}
+	full := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	var attrs []slog.Attr
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+	for _, p := range s.pre {
+		if p.depth == 0 {
+			full.AddAttrs(p.attr)
+		}
+	}
+	if len(s.groups) > 0 {
+		attrs = nestAttrs(s.groups, s.pre, 1, attrs)
+	}
+	full.AddAttrs(attrs...)
+	return full
+}
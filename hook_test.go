@@ -0,0 +1,135 @@
+package logx
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+type recordingHook struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordingHook) Fire(_ context.Context, r slog.Record) {
+	h.mu.Lock()
+	h.records = append(h.records, r)
+	h.mu.Unlock()
+}
+
+func attrMap(r slog.Record) map[string]slog.Value {
+	m := map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		m[a.Key] = a.Value
+		return true
+	})
+	return m
+}
+
+func TestHooks_SeeWithAttrsGroupsAndStack(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	hook := &recordingHook{}
+	w := &trackingWriteCloser{}
+	err := Configure(Config{
+		FileWriter:       w,
+		Hooks:            []Hook{hook},
+		StacktraceLevel:  slog.LevelError,
+		StacktraceFrames: true,
+	})
+	if err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+
+	Logger().With("request_id", "r1").WithGroup("db").With("table", "users").Error("query failed", "rows", 0)
+	Debug("below level")
+
+	if len(hook.records) != 1 {
+		t.Fatalf("expected 1 hooked record, got %d", len(hook.records))
+	}
+	attrs := attrMap(hook.records[0])
+	if attrs["request_id"].String() != "r1" {
+		t.Fatalf("expected request_id from With, got %v", attrs)
+	}
+	db := attrs["db"].Group()
+	if len(db) != 2 || db[0].Key != "table" || db[1].Key != "rows" {
+		t.Fatalf("expected db group with table and rows, got %v", db)
+	}
+	if _, ok := attrs["stack"].Any().([]StackFrame); !ok {
+		t.Fatalf("expected structured stack, got %v", attrs["stack"])
+	}
+	assertContains(t, w.String(), "query failed")
+	assertContains(t, Describe().Decorators[len(Describe().Decorators)-1], "hooks")
+}
+
+type panicHook struct{}
+
+func (panicHook) Fire(context.Context, slog.Record) { panic("boom") }
+
+func TestHooks_PanicIsIgnored(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	hook := &recordingHook{}
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w, Hooks: []Hook{panicHook{}, hook}}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Info("still logged")
+
+	assertContains(t, w.String(), "still logged")
+	if len(hook.records) != 1 {
+		t.Fatalf("expected later hooks to run, got %d records", len(hook.records))
+	}
+}
+
+func TestAddHook_LevelFilterRemoveAndReconfigure(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var mu sync.Mutex
+	var got []string
+	remove := AddHook(slog.LevelWarn, func(_ context.Context, r slog.Record) {
+		mu.Lock()
+		got = append(got, r.Message)
+		mu.Unlock()
+	})
+
+	if err := Configure(Config{FileWriter: &trackingWriteCloser{}}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Info("info")
+	Warn("warn")
+	if err := Configure(Config{FileWriter: &trackingWriteCloser{}}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Error("after reconfigure")
+	remove()
+	Error("after remove")
+
+	if len(got) != 2 || got[0] != "warn" || got[1] != "after reconfigure" {
+		t.Fatalf("unexpected hooked messages: %v", got)
+	}
+}
+
+func TestAddHook_ConcurrentWithLogging(t *testing.T) {
+	Reset()
+	defer Reset()
+	if err := Configure(Config{FileWriter: &trackingWriteCloser{}}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 100 {
+				remove := AddHook(slog.LevelInfo, func(context.Context, slog.Record) {})
+				Info("x")
+				remove()
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,237 @@
+package httpx
+
+// accesslog.go renders completion records in Apache/Nginx access log
+// formats, so tools such as GoAccess and awstats can read them unchanged.
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// CommonLogFormat is Apache's "common" format.
+	CommonLogFormat = `%h %l %u %t "%r" %>s %b`
+	// CombinedLogFormat is Apache's "combined" format, also Nginx's default.
+	CombinedLogFormat = `%h %l %u %t "%r" %>s %b "%{Referer}i" "%{User-Agent}i"`
+)
+
+// NewAccessLogHandler returns a slog.Handler that writes completion
+// records from HTTPMiddleware as access log lines in format, for use as
+// MiddlewareOptions.AccessLog:
+//
+//	AccessLog: slog.New(httpx.NewAccessLogHandler(w, httpx.CombinedLogFormat))
+//
+// format supports Apache's %h %l %u %t %r %m %U %H %s %>s %b %B %D %T,
+// %{Referer}i and %{User-Agent}i, plus %{key}L for any other record attr
+// (e.g. %{request_id}L). Missing values are written as "-". As in Apache
+// and Nginx, '"' and '\' are backslash-escaped and control and non-ASCII
+// bytes are written as \xHH, so a client cannot forge fields or lines. The
+// handler accepts every level.
+func NewAccessLogHandler(w io.Writer, format string) slog.Handler {
+	return &accessLogHandler{w: w, mu: new(sync.Mutex), format: format}
+}
+
+type accessLogHandler struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	format string
+	attrs  []slog.Attr
+	prefix string
+}
+
+func (h *accessLogHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *accessLogHandler) Handle(_ context.Context, r slog.Record) error {
+	vals := make(map[string]slog.Value, 12)
+	for _, a := range h.attrs {
+		collectAttr(vals, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		collectAttr(vals, h.prefix, a)
+		return true
+	})
+
+	var b strings.Builder
+	renderAccessLine(&b, h.format, r.Time, vals)
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *accessLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		if h.prefix != "" {
+			a.Key = h.prefix + a.Key
+		}
+		c.attrs = append(c.attrs, a)
+	}
+	return &c
+}
+
+func (h *accessLogHandler) WithGroup(name string) slog.Handler {
+	c := *h
+	c.prefix = h.prefix + name + "."
+	return &c
+}
+
+// collectAttr stores a under its dotted key.
+func collectAttr(vals map[string]slog.Value, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			collectAttr(vals, prefix, ga)
+		}
+		return
+	}
+	vals[prefix+a.Key] = v
+}
+
+func renderAccessLine(b *strings.Builder, format string, t time.Time, vals map[string]slog.Value) {
+	str := func(key string) string {
+		if v, ok := vals[key]; ok {
+			if s := v.String(); s != "" {
+				return escapeAccessValue(s)
+			}
+		}
+		return "-"
+	}
+
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' || i+1 == len(format) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		// %{name}X
+		if format[i] == '{' {
+			end := strings.IndexByte(format[i:], '}')
+			if end < 0 || i+end+1 >= len(format) {
+				b.WriteString(format[i-1:])
+				return
+			}
+			name := format[i+1 : i+end]
+			i += end + 1
+			switch format[i] {
+			case 'i':
+				switch strings.ToLower(name) {
+				case "referer":
+					b.WriteString(str("referer"))
+				case "user-agent":
+					b.WriteString(str("user_agent"))
+				default:
+					b.WriteByte('-')
+				}
+			case 'L':
+				b.WriteString(str(name))
+			default:
+				b.WriteByte('-')
+			}
+			continue
+		}
+		if format[i] == '>' && i+1 < len(format) {
+			i++
+		}
+		switch format[i] {
+		case 'h':
+			host := str("remote_addr")
+			if h, _, err := net.SplitHostPort(host); err == nil {
+				host = h
+			}
+			b.WriteString(host)
+		case 'l', 'u':
+			b.WriteByte('-')
+		case 't':
+			b.WriteString(t.Format("[02/Jan/2006:15:04:05 -0700]"))
+		case 'r':
+			b.WriteString(str("method") + " " + str("url") + " " + str("proto"))
+		case 'm':
+			b.WriteString(str("method"))
+		case 'U':
+			u := str("url")
+			if j := strings.IndexByte(u, '?'); j >= 0 {
+				u = u[:j]
+			}
+			b.WriteString(u)
+		case 'H':
+			b.WriteString(str("proto"))
+		case 's':
+			b.WriteString(str("status"))
+		case 'b', 'B':
+			n := intValue(vals["bytes"])
+			if n == 0 && format[i] == 'b' {
+				b.WriteByte('-')
+			} else {
+				b.WriteString(strconv.FormatInt(n, 10))
+			}
+		case 'D':
+			b.WriteString(strconv.FormatInt(durationValue(vals["duration"]).Microseconds(), 10))
+		case 'T':
+			b.WriteString(strconv.FormatInt(int64(durationValue(vals["duration"])/time.Second), 10))
+		case '%':
+			b.WriteByte('%')
+		default:
+			fmt.Fprintf(b, "%%%c", format[i])
+		}
+	}
+}
+
+// escapeAccessValue escapes s the way Apache's ap_escape_logitem does.
+func escapeAccessValue(s string) string {
+	clean := true
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c >= 0x7f || c == '"' || c == '\\' {
+			clean = false
+			break
+		}
+	}
+	if clean {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+func intValue(v slog.Value) int64 {
+	switch v.Kind() {
+	case slog.KindInt64:
+		return v.Int64()
+	case slog.KindUint64:
+		return int64(v.Uint64())
+	default:
+		return 0
+	}
+}
+
+func durationValue(v slog.Value) time.Duration {
+	if v.Kind() == slog.KindDuration {
+		return v.Duration()
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package httpx
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessLogHandler_CombinedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	clock := []time.Time{start, start.Add(1500 * time.Millisecond)}
+
+	handler := HTTPMiddlewareWithOptions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}), MiddlewareOptions{
+		AccessLog: slog.New(NewAccessLogHandler(&buf, CombinedLogFormat+` %D %{request_id}L`)),
+		Now: func() time.Time {
+			now := clock[0]
+			clock = clock[1:]
+			return now
+		},
+	})
+
+	req := httptest.NewRequest("GET", "/items?page=2", nil)
+	req.RemoteAddr = "203.0.113.9:5123"
+	req.Header.Set("Referer", "https://example.com/")
+	req.Header.Set("User-Agent", "curl/8.0")
+	req.Header.Set("X-Request-ID", "rid-9")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	line := strings.TrimSuffix(buf.String(), "\n")
+	// %t uses the record time, so only check its shape
+	prefix, rest, ok := strings.Cut(line, " [")
+	if !ok || prefix != "203.0.113.9 - -" {
+		t.Fatalf("unexpected prefix: %q", line)
+	}
+	_, rest, _ = strings.Cut(rest, "] ")
+	want := `"GET /items?page=2 HTTP/1.1" 200 5 "https://example.com/" "curl/8.0" 1500000 rid-9`
+	if rest != want {
+		t.Fatalf("unexpected line:\n got %s\nwant %s", rest, want)
+	}
+}
+
+func TestAccessLogHandler_MissingValues(t *testing.T) {
+	var buf bytes.Buffer
+	slog.New(NewAccessLogHandler(&buf, CommonLogFormat+" %T %B %q")).Info("other record")
+
+	_, rest, _ := strings.Cut(buf.String(), "] ")
+	if rest != `"- - -" - - 0 0 %q`+"\n" {
+		t.Fatalf("unexpected line: %q", rest)
+	}
+}
+
+func TestAccessLogHandler_EscapesRequestValues(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(NewAccessLogHandler(&buf, CombinedLogFormat))
+	l.Info("request completed",
+		"method", "GET",
+		"url", "/a\"b",
+		"proto", "HTTP/1.1",
+		"referer", `x" 200 0 "forged`,
+		"user_agent", "evil\n203.0.113.1 - - [01/Jan/2026:00:00:00 +0000] \"GET / HTTP/1.1\" 200 0\\\xff",
+	)
+
+	if strings.Count(buf.String(), "\n") != 1 {
+		t.Fatalf("expected a single line: %q", buf.String())
+	}
+	_, rest, _ := strings.Cut(buf.String(), "] ")
+	want := `"GET /a\"b HTTP/1.1" - - "x\" 200 0 \"forged" "evil\x0a203.0.113.1 - - [01/Jan/2026:00:00:00 +0000] \"GET / HTTP/1.1\" 200 0\\\xff"` + "\n"
+	if rest != want {
+		t.Fatalf("unexpected line:\n got %s\nwant %s", rest, want)
+	}
+}
@@ -5,7 +5,6 @@ package httpx
 import (
 	"log/slog"
 	"net/http"
-	"time"
 
 	"github.com/rannday/logx"
 )
@@ -24,7 +23,7 @@ func Transport(rt http.RoundTripper) http.RoundTripper {
 }
 
 func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	start := time.Now()
+	start := logx.Now()
 
 	if req == nil {
 		return t.next.RoundTrip(req)
@@ -32,7 +31,7 @@ func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	l := logx.LoggerFromContext(req.Context())
 
 	resp, err := t.next.RoundTrip(req)
-	duration := time.Since(start)
+	duration := logx.Since(start)
 
 	var urlStr, host string
 	if req.URL != nil {
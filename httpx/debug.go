@@ -0,0 +1,163 @@
+package httpx
+
+// debug.go implements short-lived debug session tokens. An admin endpoint
+// issues a token; requests presenting it in the X-Debug-Token header are
+// logged at DEBUG level with request bodies captured, without touching the
+// global level for other traffic.
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rannday/logx"
+)
+
+// DebugTokenHeader is the request header carrying a debug session token.
+const DebugTokenHeader = "X-Debug-Token"
+
+// DebugSessions issues and validates ephemeral debug session tokens.
+// Use Handler to expose issuance on an admin endpoint and set
+// MiddlewareOptions.DebugSessions to honor tokens on incoming requests.
+type DebugSessions struct {
+	// TTL is the default token lifetime. If 0, default is 15 minutes.
+	TTL time.Duration
+	// MaxTTL caps the lifetime callers may request. If 0, default is 1 hour.
+	MaxTTL time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]time.Time
+}
+
+// NewDebugSessions constructs a DebugSessions with the given default TTL.
+func NewDebugSessions(ttl time.Duration) *DebugSessions {
+	return &DebugSessions{TTL: ttl, tokens: map[string]time.Time{}}
+}
+
+// Issue creates a token valid for ttl (the default TTL when ttl <= 0,
+// capped at MaxTTL) and returns it with its expiry.
+func (d *DebugSessions) Issue(ttl time.Duration) (string, time.Time, error) {
+	if ttl <= 0 {
+		ttl = d.TTL
+		if ttl <= 0 {
+			ttl = 15 * time.Minute
+		}
+	}
+	maxTTL := d.MaxTTL
+	if maxTTL <= 0 {
+		maxTTL = time.Hour
+	}
+	ttl = min(ttl, maxTTL)
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(b)
+	now := logx.Now()
+	expires := now.Add(ttl)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.tokens == nil {
+		d.tokens = map[string]time.Time{}
+	}
+	d.pruneLocked(now)
+	d.tokens[token] = expires
+
+	return token, expires, nil
+}
+
+// Valid reports whether token was issued and has not expired.
+func (d *DebugSessions) Valid(token string) bool {
+	if token == "" {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	expires, ok := d.tokens[token]
+	if !ok {
+		return false
+	}
+	if logx.Now().After(expires) {
+		delete(d.tokens, token)
+		return false
+	}
+	return true
+}
+
+// Revoke invalidates token before its expiry.
+func (d *DebugSessions) Revoke(token string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.tokens, token)
+}
+
+func (d *DebugSessions) pruneLocked(now time.Time) {
+	for t, exp := range d.tokens {
+		if now.After(exp) {
+			delete(d.tokens, t)
+		}
+	}
+}
+
+// Handler returns an admin http.Handler that issues a token on POST, with
+// an optional "ttl" query parameter (a Go duration). Each token is recorded
+// with "reason" as a logx.Audit event, or logged at INFO when no audit
+// output is configured. Mount it behind your own admin authentication;
+// anyone who can reach it can enable debug logging.
+func (d *DebugSessions) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		var ttl time.Duration
+		if s := r.URL.Query().Get("ttl"); s != "" {
+			v, err := time.ParseDuration(s)
+			if err != nil {
+				http.Error(w, "invalid ttl", http.StatusBadRequest)
+				return
+			}
+			ttl = v
+		}
+
+		token, expires, err := d.Issue(ttl)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		attrs := []any{
+			slog.String("debug_session", tokenID(token)),
+			slog.Time("expires_at", expires),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("reason", r.URL.Query().Get("reason")),
+		}
+		if err := logx.AuditContext(r.Context(), "debug session issued", attrs...); errors.Is(err, logx.ErrNoAuditSink) {
+			logx.LoggerFromContext(r.Context()).InfoContext(r.Context(), "debug session issued", attrs...)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(struct {
+			Token     string    `json:"token"`
+			ExpiresAt time.Time `json:"expires_at"`
+		}{token, expires})
+	})
+}
+
+// tokenID returns a short, non-secret identifier for audit fields.
+func tokenID(token string) string {
+	if len(token) > 8 {
+		return token[:8]
+	}
+	return token
+}
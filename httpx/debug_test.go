@@ -0,0 +1,115 @@
+package httpx
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rannday/logx"
+)
+
+type bufWriteCloser struct{ bytes.Buffer }
+
+func (b *bufWriteCloser) Close() error { return nil }
+
+func TestDebugSessions_HandlerIssuesAuditedToken(t *testing.T) {
+	out := captureMiddleware(t, func() {
+		d := NewDebugSessions(time.Minute)
+
+		rec := httptest.NewRecorder()
+		d.Handler().ServeHTTP(rec, httptest.NewRequest("POST", "/debug?reason=ticket-42", nil))
+
+		var resp struct {
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode failed: %v", err)
+		}
+		if !d.Valid(resp.Token) {
+			t.Fatalf("expected issued token to be valid")
+		}
+		d.Revoke(resp.Token)
+		if d.Valid(resp.Token) {
+			t.Fatalf("expected revoked token to be invalid")
+		}
+	})
+
+	if !strings.Contains(out, "debug session issued") || !strings.Contains(out, "reason=ticket-42") {
+		t.Fatalf("expected audit record, got: %s", out)
+	}
+}
+
+func TestDebugSessions_HandlerWritesAuditEvent(t *testing.T) {
+	logx.Reset()
+	defer logx.Reset()
+
+	var logs, audit bufWriteCloser
+	if err := logx.Configure(logx.Config{Level: slog.LevelError, FileWriter: &logs, AuditWriter: &audit}); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+	NewDebugSessions(time.Minute).Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/debug?reason=ticket-42", nil))
+
+	if !strings.Contains(audit.String(), `"msg":"debug session issued"`) || !strings.Contains(audit.String(), `"reason":"ticket-42"`) {
+		t.Fatalf("expected an audit event despite the level, got: %s", audit.String())
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("expected nothing in the application log, got: %s", logs.String())
+	}
+}
+
+func TestDebugSessions_ExpiredTokenInvalid(t *testing.T) {
+	defer logx.SetClock(nil)
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	logx.SetClock(func() time.Time { return now })
+
+	d := NewDebugSessions(time.Minute)
+	tok, expires, err := d.Issue(0)
+	if err != nil {
+		t.Fatalf("issue failed: %v", err)
+	}
+	if !expires.Equal(now.Add(time.Minute)) || !d.Valid(tok) {
+		t.Fatalf("expected the token to be valid until %s, got %s", now.Add(time.Minute), expires)
+	}
+	now = now.Add(time.Minute + time.Second)
+	if d.Valid(tok) {
+		t.Fatalf("expected expired token to be invalid")
+	}
+}
+
+func TestMiddleware_DebugSessionEnablesDebugAndBody(t *testing.T) {
+	logx.Reset()
+	defer logx.Reset()
+
+	var buf bufWriteCloser
+	if err := logx.Configure(logx.Config{Level: slog.LevelInfo, FileWriter: &buf}); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	d := NewDebugSessions(time.Minute)
+	tok, _, _ := d.Issue(0)
+
+	handler := HTTPMiddlewareWithOptions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		logx.LoggerFromContext(r.Context()).DebugContext(r.Context(), "handler detail", "len", len(b))
+	}), MiddlewareOptions{DebugSessions: d})
+
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader("payload"))
+	req.Header.Set(DebugTokenHeader, tok)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/orders", strings.NewReader("other")))
+
+	out := buf.String()
+	if strings.Count(out, "handler detail") != 1 {
+		t.Fatalf("expected debug record only for the debug session request, got: %s", out)
+	}
+	if !strings.Contains(out, "len=7") || !strings.Contains(out, "req_body=payload") {
+		t.Fatalf("expected full body for handler and captured body in log, got: %s", out)
+	}
+}
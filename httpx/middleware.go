@@ -2,10 +2,13 @@ package httpx
 
 import (
 	"bufio"
+	"bytes"
+	"io"
 	"log/slog"
 	"net"
 	"net/http"
 	"runtime/debug"
+	"sync/atomic"
 	"time"
 
 	"github.com/rannday/logx"
@@ -15,8 +18,67 @@ import (
 // status-level mapping, panic recovery, and a request-scoped logger stored
 // in the request context (accessible via logx.LoggerFromContext).
 func HTTPMiddleware(next http.Handler) http.Handler {
+	return HTTPMiddlewareWithOptions(next, MiddlewareOptions{})
+}
+
+// MiddlewareOptions configures HTTPMiddlewareWithOptions.
+type MiddlewareOptions struct {
+	// SampleEvery logs one in every N successful (2xx) requests.
+	// 0 or 1 logs every request.
+	SampleEvery int
+	// SampleRate logs this fraction (0 < rate < 1) of successful requests.
+	// Ignored when SampleEvery is set; 0 or >= 1 logs every request.
+	SampleRate float64
+	// SlowThreshold always logs requests that take at least this long,
+	// regardless of sampling (0 = disabled).
+	SlowThreshold time.Duration
+	// DebugSessions enables DEBUG level and request body logging for
+	// requests carrying a valid DebugTokenHeader. Such requests bypass sampling.
+	DebugSessions *DebugSessions
+	// MaxBodyLogBytes limits request bodies captured for debug sessions.
+	// If 0, default is 32*1024.
+	MaxBodyLogBytes int
+	// Rand returns values in [0, 1) for SampleRate decisions
+	// (nil = logx.RandFloat64, see logx.SetRandSource). Tests can inject a
+	// fixed sequence.
+	Rand func() float64
+	// Now is the clock used for durations and SlowThreshold
+	// (nil = logx.Now, see logx.SetClock).
+	Now func() time.Time
+	// Stats, if set, counts sampling decisions.
+	Stats *SamplingStats
+	// AccessLog, if set, receives the completion records instead of the
+	// request-scoped logger, keeping access logs out of application logs.
+	// Panics are still logged to the request-scoped logger.
+	AccessLog *slog.Logger
+}
+
+// SamplingStats counts completion records logged and successful requests
+// dropped by sampling. It is safe for concurrent use.
+type SamplingStats struct {
+	logged  atomic.Uint64
+	dropped atomic.Uint64
+}
+
+// Logged returns the number of completion records emitted.
+func (s *SamplingStats) Logged() uint64 { return s.logged.Load() }
+
+// Dropped returns the number of successful requests skipped by sampling.
+func (s *SamplingStats) Dropped() uint64 { return s.dropped.Load() }
+
+// HTTPMiddlewareWithOptions is like HTTPMiddleware but allows sampling of
+// completion logs and debug sessions. Only 2xx responses are sampled:
+// redirects, client and server errors (status >= 300), panics and slow
+// requests are always logged.
+func HTTPMiddlewareWithOptions(next http.Handler, opts MiddlewareOptions) http.Handler {
+	var counter atomic.Uint64
+	now := opts.Now
+	if now == nil {
+		now = logx.Now
+	}
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
+		start := now()
 
 		// populate request-scoped logger and ensure a request id
 		ctx := r.Context()
@@ -30,6 +92,16 @@ func HTTPMiddleware(next http.Handler) http.Handler {
 		}
 		ctx = logx.WithRequestID(ctx, reqID)
 
+		// debug session: lower the level for this request only
+		var session, reqBody string
+		if opts.DebugSessions != nil {
+			if tok := r.Header.Get(DebugTokenHeader); opts.DebugSessions.Valid(tok) {
+				session = tokenID(tok)
+				ctx = logx.WithLevel(ctx, slog.LevelDebug)
+				reqBody = captureRequestBody(r, opts.MaxBodyLogBytes)
+			}
+		}
+
 		// build per-request logger with useful fields
 		l := logx.Logger().With(
 			"remote_addr", r.RemoteAddr,
@@ -40,6 +112,9 @@ func HTTPMiddleware(next http.Handler) http.Handler {
 		if id, ok := logx.RequestID(ctx); ok {
 			l = l.With("request_id", id)
 		}
+		if session != "" {
+			l = l.With("debug_session", session)
+		}
 
 		ctx = logx.WithLogger(ctx, l)
 		// update request with new context
@@ -67,7 +142,18 @@ func HTTPMiddleware(next http.Handler) http.Handler {
 				http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 			}
 
-			duration := time.Since(start)
+			duration := now().Sub(start)
+
+			slow := opts.SlowThreshold > 0 && duration >= opts.SlowThreshold
+			if rw.status < 300 && !slow && session == "" && !opts.sampled(&counter) {
+				if opts.Stats != nil {
+					opts.Stats.dropped.Add(1)
+				}
+				return
+			}
+			if opts.Stats != nil {
+				opts.Stats.logged.Add(1)
+			}
 
 			fields := []any{
 				"method", r.Method,
@@ -77,11 +163,21 @@ func HTTPMiddleware(next http.Handler) http.Handler {
 				"remote_addr", r.RemoteAddr,
 				"user_agent", r.UserAgent(),
 				"bytes", rw.bytes,
+				"proto", r.Proto,
 			}
 
+			if ref := r.Referer(); ref != "" {
+				fields = append(fields, "referer", ref)
+			}
 			if id, ok := logx.RequestID(r.Context()); ok {
 				fields = append(fields, "request_id", id)
 			}
+			if slow {
+				fields = append(fields, "slow", true)
+			}
+			if session != "" {
+				fields = append(fields, "req_body", reqBody)
+			}
 
 			level := slog.LevelInfo
 			switch {
@@ -91,17 +187,56 @@ func HTTPMiddleware(next http.Handler) http.Handler {
 				level = slog.LevelWarn
 			}
 
-			// use request-scoped logger
-			logx.LoggerFromContext(r.Context()).Log(r.Context(), level,
-				"http request completed",
-				fields...,
-			)
+			// use the access log or the request-scoped logger
+			out := opts.AccessLog
+			if out == nil {
+				out = logx.LoggerFromContext(r.Context())
+			}
+			out.Log(r.Context(), level, "http request completed", fields...)
 		}()
 
 		next.ServeHTTP(rw, r)
 	})
 }
 
+// captureRequestBody reads up to max bytes of the request body for logging
+// and restores the body so the handler still sees the full stream.
+func captureRequestBody(r *http.Request, max int) string {
+	if r.Body == nil || r.Body == http.NoBody {
+		return ""
+	}
+	if max <= 0 {
+		max = 32 * 1024
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, int64(max)))
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(b), r.Body), r.Body}
+	if err != nil {
+		return ""
+	}
+	return redactBody(b, r.Header.Get("Content-Type"), max)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// sampled reports whether a successful request should be logged.
+func (o MiddlewareOptions) sampled(counter *atomic.Uint64) bool {
+	switch {
+	case o.SampleEvery > 1:
+		return (counter.Add(1)-1)%uint64(o.SampleEvery) == 0
+	case o.SampleRate > 0 && o.SampleRate < 1:
+		if o.Rand != nil {
+			return o.Rand() < o.SampleRate
+		}
+		return logx.RandFloat64() < o.SampleRate
+	default:
+		return true
+	}
+}
+
 type responseWriter struct {
 	http.ResponseWriter
 	status int
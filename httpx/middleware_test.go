@@ -7,22 +7,19 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/rannday/logx"
+	"github.com/rannday/logx/logxtest"
 )
 
 func captureMiddleware(t *testing.T, fn func()) string {
 	t.Helper()
 
-	logx.Reset()
-
-	var buf bytes.Buffer
-	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug, AddSource: false})
-	logx.SetLogger(slog.New(handler))
-
+	rec := logxtest.Swap(t)
 	fn()
 
-	return buf.String()
+	return rec.String()
 }
 
 func TestMiddleware_LogsStatus(t *testing.T) {
@@ -62,3 +59,108 @@ func TestMiddleware_RecoversPanic(t *testing.T) {
 		t.Fatalf("expected stack trace")
 	}
 }
+
+func TestMiddlewareWithOptions_SamplesSuccessfulRequests(t *testing.T) {
+	out := captureMiddleware(t, func() {
+		handler := HTTPMiddlewareWithOptions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/fail":
+				w.WriteHeader(500)
+			case "/moved":
+				w.WriteHeader(301)
+			}
+		}), MiddlewareOptions{SampleEvery: 3})
+
+		for i := 0; i < 6; i++ {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+		}
+		for i := 0; i < 2; i++ {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/moved", nil))
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+	})
+
+	if got := strings.Count(out, "status=200"); got != 2 {
+		t.Fatalf("expected 2 sampled success logs, got %d: %s", got, out)
+	}
+	if got := strings.Count(out, "status=301"); got != 2 {
+		t.Fatalf("expected every redirect to bypass sampling, got %d: %s", got, out)
+	}
+	if !strings.Contains(out, "status=500") {
+		t.Fatalf("expected error request to bypass sampling")
+	}
+}
+
+func TestMiddlewareWithOptions_DeterministicRateAndClock(t *testing.T) {
+	rolls := []float64{0.1, 0.7, 0.2, 0.9}
+	i := 0
+	// each request reads the clock twice; every second call advances 5ms
+	var clock time.Time
+	calls := 0
+	stats := &SamplingStats{}
+
+	out := captureMiddleware(t, func() {
+		handler := HTTPMiddlewareWithOptions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), MiddlewareOptions{
+			SampleRate:    0.5,
+			SlowThreshold: time.Hour,
+			Rand:          func() float64 { v := rolls[i]; i++; return v },
+			Now: func() time.Time {
+				calls++
+				if calls%2 == 0 {
+					clock = clock.Add(5 * time.Millisecond)
+				}
+				return clock
+			},
+			Stats: stats,
+		})
+
+		for range rolls {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ok", nil))
+		}
+	})
+
+	if got := strings.Count(out, "status=200"); got != 2 {
+		t.Fatalf("expected 2 sampled logs, got %d: %s", got, out)
+	}
+	if got := strings.Count(out, "duration=5ms"); got != 2 {
+		t.Fatalf("expected injected clock durations, got: %s", out)
+	}
+	if stats.Logged() != 2 || stats.Dropped() != 2 {
+		t.Fatalf("unexpected stats: logged=%d dropped=%d", stats.Logged(), stats.Dropped())
+	}
+}
+
+func TestMiddlewareWithOptions_AlwaysLogsSlowRequests(t *testing.T) {
+	out := captureMiddleware(t, func() {
+		handler := HTTPMiddlewareWithOptions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(2 * time.Millisecond)
+		}), MiddlewareOptions{SampleRate: 0.000001, SlowThreshold: time.Millisecond})
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+	})
+
+	if !strings.Contains(out, "slow=true") {
+		t.Fatalf("expected slow request to be logged, got: %s", out)
+	}
+}
+
+func TestMiddlewareWithOptions_AccessLog(t *testing.T) {
+	var access bytes.Buffer
+	out := captureMiddleware(t, func() {
+		handler := HTTPMiddlewareWithOptions(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logx.LoggerFromContext(r.Context()).Info("handling")
+		}), MiddlewareOptions{AccessLog: slog.New(slog.NewJSONHandler(&access, nil))})
+
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/a", nil))
+	})
+
+	if strings.Contains(out, "http request completed") {
+		t.Fatalf("expected completion record only in the access log, got: %s", out)
+	}
+	if !strings.Contains(out, "msg=handling") {
+		t.Fatalf("expected application record in the application log, got: %s", out)
+	}
+	if !strings.Contains(access.String(), `"msg":"http request completed","method":"GET","url":"/a","status":200`) {
+		t.Fatalf("unexpected access log: %s", access.String())
+	}
+}
@@ -12,7 +12,6 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
-	"time"
 
 	"github.com/rannday/logx"
 )
@@ -107,6 +106,21 @@ func redactForm(s string, redactedKeys []string) string {
 	return vals.Encode()
 }
 
+// redactBody renders a captured body for logging, masking redacted keys in
+// JSON and form payloads and truncating anything else to max bytes.
+func redactBody(b []byte, contentType string, max int) string {
+	switch {
+	case strings.Contains(contentType, "application/json"):
+		return string(redactJSON(b, logx.ListRedactedKeys()))
+	case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+		return redactForm(string(b), logx.ListRedactedKeys())
+	case len(b) > max:
+		return string(b[:max])
+	default:
+		return string(b)
+	}
+}
+
 func (t *TransportLogger) RoundTrip(req *http.Request) (*http.Response, error) {
 	// choose logger: explicit -> context -> global
 	var l *slog.Logger
@@ -133,21 +147,7 @@ func (t *TransportLogger) RoundTrip(req *http.Request) (*http.Response, error) {
 				// restore request body for actual transport
 				req.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 
-				ct := req.Header.Get("Content-Type")
-				redacted := ""
-				if strings.Contains(ct, "application/json") {
-					redacted = string(redactJSON(bodyBytes, logx.ListRedactedKeys()))
-				} else if strings.Contains(ct, "application/x-www-form-urlencoded") {
-					redacted = redactForm(string(bodyBytes), logx.ListRedactedKeys())
-				} else {
-					// default: include as string (truncated)
-					if len(bodyBytes) > max {
-						redacted = string(bodyBytes[:max])
-					} else {
-						redacted = string(bodyBytes)
-					}
-				}
-
+				redacted := redactBody(bodyBytes, req.Header.Get("Content-Type"), max)
 				fields = append(fields, "req_body", redacted)
 			}
 		} else {
@@ -162,9 +162,9 @@ func (t *TransportLogger) RoundTrip(req *http.Request) (*http.Response, error) {
 		}
 	}
 
-	start := time.Now()
+	start := logx.Now()
 	resp, err := t.rt.RoundTrip(req)
-	duration := time.Since(start)
+	duration := logx.Since(start)
 
 	// append duration
 	fields = append(fields, "duration", duration)
@@ -186,20 +186,7 @@ func (t *TransportLogger) RoundTrip(req *http.Request) (*http.Response, error) {
 				// restore response body for caller
 				resp.Body = io.NopCloser(bytes.NewReader(bodyBytes))
 
-				ct := resp.Header.Get("Content-Type")
-				redacted := ""
-				if strings.Contains(ct, "application/json") {
-					redacted = string(redactJSON(bodyBytes, logx.ListRedactedKeys()))
-				} else if strings.Contains(ct, "application/x-www-form-urlencoded") {
-					redacted = redactForm(string(bodyBytes), logx.ListRedactedKeys())
-				} else {
-					if len(bodyBytes) > max {
-						redacted = string(bodyBytes[:max])
-					} else {
-						redacted = string(bodyBytes)
-					}
-				}
-
+				redacted := redactBody(bodyBytes, resp.Header.Get("Content-Type"), max)
 				fields = append(fields, "resp_body", redacted)
 			}
 		} else {
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/rannday/logx"
+	"github.com/rannday/logx/logxtest"
 )
 
 type mockRoundTripper struct {
@@ -25,20 +26,10 @@ func (m *mockRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
 func captureHTTP(t *testing.T, fn func()) string {
 	t.Helper()
 
-	logx.Reset()
-
-	var buf bytes.Buffer
-
-	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
-		Level:     slog.LevelDebug,
-		AddSource: false,
-	})
-
-	logx.SetLogger(slog.New(handler)) // or direct assignment if internal
-
+	rec := logxtest.Swap(t)
 	fn()
 
-	return buf.String()
+	return rec.String()
 }
 
 func TestTransport_Success(t *testing.T) {
@@ -2,16 +2,19 @@ package logx
 
 import (
 	"crypto/rand"
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
-	"time"
 )
 
 // NewRequestID returns a v4-style request id (hex with dashes).
 func NewRequestID() string {
 	b := make([]byte, 16)
-	if _, err := rand.Read(b); err != nil {
-		return fmt.Sprintf("rid-%d", time.Now().UnixNano())
+	if s := randSrc.Load(); s != nil {
+		binary.LittleEndian.PutUint64(b[0:8], s.Uint64())
+		binary.LittleEndian.PutUint64(b[8:16], s.Uint64())
+	} else if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("rid-%d", Now().UnixNano())
 	}
 	// Set version and variant per RFC 4122.
 	b[6] = (b[6] & 0x0f) | 0x40
@@ -0,0 +1,186 @@
+package logx
+
+// instance.go builds loggers that are independent of the package logger,
+// for libraries and for processes that host several components with their
+// own outputs.
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+)
+
+// NewHandler builds the handler chain for cfg, with every output and
+// decorator Configure would install, without installing it: the package
+// logger, slog.Default and the SetLevel level are left alone. The closer
+// closes cfg's outputs and must be called when the handler is done.
+//
+// As for the first Configure, a failed output is reported in err and the
+// handler writes to the remaining outputs (or stderr if none remain); with
+// cfg.Strict an invalid or failed config returns a nil handler instead.
+//
+// Process-wide controls still apply: redacted keys and patterns, rate
+// limits, Disable, SetClock and SetErrorHandler. Settings that act on the
+// process rather than on records (the audit output, RecentRecords,
+// NamedLevels, CrashMarker, DetectUncleanShutdown, OnFatal, and the
+// Configure-time FileManifest check) are ignored.
+func NewHandler(cfg Config) (slog.Handler, io.Closer, error) {
+	l, err := newInstance(cfg, nil)
+	if l == nil {
+		return nil, nil, err
+	}
+	return l.Handler(), l.closer, err
+}
+
+// Instance is a logger built by New. Its outputs, level, redacted keys and
+// redaction patterns are its own, the keys and patterns starting from the
+// global ones; the other process-wide controls listed at NewHandler are
+// shared with the package logger.
+//
+// Instances derived with With and WithGroup share the level, redaction and
+// outputs of their parent.
+type Instance struct {
+	logger *slog.Logger
+	level  *slog.LevelVar
+	keys   *redactionSet // keys and patterns
+	closer io.Closer
+}
+
+// New builds an Instance for cfg, with the error semantics of NewHandler.
+// Its redacted keys and patterns start as a copy of the global ones; see
+// SetRedactedKeys and AddRedactedPatterns.
+func New(cfg Config) (*Instance, error) {
+	keys := new(redactionSet)
+	keys.add(redactedKeys.list()...)
+	keys.addPatterns(redactedKeys.loadPatterns())
+	return newInstance(cfg, keys)
+}
+
+// newInstance builds an Instance redacting with keys (nil = the global set).
+func newInstance(cfg Config, keys *redactionSet) (*Instance, error) {
+	if cfg.Strict {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	level := new(slog.LevelVar)
+	level.Set(cfg.Level)
+	l, closer, _, err := buildLogger(cfg, level, keys, nil)
+	if err != nil && cfg.Strict {
+		if closer != nil {
+			_ = closer.Close()
+		}
+		return nil, err
+	}
+	if closer == nil {
+		closer = multiCloser(nil)
+	}
+	return &Instance{logger: l, level: level, keys: keys, closer: closer}, err
+}
+
+// Slog returns the instance as a *slog.Logger.
+func (l *Instance) Slog() *slog.Logger {
+	return l.logger
+}
+
+// Handler returns the instance's handler chain.
+func (l *Instance) Handler() slog.Handler {
+	return l.logger.Handler()
+}
+
+// SetLevel updates the instance's minimum level at runtime.
+func (l *Instance) SetLevel(level slog.Level) {
+	l.level.Set(level)
+}
+
+// Level returns the instance's minimum level.
+func (l *Instance) Level() slog.Level {
+	return l.level.Level()
+}
+
+// SetRedactedKeys adds keys to the instance's redaction set, which starts
+// with the global keys set before New. Keys are normalized to lowercase.
+func (l *Instance) SetRedactedKeys(keys ...string) {
+	l.keys.add(keys...)
+}
+
+// ClearRedactedKeys removes the instance's redacted keys.
+func (l *Instance) ClearRedactedKeys() {
+	l.keys.clear()
+}
+
+// RedactedKeys returns a snapshot of the instance's redacted keys.
+func (l *Instance) RedactedKeys() []string {
+	return l.keys.list()
+}
+
+// AddRedactedPatterns adds value patterns to the instance's set, which
+// starts with the global patterns added before New.
+func (l *Instance) AddRedactedPatterns(patterns ...*regexp.Regexp) {
+	l.keys.addPatterns(toValuePatterns(patterns))
+}
+
+// ClearRedactedPatterns removes the instance's value patterns.
+func (l *Instance) ClearRedactedPatterns() {
+	l.keys.clearPatterns()
+}
+
+// UseRedactPreset adds the keys and value patterns of each preset to the
+// instance.
+func (l *Instance) UseRedactPreset(presets ...RedactPreset) {
+	for _, p := range presets {
+		l.keys.add(p.Keys...)
+		l.keys.addPatterns(p.patterns)
+	}
+}
+
+// With returns a child instance that adds args to every record.
+func (l *Instance) With(args ...any) *Instance {
+	c := *l
+	c.logger = l.logger.With(args...)
+	return &c
+}
+
+// WithGroup returns a child instance that scopes subsequent fields under name.
+func (l *Instance) WithGroup(name string) *Instance {
+	c := *l
+	c.logger = l.logger.WithGroup(name)
+	return &c
+}
+
+// Debug logs a message at debug level.
+func (l *Instance) Debug(msg string, args ...any) {
+	logDepth(l.logger, context.Background(), 0, slog.LevelDebug, msg, args...)
+}
+
+// Info logs a message at info level.
+func (l *Instance) Info(msg string, args ...any) {
+	logDepth(l.logger, context.Background(), 0, slog.LevelInfo, msg, args...)
+}
+
+// Warn logs a message at warn level.
+func (l *Instance) Warn(msg string, args ...any) {
+	logDepth(l.logger, context.Background(), 0, slog.LevelWarn, msg, args...)
+}
+
+// Error logs a message at error level.
+func (l *Instance) Error(msg string, args ...any) {
+	logDepth(l.logger, context.Background(), 0, slog.LevelError, msg, args...)
+}
+
+// ErrorErr logs at error level with the error fields of the package
+// ErrorErr.
+func (l *Instance) ErrorErr(msg string, err error, args ...any) {
+	logDepth(l.logger, context.Background(), 0, slog.LevelError, msg, errFields(err, args)...)
+}
+
+// Timed is the package Timed on the instance.
+func (l *Instance) Timed(ctx context.Context, msg string, args ...any) func(extra ...any) {
+	return timed(l.logger, slog.LevelInfo, 0, ctx, msg, args, nil)
+}
+
+// Close closes the instance's outputs, which derived instances share.
+func (l *Instance) Close() error {
+	return l.closer.Close()
+}
@@ -0,0 +1,150 @@
+package logx
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNew_InstancesAreIndependent(t *testing.T) {
+	Reset()
+	defer Reset()
+	dir := t.TempDir()
+	billingPath, searchPath := filepath.Join(dir, "billing.log"), filepath.Join(dir, "search.log")
+
+	billing, err := New(Config{Level: slog.LevelDebug, FilePath: billingPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	search, err := New(Config{Level: slog.LevelWarn, FilePath: searchPath, JSONFile: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logger.Load() != nil || Describe().Configured {
+		t.Fatalf("expected the package logger to be left alone")
+	}
+
+	billing.Slog().Debug("charge", "amount", 10)
+	search.Slog().Info("dropped")
+	search.SetLevel(slog.LevelInfo)
+	search.Slog().Info("indexed")
+	if billing.Level() != slog.LevelDebug || levelVar.Level() != slog.LevelInfo {
+		t.Fatalf("expected SetLevel to change one instance only")
+	}
+	if err := billing.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := search.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := os.ReadFile(billingPath)
+	s, _ := os.ReadFile(searchPath)
+	assertContains(t, string(b), "msg=charge amount=10")
+	assertContains(t, string(s), `"msg":"indexed"`)
+	if strings.Contains(string(s), "dropped") || strings.Contains(string(b), "indexed") {
+		t.Fatalf("expected separate outputs and levels:\n%s\n%s", b, s)
+	}
+}
+
+func TestNewHandler_AppliesDecorators(t *testing.T) {
+	defer ClearRedactedKeys()
+	SetRedactedKeys("token")
+	var buf bytes.Buffer
+	h, closer, err := NewHandler(Config{Level: slog.LevelInfo, FileWriter: nopWriteCloser{&buf}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closer.Close()
+
+	slog.New(h).With("component", "api").Info("call", "token", "abc")
+	assertContains(t, buf.String(), "msg=call component=api token=REDACTED")
+}
+
+func TestNewHandler_FailedOutputs(t *testing.T) {
+	cfg := Config{Level: slog.LevelInfo, FilePath: filepath.Join(t.TempDir(), "missing", "app.log")}
+	h, closer, err := NewHandler(cfg)
+	if !errors.Is(err, ErrFileOpen) || h == nil || closer == nil {
+		t.Fatalf("expected a degraded handler and ErrFileOpen, got %v, %v", h, err)
+	}
+	_ = closer.Close()
+
+	cfg.Strict = true
+	if l, err := New(cfg); l != nil || !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected a strict config to fail, got %v, %v", l, err)
+	}
+}
+
+func TestInstance_MethodsAndRedactionAreIndependent(t *testing.T) {
+	defer ClearRedactedKeys()
+	SetRedactedKeys("email")
+	var a, b bytes.Buffer
+	billing, err := New(Config{Level: slog.LevelDebug, FileWriter: nopWriteCloser{&a}, AddSource: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	search, err := New(Config{Level: slog.LevelInfo, FileWriter: nopWriteCloser{&b}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	billing.SetRedactedKeys("Card")
+	SetRedactedKeys("token")
+	if keys := billing.RedactedKeys(); !slices.Equal(slices.Sorted(slices.Values(keys)), []string{"card", "email"}) {
+		t.Fatalf("expected the global keys at New plus the instance's own, got %v", keys)
+	}
+
+	child := billing.With("component", "charges")
+	child.Debug("charge", "card", "4111", "email", "a@example.com", "token", "t")
+	child.ErrorErr("charge failed", errors.New("declined"))
+	done := child.WithGroup("req").Timed(t.Context(), "refund")
+	done()
+	search.Info("query", "card", "4111", "email", "a@example.com")
+	search.Debug("dropped")
+
+	out := a.String()
+	assertContains(t, out, "msg=charge component=charges card=REDACTED email=REDACTED token=t")
+	assertContains(t, out, `msg="charge failed" component=charges error=declined`)
+	assertContains(t, out, `msg="refund completed"`)
+	assertContains(t, out, "instance_test.go")
+	assertContains(t, b.String(), "msg=query card=4111 email=REDACTED")
+	if strings.Contains(b.String(), "dropped") {
+		t.Fatalf("expected the search level to apply:\n%s", b.String())
+	}
+
+	billing.ClearRedactedKeys()
+	child.Info("after", "card", "4111")
+	assertContains(t, a.String(), "msg=after component=charges card=4111")
+}
+
+func TestInstance_RedactedPatternsAreIndependent(t *testing.T) {
+	defer ClearRedactedPatterns()
+	AddRedactedPatterns(regexp.MustCompile(`sk-[a-z0-9]+`))
+	var a, b bytes.Buffer
+	billing, err := New(Config{FileWriter: nopWriteCloser{&a}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	search, err := New(Config{FileWriter: nopWriteCloser{&b}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	billing.UseRedactPreset(RedactPresetPayment)
+	AddRedactedPatterns(regexp.MustCompile(`pk-[a-z0-9]+`))
+
+	billing.Info("charge", "note", "4111 1111 1111 1111", "key", "sk-abc", "pub", "pk-abc")
+	search.Info("query", "note", "4111 1111 1111 1111", "key", "sk-abc")
+	assertContains(t, a.String(), "note=REDACTED key=REDACTED pub=pk-abc")
+	assertContains(t, b.String(), `note="4111 1111 1111 1111" key=REDACTED`)
+
+	billing.ClearRedactedPatterns()
+	billing.Info("after", "key", "sk-abc")
+	search.Info("after", "key", "sk-abc")
+	assertContains(t, a.String(), "msg=after key=sk-abc")
+	assertContains(t, b.String(), "msg=after key=REDACTED")
+}
@@ -0,0 +1,94 @@
+// Package backups lists and orders the rotated backups of a log file. It is
+// shared by the rotator in package logx and the readers in package logread,
+// so both agree on which files next to a log are backups.
+package backups
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// TimeFormat is the timestamp in backup names.
+const TimeFormat = "20060102T150405"
+
+// Suffixes appended to a log file's path to name the files logx keeps next
+// to it. None of them is a backup.
+const (
+	// LockSuffix names the advisory lock of Config.FileLock.
+	LockSuffix = ".lock"
+	// ManifestSuffix names the rotation manifest of Config.FileManifest; its
+	// temporary file adds ".tmp".
+	ManifestSuffix = ".manifest"
+	// CrashSuffix names the marker of Config.CrashMarker.
+	CrashSuffix = ".crash"
+	// CleanSuffix names the sentinel of Config.DetectUncleanShutdown.
+	CleanSuffix = ".clean"
+)
+
+// IsBackup reports whether name is a rotated backup of the log file at
+// path: "<path>.<timestamp>", optionally followed by ".<digits>" (sequence
+// number or fraction of a second) and ".gz". Other files sharing the prefix,
+// such as sidecars or an error log at "<path>.err", are not backups.
+func IsBackup(path, name string) bool {
+	suffix, ok := strings.CutPrefix(name, path+".")
+	if !ok {
+		return false
+	}
+	ts, seq, hasSeq := strings.Cut(strings.TrimSuffix(suffix, ".gz"), ".")
+	if len(ts) != len(TimeFormat) || ts[8] != 'T' || !digits(ts[:8]) || !digits(ts[9:]) {
+		return false
+	}
+	return !hasSeq || digits(seq)
+}
+
+func digits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// List returns the rotated backups of path, oldest first (see Sort).
+func List(path string) ([]string, error) {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	backups := slices.DeleteFunc(matches, func(m string) bool { return !IsBackup(path, m) })
+	Sort(path, backups)
+	return backups, nil
+}
+
+// Sort sorts backups of path oldest first by timestamp and then by
+// sequence number, so "<ts>.1000" follows "<ts>.999". Backups named with
+// fractional-second timestamps ("<ts>.123456789") sort the same way, and a
+// trailing ".gz" is ignored.
+func Sort(path string, backups []string) {
+	type key struct {
+		ts  string
+		seq int
+	}
+	parse := func(name string) key {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(name, path+"."), ".gz")
+		ts, seq, _ := strings.Cut(suffix, ".")
+		n, _ := strconv.Atoi(seq)
+		return key{ts, n}
+	}
+	slices.SortStableFunc(backups, func(a, b string) int {
+		ka, kb := parse(a), parse(b)
+		if c := strings.Compare(ka.ts, kb.ts); c != 0 {
+			return c
+		}
+		if ka.seq != kb.seq {
+			return ka.seq - kb.seq
+		}
+		return strings.Compare(a, b)
+	})
+}
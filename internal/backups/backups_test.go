@@ -0,0 +1,53 @@
+package backups
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSort_OrdersBySequence(t *testing.T) {
+	p := "app.log"
+	got := []string{
+		p + ".20240101T120001",
+		p + ".20240101T120000.1000",
+		p + ".20240101T120000.002.gz",
+		p + ".20240101T120000",
+		p + ".20240101T120000.999",
+		p + ".20240101T120000.001",
+	}
+	Sort(p, got)
+	want := []string{
+		p + ".20240101T120000",
+		p + ".20240101T120000.001",
+		p + ".20240101T120000.002.gz",
+		p + ".20240101T120000.999",
+		p + ".20240101T120000.1000",
+		p + ".20240101T120001",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("unexpected order:\n got %v\nwant %v", got, want)
+	}
+}
+
+func TestList_SkipsSidecarsAndOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	for _, name := range []string{"app.log", "app.log.lock", "app.log.manifest", "app.log.manifest.tmp", "app.log.crash", "app.log.clean", "app.log.err", "app.log.1", "app.log.20240101T120000.001", "app.log.20240101T120000"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := List(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, filepath.Base(f))
+	}
+	if want := "app.log.20240101T120000 app.log.20240101T120000.001"; strings.Join(got, " ") != want {
+		t.Fatalf("unexpected backups: %v", got)
+	}
+}
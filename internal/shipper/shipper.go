@@ -0,0 +1,216 @@
+// Package shipper is the buffering, batching and retry core shared by the
+// network sinks (lokix, natsx). Records are formatted on the logging
+// goroutine, queued, and delivered in batches by one goroutine per sink, so
+// a slow or unreachable server never blocks logging.
+package shipper
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options tunes batching and retries. Zero values take the defaults noted.
+type Options struct {
+	// BatchSize delivers once this many entries are queued.
+	BatchSize int
+	// BatchWait delivers queued entries at least this often. With 0 a batch
+	// is delivered as soon as no more entries are waiting.
+	BatchWait time.Duration
+	// MaxBuffer is the number of entries waiting for delivery; entries
+	// queued while it is full are dropped (default 10000).
+	MaxBuffer int
+	// MaxRetries retries a failed delivery, doubling the wait from
+	// MinBackoff up to MaxBackoff (defaults 5, 500ms, 30s).
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Config configures a Shipper.
+type Config[E any] struct {
+	Options
+	// Err wraps delivery failures and is returned by Enqueue after Close.
+	Err error
+	// Send delivers batch. It returns the entries that were not delivered
+	// and whether they are worth retrying.
+	Send func(batch []E) (rest []E, retry bool, err error)
+	// Stop, if set, runs on the delivery goroutine after the last batch.
+	Stop func()
+}
+
+// Shipper queues entries and delivers them in batches on its own goroutine.
+type Shipper[E any] struct {
+	cfg Config[E]
+
+	entries chan E
+	done    chan struct{}
+	stopped chan struct{}
+	closing sync.Once
+	lastErr atomic.Pointer[error]
+
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// New applies the defaults to cfg and starts delivering.
+func New[E any](cfg Config[E]) *Shipper[E] {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 1
+	}
+	if cfg.MaxBuffer <= 0 {
+		cfg.MaxBuffer = 10000
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 5
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = 500 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 30 * time.Second
+	}
+	s := &Shipper[E]{
+		cfg:     cfg,
+		entries: make(chan E, cfg.MaxBuffer),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Enqueue queues e, counting it as dropped when the buffer is full. After
+// Close it returns an error wrapping Config.Err.
+func (s *Shipper[E]) Enqueue(e E) error {
+	select {
+	case <-s.done:
+		return fmt.Errorf("%w: handler closed", s.cfg.Err)
+	default:
+	}
+	select {
+	case s.entries <- e:
+	default:
+		s.dropped.Add(1)
+	}
+	return nil
+}
+
+// Sent returns the number of entries delivered.
+func (s *Shipper[E]) Sent() uint64 { return s.sent.Load() }
+
+// Dropped returns the number of entries dropped because the buffer was full.
+func (s *Shipper[E]) Dropped() uint64 { return s.dropped.Load() }
+
+// Failed returns the number of entries that could not be delivered.
+func (s *Shipper[E]) Failed() uint64 { return s.failed.Load() }
+
+// Close delivers queued entries and stops. It returns the error of the
+// last failed delivery, if any. It is safe to call more than once.
+func (s *Shipper[E]) Close() error {
+	s.closing.Do(func() { close(s.done) })
+	<-s.stopped
+	if p := s.lastErr.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// run batches entries and delivers them until done is closed.
+func (s *Shipper[E]) run() {
+	defer close(s.stopped)
+	if s.cfg.Stop != nil {
+		defer s.cfg.Stop()
+	}
+	var tick <-chan time.Time
+	if s.cfg.BatchWait > 0 {
+		ticker := time.NewTicker(s.cfg.BatchWait)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	var batch []E
+	flush := func() {
+		if len(batch) > 0 {
+			s.deliver(batch)
+			batch = nil
+		}
+	}
+	add := func(e E) {
+		batch = append(batch, e)
+		if len(batch) >= s.cfg.BatchSize || (tick == nil && len(s.entries) == 0) {
+			flush()
+		}
+	}
+	for {
+		select {
+		case e := <-s.entries:
+			add(e)
+		case <-tick:
+			flush()
+		case <-s.done:
+			for {
+				select {
+				case e := <-s.entries:
+					add(e)
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver sends batch, retrying the entries that were not delivered.
+func (s *Shipper[E]) deliver(batch []E) {
+	rest := batch
+	var err error
+	backoff := s.cfg.MinBackoff
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		rest, retry, err = s.cfg.Send(rest)
+		if err == nil || !retry || attempt == s.cfg.MaxRetries {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-s.done:
+			// closing: retry without waiting
+		}
+		backoff = min(backoff*2, s.cfg.MaxBackoff)
+	}
+	s.sent.Add(uint64(len(batch) - len(rest)))
+	if err != nil {
+		s.failed.Add(uint64(len(rest)))
+		err = fmt.Errorf("%w: %w", s.cfg.Err, err)
+		s.lastErr.Store(&err)
+	}
+}
+
+// Formatter renders records with a slog handler into one reusable buffer.
+// The handlers passed to Format must write to Buffer.
+type Formatter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Buffer is the writer for the handlers passed to Format.
+func (f *Formatter) Buffer() *bytes.Buffer { return &f.buf }
+
+// Format renders r with h and returns a copy of the output without its
+// trailing newline.
+func (f *Formatter) Format(ctx context.Context, h slog.Handler, r slog.Record) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.buf.Reset()
+	if err := h.Handle(ctx, r); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(bytes.Clone(f.buf.Bytes()), []byte("\n")), nil
+}
@@ -0,0 +1,71 @@
+package shipper
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errTest = errors.New("test: deliver")
+
+func TestShipper_BatchesBySizeAndFlushesOnClose(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]int
+	s := New(Config[int]{
+		Options: Options{BatchSize: 2, BatchWait: time.Hour},
+		Err:     errTest,
+		Send: func(batch []int) ([]int, bool, error) {
+			mu.Lock()
+			batches = append(batches, slices.Clone(batch))
+			mu.Unlock()
+			return nil, false, nil
+		},
+	})
+	for i := range 3 {
+		if err := s.Enqueue(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(batches) != 2 || !slices.Equal(batches[0], []int{0, 1}) || !slices.Equal(batches[1], []int{2}) {
+		t.Fatalf("unexpected batches: %v", batches)
+	}
+	if s.Sent() != 3 {
+		t.Fatalf("expected 3 sent, got %d", s.Sent())
+	}
+	if err := s.Enqueue(3); !errors.Is(err, errTest) {
+		t.Fatalf("expected Enqueue after Close to fail, got %v", err)
+	}
+}
+
+func TestShipper_RetriesUndeliveredEntries(t *testing.T) {
+	var attempts [][]int
+	s := New(Config[string]{
+		Options: Options{BatchSize: 3, BatchWait: time.Hour, MaxRetries: 1, MinBackoff: time.Millisecond},
+		Err:     errTest,
+		Send: func(batch []string) ([]string, bool, error) {
+			attempts = append(attempts, []int{len(batch)})
+			// the first entry is always delivered, the rest never
+			return batch[1:], true, errors.New("busy")
+		},
+	})
+	for _, e := range []string{"a", "b", "c"} {
+		_ = s.Enqueue(e)
+	}
+	err := s.Close()
+
+	if !errors.Is(err, errTest) {
+		t.Fatalf("expected the last failure, got %v", err)
+	}
+	if len(attempts) != 2 || attempts[1][0] != 2 {
+		t.Fatalf("expected the undelivered entries to be retried: %v", attempts)
+	}
+	if s.Sent() != 2 || s.Failed() != 1 {
+		t.Fatalf("expected 2 sent and 1 failed, got %d and %d", s.Sent(), s.Failed())
+	}
+}
@@ -0,0 +1,277 @@
+package logx
+
+// journald.go writes records to the systemd journal over its native socket
+// protocol, so journalctl can filter on attrs (journalctl FIELD=value)
+// instead of seeing flat text lines. No cgo or libsystemd is needed.
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JournaldConfig configures the systemd journal output (Config.Journald).
+type JournaldConfig struct {
+	// Socket is the journal's datagram socket
+	// (default /run/systemd/journal/socket).
+	Socket string
+	// Identifier is the SYSLOG_IDENTIFIER field (default the program name).
+	Identifier string
+	// WriteTimeout bounds how long the output may block a record (see
+	// Config.ConsoleWriteTimeout).
+	WriteTimeout time.Duration
+	// QueueSize queues records for the output (see SinkConfig.QueueSize).
+	QueueSize int
+	// MinLevel drops records below this level from the output (see
+	// Config.ConsoleMinLevel).
+	MinLevel slog.Leveler
+}
+
+// ErrJournaldDial reports that the journal socket could not be reached.
+// Records are still sent to the journald output, which reconnects on the
+// next write.
+var ErrJournaldDial = errors.New("logx: dial journald")
+
+// journaldSocket is the default journal socket; tests replace it.
+var journaldSocket = "/run/systemd/journal/socket"
+
+// underJournald reports whether systemd connected the process's output to
+// the journal ($JOURNAL_STREAM) and the journal socket exists.
+func underJournald() bool {
+	if os.Getenv("JOURNAL_STREAM") == "" {
+		return false
+	}
+	_, err := os.Stat(journaldSocket)
+	return err == nil
+}
+
+// buildJournald creates the journald handler and its connection. A failed
+// dial is returned as an error together with a usable handler.
+func buildJournald(jc *JournaldConfig, opts *slog.HandlerOptions) (slog.Handler, *journaldWriter, error) {
+	w := &journaldWriter{addr: jc.Socket}
+	if w.addr == "" {
+		w.addr = journaldSocket
+	}
+	ident := jc.Identifier
+	if ident == "" {
+		ident = filepath.Base(os.Args[0])
+	}
+	h := &journaldHandler{w: w, opts: opts}
+	h.fields = appendJournalField(nil, "SYSLOG_IDENTIFIER", ident)
+
+	w.mu.Lock()
+	err := w.dial()
+	w.mu.Unlock()
+	if err != nil {
+		return h, w, fmt.Errorf("%w %s: %w", ErrJournaldDial, w.addr, err)
+	}
+	return h, w, nil
+}
+
+// journaldReserved are the fields written by the handler itself; attrs
+// with these names are written with an "ATTR_" prefix.
+var journaldReserved = map[string]bool{
+	"MESSAGE":           true,
+	"PRIORITY":          true,
+	"LEVEL":             true,
+	"SYSLOG_IDENTIFIER": true,
+	"CODE_FILE":         true,
+	"CODE_LINE":         true,
+	"CODE_FUNC":         true,
+}
+
+// journaldHandler formats records as journal entries: MESSAGE, PRIORITY
+// (SyslogSeverity), LEVEL (the slog level name), SYSLOG_IDENTIFIER,
+// CODE_* with AddSource, and one field per attr.
+type journaldHandler struct {
+	w      *journaldWriter
+	opts   *slog.HandlerOptions
+	groups []string
+	// fields holds the encoded identifier and WithAttrs fields
+	fields []byte
+}
+
+func (h *journaldHandler) Enabled(_ context.Context, level slog.Level) bool {
+	min := slog.LevelInfo
+	if h.opts != nil && h.opts.Level != nil {
+		min = h.opts.Level.Level()
+	}
+	return level >= min
+}
+
+func (h *journaldHandler) Handle(_ context.Context, r slog.Record) error {
+	b := appendJournalField(nil, "MESSAGE", r.Message)
+	b = appendJournalField(b, "PRIORITY", strconv.FormatInt(SyslogSeverity(r.Level).Int64(), 10))
+	b = appendJournalField(b, "LEVEL", r.Level.String())
+	if h.opts != nil && h.opts.AddSource && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		b = appendJournalField(b, "CODE_FILE", f.File)
+		b = appendJournalField(b, "CODE_LINE", strconv.Itoa(f.Line))
+		b = appendJournalField(b, "CODE_FUNC", f.Function)
+	}
+	b = append(b, h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		b = h.appendAttr(b, h.groups, a)
+		return true
+	})
+	return h.w.send(b)
+}
+
+func (h *journaldHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.fields = slices.Clip(h.fields)
+	for _, a := range attrs {
+		c.fields = h.appendAttr(c.fields, h.groups, a)
+	}
+	return &c
+}
+
+func (h *journaldHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.groups = append(slices.Clip(h.groups), name)
+	return &c
+}
+
+// appendAttr flattens a into journal fields after applying
+// opts.ReplaceAttr. Groups are joined with "_".
+func (h *journaldHandler) appendAttr(b []byte, groups []string, a slog.Attr) []byte {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups = append(slices.Clip(groups), a.Key)
+		}
+		for _, ga := range v.Group() {
+			b = h.appendAttr(b, groups, ga)
+		}
+		return b
+	}
+	if h.opts != nil && h.opts.ReplaceAttr != nil {
+		a.Value = v
+		a = h.opts.ReplaceAttr(groups, a)
+		v = a.Value.Resolve()
+	}
+	if a.Key == "" {
+		return b
+	}
+	key := journaldKey(strings.Join(append(slices.Clip(groups), a.Key), "_"))
+	if key == "" {
+		return b
+	}
+	if journaldReserved[key] {
+		key = "ATTR_" + key
+	}
+	value := v.String()
+	if v.Kind() == slog.KindTime {
+		value = v.Time().Format(time.RFC3339Nano)
+	}
+	return appendJournalField(b, key, value)
+}
+
+// journaldKey converts k to a journal field name: upper case letters,
+// digits and "_", not starting with "_" (reserved for trusted fields) or a
+// digit, at most 64 bytes.
+func journaldKey(k string) string {
+	k = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, k)
+	k = strings.TrimLeft(k, "_0123456789")
+	if len(k) > 64 {
+		k = k[:64]
+	}
+	return k
+}
+
+// appendJournalField encodes one field. Values containing newlines use the
+// binary form: name, newline, little-endian 64-bit length, value.
+func appendJournalField(b []byte, key, value string) []byte {
+	b = append(b, key...)
+	if strings.IndexByte(value, '\n') < 0 {
+		b = append(b, '=')
+		b = append(b, value...)
+		return append(b, '\n')
+	}
+	b = append(b, '\n')
+	b = binary.LittleEndian.AppendUint64(b, uint64(len(value)))
+	b = append(b, value...)
+	return append(b, '\n')
+}
+
+// errJournalFDUnsupported reports an entry too large for a datagram on a
+// connection that cannot pass file descriptors.
+var errJournalFDUnsupported = errors.New("logx: journald entry too large for a datagram")
+
+// journaldWriter sends one datagram per entry and redials once after a
+// failed write. Entries too large for a datagram are passed as a file
+// descriptor (see sendJournalFD), as journald's native protocol allows.
+type journaldWriter struct {
+	mu     sync.Mutex
+	addr   string
+	conn   net.Conn
+	closed bool
+}
+
+func (w *journaldWriter) dial() error {
+	c, err := net.Dial("unixgram", w.addr)
+	if err != nil {
+		return err
+	}
+	w.conn = c
+	return nil
+}
+
+func (w *journaldWriter) send(entry []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return net.ErrClosed
+	}
+	for attempt := 0; ; attempt++ {
+		if w.conn == nil {
+			if err := w.dial(); err != nil {
+				return err
+			}
+		}
+		_, err := w.conn.Write(entry)
+		if journalTooLarge(err) {
+			return sendJournalFD(w.conn, entry)
+		}
+		if err == nil || attempt == 1 {
+			return err
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+}
+
+func (w *journaldWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
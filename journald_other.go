@@ -0,0 +1,13 @@
+//go:build !unix
+
+package logx
+
+import "net"
+
+func journalTooLarge(err error) bool {
+	return false
+}
+
+func sendJournalFD(conn net.Conn, entry []byte) error {
+	return errJournalFDUnsupported
+}
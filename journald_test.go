@@ -0,0 +1,159 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func listenJournald(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.sock")
+	c, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram not supported: %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	_ = c.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return c, path
+}
+
+// readJournalEntry reads one datagram and decodes its fields.
+func readJournalEntry(t *testing.T, c *net.UnixConn) map[string]string {
+	t.Helper()
+	buf := make([]byte, 64*1024)
+	n, err := c.Read(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	fields := map[string]string{}
+	b := buf[:n]
+	for len(b) > 0 {
+		nl := bytes.IndexByte(b, '\n')
+		line := b[:nl]
+		if eq := bytes.IndexByte(line, '='); eq >= 0 {
+			fields[string(line[:eq])] = string(line[eq+1:])
+			b = b[nl+1:]
+			continue
+		}
+		size := binary.LittleEndian.Uint64(b[nl+1:])
+		start := nl + 9
+		fields[string(line)] = string(b[start : start+int(size)])
+		b = b[start+int(size)+1:]
+	}
+	return fields
+}
+
+func TestJournald_Fields(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	c, path := listenJournald(t)
+	if err := Configure(Config{Journald: &JournaldConfig{Socket: path, Identifier: "billing"}}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Logger().With("request_id", "r1").WithGroup("db").Error("query failed\nretrying", "table", "users", "priority", 9)
+
+	f := readJournalEntry(t, c)
+	want := map[string]string{
+		"MESSAGE":           "query failed\nretrying",
+		"PRIORITY":          "3",
+		"LEVEL":             "ERROR",
+		"SYSLOG_IDENTIFIER": "billing",
+		"REQUEST_ID":        "r1",
+		"DB_TABLE":          "users",
+		"DB_PRIORITY":       "9",
+	}
+	for k, v := range want {
+		if f[k] != v {
+			t.Errorf("%s = %q, want %q (entry %v)", k, f[k], v, f)
+		}
+	}
+	if out := Describe().Outputs; len(out) != 1 || out[0].Kind != "journald" || out[0].Fallback {
+		t.Fatalf("unexpected outputs: %+v", out)
+	}
+}
+
+func TestJournald_AutoUnderSystemd(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	c, path := listenJournald(t)
+	old := journaldSocket
+	journaldSocket = path
+	defer func() { journaldSocket = old }()
+	t.Setenv("JOURNAL_STREAM", "8:12345")
+
+	if err := Configure(Config{}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Warn("disk low", "reserved", 1)
+
+	f := readJournalEntry(t, c)
+	if f["MESSAGE"] != "disk low" || f["PRIORITY"] != "4" || f["ATTR_RESERVED"] != "" || f["RESERVED"] != "1" {
+		t.Fatalf("unexpected entry: %v", f)
+	}
+	if out := Describe().Outputs; len(out) != 1 || out[0].Kind != "journald" || !out[0].Fallback {
+		t.Fatalf("expected journald fallback, got %+v", out)
+	}
+}
+
+func TestJournald_DialFailureReported(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	err := Configure(Config{Journald: &JournaldConfig{Socket: filepath.Join(t.TempDir(), "missing.sock")}})
+	if !errors.Is(err, ErrJournaldDial) {
+		t.Fatalf("expected ErrJournaldDial, got %v", err)
+	}
+}
+
+func TestJournaldKey(t *testing.T) {
+	h := &journaldHandler{w: &journaldWriter{}, opts: &slog.HandlerOptions{}}
+	cases := map[string]string{
+		"user.id": "USER_ID",
+		"_secret": "SECRET",
+		"2fa":     "FA",
+		"message": "MESSAGE",
+		"Content": "CONTENT",
+		"ünicode": "NICODE",
+		"__":      "",
+		"a-b c":   "A_B_C",
+	}
+	for in, want := range cases {
+		if got := journaldKey(in); got != want {
+			t.Errorf("journaldKey(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if b := h.appendAttr(nil, nil, slog.String("message", "x")); string(b) != "ATTR_MESSAGE=x\n" {
+		t.Fatalf("expected reserved field prefixed, got %q", b)
+	}
+}
+
+func TestJournald_MinLevel(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	c, path := listenJournald(t)
+	cfg := Config{
+		Level:    slog.LevelDebug,
+		Journald: &JournaldConfig{Socket: path, MinLevel: slog.LevelWarn},
+	}
+	if err := Configure(cfg); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Info("skipped")
+	Warn("kept")
+
+	if f := readJournalEntry(t, c); f["MESSAGE"] != "kept" {
+		t.Fatalf("expected the INFO record to be dropped, got %v", f)
+	}
+	if out := Describe().Outputs; out[0].MinLevel != "WARN" {
+		t.Fatalf("expected the floor to be described, got %+v", out)
+	}
+}
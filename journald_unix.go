@@ -0,0 +1,58 @@
+//go:build unix
+
+package logx
+
+import (
+	"errors"
+	"net"
+	"os"
+	"syscall"
+)
+
+// journaldShmDir holds the file of an entry too large for a datagram;
+// tests replace it.
+var journaldShmDir = "/dev/shm"
+
+// journalTooLarge reports whether err means the entry does not fit in one
+// datagram.
+func journalTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// sendJournalFD passes entry to the journal as a file descriptor. The
+// standard library has no memfd_create, so, as libsystemd does without
+// memfd, the entry is written to an unlinked file in /dev/shm (or the
+// temporary directory) and its descriptor sent with SCM_RIGHTS.
+func sendJournalFD(conn net.Conn, entry []byte) error {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return errJournalFDUnsupported
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return err
+	}
+	dir := journaldShmDir
+	if _, err := os.Stat(dir); err != nil {
+		dir = os.TempDir()
+	}
+	f, err := os.CreateTemp(dir, "logx-journal-")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	if _, err := f.Write(entry); err != nil {
+		return err
+	}
+	// WriteMsgUnix refuses a connected datagram socket, so call sendmsg
+	rights := syscall.UnixRights(int(f.Fd()))
+	var sendErr error
+	err = raw.Write(func(fd uintptr) bool {
+		sendErr = syscall.Sendmsg(int(fd), nil, rights, nil, 0)
+		return sendErr != syscall.EAGAIN
+	})
+	return errors.Join(err, sendErr)
+}
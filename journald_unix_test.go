@@ -0,0 +1,61 @@
+//go:build unix
+
+package logx
+
+import (
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestJournald_LargeEntryPassedAsFile(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	c, path := listenJournald(t)
+	old := journaldShmDir
+	journaldShmDir = t.TempDir()
+	defer func() { journaldShmDir = old }()
+	if err := Configure(Config{Journald: &JournaldConfig{Socket: path}}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	big := strings.Repeat("x", 4<<20)
+	Info("dump", "body", big)
+	if n := WriteErrors(); n != 0 {
+		t.Fatalf("expected the entry to be sent, got %d write errors", n)
+	}
+
+	oob := make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := c.ReadMsgUnix(nil, oob)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("expected an empty datagram carrying a descriptor, got %d bytes", n)
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("expected one control message: %v", err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("expected one descriptor: %v", err)
+	}
+	f := os.NewFile(uintptr(fds[0]), "entry")
+	defer f.Close()
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(entry), "MESSAGE=dump\n") || !strings.Contains(string(entry), "BODY="+big+"\n") {
+		t.Fatalf("unexpected entry of %d bytes", len(entry))
+	}
+	if files, _ := os.ReadDir(journaldShmDir); len(files) != 0 {
+		t.Fatalf("expected the entry file to be unlinked, got %v", files)
+	}
+}
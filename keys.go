@@ -0,0 +1,224 @@
+package logx
+
+// keys.go rewrites attr key structure per output: flattening groups into
+// dotted keys for backends that index flat documents, or nesting dotted keys
+// into objects for backends that prefer nested records.
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// KeyLayout controls how nested attrs are written by an output.
+type KeyLayout int
+
+const (
+	// KeysAsIs writes groups and keys as logged.
+	KeysAsIs KeyLayout = iota
+	// KeysFlatten writes groups as dotted keys: {"req.method": "GET"}.
+	KeysFlatten
+	// KeysNest splits dotted keys into groups: {"req": {"method": "GET"}}.
+	KeysNest
+)
+
+// String returns "as_is", "flatten" or "nest".
+func (l KeyLayout) String() string {
+	switch l {
+	case KeysFlatten:
+		return "flatten"
+	case KeysNest:
+		return "nest"
+	default:
+		return "as_is"
+	}
+}
+
+func newKeyLayoutHandler(next slog.Handler, layout KeyLayout) slog.Handler {
+	switch layout {
+	case KeysFlatten:
+		return &flattenHandler{next: next}
+	case KeysNest:
+		return &nestHandler{next: next}
+	default:
+		return next
+	}
+}
+
+// flattenHandler keeps WithGroup names as a key prefix instead of opening
+// groups on the next handler.
+type flattenHandler struct {
+	next   slog.Handler
+	prefix string
+}
+
+func (h *flattenHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *flattenHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := getAttrs()
+	defer putAttrs(attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		*attrs = flattenAttr(*attrs, h.prefix, a)
+		return true
+	})
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(*attrs...)
+	return h.next.Handle(ctx, nr)
+}
+
+func (h *flattenHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var out []slog.Attr
+	for _, a := range attrs {
+		out = flattenAttr(out, h.prefix, a)
+	}
+	return &flattenHandler{next: h.next.WithAttrs(out), prefix: h.prefix}
+}
+
+func (h *flattenHandler) WithGroup(name string) slog.Handler {
+	return &flattenHandler{next: h.next, prefix: h.prefix + name + "."}
+}
+
+func flattenAttr(dst []slog.Attr, prefix string, a slog.Attr) []slog.Attr {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		// slog drops empty attrs; keep them from gaining a prefix
+		if a.Key == "" && v.Kind() == slog.KindAny && v.Any() == nil {
+			return dst
+		}
+		return append(dst, slog.Attr{Key: prefix + a.Key, Value: v})
+	}
+	// an empty group key inlines its members
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	for _, ga := range v.Group() {
+		dst = flattenAttr(dst, prefix, ga)
+	}
+	return dst
+}
+
+// nestHandler buffers WithAttrs/WithGroup so that dotted keys from every
+// level merge into one tree per record.
+type nestHandler struct {
+	next   slog.Handler
+	pre    []slog.Attr // already placed under their groups
+	groups []string
+}
+
+func (h *nestHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *nestHandler) Handle(ctx context.Context, r slog.Record) error {
+	t := &keyTree{}
+	for _, a := range h.pre {
+		t.insert(nil, a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		t.insert(h.groups, a)
+		return true
+	})
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(t.attrs()...)
+	return h.next.Handle(ctx, nr)
+}
+
+func (h *nestHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	pre := append([]slog.Attr(nil), h.pre...)
+	for _, a := range attrs {
+		pre = append(pre, wrapGroups(h.groups, a))
+	}
+	return &nestHandler{next: h.next, pre: pre, groups: h.groups}
+}
+
+func (h *nestHandler) WithGroup(name string) slog.Handler {
+	groups := append(append([]string(nil), h.groups...), name)
+	return &nestHandler{next: h.next, pre: h.pre, groups: groups}
+}
+
+// wrapGroups nests a under the given group names.
+func wrapGroups(groups []string, a slog.Attr) slog.Attr {
+	for i := len(groups) - 1; i >= 0; i-- {
+		a = slog.Attr{Key: groups[i], Value: slog.GroupValue(a)}
+	}
+	return a
+}
+
+// keyTree is an insertion-ordered tree of attrs keyed by path segment.
+type keyTree struct {
+	order []*keyNode
+	index map[string]*keyNode
+}
+
+type keyNode struct {
+	key      string
+	leaf     bool
+	value    slog.Value
+	children *keyTree
+}
+
+// insert adds a below path, splitting dotted keys and descending into groups.
+func (t *keyTree) insert(path []string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if a.Key == "" && v.Kind() != slog.KindGroup {
+		return
+	}
+
+	var segs []string
+	if a.Key != "" {
+		segs = strings.Split(a.Key, ".")
+	}
+	full := append(append([]string(nil), path...), segs...)
+
+	if v.Kind() == slog.KindGroup {
+		for _, ga := range v.Group() {
+			t.insert(full, ga)
+		}
+		return
+	}
+	t.set(full, v)
+}
+
+func (t *keyTree) set(path []string, v slog.Value) {
+	cur := t
+	for _, seg := range path[:len(path)-1] {
+		n := cur.lookup(seg)
+		if n == nil || n.leaf {
+			n = cur.add(&keyNode{key: seg, children: &keyTree{}})
+		}
+		cur = n.children
+	}
+	cur.add(&keyNode{key: path[len(path)-1], leaf: true, value: v})
+}
+
+func (t *keyTree) lookup(key string) *keyNode {
+	if t.index == nil {
+		return nil
+	}
+	return t.index[key]
+}
+
+func (t *keyTree) add(n *keyNode) *keyNode {
+	if t.index == nil {
+		t.index = map[string]*keyNode{}
+	}
+	t.order = append(t.order, n)
+	t.index[n.key] = n
+	return n
+}
+
+func (t *keyTree) attrs() []slog.Attr {
+	out := make([]slog.Attr, 0, len(t.order))
+	for _, n := range t.order {
+		if n.leaf {
+			out = append(out, slog.Attr{Key: n.key, Value: n.value})
+			continue
+		}
+		out = append(out, slog.Attr{Key: n.key, Value: slog.GroupValue(n.children.attrs()...)})
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package logx
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestKeyLayout_Flatten(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(newKeyLayoutHandler(slog.NewJSONHandler(&buf, nil), KeysFlatten))
+
+	l.With("svc", "api").WithGroup("req").Info("call",
+		"method", "GET",
+		slog.Group("user", "id", 7),
+	)
+
+	assertContains(t, buf.String(), `"svc":"api","req.method":"GET","req.user.id":7}`)
+}
+
+func TestKeyLayout_Nest(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(newKeyLayoutHandler(slog.NewJSONHandler(&buf, nil), KeysNest))
+
+	l.With("req.id", "r1").Info("call",
+		"req.method", "GET",
+		"db.rows", 3,
+		"plain", true,
+	)
+
+	assertContains(t, buf.String(), `"req":{"id":"r1","method":"GET"},"db":{"rows":3},"plain":true}`)
+}
+
+func TestKeyLayout_NestWithGroup(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(newKeyLayoutHandler(slog.NewJSONHandler(&buf, nil), KeysNest))
+
+	l.WithGroup("http").With("req.id", "r1").Info("call", "req.method", "GET")
+
+	assertContains(t, buf.String(), `"http":{"req":{"id":"r1","method":"GET"}}}`)
+}
+
+func TestConfigure_FileKeysDescribed(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	err := Configure(Config{FileWriter: nopWriteCloser{&buf}, JSONFile: true, FileKeys: KeysFlatten})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Info("call", slog.Group("req", "method", "GET"))
+
+	assertContains(t, buf.String(), `"req.method":"GET"`)
+	if got := Describe().Outputs[0].Keys; got != "flatten" {
+		t.Fatalf("expected keys=flatten, got %q", got)
+	}
+	if strings.Contains(buf.String(), `"req":{`) {
+		t.Fatalf("expected no nested group: %s", buf.String())
+	}
+}
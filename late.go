@@ -0,0 +1,78 @@
+package logx
+
+// late.go decides what happens to records logged after Shutdown or Reset,
+// when deferred calls and background goroutines are still running during
+// teardown.
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+// LateLogPolicy selects how logging after Shutdown or Reset is handled.
+type LateLogPolicy int32
+
+const (
+	// LateLogReinit lazily installs a default console logger, as before
+	// Configure, and logs a warning naming the teardown. This is the default.
+	LateLogReinit LateLogPolicy = iota
+	// LateLogDrop discards late records, counting them in LateRecords and
+	// printing one notice to stderr.
+	LateLogDrop
+	// LateLogPanic panics, to catch late logging in tests.
+	LateLogPanic
+)
+
+var (
+	latePolicy  atomic.Int32
+	lateRecords atomic.Uint64
+	// tornDownBy names the call ("Shutdown" or "Reset") that uninstalled
+	// the logger; nil while a logger is installed or before the first one.
+	tornDownBy atomic.Pointer[string]
+)
+
+var (
+	byShutdown = "Shutdown"
+	byReset    = "Reset"
+)
+
+// SetLateLogPolicy sets the policy for logging after Shutdown or Reset. It
+// is not affected by Reset, so tests can set it once in TestMain.
+func SetLateLogPolicy(p LateLogPolicy) {
+	latePolicy.Store(int32(p))
+}
+
+// LateRecords returns the number of late log calls: records dropped under
+// LateLogDrop, or reinitializations under LateLogReinit.
+func LateRecords() uint64 {
+	return lateRecords.Load()
+}
+
+// lateLogger applies the policy when no logger is installed. It returns a
+// logger to use instead of the lazy default, or the teardown name when the
+// caller should reinitialize and then warn.
+func lateLogger() (l *slog.Logger, reinitAfter string) {
+	by := tornDownBy.Load()
+	if by == nil {
+		return nil, ""
+	}
+	switch LateLogPolicy(latePolicy.Load()) {
+	case LateLogDrop:
+		if lateRecords.Add(1) == 1 {
+			fmt.Fprintf(os.Stderr, "logx: dropping records logged after %s\n", *by)
+		}
+		return discardLogger, ""
+	case LateLogPanic:
+		panic("logx: logging after " + *by)
+	}
+	// only the first late caller reports the reinitialization
+	if !tornDownBy.CompareAndSwap(by, nil) {
+		return nil, ""
+	}
+	lateRecords.Add(1)
+	return nil, *by
+}
+
+var discardLogger = slog.New(slog.DiscardHandler)
@@ -0,0 +1,98 @@
+package logx
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+// withStderr runs fn with os.Stderr redirected and returns what was written.
+func withStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = orig }()
+
+	done := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- string(b)
+	}()
+	fn()
+	_ = w.Close()
+	return <-done
+}
+
+func TestLateLog_ReinitWarns(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: nopWriteCloser{&buf}}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := withStderr(t, func() {
+		_ = Shutdown()
+		Info("late one")
+		Info("late two")
+	})
+
+	assertContains(t, out, `msg="logging after Shutdown; reinitialized default logger"`)
+	assertContains(t, out, `msg="late two"`)
+	if strings.Count(out, "reinitialized") != 1 {
+		t.Fatalf("expected a single warning: %s", out)
+	}
+	if LateRecords() != 1 {
+		t.Fatalf("expected 1 late record, got %d", LateRecords())
+	}
+}
+
+func TestLateLog_Drop(t *testing.T) {
+	Reset()
+	defer Reset()
+	SetLateLogPolicy(LateLogDrop)
+	defer SetLateLogPolicy(LateLogReinit)
+
+	out := withStderr(t, func() {
+		Info("dropped")
+		Warn("dropped too")
+	})
+
+	if LateRecords() != 2 {
+		t.Fatalf("expected 2 dropped records, got %d", LateRecords())
+	}
+	assertContains(t, out, "logx: dropping records logged after Reset")
+	if strings.Contains(out, "msg=dropped") {
+		t.Fatalf("expected records to be dropped: %s", out)
+	}
+
+	// configuring again ends the late period
+	var buf bytes.Buffer
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: nopWriteCloser{&buf}}); err != nil {
+		t.Fatal(err)
+	}
+	Info("kept")
+	assertContains(t, buf.String(), "msg=kept")
+}
+
+func TestLateLog_Panic(t *testing.T) {
+	Reset()
+	defer Reset()
+	SetLateLogPolicy(LateLogPanic)
+	defer SetLateLogPolicy(LateLogReinit)
+
+	defer func() {
+		if r := recover(); r != "logx: logging after Reset" {
+			t.Fatalf("expected late logging panic, got %v", r)
+		}
+	}()
+	Info("boom")
+}
@@ -0,0 +1,87 @@
+package logx
+
+// level.go provides a slog.Handler that honors per-context level overrides
+// set with WithLevel and the Disable kill switch.
+
+import (
+	"context"
+	"log/slog"
+)
+
+type ctxLevelHandler struct {
+	next slog.Handler
+	// all enables every level for the recent records ring; Handle marks
+	// the records the outputs do not accept as ringOnly.
+	all bool
+}
+
+func newCtxLevelHandler(next slog.Handler, all bool) slog.Handler {
+	return &ctxLevelHandler{next: next, all: all}
+}
+
+func (h *ctxLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if disabled.Load() {
+		return false
+	}
+	if override, ok := LevelFromContext(ctx); ok {
+		return level >= override
+	}
+	return h.all || h.next.Enabled(ctx, level)
+}
+
+func (h *ctxLevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if disabled.Load() {
+		return nil
+	}
+	// the outermost decorator applies SetClock to records from any logger
+	if c := clock.Load(); c != nil && !r.Time.IsZero() {
+		r.Time = (*c)()
+	}
+	if h.all && !h.next.Enabled(ctx, r.Level) {
+		ctx = context.WithValue(ctx, ringOnlyKey{}, true)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *ctxLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return newCtxLevelHandler(h.next.WithAttrs(attrs), h.all)
+}
+
+func (h *ctxLevelHandler) WithGroup(name string) slog.Handler {
+	return newCtxLevelHandler(h.next.WithGroup(name), h.all)
+}
+
+// minLevelHandler gives one output a level floor above the logger's Level.
+// multiHandler hands every record to every output, so Handle checks the
+// level as well as Enabled.
+type minLevelHandler struct {
+	next slog.Handler
+	min  slog.Leveler
+}
+
+// newMinLevelHandler returns next unchanged when min is nil.
+func newMinLevelHandler(next slog.Handler, min slog.Leveler) slog.Handler {
+	if min == nil {
+		return next
+	}
+	return &minLevelHandler{next: next, min: min}
+}
+
+func (h *minLevelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.min.Level() && h.next.Enabled(ctx, level)
+}
+
+func (h *minLevelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < h.min.Level() {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *minLevelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &minLevelHandler{next: h.next.WithAttrs(attrs), min: h.min}
+}
+
+func (h *minLevelHandler) WithGroup(name string) slog.Handler {
+	return &minLevelHandler{next: h.next.WithGroup(name), min: h.min}
+}
@@ -0,0 +1,62 @@
+package logx
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestConfigure_OutputMinLevelsAreIndependent(t *testing.T) {
+	Reset()
+	defer Reset()
+	console, file := &bytes.Buffer{}, &bytes.Buffer{}
+	consoleLevel := new(slog.LevelVar)
+	err := Configure(Config{
+		Level:           slog.LevelDebug,
+		Console:         true,
+		ConsoleWriter:   console,
+		ConsoleMinLevel: consoleLevel,
+		FileWriter:      nopWriteCloser{file},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Debug("cache miss")
+	Info("request served")
+	if strings.Contains(console.String(), "cache miss") {
+		t.Fatalf("expected the console to stay at INFO:\n%s", console)
+	}
+	assertContains(t, console.String(), `msg="request served"`)
+	assertContains(t, file.String(), `msg="cache miss"`)
+
+	consoleLevel.Set(slog.LevelWarn)
+	if o := Describe().Outputs[0]; o.MinLevel != "WARN" {
+		t.Fatalf("expected the current console level, got %+v", o)
+	}
+	Info("quiet")
+	if strings.Contains(console.String(), "quiet") {
+		t.Fatalf("expected the console to follow its LevelVar:\n%s", console)
+	}
+	assertContains(t, file.String(), "msg=quiet")
+	if Describe().Outputs[1].MinLevel != "" {
+		t.Fatalf("expected the file to have no floor of its own")
+	}
+
+	SetLevel(slog.LevelInfo)
+	Debug("dropped")
+	if strings.Contains(file.String(), "dropped") {
+		t.Fatalf("expected Level to still apply to every output:\n%s", file)
+	}
+}
+
+func TestMinLevelHandler_EnabledHonorsFloor(t *testing.T) {
+	h := newMinLevelHandler(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelDebug}), slog.LevelWarn)
+	if h.Enabled(t.Context(), slog.LevelInfo) || !h.Enabled(t.Context(), slog.LevelError) {
+		t.Fatalf("expected only WARN and above to be enabled")
+	}
+	if next := newMinLevelHandler(h, nil); next != h {
+		t.Fatalf("expected a nil floor to return the handler unchanged")
+	}
+}
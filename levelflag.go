@@ -0,0 +1,104 @@
+package logx
+
+// levelflag.go parses level names so CLIs and config files can set levels
+// from strings.
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidLevel is wrapped by the errors of ParseLevel and LevelFlag.
+var ErrInvalidLevel = errors.New("logx: invalid level")
+
+// levelNames are the names ParseLevel accepts, in addition to offsets such
+// as "debug+2" and plain numbers. TRACE and FATAL sit four below DEBUG and
+// four above ERROR, where the level mappers put them.
+var levelNames = map[string]slog.Level{
+	"trace":    slog.LevelDebug - 4,
+	"debug":    slog.LevelDebug,
+	"info":     slog.LevelInfo,
+	"warn":     slog.LevelWarn,
+	"warning":  slog.LevelWarn,
+	"error":    slog.LevelError,
+	"fatal":    slog.LevelError + 4,
+	"critical": slog.LevelError + 4,
+}
+
+// ParseLevel parses a level name, case-insensitively: trace, debug, info,
+// warn (or warning), error, fatal (or critical), optionally followed by an
+// offset as in slog's level strings ("info+2", "DEBUG-4"), or a number.
+func ParseLevel(s string) (slog.Level, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if n, err := strconv.Atoi(name); err == nil {
+		return slog.Level(n), nil
+	}
+	offset := 0
+	if i := strings.IndexAny(name, "+-"); i > 0 {
+		n, err := strconv.Atoi(name[i:])
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrInvalidLevel, s)
+		}
+		name, offset = name[:i], n
+	}
+	l, ok := levelNames[name]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidLevel, s)
+	}
+	return l + slog.Level(offset), nil
+}
+
+// levelString is slog.Level.String with TRACE and FATAL; ParseLevel accepts
+// everything it returns.
+func levelString(l slog.Level) string {
+	switch l {
+	case slog.LevelDebug - 4:
+		return "TRACE"
+	case slog.LevelError + 4:
+		return "FATAL"
+	}
+	return l.String()
+}
+
+// LevelFlag is a level that can be set from a string. It implements
+// flag.Value, so a CLI can accept -log-level=debug, and
+// encoding.TextUnmarshaler, so JSON, YAML and TOML decoders accept level
+// names. It is also a slog.Leveler.
+//
+//	lvl := logx.LevelFlag(slog.LevelInfo)
+//	flag.Var(&lvl, "log-level", "minimum log level")
+type LevelFlag slog.Level
+
+// Level returns the level.
+func (f LevelFlag) Level() slog.Level { return slog.Level(f) }
+
+// String returns the level name, such as "INFO" or "TRACE".
+func (f *LevelFlag) String() string {
+	if f == nil {
+		return slog.LevelInfo.String()
+	}
+	return levelString(slog.Level(*f))
+}
+
+// Set parses s with ParseLevel.
+func (f *LevelFlag) Set(s string) error {
+	l, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	*f = LevelFlag(l)
+	return nil
+}
+
+// MarshalText returns the level name.
+func (f LevelFlag) MarshalText() ([]byte, error) {
+	return []byte(levelString(slog.Level(f))), nil
+}
+
+// UnmarshalText parses text with ParseLevel.
+func (f *LevelFlag) UnmarshalText(text []byte) error {
+	return f.Set(string(text))
+}
@@ -0,0 +1,69 @@
+package logx
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]slog.Level{
+		"debug":    slog.LevelDebug,
+		" INFO ":   slog.LevelInfo,
+		"Warning":  slog.LevelWarn,
+		"error":    slog.LevelError,
+		"trace":    slog.LevelDebug - 4,
+		"fatal":    slog.LevelError + 4,
+		"critical": slog.LevelError + 4,
+		"info+2":   slog.LevelInfo + 2,
+		"DEBUG-4":  slog.LevelDebug - 4,
+		"-8":       slog.Level(-8),
+		"12":       slog.Level(12),
+	}
+	for in, want := range cases {
+		got, err := ParseLevel(in)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "verbose", "info+", "warn+x"} {
+		if _, err := ParseLevel(in); !errors.Is(err, ErrInvalidLevel) {
+			t.Errorf("ParseLevel(%q): expected ErrInvalidLevel, got %v", in, err)
+		}
+	}
+}
+
+func TestLevelFlag_FlagAndText(t *testing.T) {
+	lvl := LevelFlag(slog.LevelInfo)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(&lvl, "log-level", "")
+	if err := fs.Parse([]string{"-log-level=debug"}); err != nil || lvl.Level() != slog.LevelDebug {
+		t.Fatalf("expected debug, got %v (%v)", lvl.Level(), err)
+	}
+	if err := fs.Parse([]string{"-log-level=loud"}); err == nil {
+		t.Fatal("expected an error for an unknown level")
+	}
+
+	var cfg struct {
+		Level LevelFlag `json:"level"`
+	}
+	if err := json.Unmarshal([]byte(`{"level":"trace"}`), &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	out, _ := json.Marshal(cfg)
+	if string(out) != `{"level":"TRACE"}` {
+		t.Fatalf("unexpected round trip: %s", out)
+	}
+
+	for _, l := range []slog.Level{-8, -3, 0, 2, 8, 10} {
+		f := LevelFlag(l)
+		var back LevelFlag
+		if err := back.Set(f.String()); err != nil || back != f {
+			t.Errorf("level %d: %q parsed as %d (%v)", l, f.String(), back, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package logx
+
+// levelmap.go lets an output write levels in a backend's own vocabulary
+// (syslog numbers, GCP severities) instead of slog's level names.
+
+import "log/slog"
+
+// LevelMapper returns the value written for a record's level, for example
+// slog.IntValue(3) or slog.StringValue("WARNING").
+type LevelMapper func(slog.Level) slog.Value
+
+// SyslogSeverity maps levels to RFC 5424 severity numbers: 7 (debug),
+// 6 (informational), 4 (warning), 3 (error), 2 (critical, ERROR+4 and up).
+func SyslogSeverity(l slog.Level) slog.Value {
+	switch {
+	case l >= slog.LevelError+4:
+		return slog.IntValue(2)
+	case l >= slog.LevelError:
+		return slog.IntValue(3)
+	case l >= slog.LevelWarn:
+		return slog.IntValue(4)
+	case l >= slog.LevelInfo:
+		return slog.IntValue(6)
+	default:
+		return slog.IntValue(7)
+	}
+}
+
+// GCPSeverity maps levels to Google Cloud Logging severity names:
+// DEBUG, INFO, WARNING, ERROR and CRITICAL (ERROR+4 and up).
+func GCPSeverity(l slog.Level) slog.Value {
+	switch {
+	case l >= slog.LevelError+4:
+		return slog.StringValue("CRITICAL")
+	case l >= slog.LevelError:
+		return slog.StringValue("ERROR")
+	case l >= slog.LevelWarn:
+		return slog.StringValue("WARNING")
+	case l >= slog.LevelInfo:
+		return slog.StringValue("INFO")
+	default:
+		return slog.StringValue("DEBUG")
+	}
+}
+
+// withLevelMapper returns a copy of opts whose ReplaceAttr rewrites the
+// record level with m. opts is returned unchanged when m is nil.
+func withLevelMapper(opts *slog.HandlerOptions, m LevelMapper) *slog.HandlerOptions {
+	if m == nil {
+		return opts
+	}
+	o := *opts
+	next := opts.ReplaceAttr
+	o.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) == 0 && a.Key == slog.LevelKey {
+			if l, ok := a.Value.Any().(slog.Level); ok {
+				a.Value = m(l)
+			}
+		}
+		if next != nil {
+			return next(groups, a)
+		}
+		return a
+	}
+	return &o
+}
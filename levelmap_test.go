@@ -0,0 +1,71 @@
+package logx
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+func TestSyslogSeverity(t *testing.T) {
+	cases := map[slog.Level]int64{
+		slog.LevelDebug:     7,
+		slog.LevelInfo:      6,
+		slog.LevelInfo + 2:  6,
+		slog.LevelWarn:      4,
+		slog.LevelError:     3,
+		slog.LevelError + 4: 2,
+	}
+	for l, want := range cases {
+		if got := SyslogSeverity(l).Int64(); got != want {
+			t.Fatalf("%v: expected %d, got %d", l, want, got)
+		}
+	}
+}
+
+func TestConfigure_FileLevels(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	err := Configure(Config{
+		Level:      slog.LevelInfo,
+		FileWriter: nopWriteCloser{&buf},
+		JSONFile:   true,
+		FileLevels: GCPSeverity,
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure error: %v", err)
+	}
+
+	Warn("careful", "level", "nested is untouched")
+
+	assertContains(t, buf.String(), `"level":"WARNING"`)
+	assertContains(t, buf.String(), `"level":"nested is untouched"`)
+	if !Describe().Outputs[0].LevelMapper {
+		t.Fatalf("expected LevelMapper in description: %+v", Describe().Outputs[0])
+	}
+}
+
+func TestSinkConfig_Levels(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	name := testSinkName("levels")
+	RegisterSink(name, func(opts *slog.HandlerOptions, _ map[string]any) (slog.Handler, io.Closer, error) {
+		return slog.NewTextHandler(&buf, opts), nil, nil
+	})
+
+	err := Configure(Config{
+		Level: slog.LevelInfo,
+		Sinks: []SinkConfig{{Name: name, Levels: SyslogSeverity}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Error("boom")
+
+	assertContains(t, buf.String(), "level=3 msg=boom")
+}
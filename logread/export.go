@@ -0,0 +1,265 @@
+package logread
+
+// export.go flattens records into typed columns and writes them as CSV.
+// The column model is shared with other tabular exporters (see parquetx).
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ColumnType is the inferred type of an exported column.
+type ColumnType int
+
+const (
+	// TypeString is used for text and for columns with mixed value types.
+	TypeString ColumnType = iota
+	// TypeInt is used when every value is an integral number.
+	TypeInt
+	// TypeFloat is used when every value is a number and at least one is fractional.
+	TypeFloat
+	// TypeBool is used when every value is a JSON boolean.
+	TypeBool
+	// TypeTime is used when every value is an RFC 3339 timestamp string.
+	TypeTime
+)
+
+func (t ColumnType) String() string {
+	switch t {
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeBool:
+		return "bool"
+	case TypeTime:
+		return "time"
+	default:
+		return "string"
+	}
+}
+
+// Column describes one exported column.
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// ExportOptions controls which attributes are exported.
+type ExportOptions struct {
+	// Columns selects flattened keys (e.g. "http.status") in output order.
+	// When empty, every observed key is exported: time, level and msg first,
+	// then the rest sorted by name.
+	Columns []string
+}
+
+// InferSchema flattens records and infers a type for each column.
+// Missing and null values do not affect inference; a column with no
+// values at all is typed as TypeString.
+func InferSchema(records []Record, columns []string) []Column {
+	flat := make([]Record, len(records))
+	for i, r := range records {
+		flat[i] = Flatten(r)
+	}
+	return inferSchema(flat, columns)
+}
+
+func inferSchema(flat []Record, columns []string) []Column {
+	if len(columns) == 0 {
+		columns = observedColumns(flat)
+	}
+
+	schema := make([]Column, len(columns))
+	for i, name := range columns {
+		schema[i] = Column{Name: name, Type: inferType(flat, name)}
+	}
+	return schema
+}
+
+func observedColumns(flat []Record) []string {
+	seen := map[string]struct{}{}
+	for _, r := range flat {
+		for k := range r {
+			seen[k] = struct{}{}
+		}
+	}
+
+	var cols []string
+	for _, k := range []string{timeKey, levelKey, msgKey} {
+		if _, ok := seen[k]; ok {
+			cols = append(cols, k)
+			delete(seen, k)
+		}
+	}
+
+	rest := make([]string, 0, len(seen))
+	for k := range seen {
+		rest = append(rest, k)
+	}
+	sort.Strings(rest)
+	return append(cols, rest...)
+}
+
+func inferType(flat []Record, name string) ColumnType {
+	var (
+		t    ColumnType
+		seen bool
+	)
+	for _, r := range flat {
+		v, ok := r[name]
+		if !ok || v == nil {
+			continue
+		}
+		vt := valueType(v)
+		switch {
+		case !seen:
+			t, seen = vt, true
+		case t == vt:
+		case t == TypeInt && vt == TypeFloat, t == TypeFloat && vt == TypeInt:
+			t = TypeFloat
+		default:
+			return TypeString
+		}
+	}
+	return t
+}
+
+func valueType(v any) ColumnType {
+	switch x := v.(type) {
+	case bool:
+		return TypeBool
+	case json.Number:
+		if _, err := x.Int64(); err == nil {
+			return TypeInt
+		}
+		return TypeFloat
+	case float64:
+		return TypeFloat
+	case string:
+		if _, err := time.Parse(time.RFC3339Nano, x); err == nil {
+			return TypeTime
+		}
+	}
+	return TypeString
+}
+
+// Convert returns v as the Go type for t: int64, float64, bool, time.Time
+// or string. It reports false for missing or null values and for values
+// that cannot be represented as t.
+func Convert(v any, t ColumnType) (any, bool) {
+	if v == nil {
+		return nil, false
+	}
+
+	switch t {
+	case TypeInt:
+		if n, ok := v.(json.Number); ok {
+			i, err := n.Int64()
+			return i, err == nil
+		}
+	case TypeFloat:
+		switch n := v.(type) {
+		case json.Number:
+			f, err := n.Float64()
+			return f, err == nil
+		case float64:
+			return n, true
+		}
+	case TypeBool:
+		b, ok := v.(bool)
+		return b, ok
+	case TypeTime:
+		if s, ok := v.(string); ok {
+			ts, err := time.Parse(time.RFC3339Nano, s)
+			return ts, err == nil
+		}
+	case TypeString:
+		return stringValue(v), true
+	}
+	return nil, false
+}
+
+func stringValue(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	default:
+		b, err := json.Marshal(x)
+		if err != nil {
+			return fmt.Sprint(x)
+		}
+		return string(b)
+	}
+}
+
+// ExportCSV reads NDJSON records from r and writes them to w as CSV with
+// a header row. Records are buffered in memory so that the column set and
+// types can be inferred before the header is written.
+func ExportCSV(w io.Writer, r io.Reader, opts ExportOptions) error {
+	records, err := ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return WriteCSV(w, records, InferSchema(records, opts.Columns))
+}
+
+// WriteCSV writes records to w using schema for column order and formatting.
+// Times are written as RFC 3339 with nanoseconds; missing values are empty.
+func WriteCSV(w io.Writer, records []Record, schema []Column) error {
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(schema))
+	for i, c := range schema {
+		header[i] = c.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]string, len(schema))
+	for _, r := range records {
+		flat := Flatten(r)
+		for i, c := range schema {
+			row[i] = formatCSV(flat[c.Name], c.Type)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatCSV(v any, t ColumnType) string {
+	cv, ok := Convert(v, t)
+	if !ok {
+		if v == nil {
+			return ""
+		}
+		return stringValue(v)
+	}
+
+	switch x := cv.(type) {
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	case string:
+		return x
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+package logread
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const sampleNDJSON = `{"time":"2024-01-02T03:04:05Z","level":"INFO","msg":"req","status":200,"dur":1.5,"ok":true,"http":{"method":"GET"}}
+{"time":"2024-01-02T03:04:06Z","level":"ERROR","msg":"req","status":500,"dur":2,"ok":false,"user":"bob"}
+`
+
+func TestInferSchema_TypesColumns(t *testing.T) {
+	recs, err := ReadAll(strings.NewReader(sampleNDJSON))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]ColumnType{
+		"time":        TypeTime,
+		"level":       TypeString,
+		"status":      TypeInt,
+		"dur":         TypeFloat,
+		"ok":          TypeBool,
+		"http.method": TypeString,
+		"user":        TypeString,
+	}
+
+	schema := InferSchema(recs, nil)
+	if schema[0].Name != "time" || schema[1].Name != "level" || schema[2].Name != "msg" {
+		t.Fatalf("expected time, level, msg first, got %v", schema)
+	}
+	for _, c := range schema {
+		if wt, ok := want[c.Name]; ok && wt != c.Type {
+			t.Fatalf("column %s: expected %s, got %s", c.Name, wt, c.Type)
+		}
+	}
+}
+
+func TestExportCSV_SelectedColumns(t *testing.T) {
+	var buf bytes.Buffer
+	err := ExportCSV(&buf, strings.NewReader(sampleNDJSON), ExportOptions{
+		Columns: []string{"status", "http.method", "user"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "status,http.method,user\n200,GET,\n500,,bob\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected csv:\n%s", buf.String())
+	}
+}
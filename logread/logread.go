@@ -0,0 +1,143 @@
+// Package logread reads newline-delimited JSON logs produced by logx
+// (Config.JSONFile or Config.ConsoleJSON) for offline analysis.
+package logread
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// maxLineBytes bounds a single NDJSON line. Stack traces attached by
+// StacktraceLevel are capped at 64KB by default, so 1MB leaves plenty of room.
+const maxLineBytes = 1024 * 1024
+
+// Record is a single decoded log line. Nested slog groups are kept as
+// nested maps; use Flatten to collapse them into dotted keys.
+// Numbers are decoded as json.Number to preserve integer precision.
+type Record map[string]any
+
+// Time returns the record timestamp from the standard "time" key.
+func (r Record) Time() (time.Time, bool) {
+	s, ok := r[timeKey].(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// Level returns the record level from the standard "level" key.
+func (r Record) Level() string {
+	s, _ := r[levelKey].(string)
+	return s
+}
+
+// Message returns the record message from the standard "msg" key.
+func (r Record) Message() string {
+	s, _ := r[msgKey].(string)
+	return s
+}
+
+const (
+	timeKey  = "time"
+	levelKey = "level"
+	msgKey   = "msg"
+)
+
+// Reader decodes records from an NDJSON stream one line at a time.
+type Reader struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+// NewReader returns a Reader that decodes records from r.
+func NewReader(r io.Reader) *Reader {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	return &Reader{sc: sc}
+}
+
+// Next returns the next record. Blank lines are skipped.
+// It returns io.EOF when the stream is exhausted.
+func (r *Reader) Next() (Record, error) {
+	for r.sc.Scan() {
+		r.line++
+		b := bytes.TrimSpace(r.sc.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+		rec, err := ParseRecord(b)
+		if err != nil {
+			return nil, fmt.Errorf("logread: line %d: %w", r.line, err)
+		}
+		return rec, nil
+	}
+	if err := r.sc.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// Err returns the first read error of the underlying stream, as opposed to
+// a line that failed to decode. Callers that skip undecodable lines use it
+// to tell the two apart.
+func (r *Reader) Err() error {
+	return r.sc.Err()
+}
+
+// ParseRecord decodes a single NDJSON line.
+func ParseRecord(line []byte) (Record, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	var rec Record
+	if err := dec.Decode(&rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// ReadAll decodes every record from r.
+func ReadAll(r io.Reader) ([]Record, error) {
+	rd := NewReader(r)
+	var out []Record
+	for {
+		rec, err := rd.Next()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, rec)
+	}
+}
+
+// Flatten returns a copy of r with nested groups collapsed into
+// dot-separated keys, e.g. {"http":{"status":200}} becomes {"http.status":200}.
+func Flatten(r Record) Record {
+	out := make(Record, len(r))
+	flattenInto(out, "", r)
+	return out
+}
+
+func flattenInto(dst Record, prefix string, src map[string]any) {
+	for k, v := range src {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if m, ok := v.(map[string]any); ok {
+			flattenInto(dst, key, m)
+			continue
+		}
+		dst[key] = v
+	}
+}
@@ -0,0 +1,72 @@
+package logread
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReader_DecodesRecordsAndSkipsBlankLines(t *testing.T) {
+	in := `{"time":"2024-01-02T03:04:05Z","level":"INFO","msg":"a","n":1}
+
+{"time":"2024-01-02T03:04:06Z","level":"WARN","msg":"b"}
+`
+	rd := NewReader(strings.NewReader(in))
+
+	rec, err := rd.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Message() != "a" || rec.Level() != "INFO" {
+		t.Fatalf("unexpected record: %v", rec)
+	}
+	if ts, ok := rec.Time(); !ok || ts.Second() != 5 {
+		t.Fatalf("expected parsed time, got %v %v", ts, ok)
+	}
+
+	if rec, err = rd.Next(); err != nil || rec.Message() != "b" {
+		t.Fatalf("expected second record, got %v %v", rec, err)
+	}
+	if _, err = rd.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestReader_ReportsLineOfMalformedRecord(t *testing.T) {
+	rd := NewReader(strings.NewReader("{\"msg\":\"ok\"}\n{oops\n"))
+	_, _ = rd.Next()
+	_, err := rd.Next()
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected line 2 error, got %v", err)
+	}
+}
+
+func TestParseRecord_KeepsNumberPrecision(t *testing.T) {
+	rec, err := ParseRecord([]byte(`{"msg":"a","id":9007199254740993}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n, ok := rec["id"].(json.Number); !ok || n.String() != "9007199254740993" {
+		t.Fatalf("expected exact json.Number, got %#v", rec["id"])
+	}
+	if _, err := ParseRecord([]byte("not json")); err == nil {
+		t.Fatal("expected decode error")
+	}
+}
+
+func TestFlatten_NestsGroupsWithDots(t *testing.T) {
+	recs, err := ReadAll(strings.NewReader(`{"msg":"x","http":{"status":200,"req":{"method":"GET"}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	flat := Flatten(recs[0])
+	if _, ok := flat["http.status"]; !ok {
+		t.Fatalf("expected http.status key, got %v", flat)
+	}
+	if flat["http.req.method"] != "GET" {
+		t.Fatalf("expected http.req.method=GET, got %v", flat)
+	}
+}
@@ -0,0 +1,66 @@
+// Package parquetx exports logread records as Parquet files.
+// It lives in its own module so the parquet dependency is only pulled in
+// by programs that import it.
+package parquetx
+
+import (
+	"io"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/rannday/logx/logread"
+)
+
+// Export reads NDJSON records from r and writes them to w as a Parquet file.
+// Column selection and type inference follow logread.ExportCSV; every column
+// is optional so records missing an attribute are stored as null.
+func Export(w io.Writer, r io.Reader, opts logread.ExportOptions) error {
+	records, err := logread.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return Write(w, records, logread.InferSchema(records, opts.Columns))
+}
+
+// Write writes records to w using schema for the Parquet column layout.
+func Write(w io.Writer, records []logread.Record, schema []logread.Column) error {
+	pw := parquet.NewWriter(w, parquetSchema(schema))
+
+	for _, r := range records {
+		flat := logread.Flatten(r)
+		row := make(map[string]any, len(schema))
+		for _, c := range schema {
+			if v, ok := logread.Convert(flat[c.Name], c.Type); ok {
+				row[c.Name] = v
+			}
+		}
+		if err := pw.Write(row); err != nil {
+			_ = pw.Close()
+			return err
+		}
+	}
+
+	return pw.Close()
+}
+
+func parquetSchema(schema []logread.Column) *parquet.Schema {
+	group := make(parquet.Group, len(schema))
+	for _, c := range schema {
+		group[c.Name] = parquet.Optional(parquetNode(c.Type))
+	}
+	return parquet.NewSchema("record", group)
+}
+
+func parquetNode(t logread.ColumnType) parquet.Node {
+	switch t {
+	case logread.TypeInt:
+		return parquet.Int(64)
+	case logread.TypeFloat:
+		return parquet.Leaf(parquet.DoubleType)
+	case logread.TypeBool:
+		return parquet.Leaf(parquet.BooleanType)
+	case logread.TypeTime:
+		return parquet.Timestamp(parquet.Nanosecond)
+	default:
+		return parquet.String()
+	}
+}
@@ -0,0 +1,35 @@
+package parquetx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/rannday/logx/logread"
+)
+
+func TestExport_WritesTypedOptionalColumns(t *testing.T) {
+	in := `{"time":"2024-01-02T03:04:05Z","msg":"a","status":200}
+{"time":"2024-01-02T03:04:06Z","msg":"b","user":"bob"}
+`
+	var buf bytes.Buffer
+	if err := Export(&buf, strings.NewReader(in), logread.ExportOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rd := parquet.NewReader(bytes.NewReader(buf.Bytes()))
+	defer rd.Close()
+
+	if rd.NumRows() != 2 {
+		t.Fatalf("expected 2 rows, got %d", rd.NumRows())
+	}
+
+	row := map[string]any{}
+	if err := rd.Read(&row); err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	if row["status"] != int64(200) || row["user"] != nil {
+		t.Fatalf("unexpected first row: %v", row)
+	}
+}
@@ -0,0 +1,257 @@
+package logread
+
+// query.go locates records in a time window across the active log file and
+// its rotated backups. Each file's first and last timestamps are checked
+// before it is scanned so that files outside the window are never read in full.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rannday/logx/internal/backups"
+)
+
+// FileSpan describes the time range covered by a single log file.
+type FileSpan struct {
+	Path  string
+	First time.Time
+	Last  time.Time
+}
+
+// Overlaps reports whether the span intersects [from, to).
+// A zero from or to leaves that side of the window unbounded.
+func (s FileSpan) Overlaps(from, to time.Time) bool {
+	if !to.IsZero() && !s.First.Before(to) {
+		return false
+	}
+	if !from.IsZero() && s.Last.Before(from) {
+		return false
+	}
+	return true
+}
+
+// LogFiles returns the rotated backups of path (oldest first) followed by
+// path itself. Backups are files named "<path>.<timestamp>", with a
+// ".<sequence>" for rotations within the same second, optionally gzip
+// compressed with a trailing ".gz". The files logx keeps next to the log
+// (the "<path>.lock" of Config.FileLock, the "<path>.manifest" of
+// Config.FileManifest, the "<path>.crash" marker and the "<path>.clean"
+// sentinel) are not backups and are excluded.
+func LogFiles(path string) ([]string, error) {
+	files, err := backups.List(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// Span returns the first and last record timestamps in the file at path.
+// Plain files are read from both ends; gzip files are streamed.
+func Span(path string) (FileSpan, error) {
+	span := FileSpan{Path: path}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return span, err
+	}
+	defer f.Close()
+
+	if isGzip(path) {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return span, err
+		}
+		defer zr.Close()
+		err = scan(zr, func(rec Record, ts time.Time) bool {
+			if span.First.IsZero() {
+				span.First = ts
+			}
+			span.Last = ts
+			return true
+		})
+		return span, err
+	}
+
+	_ = scan(f, func(rec Record, ts time.Time) bool {
+		span.First = ts
+		return false
+	})
+
+	last, err := lastTime(f)
+	if err != nil {
+		return span, err
+	}
+	span.Last = last
+	return span, nil
+}
+
+// QueryRange calls fn for each record in the log file at path and its
+// rotated backups whose time is in [from, to), oldest file first. Files whose
+// span does not overlap the window are skipped. Lines that are not valid
+// JSON or lack a timestamp are ignored. Returning false from fn stops the query.
+func QueryRange(path string, from, to time.Time, fn func(Record) bool) error {
+	files, err := RangeFiles(path, from, to)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range files {
+		stop, err := queryFile(p, from, to, fn)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// RangeFiles returns the files of LogFiles(path) that hold at least one
+// record and whose span overlaps [from, to), oldest first.
+func RangeFiles(path string, from, to time.Time) ([]string, error) {
+	files, err := LogFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, p := range files {
+		span, err := Span(p)
+		if err != nil {
+			return nil, err
+		}
+		if span.First.IsZero() || !span.Overlaps(from, to) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// ReadRange returns every record in [from, to) across path and its backups.
+func ReadRange(path string, from, to time.Time) ([]Record, error) {
+	var out []Record
+	err := QueryRange(path, from, to, func(r Record) bool {
+		out = append(out, r)
+		return true
+	})
+	return out, err
+}
+
+func queryFile(path string, from, to time.Time, fn func(Record) bool) (bool, error) {
+	f, err := OpenFile(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	stop := false
+	err = scan(f, func(rec Record, ts time.Time) bool {
+		// Concurrent writers can interleave timestamps slightly, so keep
+		// scanning past out-of-window records instead of stopping early.
+		if !from.IsZero() && ts.Before(from) {
+			return true
+		}
+		if !to.IsZero() && !ts.Before(to) {
+			return true
+		}
+		if !fn(rec) {
+			stop = true
+			return false
+		}
+		return true
+	})
+	return stop, err
+}
+
+// OpenFile opens the log file at path for reading, decompressing backups
+// with a ".gz" suffix. The caller closes it.
+func OpenFile(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if !isGzip(path) {
+		return f, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &gzipFile{Reader: zr, f: f}, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	f *os.File
+}
+
+func (g *gzipFile) Close() error {
+	return errors.Join(g.Reader.Close(), g.f.Close())
+}
+
+func isGzip(path string) bool {
+	return strings.HasSuffix(path, ".gz")
+}
+
+// scan calls fn for each decodable, timestamped record until fn returns false.
+func scan(r io.Reader, fn func(Record, time.Time) bool) error {
+	rd := NewReader(r)
+	for {
+		rec, err := rd.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if rd.Err() != nil {
+				return err
+			}
+			continue
+		}
+		ts, ok := rec.Time()
+		if !ok {
+			continue
+		}
+		if !fn(rec, ts) {
+			return nil
+		}
+	}
+}
+
+// lastTime reads the tail of f and returns the timestamp of the last
+// complete record.
+func lastTime(f *os.File) (time.Time, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	size := info.Size()
+	n := min(size, int64(maxLineBytes))
+	buf := make([]byte, n)
+	if _, err := f.ReadAt(buf, size-n); err != nil && !errors.Is(err, io.EOF) {
+		return time.Time{}, err
+	}
+
+	lines := bytes.Split(buf, []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		rec, err := ParseRecord(bytes.TrimSpace(lines[i]))
+		if err != nil {
+			continue
+		}
+		if ts, ok := rec.Time(); ok {
+			return ts, nil
+		}
+	}
+	return time.Time{}, nil
+}
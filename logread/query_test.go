@@ -0,0 +1,98 @@
+package logread
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeLog(t *testing.T, path string, start time.Time, n int, gz bool) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	defer f.Close()
+
+	var w interface {
+		Write([]byte) (int, error)
+	} = f
+	if gz {
+		zw := gzip.NewWriter(f)
+		defer zw.Close()
+		w = zw
+	}
+
+	for i := 0; i < n; i++ {
+		ts := start.Add(time.Duration(i) * time.Minute).Format(time.RFC3339Nano)
+		fmt.Fprintf(w, "{\"time\":%q,\"level\":\"INFO\",\"msg\":\"m%d\"}\n", ts, i)
+	}
+}
+
+func TestQueryRange_SpansBackupsAndCompressedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	base := time.Date(2024, 1, 2, 13, 50, 0, 0, time.UTC)
+
+	writeLog(t, path+".20240102T135000.gz", base, 10, true)                   // 13:50-13:59
+	writeLog(t, path+".20240102T140000", base.Add(10*time.Minute), 10, false) // 14:00-14:09
+	writeLog(t, path, base.Add(20*time.Minute), 10, false)                    // 14:10-14:19
+
+	from := time.Date(2024, 1, 2, 13, 58, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 2, 14, 2, 0, 0, time.UTC)
+
+	recs, err := ReadRange(path, from, to)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recs) != 4 {
+		t.Fatalf("expected 4 records in window, got %d", len(recs))
+	}
+	if recs[0].Message() != "m8" || recs[3].Message() != "m1" {
+		t.Fatalf("unexpected records: %v", recs)
+	}
+}
+
+func TestSpan_ReadsFirstAndLastTimestamps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	base := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	writeLog(t, path, base, 5, false)
+
+	span, err := Span(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !span.First.Equal(base) || !span.Last.Equal(base.Add(4*time.Minute)) {
+		t.Fatalf("unexpected span: %+v", span)
+	}
+	if span.Overlaps(base.Add(time.Hour), time.Time{}) {
+		t.Fatalf("expected span not to overlap a later window")
+	}
+}
+
+func TestLogFiles_OrdersSequencedBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	for _, name := range []string{"app.log", "app.log.lock", "app.log.manifest", "app.log.crash", "app.log.clean", "app.log.20240101T120000.010", "app.log.20240101T120000.9", "app.log.20240101T120000"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := LogFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		got = append(got, filepath.Base(f))
+	}
+	want := "app.log.20240101T120000 app.log.20240101T120000.9 app.log.20240101T120000.010 app.log"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("unexpected files: %v", got)
+	}
+}
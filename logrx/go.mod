@@ -0,0 +1,9 @@
+module github.com/rannday/logx/logrx
+
+go 1.26
+
+require github.com/rannday/logx v0.0.0
+
+require github.com/go-logr/logr v1.4.4
+
+replace github.com/rannday/logx => ..
@@ -0,0 +1,113 @@
+// Package logrx adapts logx to github.com/go-logr/logr so that libraries
+// such as controller-runtime and the Kubernetes client log through logx
+// handlers (redaction, file sinks, stack traces). It lives in its own
+// module so the logr dependency is only pulled in by programs that import it.
+package logrx
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"github.com/go-logr/logr"
+	"github.com/rannday/logx"
+)
+
+// New returns a logr.Logger backed by l. If l is nil, the logx package
+// logger is resolved on every call, so later Configure calls take effect.
+//
+//	ctrl.SetLogger(logrx.New(nil))
+//	klog.SetLogger(logrx.New(nil))
+func New(l *slog.Logger) logr.Logger {
+	return logr.New(NewSink(l))
+}
+
+// NewSink returns the logr.LogSink used by New.
+//
+// logr verbosity maps to slog levels as slog.Level(-v): V(0) is info and
+// V(4) is debug. Errors are logged at error level with an "error" attribute.
+// Names added with WithName are joined with "/" in a "logger" attribute.
+func NewSink(l *slog.Logger) logr.LogSink {
+	return &sink{base: l}
+}
+
+type sink struct {
+	base   *slog.Logger
+	name   string
+	values []any
+	depth  int
+}
+
+var (
+	_ logr.LogSink          = (*sink)(nil)
+	_ logr.CallDepthLogSink = (*sink)(nil)
+)
+
+func (s *sink) logger() *slog.Logger {
+	if s.base != nil {
+		return s.base
+	}
+	return logx.Logger()
+}
+
+func (s *sink) Init(info logr.RuntimeInfo) {
+	s.depth += info.CallDepth
+}
+
+func (s *sink) Enabled(level int) bool {
+	return s.logger().Enabled(context.Background(), slog.Level(-level))
+}
+
+func (s *sink) Info(level int, msg string, kv ...any) {
+	s.log(slog.Level(-level), msg, nil, kv)
+}
+
+func (s *sink) Error(err error, msg string, kv ...any) {
+	s.log(slog.LevelError, msg, err, kv)
+}
+
+func (s *sink) log(level slog.Level, msg string, err error, kv []any) {
+	ctx := context.Background()
+	l := s.logger()
+	if !l.Enabled(ctx, level) {
+		return
+	}
+
+	// skip [Callers, log, Info/Error]; s.depth covers logr.Logger.Info/Error
+	// (RuntimeInfo.CallDepth) plus wrappers
+	var pcs [1]uintptr
+	runtime.Callers(3+s.depth, pcs[:])
+
+	r := slog.NewRecord(logx.Now(), level, msg, pcs[0])
+	if s.name != "" {
+		r.AddAttrs(slog.String("logger", s.name))
+	}
+	if err != nil {
+		r.Add("error", err)
+	}
+	r.Add(s.values...)
+	r.Add(kv...)
+	_ = l.Handler().Handle(ctx, r)
+}
+
+func (s *sink) WithValues(kv ...any) logr.LogSink {
+	next := *s
+	next.values = append(append([]any(nil), s.values...), kv...)
+	return &next
+}
+
+func (s *sink) WithName(name string) logr.LogSink {
+	next := *s
+	if next.name == "" {
+		next.name = name
+	} else {
+		next.name += "/" + name
+	}
+	return &next
+}
+
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	next := *s
+	next.depth += depth
+	return &next
+}
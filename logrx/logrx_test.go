@@ -0,0 +1,83 @@
+package logrx
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rannday/logx"
+)
+
+func TestNew_LogsThroughSlogWithNamesAndValues(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	l.WithName("controller").WithName("pods").WithValues("ns", "default").Info("reconciled", "pod", "web-1")
+	l.Error(errors.New("boom"), "sync failed")
+
+	out := buf.String()
+	if !strings.Contains(out, `level=INFO msg=reconciled logger=controller/pods ns=default pod=web-1`) {
+		t.Fatalf("unexpected info record: %s", out)
+	}
+	if !strings.Contains(out, `level=ERROR msg="sync failed" error=boom`) {
+		t.Fatalf("unexpected error record: %s", out)
+	}
+}
+
+func TestNew_VerbosityMapsToSlogLevels(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	l.V(4).Info("debug detail")
+	l.V(5).Info("too verbose")
+
+	out := buf.String()
+	if !strings.Contains(out, "level=DEBUG") || strings.Contains(out, "too verbose") {
+		t.Fatalf("unexpected verbosity handling: %s", out)
+	}
+}
+
+func TestNew_NilUsesPackageLogger(t *testing.T) {
+	logx.Reset()
+	defer logx.Reset()
+
+	var buf bytes.Buffer
+	logx.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	New(nil).Info("hello", "k", "v")
+	if !strings.Contains(buf.String(), "k=v") {
+		t.Fatalf("expected record via logx logger, got: %s", buf.String())
+	}
+}
+
+func TestNew_UsesLogxClock(t *testing.T) {
+	defer logx.SetClock(nil)
+	logx.SetClock(func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) })
+
+	var buf bytes.Buffer
+	New(slog.New(slog.NewTextHandler(&buf, nil))).Info("tick")
+	if !strings.Contains(buf.String(), "time=2024-01-02T03:04:05.000Z") {
+		t.Fatalf("expected the logx clock: %s", buf.String())
+	}
+}
+
+func TestNew_SourceIsLogrCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{AddSource: true})))
+
+	_, file, line, _ := runtime.Caller(0)
+	l.Info("here")
+	l.WithCallDepth(0).Error(errors.New("boom"), "there")
+
+	out := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	for i, want := range []int{line + 1, line + 2} {
+		if !strings.Contains(out[i], "source="+file+":"+strconv.Itoa(want)) {
+			t.Fatalf("expected source at line %d: %s", want, out[i])
+		}
+	}
+}
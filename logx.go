@@ -2,39 +2,42 @@
 package logx
 
 import (
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
-	"runtime"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 var (
-	logger        *slog.Logger
-	levelVar      = new(slog.LevelVar)
-	useColor      bool
+	logger        atomic.Pointer[slog.Logger]
+	lazyInit      = new(sync.Once)     // replaced by Reset; guarded by loggerMu
+	levelVar      = new(slog.LevelVar) // shared by every package logger; never replaced
 	loggerMu      sync.RWMutex
 	currentCloser io.Closer
-)
-
-const (
-	colorReset  = "\033[0m"
-	colorRed    = "\033[31m"
-	colorYellow = "\033[33m"
-	colorGreen  = "\033[32m"
-	colorGray   = "\033[90m"
+	currentDesc   Description
+	onFatal       func() // Config.OnFatal; guarded by loggerMu
 )
 
 // Config controls logger construction for Configure.
 type Config struct {
 	// Level is the minimum enabled log level.
 	Level slog.Level
-	// Console enables console logging to stderr.
+	// NamedLevels sets the level of loggers returned by Named, replacing
+	// levels set earlier with SetNamedLevel.
+	NamedLevels map[string]slog.Level
+	// Console enables console logging to stderr, or to ConsoleWriter.
 	Console bool
+	// ConsoleWriter replaces stderr as the console destination, e.g.
+	// os.Stdout, a test buffer or a TUI pane; use io.MultiWriter to write
+	// to several. Colors are only used when it is a terminal *os.File. It
+	// is not closed.
+	ConsoleWriter io.Writer
 	// FilePath enables file logging to this path when FileWriter is nil.
 	FilePath string
 	// JSONFile enables JSON output for file logs (text otherwise).
@@ -42,59 +45,484 @@ type Config struct {
 	// AddSource enables source file/line annotation in records.
 	AddSource bool
 	// StacktraceLevel appends stack traces for records at/above this level.
+	// Because the zero value is slog.LevelInfo, a zero StacktraceLevel means
+	// disabled unless StacktraceEnabled is set.
 	StacktraceLevel slog.Level
+	// StacktraceEnabled enables stack traces at StacktraceLevel explicitly,
+	// which is required to capture stacks from INFO (level 0) up.
+	StacktraceEnabled bool
+	// StacktraceFrames attaches "stack" as a list of frames (function,
+	// file, line) instead of one raw string, for structured pipelines.
+	// StacktraceMaxFrames caps the frame count (0 = 32) and frames whose
+	// function starts with a StacktraceSkipPackages prefix are dropped
+	// (nil = "runtime.", "log/slog.").
+	StacktraceFrames       bool
+	StacktraceMaxFrames    int
+	StacktraceSkipPackages []string
+	// StacktraceSkipFrames drops this many frames below the call site, for
+	// applications that log through their own wrapper functions.
+	StacktraceSkipFrames int
 	// File rotation settings
 	FileMaxSizeBytes int // rotate when file exceeds this many bytes (0 = disabled)
 	FileMaxBackups   int // number of rotated files to keep
+	// FileMaxTotalBytes bounds the disk usage of FilePath and its backups
+	// together: rotation deletes the oldest backups until they fit in
+	// FileMaxTotalBytes minus FileMaxSizeBytes (0 = no quota).
+	FileMaxTotalBytes int
+	// FileRotateEvery also rotates FilePath at period boundaries, e.g.
+	// 24*time.Hour for daily files, and FileCompress gzips rotated files
+	// (see Rotation).
+	FileRotateEvery time.Duration
+	FileCompress    bool
+	// FileReopenInterval makes the file output check at most this often,
+	// on write, whether FilePath still names the open file, and reopen
+	// FilePath when it was renamed or deleted, so external rotation such as
+	// logrotate's default create mode needs no copytruncate or signal
+	// (0 = never check).
+	FileReopenInterval time.Duration
+	// ConsoleWriteTimeout and FileWriteTimeout bound how long the console
+	// and file outputs may block a record. An output that times out is
+	// skipped, each record reported as ErrSinkStalled, until the blocked
+	// write returns (0 = no limit). See also SinkConfig.WriteTimeout.
+	ConsoleWriteTimeout time.Duration
+	FileWriteTimeout    time.Duration
+	// ParallelOutputs writes each record to up to this many outputs at once
+	// instead of one after another, so a record costs the slowest output's
+	// latency rather than the sum (0 or 1 = sequential). A logging call
+	// still waits for the slowest output unless it has a queue
+	// (SinkConfig.QueueSize, GELFConfig.QueueSize, JournaldConfig.QueueSize);
+	// write timeouts bound the others.
+	ParallelOutputs int
+	// ConsoleMinLevel and FileMinLevel give the console and the FilePath or
+	// FileWriter output their own floor above Level, e.g. Level DEBUG with
+	// ConsoleMinLevel INFO for a verbose file and a quiet console (nil =
+	// Level only). Pass a *slog.LevelVar to change one output at runtime
+	// independently of SetLevel. See also FileConfig.MinLevel,
+	// SinkConfig.MinLevel, GELFConfig.MinLevel and JournaldConfig.MinLevel.
+	ConsoleMinLevel slog.Leveler
+	FileMinLevel    slog.Leveler
+	// Files are additional file outputs, formatted like FilePath (FileAttrs,
+	// FileKeys, FileLevels), each with its own format and rotation policy.
+	Files []FileConfig
+	// ErrorFilePath duplicates WARN and ERROR records into a separate file,
+	// whatever the console, FilePath and Sinks settings, so the problems
+	// can be read without the noise. It is written like a Files entry with
+	// MinLevel slog.LevelWarn, in the FilePath format (JSONFile), and
+	// rotated by ErrorFileRotation.
+	ErrorFilePath     string
+	ErrorFileRotation Rotation
 	// ConsoleJSON outputs console logs as JSON when true
 	ConsoleJSON bool
 	// FileWriter can be provided to control file output (overrides FilePath)
 	FileWriter io.WriteCloser
+	// ConsoleIcons replaces console level names with compact glyphs
+	// (✖ ⚠ ℹ ·) and dims timestamps. It applies to colored text console
+	// output only; when the console is not a color-capable TTY the plain
+	// "level=" format is kept.
+	ConsoleIcons bool
+	// ConsoleColor selects what a color console colors by severity: the
+	// level (default), the level and message, or the whole line.
+	ConsoleColor ColorMode
+	// ConsoleHighlightKeys are attr keys, such as "error" or "duration",
+	// whose fields a color console highlights.
+	ConsoleHighlightKeys []string
+	// OnFatal runs before Fatal/FatalCode exit the process, after the
+	// fatal record is logged and before file writers are flushed and closed.
+	OnFatal func()
+	// FileLock coordinates appends and rotation of FilePath with other
+	// processes through an advisory lock on "<FilePath>.lock" (unix only).
+	FileLock bool
+	// FileManifest keeps "<FilePath>.manifest", a JSON index of rotated
+	// files with their time range, size and SHA-256, and makes Configure
+	// log a warning for each listed file that is missing or changed (see
+	// VerifyManifest). It requires FileMaxSizeBytes.
+	FileManifest bool
+	// MaxAttrValueBytes truncates string attr values longer than this many
+	// bytes, appending "…(+N bytes)" and listing the affected keys under
+	// "truncated_keys" (0 = unlimited).
+	MaxAttrValueBytes int
+	// ConsoleMaxAttrValueBytes and FileMaxAttrValueBytes override
+	// MaxAttrValueBytes per output (0 = inherit, negative = unlimited). See
+	// also SinkConfig.MaxAttrValueBytes.
+	ConsoleMaxAttrValueBytes int
+	FileMaxAttrValueBytes    int
+	// RecentRecords keeps the last N records at every level, including
+	// those below Level, in memory for DumpRecent (0 = disabled). Enabling
+	// it makes below-level calls pay for formatting.
+	RecentRecords int
+	// SanitizeUTF8 replaces invalid UTF-8 and control characters in messages
+	// and string attr values with U+FFFD. Tabs are kept; newlines are kept
+	// only with SanitizeKeepNewlines, for outputs that render multi-line values.
+	SanitizeUTF8         bool
+	SanitizeKeepNewlines bool
+	// CrashMarker writes "<FilePath>.crash" (time, message, fingerprint,
+	// request ID) on Fatal, and on Panic when CrashMarkerOnPanic is set.
+	// The next Configure with the same FilePath logs "previous run ended
+	// fatally" and removes the marker. Ignored without FilePath.
+	CrashMarker        bool
+	CrashMarkerOnPanic bool
+	// FileFallback sends file records to stderr while the file output fails
+	// (disk full, file removed), logging a single "file sink degraded"
+	// notice, and retries the file every FileRetryInterval (default 30s).
+	// When the console writes every record to stderr (no ConsoleWriter,
+	// ConsoleMinLevel or ConsoleAttrs), the records already reach stderr
+	// and only the notices are added.
+	FileFallback      bool
+	FileRetryInterval time.Duration
+	// DetectUncleanShutdown makes Configure log "previous shutdown was not
+	// clean" (with the last record's time from the log file tail) when the
+	// previous run that wrote FilePath did not call Shutdown. Shutdown
+	// writes "<FilePath>.clean", which Configure consumes.
+	DetectUncleanShutdown bool
+	// ConsoleAttrs and FileAttrs select the attr keys written to the
+	// console and file outputs (see AttrFilter).
+	ConsoleAttrs AttrFilter
+	FileAttrs    AttrFilter
+	// ConsoleKeys and FileKeys flatten groups into dotted keys or nest
+	// dotted keys into groups for that output (default KeysAsIs).
+	ConsoleKeys KeyLayout
+	FileKeys    KeyLayout
+	// ConsoleLevels and FileLevels replace the level written by that
+	// output, e.g. with SyslogSeverity or GCPSeverity (nil = slog names).
+	ConsoleLevels LevelMapper
+	FileLevels    LevelMapper
+	// AuditPath or AuditWriter enables Audit, which writes JSON audit
+	// events there and nowhere else. With AuditHashChain every event
+	// carries "prev_hash", the SHA-256 of the previous line (see
+	// VerifyAuditChain).
+	AuditPath      string
+	AuditWriter    io.WriteCloser
+	AuditHashChain bool
+	// AuditRotation rotates AuditPath; the hash chain continues across
+	// rotated files.
+	AuditRotation Rotation
+	// GELF sends records to Graylog. A failed connection is reported in
+	// the returned error (ErrGELFDial) and retried on later records.
+	GELF *GELFConfig
+	// Journald writes records to the systemd journal with attrs as journal
+	// fields. It is also used instead of the stderr fallback when no
+	// output is configured and the process runs under systemd
+	// ($JOURNAL_STREAM). A failed connection is reported in the returned
+	// error (ErrJournaldDial) and retried on later records.
+	Journald *JournaldConfig
+	// Discard replaces every output with one that drops records, keeping
+	// levels and the decorators (redaction, stack traces, hooks, ...) so
+	// that their cost can be benchmarked. Console, file, sink, GELF and
+	// journald settings are ignored.
+	Discard bool
+	// AllowDegraded makes Configure install the new logger even when some
+	// outputs fail, with the remaining outputs or the stderr fallback,
+	// instead of keeping the previous logger.
+	AllowDegraded bool
+	// Strict makes Configure run Validate first and never install a config
+	// that is invalid or whose outputs fail, even as the first logger.
+	Strict bool
+	// Counter is an additional output counting records by level (see
+	// NewCountingHandler).
+	Counter *CountingHandler
+	// TimeFormat renders the time of text and JSON records as a time.Format
+	// layout (e.g. time.RFC3339Nano) or as TimeEpochSeconds,
+	// TimeEpochMillis or TimeEpochNanos numbers ("" = slog's default).
+	// TimeUTC converts the time to UTC first. Registered sinks receive both
+	// through HandlerOptions.ReplaceAttr.
+	TimeFormat string
+	TimeUTC    bool
+	// Schema renames keys for a log backend, e.g. SchemaECS for Elastic,
+	// SchemaGCP for Cloud Logging or SchemaCloudWatch for AWS Lambda.
+	// It applies to console, file and registered sink outputs.
+	Schema OutputSchema
+	// GCPProjectID prefixes trace IDs for SchemaGCP
+	// ("projects/<id>/traces/<trace>"); default $GOOGLE_CLOUD_PROJECT.
+	GCPProjectID string
+	// EMFNamespace and EMFDimensions configure the Embedded Metric Format
+	// blocks of SchemaCloudWatch (namespace default "logx"). Dimensions
+	// are attr keys; those present at the top level of a record are used.
+	EMFNamespace  string
+	EMFDimensions []string
+	// ReplaceAttr rewrites or drops attrs, including the built-in time,
+	// level, msg and source keys, like slog.HandlerOptions.ReplaceAttr. It
+	// applies to every output; for level keys it sees the value written
+	// by a LevelMapper, for the time key the value formatted by TimeFormat,
+	// and keys already renamed by Schema. Registered sinks receive it in their HandlerOptions.
+	// The GELF and journald outputs apply it to attrs only, and audit
+	// events are not affected.
+	ReplaceAttr func(groups []string, a slog.Attr) slog.Attr
+	// Filter drops records for which it returns false before they reach
+	// any output (see RecordFilter and AddFilter).
+	Filter RecordFilter
+	// DedupWindow collapses consecutive identical records (same level,
+	// message and attrs): duplicates are dropped and a "last message
+	// repeated N times" record is written when a different record arrives
+	// or DedupWindow after the first duplicate (0 = disabled). A pending
+	// summary is written on Shutdown and reconfiguration.
+	DedupWindow time.Duration
+	// Hooks are fired for every record written, after the outputs (see Hook).
+	Hooks []Hook
+	// Sinks adds outputs registered with RegisterSink. A sink that is
+	// unknown or fails to initialize is skipped and reported in the
+	// returned error (ErrUnknownSink, ErrSinkInit).
+	Sinks []SinkConfig
+}
+
+// stacktraceEnabled resolves the StacktraceLevel zero-value ambiguity.
+func (cfg Config) stacktraceEnabled() bool {
+	return cfg.StacktraceEnabled || cfg.StacktraceLevel != 0
+}
+
+// consoleCoversStderr reports whether the console output writes every
+// record, with all its attrs, to stderr.
+func (cfg Config) consoleCoversStderr() bool {
+	return cfg.Console && (cfg.ConsoleWriter == nil || cfg.ConsoleWriter == os.Stderr) &&
+		cfg.ConsoleMinLevel == nil && cfg.ConsoleAttrs.empty()
 }
 
 // Configure rebuilds logger handlers and installs the new global logger.
 // Calling Configure again replaces the current handlers and closes any
 // previously configured file-backed writer after the swap.
+//
+// If an output cannot be set up, Configure returns an error (wrapping
+// ErrFileOpen, ErrRotatorInit, ErrSinkInit, ...) and keeps the previous
+// logger, so a failed reconfigure never downgrades a running service. When
+// no logger is installed yet, or with Config.AllowDegraded, it installs a
+// logger with the remaining outputs instead (falling back to stderr if none
+// remain). With Config.Strict it never installs a failed config.
 func Configure(cfg Config) error {
-	nextLogger, nextCloser, err := buildLogger(cfg)
+	_, _, err := install(cfg, false)
+	return err
+}
+
+var (
+	// ErrFileOpen reports that Config.FilePath could not be opened.
+	ErrFileOpen = errors.New("logx: open log file")
+	// ErrRotatorInit reports that the rotating (or locked) file writer for
+	// Config.FilePath could not be initialized.
+	ErrRotatorInit = errors.New("logx: init file rotator")
+)
+
+// ConfigureResult reports what Configure actually installed.
+type ConfigureResult struct {
+	// Outputs lists the active sinks. A console output with Fallback set
+	// means no requested output could be used.
+	Outputs []OutputDescription
+	// Degraded is true when a requested output could not be set up.
+	Degraded bool
+	// Kept is true when the previous logger stayed active because of the
+	// failure; Outputs then describes that logger.
+	Kept bool
+}
+
+// ConfigureWithResult is like Configure but also reports which outputs are
+// active, so applications can decide whether degraded logging is acceptable.
+func ConfigureWithResult(cfg Config) (ConfigureResult, error) {
+	desc, kept, err := install(cfg, false)
+	return ConfigureResult{
+		Outputs:  desc.Outputs,
+		Degraded: err != nil,
+		Kept:     kept,
+	}, err
+}
+
+// install builds and installs a logger for cfg. When onlyIfUnset is true the
+// logger is discarded if another one was installed while it was being built,
+// so lazy initialization never overrides an explicit Configure. kept
+// reports that a failed build was discarded in favor of the active logger,
+// whose description is returned.
+func install(cfg Config, onlyIfUnset bool) (desc Description, kept bool, err error) {
+	if cfg.Strict {
+		if err := cfg.Validate(); err != nil {
+			return Describe(), true, err
+		}
+	}
+	var ring *recentRing
+	if cfg.RecentRecords > 0 {
+		ring = newRecentRing(cfg.RecentRecords)
+	}
+	nextLogger, nextCloser, desc, err := buildLogger(cfg, levelVar, nil, ring)
+	audit, auditCloser, auditDesc, auditErr := buildAudit(cfg)
+	if auditErr != nil {
+		err = errors.Join(err, auditErr)
+	}
+	if auditCloser != nil {
+		nextCloser = joinClosers(nextCloser, auditCloser)
+		desc.Outputs = append(desc.Outputs, *auditDesc)
+	}
 
 	loggerMu.Lock()
+	kept = err != nil && (cfg.Strict || (!cfg.AllowDegraded && logger.Load() != nil))
+	if (onlyIfUnset && logger.Load() != nil) || kept {
+		if kept {
+			desc = currentDesc
+		}
+		loggerMu.Unlock()
+		if nextCloser != nil {
+			_ = nextCloser.Close()
+		}
+		return desc, kept, err
+	}
 	prevCloser := currentCloser
 	levelVar.Set(cfg.Level)
-	logger = nextLogger
+	setNamedLevels(cfg.NamedLevels)
+	logger.Store(nextLogger)
 	currentCloser = nextCloser
+	currentDesc = desc
+	onFatal = cfg.OnFatal
+	recentBuf.Store(ring)
+	auditOut.Store(audit)
+	tornDownBy.Store(nil)
+	crashMarkerPath = ""
+	if cfg.CrashMarker && cfg.FilePath != "" {
+		crashMarkerPath = cfg.FilePath + crashMarkerSuffix
+	}
+	crashOnPanic = cfg.CrashMarkerOnPanic
+	markerPath := crashMarkerPath
+	prevSentinel := cleanSentinelPath
+	cleanSentinelPath = ""
+	if cfg.DetectUncleanShutdown && cfg.FilePath != "" {
+		cleanSentinelPath = cfg.FilePath + cleanSentinelSuffix
+	}
+	// reconfiguring the same file within a run is not a restart
+	checkSentinel := cleanSentinelPath != "" && cleanSentinelPath != prevSentinel
+	sentinel := cleanSentinelPath
 	slog.SetDefault(nextLogger)
+	if stdRedirected.Load() {
+		installStdRedirect()
+	}
 	loggerMu.Unlock()
 
 	if prevCloser != nil {
 		_ = prevCloser.Close()
 	}
 
-	return err
+	var unclean bool
+	var lastRecord time.Time
+	if checkSentinel {
+		unclean, lastRecord = checkCleanShutdown(cfg.FilePath, sentinel)
+	}
+	if cfg.FileManifest && cfg.FilePath != "" {
+		reportManifest(nextLogger, cfg.FilePath)
+	}
+	crashed := markerPath != "" && reportCrashMarker(nextLogger, markerPath)
+	// a crash marker already explains the unclean end
+	if unclean && !crashed {
+		fields := []any{"log_file", cfg.FilePath}
+		if !lastRecord.IsZero() {
+			fields = append(fields, "last_record", lastRecord)
+		}
+		nextLogger.Warn("previous shutdown was not clean", fields...)
+	}
+
+	return desc, false, err
 }
 
-func buildLogger(cfg Config) (*slog.Logger, io.Closer, error) {
+// fileRotation is the rotation policy of FilePath.
+func (cfg Config) fileRotation() Rotation {
+	return Rotation{
+		MaxSizeBytes:  cfg.FileMaxSizeBytes,
+		Every:         cfg.FileRotateEvery,
+		MaxBackups:    cfg.FileMaxBackups,
+		MaxTotalBytes: cfg.FileMaxTotalBytes,
+		Compress:      cfg.FileCompress,
+	}
+}
+
+// FileConfig is an additional file output (see Config.Files).
+type FileConfig struct {
+	Path string
+	// JSON writes JSON records (text otherwise).
+	JSON     bool
+	Rotation Rotation
+	// ReopenInterval and WriteTimeout are Config.FileReopenInterval and
+	// Config.FileWriteTimeout for this file.
+	ReopenInterval time.Duration
+	WriteTimeout   time.Duration
+	// MinLevel drops records below this level from the file (nil = every
+	// record Level lets through).
+	MinLevel slog.Leveler
+}
+
+// fileOutputs returns Files followed by the ErrorFilePath output, if any.
+func (cfg Config) fileOutputs() []FileConfig {
+	files := slices.Clip(cfg.Files)
+	if cfg.ErrorFilePath != "" {
+		files = append(files, FileConfig{
+			Path:     cfg.ErrorFilePath,
+			JSON:     cfg.JSONFile,
+			Rotation: cfg.ErrorFileRotation,
+			MinLevel: slog.LevelWarn,
+		})
+	}
+	return files
+}
+
+// buildLogger builds the handler chain for cfg with level as the minimum
+// level of every output and keys as the redacted keys (nil = the global set).
+func buildLogger(cfg Config, level *slog.LevelVar, keys *redactionSet, ring *recentRing) (*slog.Logger, io.Closer, Description, error) {
+	// time formatting, then schema renames, then the application's rewrites
+	replace := chainReplaceAttr(timeReplacer(cfg.TimeFormat, cfg.TimeUTC), cfg.Schema.replacer(cfg))
 	opts := &slog.HandlerOptions{
-		Level:     levelVar,
-		AddSource: cfg.AddSource,
+		Level:       level,
+		AddSource:   cfg.AddSource,
+		ReplaceAttr: chainReplaceAttr(replace, cfg.ReplaceAttr),
+	}
+
+	desc := Description{
+		Configured: true,
+		AddSource:  cfg.AddSource,
 	}
 
 	var handlers []slog.Handler
 
-	if cfg.Console {
-		colorEnabled := detectColor()
-		useColor = colorEnabled
+	// queues are closed before the outputs, so queued records are written
+	var queues multiCloser
+	queued := func(h slog.Handler, size int, output string) slog.Handler {
+		if size <= 0 {
+			return h
+		}
+		q := newOutputQueue(size, output)
+		queues = append(queues, q)
+		return newQueueHandler(h, q)
+	}
+
+	if cfg.Discard {
+		cfg.Console, cfg.FileWriter, cfg.FilePath = false, nil, ""
+		cfg.Sinks, cfg.GELF, cfg.Journald = nil, nil, nil
+		handlers = append(handlers, discardHandler{level: level})
+		desc.Outputs = append(desc.Outputs, OutputDescription{Kind: "discard", Format: "none"})
+	}
 
+	if cfg.Console {
 		var writer io.Writer = os.Stderr
-		if colorEnabled {
-			writer = &colorWriter{w: os.Stderr}
+		if cfg.ConsoleWriter != nil {
+			writer = cfg.ConsoleWriter
 		}
+		colorEnabled := detectColor(writer)
+		target := consoleTarget(writer)
 
-		if cfg.ConsoleJSON {
-			handlers = append(handlers, slog.NewJSONHandler(writer, opts))
-		} else {
-			handlers = append(handlers, slog.NewTextHandler(writer, opts))
+		consoleOpts := withLevelMapper(opts, cfg.ConsoleLevels)
+		var style *consoleStyle
+		if colorEnabled {
+			style = &consoleStyle{icons: cfg.ConsoleIcons, mode: cfg.ConsoleColor, highlight: cfg.ConsoleHighlightKeys}
 		}
+		h := newConsoleHandler(writer, cfg.ConsoleJSON, style, consoleOpts)
+		h = cfg.Schema.wrap(h, cfg)
+		limit := attrLimit(cfg.MaxAttrValueBytes, cfg.ConsoleMaxAttrValueBytes)
+		h = newKeyLayoutHandler(h, cfg.ConsoleKeys)
+		h = newAttrFilterHandler(newTruncateHandler(h, limit), cfg.ConsoleAttrs)
+		h = newStallHandler(h, cfg.ConsoleWriteTimeout)
+		handlers = append(handlers, newMinLevelHandler(newSinkErrHandler(h, "console"), cfg.ConsoleMinLevel))
+		desc.Outputs = append(desc.Outputs, OutputDescription{
+			Kind:              "console",
+			Target:            target,
+			Format:            formatName(cfg.ConsoleJSON),
+			Color:             colorEnabled,
+			Icons:             colorEnabled && cfg.ConsoleIcons && !cfg.ConsoleJSON,
+			MaxAttrValueBytes: limit,
+			AttrFilter:        cfg.ConsoleAttrs.describe(),
+			Keys:              describeKeys(cfg.ConsoleKeys),
+			LevelMapper:       cfg.ConsoleLevels != nil,
+			WriteTimeout:      describeTimeout(cfg.ConsoleWriteTimeout),
+			minLevel:          cfg.ConsoleMinLevel,
+		})
 	}
 
 	var fileWriter io.WriteCloser
@@ -102,12 +530,16 @@ func buildLogger(cfg Config) (*slog.Logger, io.Closer, error) {
 	if cfg.FileWriter != nil {
 		fileWriter = cfg.FileWriter
 	} else if cfg.FilePath != "" {
-		if cfg.FileMaxSizeBytes > 0 {
-			r, err := newFileRotator(cfg.FilePath, cfg.FileMaxSizeBytes, cfg.FileMaxBackups)
-			if err != nil {
-				buildErr = err
+		if rot := cfg.fileRotation(); cfg.FileLock || rot.active() || cfg.FileReopenInterval > 0 {
+			r, err := openRotator(cfg.FilePath, rot, 0o644)
+			if err == nil && cfg.FileLock {
+				err = r.addLock()
 			}
-			if r != nil {
+			if err != nil {
+				buildErr = fmt.Errorf("%w: %w", ErrRotatorInit, err)
+			} else {
+				r.manifest = cfg.FileManifest
+				r.reopen = cfg.FileReopenInterval
 				fileWriter = r
 			}
 		} else {
@@ -117,7 +549,7 @@ func buildLogger(cfg Config) (*slog.Logger, io.Closer, error) {
 				0o644,
 			)
 			if err != nil {
-				buildErr = err
+				buildErr = fmt.Errorf("%w: %w", ErrFileOpen, err)
 			}
 			if f != nil {
 				fileWriter = f
@@ -126,28 +558,210 @@ func buildLogger(cfg Config) (*slog.Logger, io.Closer, error) {
 	}
 
 	if fileWriter != nil {
+		fileOpts := withLevelMapper(opts, cfg.FileLevels)
+		var h slog.Handler
 		if cfg.JSONFile {
-			handlers = append(handlers, slog.NewJSONHandler(fileWriter, opts))
+			h = slog.NewJSONHandler(fileWriter, fileOpts)
 		} else {
-			handlers = append(handlers, slog.NewTextHandler(fileWriter, opts))
+			h = slog.NewTextHandler(fileWriter, fileOpts)
+		}
+		h = cfg.Schema.wrap(h, cfg)
+		limit := attrLimit(cfg.MaxAttrValueBytes, cfg.FileMaxAttrValueBytes)
+		out := describeFile(cfg, fileWriter)
+		h = newKeyLayoutHandler(h, cfg.FileKeys)
+		h = newAttrFilterHandler(newTruncateHandler(h, limit), cfg.FileAttrs)
+		h = newSinkErrHandler(newStallHandler(h, cfg.FileWriteTimeout), out.Kind)
+		if cfg.FileFallback {
+			var secondary slog.Handler
+			if !cfg.consoleCoversStderr() {
+				secondary = newTruncateHandler(cfg.Schema.wrap(slog.NewTextHandler(os.Stderr, fileOpts), cfg), limit)
+				secondary = newAttrFilterHandler(secondary, cfg.FileAttrs)
+			}
+			h = newFallbackHandler(h, secondary, cfg.FileRetryInterval)
+			out.Failover = true
 		}
+		handlers = append(handlers, newMinLevelHandler(h, cfg.FileMinLevel))
+		out.MaxAttrValueBytes = limit
+		out.AttrFilter = cfg.FileAttrs.describe()
+		out.Keys = describeKeys(cfg.FileKeys)
+		out.LevelMapper = cfg.FileLevels != nil
+		out.WriteTimeout = describeTimeout(cfg.FileWriteTimeout)
+		out.minLevel = cfg.FileMinLevel
+		desc.Outputs = append(desc.Outputs, out)
+	}
+
+	var closers multiCloser
+	if fileWriter != nil {
+		closers = append(closers, fileWriter)
+	}
+	for _, fc := range cfg.fileOutputs() {
+		r, err := openRotator(fc.Path, fc.Rotation, 0o644)
+		if err != nil {
+			buildErr = errors.Join(buildErr, fmt.Errorf("%w: %s: %w", ErrRotatorInit, fc.Path, err))
+			continue
+		}
+		r.reopen = fc.ReopenInterval
+		closers = append(closers, r)
+		fileOpts := withLevelMapper(opts, cfg.FileLevels)
+		var h slog.Handler
+		if fc.JSON {
+			h = slog.NewJSONHandler(r, fileOpts)
+		} else {
+			h = slog.NewTextHandler(r, fileOpts)
+		}
+		limit := attrLimit(cfg.MaxAttrValueBytes, cfg.FileMaxAttrValueBytes)
+		h = newKeyLayoutHandler(cfg.Schema.wrap(h, cfg), cfg.FileKeys)
+		h = newAttrFilterHandler(newTruncateHandler(h, limit), cfg.FileAttrs)
+		h = newStallHandler(h, fc.WriteTimeout)
+		handlers = append(handlers, newMinLevelHandler(newSinkErrHandler(h, fc.Path), fc.MinLevel))
+		out := describeFile(cfg, r)
+		out.Format = formatName(fc.JSON)
+		out.MaxAttrValueBytes = limit
+		out.AttrFilter = cfg.FileAttrs.describe()
+		out.Keys = describeKeys(cfg.FileKeys)
+		out.LevelMapper = cfg.FileLevels != nil
+		out.WriteTimeout = describeTimeout(fc.WriteTimeout)
+		out.minLevel = fc.MinLevel
+		desc.Outputs = append(desc.Outputs, out)
+	}
+	for _, sc := range cfg.Sinks {
+		h, c, err := buildSink(sc, withLevelMapper(opts, sc.Levels))
+		if c != nil {
+			closers = append(closers, c)
+		}
+		if err != nil {
+			buildErr = errors.Join(buildErr, err)
+			continue
+		}
+		limit := attrLimit(cfg.MaxAttrValueBytes, sc.MaxAttrValueBytes)
+		h = newKeyLayoutHandler(cfg.Schema.wrap(h, cfg), sc.Keys)
+		h = newAttrFilterHandler(newTruncateHandler(h, limit), sc.Attrs)
+		h = newSinkErrHandler(newStallHandler(h, sc.WriteTimeout), sc.Name)
+		handlers = append(handlers, newMinLevelHandler(queued(h, sc.QueueSize, sc.Name), sc.MinLevel))
+		desc.Outputs = append(desc.Outputs, OutputDescription{
+			Kind:              "sink",
+			Target:            sc.Name,
+			MaxAttrValueBytes: limit,
+			AttrFilter:        sc.Attrs.describe(),
+			Keys:              describeKeys(sc.Keys),
+			LevelMapper:       sc.Levels != nil,
+			WriteTimeout:      describeTimeout(sc.WriteTimeout),
+			QueueSize:         max(sc.QueueSize, 0),
+			minLevel:          sc.MinLevel,
+		})
+	}
+
+	if cfg.GELF != nil {
+		h, w, err := buildGELF(cfg.GELF, opts)
+		closers = append(closers, w)
+		buildErr = errors.Join(buildErr, err)
+		h = newSinkErrHandler(newStallHandler(h, cfg.GELF.WriteTimeout), "gelf")
+		handlers = append(handlers, newMinLevelHandler(queued(h, cfg.GELF.QueueSize, "gelf"), cfg.GELF.MinLevel))
+		desc.Outputs = append(desc.Outputs, OutputDescription{
+			Kind:         "gelf",
+			Target:       w.network + "://" + cfg.GELF.Addr,
+			Format:       "gelf",
+			WriteTimeout: describeTimeout(cfg.GELF.WriteTimeout),
+			QueueSize:    max(cfg.GELF.QueueSize, 0),
+			minLevel:     cfg.GELF.MinLevel,
+		})
+	}
+
+	journald := cfg.Journald
+	if journald == nil && len(handlers) == 0 && underJournald() {
+		journald = &JournaldConfig{}
+	}
+	if journald != nil {
+		h, w, err := buildJournald(journald, opts)
+		closers = append(closers, w)
+		buildErr = errors.Join(buildErr, err)
+		h = newSinkErrHandler(newStallHandler(h, journald.WriteTimeout), "journald")
+		handlers = append(handlers, newMinLevelHandler(queued(h, journald.QueueSize, "journald"), journald.MinLevel))
+		desc.Outputs = append(desc.Outputs, OutputDescription{
+			Kind:         "journald",
+			Target:       "unixgram://" + w.addr,
+			Format:       "journald",
+			Fallback:     cfg.Journald == nil,
+			WriteTimeout: describeTimeout(journald.WriteTimeout),
+			QueueSize:    max(journald.QueueSize, 0),
+			minLevel:     journald.MinLevel,
+		})
 	}
 
 	if len(handlers) == 0 {
-		handlers = append(handlers, slog.NewTextHandler(os.Stderr, opts))
+		limit := attrLimit(cfg.MaxAttrValueBytes, cfg.ConsoleMaxAttrValueBytes)
+		h := newTruncateHandler(cfg.Schema.wrap(slog.NewTextHandler(os.Stderr, opts), cfg), limit)
+		handlers = append(handlers, newSinkErrHandler(h, "console"))
+		desc.Outputs = append(desc.Outputs, OutputDescription{
+			Kind:              "console",
+			Target:            "stderr",
+			Format:            "text",
+			Fallback:          true,
+			MaxAttrValueBytes: limit,
+		})
+	}
+
+	if cfg.Counter != nil {
+		handlers = append(handlers, cfg.Counter.withLevel(level))
+		desc.Outputs = append(desc.Outputs, OutputDescription{Kind: "counter", Format: "none"})
 	}
 
 	var handler slog.Handler
 	if len(handlers) == 1 {
 		handler = handlers[0]
 	} else {
-		handler = newMultiHandler(handlers...)
+		handler = &multiHandler{handlers: handlers, parallel: cfg.ParallelOutputs}
+	}
+	if len(queues) > 0 {
+		closers = append(queues, closers...)
+	}
+	handler = newHookHandler(handler, cfg.Hooks)
+
+	if cfg.stacktraceEnabled() {
+		handler = &stackHandler{
+			next:   handler,
+			level:  cfg.StacktraceLevel,
+			frames: newFrameOptions(cfg),
+			skip:   cfg.StacktraceSkipFrames,
+		}
+	}
+	if ring != nil {
+		handler = newRecentHandler(handler, ring)
+	}
+	if cfg.SanitizeUTF8 {
+		handler = newSanitizeHandler(handler, cfg.SanitizeKeepNewlines)
+	}
+	handler = &redactionHandler{next: handler, keys: keys}
+	if cfg.DedupWindow > 0 {
+		dedup := newDedupHandler(handler, cfg.DedupWindow)
+		// flush the pending summary while the outputs are still open
+		closers = append(multiCloser{dedup.state}, closers...)
+		handler = dedup
+	}
+	handler = newRateLimitHandler(handler)
+	handler = newRecordFilterHandler(handler, cfg.Filter)
+	handler = newCtxLevelHandler(handler, ring != nil)
+	desc.Decorators = describeDecorators(cfg)
+	if cfg.Schema != SchemaDefault {
+		desc.Schema = cfg.Schema.String()
+	}
+	if cfg.stacktraceEnabled() {
+		desc.StacktraceLevel = cfg.StacktraceLevel.String()
+	}
+	if len(handlers) > 1 && cfg.ParallelOutputs > 1 {
+		desc.ParallelOutputs = cfg.ParallelOutputs
 	}
 
-	handler = newStackHandler(handler, cfg.StacktraceLevel)
-	handler = newRedactionHandler(handler)
+	var closer io.Closer
+	switch len(closers) {
+	case 0:
+	case 1:
+		closer = closers[0]
+	default:
+		closer = closers
+	}
 
-	return slog.New(handler), fileWriter, buildErr
+	return slog.New(handler), closer, desc, buildErr
 }
 
 // Reset clears logger state.
@@ -155,12 +769,37 @@ func buildLogger(cfg Config) (*slog.Logger, io.Closer, error) {
 func Reset() {
 	loggerMu.Lock()
 	prevCloser := currentCloser
-	logger = nil
+	logger.Store(nil)
+	lazyInit = new(sync.Once)
 	currentCloser = nil
-	useColor = false
-	levelVar = new(slog.LevelVar)
+	currentDesc = Description{}
+	onFatal = nil
+	recentBuf.Store(nil)
+	crashMarkerPath = ""
+	crashOnPanic = false
+	cleanSentinelPath = ""
+	errorHandler.Store(nil)
+	writeErrors.Store(0)
+	stalledWrites.Store(0)
+	tracer.Store(nil)
+	auditOut.Store(nil)
+	lateRecords.Store(0)
+	tornDownBy.Store(&byReset)
+	levelVar.Set(slog.LevelInfo)
 	loggerMu.Unlock()
 	ClearRedactedKeys()
+	ClearRedactedPatterns()
+	ClearHooks()
+	ClearFilters()
+	ClearRateLimits()
+	rateLimitDropped.Store(0)
+	queueDropped.Store(0)
+	dedupSuppressed.Store(0)
+	dedupSummaries.Store(0)
+	setNamedLevels(nil)
+	SetClock(nil)
+	SetRandSource(nil)
+	Enable()
 
 	if prevCloser != nil {
 		_ = prevCloser.Close()
@@ -172,15 +811,53 @@ func Reset() {
 func SetLogger(l *slog.Logger) {
 	loggerMu.Lock()
 	prevCloser := currentCloser
-	logger = l
+	logger.Store(l)
 	currentCloser = nil
+	currentDesc = Description{Configured: true, Custom: true}
+	recentBuf.Store(nil)
+	tornDownBy.Store(nil)
 	slog.SetDefault(l)
+	if stdRedirected.Load() {
+		installStdRedirect()
+	}
 	loggerMu.Unlock()
 	if prevCloser != nil {
 		_ = prevCloser.Close()
 	}
 }
 
+// SwapLogger installs l like SetLogger but leaves the current
+// configuration, including open files, in place. The returned function
+// reinstates the previous logger and slog default exactly; it does nothing
+// if the logger was replaced again in the meantime. Intended for tests.
+func SwapLogger(l *slog.Logger) (restore func()) {
+	loggerMu.Lock()
+	prev := logger.Load()
+	prevDesc := currentDesc
+	prevDefault := slog.Default()
+	logger.Store(l)
+	currentDesc = Description{Configured: true, Custom: true}
+	slog.SetDefault(l)
+	if stdRedirected.Load() {
+		installStdRedirect()
+	}
+	loggerMu.Unlock()
+
+	return func() {
+		loggerMu.Lock()
+		defer loggerMu.Unlock()
+		if logger.Load() != l {
+			return
+		}
+		logger.Store(prev)
+		currentDesc = prevDesc
+		slog.SetDefault(prevDefault)
+		if stdRedirected.Load() {
+			installStdRedirect()
+		}
+	}
+}
+
 // SetLevel updates the global minimum log level at runtime.
 func SetLevel(level slog.Level) {
 	levelVar.Set(level)
@@ -188,49 +865,132 @@ func SetLevel(level slog.Level) {
 
 // Logger returns the package logger.
 // If no logger has been configured yet, it initializes a default
-// console logger at info level.
+// console logger at info level exactly once; concurrent first callers wait
+// for that initialization instead of configuring again.
+//
+// After Shutdown or Reset the LateLogPolicy applies instead.
 func Logger() *slog.Logger {
+	if l := logger.Load(); l != nil {
+		return l
+	}
+	late, after := lateLogger()
+	if late != nil {
+		return late
+	}
+
 	loggerMu.RLock()
-	l := logger
+	once := lazyInit
 	loggerMu.RUnlock()
 
-	if l == nil {
-		_ = Configure(Config{
+	once.Do(func() {
+		_, _, _ = install(Config{
 			Level:   slog.LevelInfo,
 			Console: true,
-		})
+		}, true)
+	})
 
-		loggerMu.RLock()
-		l = logger
-		loggerMu.RUnlock()
+	if l := logger.Load(); l != nil {
+		if after != "" {
+			l.Warn("logging after " + after + "; reinitialized default logger")
+		}
+		return l
 	}
-	return l
+	// Reset ran between initialization and the load above.
+	return slog.Default()
+}
+
+// Handler returns the package logger's handler, with every decorator and
+// output configured (redaction, stack traces, hooks, sinks), for code that
+// builds its own slog.Logger or takes a slog.Handler:
+//
+//	log := slog.New(logx.Handler()).With("component", "billing")
+//
+// Like Logger, it is a snapshot: after Configure or Reset call Handler again.
+func Handler() slog.Handler {
+	return Logger().Handler()
 }
 
 // Debug logs a message at debug level.
 func Debug(msg string, args ...any) {
-	Logger().Debug(msg, args...)
+	logDepth(Logger(), context.Background(), 0, slog.LevelDebug, msg, args...)
 }
 
 // Info logs a message at info level.
 func Info(msg string, args ...any) {
-	Logger().Info(msg, args...)
+	logDepth(Logger(), context.Background(), 0, slog.LevelInfo, msg, args...)
 }
 
 // Warn logs a message at warn level.
 func Warn(msg string, args ...any) {
-	Logger().Warn(msg, args...)
+	logDepth(Logger(), context.Background(), 0, slog.LevelWarn, msg, args...)
 }
 
 // Error logs a message at error level.
 func Error(msg string, args ...any) {
-	Logger().Error(msg, args...)
+	logDepth(Logger(), context.Background(), 0, slog.LevelError, msg, args...)
+}
+
+// DebugAttrs logs at debug level with typed attrs through slog's LogAttrs
+// path, avoiding the key/value conversion of Debug.
+func DebugAttrs(ctx context.Context, msg string, attrs ...slog.Attr) {
+	logAttrsDepth(Logger(), ctx, 0, slog.LevelDebug, msg, attrs...)
+}
+
+// InfoAttrs logs at info level with typed attrs (see DebugAttrs).
+func InfoAttrs(ctx context.Context, msg string, attrs ...slog.Attr) {
+	logAttrsDepth(Logger(), ctx, 0, slog.LevelInfo, msg, attrs...)
+}
+
+// WarnAttrs logs at warn level with typed attrs (see DebugAttrs).
+func WarnAttrs(ctx context.Context, msg string, attrs ...slog.Attr) {
+	logAttrsDepth(Logger(), ctx, 0, slog.LevelWarn, msg, attrs...)
+}
+
+// ErrorAttrs logs at error level with typed attrs (see DebugAttrs).
+func ErrorAttrs(ctx context.Context, msg string, attrs ...slog.Attr) {
+	logAttrsDepth(Logger(), ctx, 0, slog.LevelError, msg, attrs...)
 }
 
 // Fatal logs a message at error level and exits the process with status 1.
+// See FatalCode for the shutdown sequence.
 func Fatal(msg string, args ...any) {
-	Logger().Error(msg, args...)
-	os.Exit(1)
+	logDepth(Logger(), context.Background(), 0, slog.LevelError, msg, args...)
+	writeCrashMarker("fatal", msg, args, 1)
+	exitFatal(1)
+}
+
+// FatalCode logs a message at error level and exits the process with code.
+// Before exiting it runs Config.OnFatal and then syncs and closes the
+// configured file writer so the fatal record reaches disk. With
+// Config.CrashMarker a crash marker file is written first.
+func FatalCode(code int, msg string, args ...any) {
+	logDepth(Logger(), context.Background(), 0, slog.LevelError, msg, args...)
+	writeCrashMarker("fatal", msg, args, 1)
+	exitFatal(code)
+}
+
+func exitFatal(code int) {
+	loggerMu.RLock()
+	hook := onFatal
+	closer := currentCloser
+	loggerMu.RUnlock()
+
+	if hook != nil {
+		func() {
+			// a panicking hook must not prevent the exit
+			defer func() { _ = recover() }()
+			hook()
+		}()
+	}
+
+	if closer != nil {
+		if s, ok := closer.(interface{ Sync() error }); ok {
+			_ = s.Sync()
+		}
+		_ = closer.Close()
+	}
+
+	os.Exit(code)
 }
 
 // Loggable can be implemented by custom errors to emit extra structured fields.
@@ -239,11 +999,33 @@ type Loggable interface {
 }
 
 // ErrorErr logs an error with normalized fields:
-// "error", "error_type", and optional Loggable attributes.
+// "error", "error_type", and optional Loggable attributes. Wrapped errors
+// (Unwrap() error or Unwrap() []error) are listed under "error_chain", and
+// "error_origin" gives the function and line where the innermost error
+// carrying a stack (pkg/errors style) was created.
 func ErrorErr(msg string, err error, args ...any) {
+	logDepth(Logger(), context.Background(), 0, slog.LevelError, msg, errFields(err, args)...)
+}
+
+// DebugErr is ErrorErr at debug level.
+func DebugErr(msg string, err error, args ...any) {
+	logDepth(Logger(), context.Background(), 0, slog.LevelDebug, msg, errFields(err, args)...)
+}
+
+// InfoErr is ErrorErr at info level.
+func InfoErr(msg string, err error, args ...any) {
+	logDepth(Logger(), context.Background(), 0, slog.LevelInfo, msg, errFields(err, args)...)
+}
+
+// WarnErr is ErrorErr at warn level, for failures that will be retried.
+func WarnErr(msg string, err error, args ...any) {
+	logDepth(Logger(), context.Background(), 0, slog.LevelWarn, msg, errFields(err, args)...)
+}
+
+// errFields appends the normalized error fields to args; a nil err adds none.
+func errFields(err error, args []any) []any {
 	if err == nil {
-		Logger().Error(msg, args...)
-		return
+		return args
 	}
 
 	fields := make([]any, 0, len(args)+4)
@@ -253,57 +1035,60 @@ func ErrorErr(msg string, err error, args ...any) {
 		"error_type", fmt.Sprintf("%T", err),
 	)
 
+	if chain := errorChain(err); len(chain) > 0 {
+		fields = append(fields, "error_chain", chain)
+	}
+	if origin := errorOrigin(err); origin != "" {
+		fields = append(fields, "error_origin", origin)
+	}
+
 	// Structured error support
 	if le, ok := err.(Loggable); ok {
 		for _, attr := range le.LogAttrs() {
 			fields = append(fields, attr.Key, attr.Value.Any())
 		}
 	}
-
-	Logger().Error(msg, fields...)
+	return fields
 }
 
 // DebugContext logs a debug message with context.
 func DebugContext(ctx context.Context, msg string, args ...any) {
-	Logger().DebugContext(ctx, msg, args...)
+	logDepth(Logger(), ctx, 0, slog.LevelDebug, msg, args...)
 }
 
 // InfoContext logs an info message with context.
 func InfoContext(ctx context.Context, msg string, args ...any) {
-	Logger().InfoContext(ctx, msg, args...)
+	logDepth(Logger(), ctx, 0, slog.LevelInfo, msg, args...)
 }
 
 // WarnContext logs a warn message with context.
 func WarnContext(ctx context.Context, msg string, args ...any) {
-	Logger().WarnContext(ctx, msg, args...)
+	logDepth(Logger(), ctx, 0, slog.LevelWarn, msg, args...)
 }
 
 // ErrorContext logs an error message with context.
 func ErrorContext(ctx context.Context, msg string, args ...any) {
-	Logger().ErrorContext(ctx, msg, args...)
+	logDepth(Logger(), ctx, 0, slog.LevelError, msg, args...)
 }
 
 // ErrorErrContext is the context-aware variant of ErrorErr.
 func ErrorErrContext(ctx context.Context, msg string, err error, args ...any) {
-	if err == nil {
-		Logger().ErrorContext(ctx, msg, args...)
-		return
-	}
+	logDepth(Logger(), ctx, 0, slog.LevelError, msg, errFields(err, args)...)
+}
 
-	fields := make([]any, 0, len(args)+4)
-	fields = append(fields, args...)
-	fields = append(fields,
-		"error", err,
-		"error_type", fmt.Sprintf("%T", err),
-	)
+// DebugErrContext is the context-aware variant of DebugErr.
+func DebugErrContext(ctx context.Context, msg string, err error, args ...any) {
+	logDepth(Logger(), ctx, 0, slog.LevelDebug, msg, errFields(err, args)...)
+}
 
-	if le, ok := err.(Loggable); ok {
-		for _, attr := range le.LogAttrs() {
-			fields = append(fields, attr.Key, attr.Value.Any())
-		}
-	}
+// InfoErrContext is the context-aware variant of InfoErr.
+func InfoErrContext(ctx context.Context, msg string, err error, args ...any) {
+	logDepth(Logger(), ctx, 0, slog.LevelInfo, msg, errFields(err, args)...)
+}
 
-	Logger().ErrorContext(ctx, msg, fields...)
+// WarnErrContext is the context-aware variant of WarnErr.
+func WarnErrContext(ctx context.Context, msg string, err error, args ...any) {
+	logDepth(Logger(), ctx, 0, slog.LevelWarn, msg, errFields(err, args)...)
 }
 
 // With returns a child logger with additional structured attributes.
@@ -318,6 +1103,9 @@ func WithGroup(name string) *slog.Logger {
 
 type multiHandler struct {
 	handlers []slog.Handler
+	// parallel bounds how many handlers a record is written to at once
+	// (0 or 1 = sequential)
+	parallel int
 }
 
 func newMultiHandler(h ...slog.Handler) slog.Handler {
@@ -333,14 +1121,56 @@ func (m *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
 	return false
 }
 
+// Handle writes r to every output, even after one fails, and joins their
+// errors; each output's error is an *OutputError naming it.
 func (m *multiHandler) Handle(ctx context.Context, r slog.Record) error {
-	var firstErr error
+	if m.parallel > 1 && len(m.handlers) > 1 {
+		return m.handleParallel(ctx, r)
+	}
+	var errs []error
 	for _, h := range m.handlers {
-		if err := h.Handle(ctx, r); err != nil && firstErr == nil {
-			firstErr = err
+		if err := h.Handle(ctx, r); err != nil {
+			errs = append(errs, err)
 		}
 	}
-	return firstErr
+	return errors.Join(errs...)
+}
+
+// handleParallel writes r to at most m.parallel handlers at a time, each
+// with its own clone since handlers may add attrs, and joins the errors in
+// handler order. Outputs whose own floor (minLevelHandler) excludes r get
+// no goroutine; like the sequential path it does not check Enabled, since
+// a context override or named level may have enabled r below the outputs'
+// level. It
+// returns once every output has taken r: outputs with a queue
+// (queueHandler) return as soon as r is queued, the others once it is
+// written, bounded by their write timeouts (newStallHandler).
+func (m *multiHandler) handleParallel(ctx context.Context, r slog.Record) error {
+	enabled := make([]slog.Handler, 0, len(m.handlers))
+	for _, h := range m.handlers {
+		if m, ok := h.(*minLevelHandler); !ok || r.Level >= m.min.Level() {
+			enabled = append(enabled, h)
+		}
+	}
+	switch len(enabled) {
+	case 0:
+		return nil
+	case 1:
+		return enabled[0].Handle(ctx, r)
+	}
+	errs := make([]error, len(enabled))
+	sem := make(chan struct{}, m.parallel)
+	var wg sync.WaitGroup
+	for i, h := range enabled {
+		sem <- struct{}{}
+		rec := r.Clone()
+		wg.Go(func() {
+			defer func() { <-sem }()
+			errs[i] = h.Handle(ctx, rec)
+		})
+	}
+	wg.Wait()
+	return errors.Join(errs...)
 }
 
 func (m *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
@@ -348,7 +1178,7 @@ func (m *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	for _, h := range m.handlers {
 		next = append(next, h.WithAttrs(attrs))
 	}
-	return newMultiHandler(next...)
+	return &multiHandler{handlers: next, parallel: m.parallel}
 }
 
 func (m *multiHandler) WithGroup(name string) slog.Handler {
@@ -356,32 +1186,23 @@ func (m *multiHandler) WithGroup(name string) slog.Handler {
 	for _, h := range m.handlers {
 		next = append(next, h.WithGroup(name))
 	}
-	return newMultiHandler(next...)
+	return &multiHandler{handlers: next, parallel: m.parallel}
 }
 
-// Timed uses the default logger.
+// Timed uses the default logger. With a Tracer (see SetTracer) the records
+// carry the trace and span IDs found in ctx.
+//
+// The returned closure logs "<msg> completed". If its first argument is a
+// non-nil error it logs "<msg> failed" at error level with the ErrorErr
+// fields instead; a leading nil error is ignored, so done(err) works for
+// both outcomes.
 func Timed(ctx context.Context, msg string, args ...any) func(extra ...any) {
-	return TimedLevel(Logger(), slog.LevelInfo, ctx, msg, args...)
+	return timed(Logger(), slog.LevelInfo, 0, ctx, msg, args, nil)
 }
 
 // TimedWith uses a provided logger (supports With(), WithGroup(), etc.)
 func TimedWith(l *slog.Logger, ctx context.Context, msg string, args ...any) func(extra ...any) {
-	start := time.Now()
-	startMsg := msg + " started"
-	doneMsg := msg + " completed"
-
-	l.InfoContext(ctx, startMsg, args...)
-
-	return func(extra ...any) {
-		duration := time.Since(start)
-
-		fields := make([]any, 0, len(args)+len(extra)+2)
-		fields = append(fields, args...)
-		fields = append(fields, extra...)
-		fields = append(fields, "duration", duration)
-
-		l.InfoContext(ctx, doneMsg, fields...)
-	}
+	return timed(l, slog.LevelInfo, 0, ctx, msg, args, nil)
 }
 
 // TimedLevel logs "<msg> started" and returns a closure that logs
@@ -393,69 +1214,108 @@ func TimedLevel(
 	msg string,
 	args ...any,
 ) func(extra ...any) {
-	start := time.Now()
-	startMsg := msg + " started"
-	doneMsg := msg + " completed"
+	return timed(l, level, 0, ctx, msg, args, nil)
+}
+
+// TimedOptions configures TimedOpts.
+type TimedOptions struct {
+	// Logger defaults to the package logger.
+	Logger *slog.Logger
+	// Level is used for the started and completed records (default INFO).
+	Level slog.Level
+	// SlowThreshold, if positive, logs "<msg> completed" at WARN with
+	// "slow"=true when the operation took at least this long.
+	SlowThreshold time.Duration
+}
+
+// TimedOpts is Timed with a logger, level and slow threshold:
+//
+//	done := logx.TimedOpts(ctx, logx.TimedOptions{SlowThreshold: time.Second}, "sync")
+//	err := sync()
+//	done(err)
+func TimedOpts(ctx context.Context, opts TimedOptions, msg string, args ...any) func(extra ...any) {
+	l := opts.Logger
+	if l == nil {
+		l = Logger()
+	}
+	return timed(l, opts.Level, opts.SlowThreshold, ctx, msg, args, nil)
+}
+
+// timed backs the Timed helpers; it must be called directly from them so
+// both records carry the application's source location. end, if set, is
+// called with the outcome after the completion record.
+func timed(
+	l *slog.Logger,
+	level slog.Level,
+	slow time.Duration,
+	ctx context.Context,
+	msg string,
+	args []any,
+	end func(error),
+) func(extra ...any) {
+	start := Now()
+	args = traceArgs(ctx, args)
 
-	l.Log(ctx, level, startMsg, args...)
+	logDepth(l, ctx, 1, level, msg+" started", args...)
 
 	return func(extra ...any) {
-		duration := time.Since(start)
+		duration := Since(start)
+
+		var err error
+		if len(extra) > 0 {
+			switch e := extra[0].(type) {
+			case nil:
+				extra = extra[1:]
+			case error:
+				err, extra = e, extra[1:]
+			}
+		}
 
-		fields := make([]any, 0, len(args)+len(extra)+2)
+		fields := make([]any, 0, len(args)+len(extra)+4)
 		fields = append(fields, args...)
 		fields = append(fields, extra...)
 		fields = append(fields, "duration", duration)
 
-		l.Log(ctx, level, doneMsg, fields...)
+		switch {
+		case err != nil:
+			logDepth(l, ctx, 0, slog.LevelError, msg+" failed", errFields(err, fields)...)
+		case slow > 0 && duration >= slow:
+			fields = append(fields, "slow", true)
+			logDepth(l, ctx, 0, max(level, slog.LevelWarn), msg+" completed", fields...)
+		default:
+			logDepth(l, ctx, 0, level, msg+" completed", fields...)
+		}
+		if end != nil {
+			end(err)
+		}
 	}
 }
 
-type colorWriter struct {
-	w io.Writer
-}
-
-func (cw *colorWriter) Write(p []byte) (int, error) {
-	var (
-		levelTag []byte
-		colored  []byte
-	)
-
-	switch {
-	case bytes.Contains(p, []byte("level=ERROR")):
-		levelTag = []byte("level=ERROR")
-		colored = []byte(colorRed + "level=ERROR" + colorReset)
-	case bytes.Contains(p, []byte("level=WARN")):
-		levelTag = []byte("level=WARN")
-		colored = []byte(colorYellow + "level=WARN" + colorReset)
-	case bytes.Contains(p, []byte("level=INFO")):
-		levelTag = []byte("level=INFO")
-		colored = []byte(colorGreen + "level=INFO" + colorReset)
-	case bytes.Contains(p, []byte("level=DEBUG")):
-		levelTag = []byte("level=DEBUG")
-		colored = []byte(colorGray + "level=DEBUG" + colorReset)
-	default:
-		return cw.w.Write(p)
+// consoleTarget names the console destination for Describe.
+func consoleTarget(w io.Writer) string {
+	switch w {
+	case os.Stderr:
+		return "stderr"
+	case os.Stdout:
+		return "stdout"
 	}
-
-	i := bytes.Index(p, levelTag)
-	if i < 0 {
-		return cw.w.Write(p)
+	if f, ok := w.(*os.File); ok {
+		return f.Name()
 	}
-
-	out := make([]byte, 0, len(p)+len(colored)-len(levelTag))
-	out = append(out, p[:i]...)
-	out = append(out, colored...)
-	out = append(out, p[i+len(levelTag):]...)
-	return cw.w.Write(out)
+	return "writer"
 }
 
-func detectColor() bool {
+// detectColor reports whether w is a color-capable terminal.
+func detectColor(w io.Writer) bool {
 	if os.Getenv("NO_COLOR") != "" {
 		return false
 	}
 
-	fi, err := os.Stderr.Stat()
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
 	if err != nil {
 		return false
 	}
@@ -464,10 +1324,11 @@ func detectColor() bool {
 		return false
 	}
 
-	if runtime.GOOS != "windows" {
+	if enableVirtualTerminal(f) {
 		return true
 	}
 
+	// Windows consoles where the mode cannot be set but that interpret ANSI
 	if os.Getenv("WT_SESSION") != "" {
 		return true
 	}
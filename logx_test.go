@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/exec"
@@ -23,7 +24,6 @@ func capture(t *testing.T, level slog.Level, fn func()) string {
 	var buf bytes.Buffer
 
 	levelVar.Set(level)
-	useColor = false
 
 	base := slog.NewTextHandler(&buf, &slog.HandlerOptions{
 		Level:     levelVar,
@@ -34,7 +34,7 @@ func capture(t *testing.T, level slog.Level, fn func()) string {
 	handler := newStackHandler(base, 0)
 	handler = newRedactionHandler(handler)
 
-	logger = slog.New(handler)
+	logger.Store(slog.New(handler))
 
 	fn()
 	return buf.String()
@@ -129,14 +129,13 @@ func TestSetLevel_RuntimeChange(t *testing.T) {
 	Reset()
 
 	var buf bytes.Buffer
-	useColor = false
 
 	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
 		Level:     levelVar,
 		AddSource: false,
 	})
 
-	logger = slog.New(handler)
+	logger.Store(slog.New(handler))
 
 	SetLevel(slog.LevelError)
 
@@ -175,7 +174,7 @@ func TestConfigure_FileFailureFallback(t *testing.T) {
 		Level:     levelVar,
 		AddSource: false,
 	})
-	logger = slog.New(handler)
+	logger.Store(slog.New(handler))
 
 	// Should not panic
 	Info("still works")
@@ -192,13 +191,12 @@ func TestConfigure_FileFailureFallback(t *testing.T) {
 func TestFatal_ExitsWithCode1AndLogs(t *testing.T) {
 	if os.Getenv("LOGX_FATAL_CHILD") == "1" {
 		Reset()
-		useColor = false
 
 		handler := slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 			Level:     levelVar,
 			AddSource: false,
 		})
-		logger = slog.New(handler)
+		logger.Store(slog.New(handler))
 
 		Fatal("boom", "n", 2)
 		return
@@ -222,6 +220,44 @@ func TestFatal_ExitsWithCode1AndLogs(t *testing.T) {
 	assertContains(t, out, "n=2")
 }
 
+func TestFatalCode_RunsHookFlushesFileAndExits(t *testing.T) {
+	if os.Getenv("LOGX_FATAL_CODE_CHILD") == "1" {
+		Reset()
+		err := Configure(Config{
+			Level:            slog.LevelInfo,
+			FilePath:         os.Getenv("LOGX_FATAL_CODE_FILE"),
+			FileMaxSizeBytes: 1 << 20,
+			OnFatal:          func() { fmt.Fprint(os.Stderr, "hook ran") },
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		FatalCode(3, "bad config", "n", 7)
+		return
+	}
+
+	path := filepath.Join(t.TempDir(), "fatal.log")
+	cmd := exec.Command(os.Args[0], "-test.run=TestFatalCode_RunsHookFlushesFileAndExits")
+	cmd.Env = append(os.Environ(), "LOGX_FATAL_CODE_CHILD=1", "LOGX_FATAL_CODE_FILE="+path)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("expected exit code 3, got %v", err)
+	}
+	assertContains(t, stderr.String(), "hook ran")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, string(data), "bad config")
+	assertContains(t, string(data), "n=7")
+}
+
 func TestErrorErr_AddsErrorFields(t *testing.T) {
 	out := capture(t, slog.LevelInfo, func() {
 		err := fmt.Errorf("boom")
@@ -246,6 +282,19 @@ func TestErrorErrContext_AddsErrorFields(t *testing.T) {
 	assertContains(t, out, "error=ctxboom")
 }
 
+func TestWarnErr_NormalizesAtLevel(t *testing.T) {
+	out := capture(t, slog.LevelDebug, func() {
+		err := fmt.Errorf("retry later")
+		WarnErr("upload failed", err, "attempt", 2)
+		DebugErrContext(context.Background(), "probe failed", err)
+		InfoErr("no error", nil)
+	})
+
+	assertContains(t, out, "level=WARN msg=\"upload failed\" attempt=2 error=\"retry later\" error_type=*errors.errorString")
+	assertContains(t, out, "level=DEBUG msg=\"probe failed\" error=\"retry later\"")
+	assertContains(t, out, "level=INFO msg=\"no error\"")
+}
+
 func TestTimedLevel_EmitsStartAndComplete(t *testing.T) {
 	out := capture(t, slog.LevelInfo, func() {
 		ctx := context.Background()
@@ -266,7 +315,7 @@ func TestTimedLevel_EmitsStartAndComplete(t *testing.T) {
 func TestDetectColor_NoColorEnv(t *testing.T) {
 	os.Setenv("NO_COLOR", "1")
 	defer os.Unsetenv("NO_COLOR")
-	if detectColor() {
+	if detectColor(os.Stderr) {
 		t.Fatalf("expected detectColor to be false when NO_COLOR is set")
 	}
 }
@@ -301,6 +350,37 @@ func TestTimedWith_LogsStartAndComplete(t *testing.T) {
 	}
 }
 
+func TestTimed_ErrorOutcome(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, nil))
+	ctx := context.Background()
+
+	TimedWith(l, ctx, "upload", "id", 1)(errors.New("denied"), "bytes", 10)
+	var nilErr error
+	TimedWith(l, ctx, "ping")(nilErr)
+
+	out := buf.String()
+	assertContains(t, out, `level=ERROR msg="upload failed" id=1 bytes=10 duration=`)
+	assertContains(t, out, "error=denied error_type=*errors.errorString")
+	assertContains(t, out, `level=INFO msg="ping completed" duration=`)
+}
+
+func TestTimedOpts_SlowThreshold(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, nil))
+	ctx := context.Background()
+
+	TimedOpts(ctx, TimedOptions{Logger: l, SlowThreshold: time.Hour}, "fast")()
+	done := TimedOpts(ctx, TimedOptions{Logger: l, SlowThreshold: time.Millisecond}, "slow")
+	time.Sleep(2 * time.Millisecond)
+	done()
+
+	out := buf.String()
+	assertContains(t, out, `level=INFO msg="fast completed"`)
+	assertContains(t, out, `level=WARN msg="slow completed"`)
+	assertContains(t, out, "slow=true")
+}
+
 func TestConfigure_UsesFileWriter(t *testing.T) {
 	Reset()
 	var buf nopWriteCloser
@@ -436,6 +516,32 @@ func TestConfigureTwice_NoPanic(t *testing.T) {
 	Info("still-running")
 }
 
+// Run with -race: Configure builds handlers while other goroutines log,
+// change the level and reset.
+func TestConfigure_ConcurrentWithLogging(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Go(func() {
+			for j := range 50 {
+				switch (i + j) % 4 {
+				case 0:
+					_ = Configure(Config{Console: true, FileWriter: &trackingWriteCloser{}})
+				case 1:
+					SetLevel(slog.LevelDebug)
+				case 2:
+					Logger().With("worker", i).Info("tick", "n", j)
+				default:
+					_ = Describe()
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
 func TestReset_ClearsStateAndClosesWriter(t *testing.T) {
 	Reset()
 	defer Reset()
@@ -455,7 +561,7 @@ func TestReset_ClearsStateAndClosesWriter(t *testing.T) {
 	if got := w.CloseCount(); got != 1 {
 		t.Fatalf("expected writer close once on reset, got %d", got)
 	}
-	if logger != nil {
+	if logger.Load() != nil {
 		t.Fatalf("expected logger to be nil after reset")
 	}
 	if currentCloser != nil {
@@ -467,13 +573,113 @@ func TestReset_ClearsStateAndClosesWriter(t *testing.T) {
 	}
 }
 
-func TestMultiHandler_ReturnsFirstError(t *testing.T) {
-	e1 := errors.New("first")
-	h := newMultiHandler(&errHandler{err: e1}, &errHandler{err: nil})
+func TestMultiHandler_JoinsEveryError(t *testing.T) {
+	e1, e2 := errors.New("first"), errors.New("second")
+	h := newMultiHandler(&errHandler{err: e1}, &errHandler{err: nil}, &errHandler{err: e2})
 
 	rec := slog.NewRecord(time.Now(), slog.LevelInfo, "m", 0)
-	if err := h.Handle(context.Background(), rec); err == nil {
-		t.Fatalf("expected error, got nil")
+	err := h.Handle(context.Background(), rec)
+	if !errors.Is(err, e1) || !errors.Is(err, e2) {
+		t.Fatalf("expected both errors, got %v", err)
+	}
+	if err := newMultiHandler(&errHandler{err: nil}).Handle(context.Background(), rec); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+// rendezvousHandler waits in Handle until every handler sharing started
+// has been called, which only happens when they run concurrently.
+type rendezvousHandler struct {
+	started *sync.WaitGroup
+}
+
+func (h rendezvousHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h rendezvousHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h rendezvousHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h rendezvousHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.started.Done()
+	done := make(chan struct{})
+	go func() { h.started.Wait(); close(done) }()
+	select {
+	case <-done:
+		return nil
+	case <-time.After(time.Second):
+		return errors.New("handlers ran one after another")
+	}
+}
+
+func TestMultiHandler_ParallelWritesConcurrently(t *testing.T) {
+	var started sync.WaitGroup
+	started.Add(3)
+	m := &multiHandler{parallel: 3}
+	for range 3 {
+		m.handlers = append(m.handlers, rendezvousHandler{started: &started})
+	}
+
+	h := m.WithAttrs([]slog.Attr{slog.String("k", "v")})
+	if err := h.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "m", 0)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMultiHandler_ParallelSkipsDisabledOutputs(t *testing.T) {
+	var started sync.WaitGroup
+	started.Add(2)
+	m := &multiHandler{parallel: 3, handlers: []slog.Handler{
+		rendezvousHandler{started: &started},
+		newMinLevelHandler(&errHandler{err: errors.New("disabled output was called")}, slog.LevelError),
+		rendezvousHandler{started: &started},
+	}}
+	if err := m.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "m", 0)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigure_ParallelOutputsWritesEveryOutput(t *testing.T) {
+	Reset()
+	defer Reset()
+	console, file := &bytes.Buffer{}, &bytes.Buffer{}
+	err := Configure(Config{
+		Level:           slog.LevelInfo,
+		Console:         true,
+		ConsoleWriter:   console,
+		FileWriter:      nopWriteCloser{file},
+		ParallelOutputs: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := Describe(); d.ParallelOutputs != 2 {
+		t.Fatalf("expected ParallelOutputs to be described, got %+v", d)
+	}
+	With("component", "db").Info("query", "rows", 3)
+	assertContains(t, console.String(), "msg=query component=db rows=3")
+	assertContains(t, file.String(), "msg=query component=db rows=3")
+}
+
+func TestConfigure_ParallelOutputsHonorOverrides(t *testing.T) {
+	Reset()
+	defer Reset()
+	console, file := &bytes.Buffer{}, &bytes.Buffer{}
+	err := Configure(Config{
+		Level:           slog.LevelInfo,
+		Console:         true,
+		ConsoleWriter:   console,
+		FileWriter:      nopWriteCloser{file},
+		ParallelOutputs: 4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetNamedLevel("parallel-db", slog.LevelDebug)
+
+	DebugContext(WithLevel(context.Background(), slog.LevelDebug), "ctx override")
+	Named("parallel-db").Debug("named level")
+
+	for _, out := range []string{console.String(), file.String()} {
+		assertContains(t, out, `msg="ctx override"`)
+		assertContains(t, out, `msg="named level"`)
 	}
 }
 
@@ -493,25 +699,6 @@ func TestMultiHandler_WithGroupAndWithAttrs(t *testing.T) {
 	}
 }
 
-func TestColorWriter_ReplacesLevelColor(t *testing.T) {
-	var buf bytes.Buffer
-	cw := &colorWriter{w: &buf}
-
-	line := "time=now level=ERROR msg=oops\n"
-	_, err := cw.Write([]byte(line))
-	if err != nil {
-		t.Fatalf("write failed: %v", err)
-	}
-
-	out := buf.String()
-	if !strings.Contains(out, "level=ERROR") {
-		t.Fatalf("expected level text present")
-	}
-	if !strings.Contains(out, "\033[31m") {
-		t.Fatalf("expected red color escape in output")
-	}
-}
-
 type nopWriteCloser struct{ *bytes.Buffer }
 
 func (n nopWriteCloser) Close() error { return nil }
@@ -574,3 +761,319 @@ func (s *simpleHandler) Enabled(ctx context.Context, level slog.Level) bool { re
 func (s *simpleHandler) Handle(ctx context.Context, r slog.Record) error    { return nil }
 func (s *simpleHandler) WithAttrs(attrs []slog.Attr) slog.Handler           { return s }
 func (s *simpleHandler) WithGroup(name string) slog.Handler                 { return s }
+
+func TestLogger_ConcurrentFirstUseConfiguresOnce(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		Reset()
+
+		const n = 32
+		var (
+			wg    sync.WaitGroup
+			start = make(chan struct{})
+			got   [n]*slog.Logger
+		)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				<-start
+				got[i] = Logger()
+			}(i)
+		}
+		close(start)
+		wg.Wait()
+
+		for i := 1; i < n; i++ {
+			if got[i] == nil || got[i] != got[0] {
+				t.Fatalf("round %d: expected all callers to share one lazily built logger", round)
+			}
+		}
+	}
+	Reset()
+}
+
+func TestLogger_LazyInitDoesNotOverrideConfigure(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: w}); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	Logger().Info("configured")
+	assertContains(t, w.String(), "configured")
+}
+
+func TestConfigureWithResult_ReportsDegradedOutputs(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	// a path below a regular file can be neither opened nor created
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notDir, nil, 0o644); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	badPath := filepath.Join(notDir, "fail.log")
+
+	res, err := ConfigureWithResult(Config{
+		Level:    slog.LevelInfo,
+		FilePath: badPath,
+	})
+	if !errors.Is(err, ErrFileOpen) {
+		t.Fatalf("expected ErrFileOpen, got %v", err)
+	}
+	if !res.Degraded || len(res.Outputs) != 1 || !res.Outputs[0].Fallback {
+		t.Fatalf("expected degraded result with stderr fallback, got %+v", res)
+	}
+
+	_, err = ConfigureWithResult(Config{
+		Level:            slog.LevelInfo,
+		FilePath:         badPath,
+		FileMaxSizeBytes: 1024,
+	})
+	if !errors.Is(err, ErrRotatorInit) {
+		t.Fatalf("expected ErrRotatorInit, got %v", err)
+	}
+
+	res, err = ConfigureWithResult(Config{
+		Level:    slog.LevelInfo,
+		FilePath: filepath.Join(t.TempDir(), "ok.log"),
+	})
+	if err != nil || res.Degraded || res.Outputs[0].Kind != "file" {
+		t.Fatalf("expected healthy file output, got %+v %v", res, err)
+	}
+}
+
+func TestConfigure_FailedReconfigureKeepsPreviousLogger(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	prev := Logger()
+	badPath := filepath.Join(t.TempDir(), "missing", "app.log")
+
+	res, err := ConfigureWithResult(Config{Console: true, FilePath: badPath})
+	if !errors.Is(err, ErrFileOpen) || !res.Kept || res.Outputs[0].Kind != "writer" {
+		t.Fatalf("expected the previous logger to be kept, got %+v %v", res, err)
+	}
+	Info("still to writer")
+	if Logger() != prev || w.closed {
+		t.Fatalf("expected the previous logger and writer to stay active")
+	}
+	assertContains(t, w.String(), "still to writer")
+
+	res, err = ConfigureWithResult(Config{FilePath: badPath, AllowDegraded: true})
+	if !errors.Is(err, ErrFileOpen) || res.Kept || !res.Outputs[0].Fallback {
+		t.Fatalf("expected the degraded logger with AllowDegraded, got %+v %v", res, err)
+	}
+	if Logger() == prev || !w.closed {
+		t.Fatalf("expected the previous logger to be replaced")
+	}
+}
+
+func TestAttrsVariants(t *testing.T) {
+	out := capture(t, slog.LevelDebug, func() {
+		ctx := context.Background()
+		DebugAttrs(ctx, "d", slog.Int("n", 1))
+		InfoAttrs(ctx, "i", slog.String("k", "v"))
+		WarnAttrs(ctx, "w", slog.Bool("b", true))
+		ErrorAttrs(ctx, "e", slog.Duration("took", time.Second))
+	})
+
+	assertContains(t, out, "level=DEBUG msg=d n=1")
+	assertContains(t, out, "level=INFO msg=i k=v")
+	assertContains(t, out, "level=WARN msg=w b=true")
+	assertContains(t, out, "level=ERROR msg=e took=1s")
+}
+
+func TestAddSource_PointsAtCaller(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	err := Configure(Config{
+		Level:      slog.LevelDebug,
+		FileWriter: nopWriteCloser{&buf},
+		AddSource:  true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure error: %v", err)
+	}
+
+	Debug("d")
+	Info("i")
+	Warn("w")
+	Error("e")
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if !strings.Contains(line, "logx_test.go:") {
+			t.Fatalf("expected source in logx_test.go, got %s", line)
+		}
+	}
+}
+
+func TestConfigReplaceAttr_AppliesToOutputs(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	var sinkOut bytes.Buffer
+	name := testSinkName("replace-attr")
+	RegisterSink(name, func(opts *slog.HandlerOptions, _ map[string]any) (slog.Handler, io.Closer, error) {
+		return slog.NewJSONHandler(&sinkOut, opts), nil, nil
+	})
+	err := Configure(Config{
+		FileWriter: w,
+		FileLevels: SyslogSeverity,
+		Sinks:      []SinkConfig{{Name: name}},
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			switch {
+			case len(groups) == 0 && a.Key == slog.MessageKey:
+				a.Key = "message"
+			case len(groups) == 0 && a.Key == slog.TimeKey:
+				return slog.Attr{}
+			case len(groups) == 1 && groups[0] == "req" && a.Key == "internal":
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	if err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Logger().WithGroup("req").Warn("renamed", "internal", "x", "path", "/a")
+
+	out := w.String()
+	assertContains(t, out, `level=4 message=renamed req.path=/a`)
+	if strings.Contains(out, "time=") || strings.Contains(out, "internal") {
+		t.Fatalf("expected time and req.internal to be dropped: %s", out)
+	}
+	assertContains(t, sinkOut.String(), `"message":"renamed"`)
+}
+
+func TestConfigure_ConsoleWriter(t *testing.T) {
+	defer Reset()
+
+	var a, b bytes.Buffer
+	res, err := ConfigureWithResult(Config{Console: true, ConsoleWriter: io.MultiWriter(&a, &b), ConsoleIcons: true})
+	if err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	if o := res.Outputs[0]; o.Kind != "console" || o.Target != "writer" || o.Color {
+		t.Fatalf("unexpected console description: %+v", o)
+	}
+	Info("to both", "k", "v")
+	for _, out := range []string{a.String(), b.String()} {
+		assertContains(t, out, `level=INFO msg="to both" k=v`)
+	}
+
+	if err := Configure(Config{Console: true, ConsoleWriter: os.Stdout}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	if got := Describe().Outputs[0].Target; got != "stdout" {
+		t.Fatalf("expected stdout target, got %q", got)
+	}
+}
+
+func TestConfigure_FilesHaveTheirOwnRotation(t *testing.T) {
+	Reset()
+	defer Reset()
+	dir := t.TempDir()
+	debug := filepath.Join(dir, "debug.log")
+	audit := filepath.Join(dir, "audit.json")
+	err := Configure(Config{
+		Level: slog.LevelDebug,
+		Files: []FileConfig{
+			{Path: debug, Rotation: Rotation{MaxSizeBytes: 100, MaxBackups: 3}},
+			{Path: audit, JSON: true, Rotation: Rotation{Every: 24 * time.Hour, MaxBackups: 365}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 5 {
+		Debug("filling the debug file", "i", i)
+	}
+
+	d := Describe()
+	if len(d.Outputs) != 2 || d.Outputs[0].Rotation.MaxSizeBytes != 100 || d.Outputs[1].Rotation.Every != "24h0m0s" || d.Outputs[1].Format != "json" {
+		t.Fatalf("unexpected outputs: %+v", d.Outputs)
+	}
+	out := capture(t, slog.LevelInfo, func() { Info("pipeline", "d", d) })
+	assertContains(t, out, "d.file.max_size_bytes=100")
+	assertContains(t, out, "d.file."+audit+".every=24h0m0s")
+	Reset()
+
+	if backups, _ := filepath.Glob(debug + ".*"); len(backups) != 3 {
+		t.Fatalf("expected 3 debug backups, got %v", backups)
+	}
+	data, err := os.ReadFile(audit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), `"msg":"filling the debug file"`); n != 5 {
+		t.Fatalf("expected every record in the JSON file, got %d:\n%s", n, data)
+	}
+}
+
+func TestConfigure_ErrorFileKeepsWarningsAndErrors(t *testing.T) {
+	Reset()
+	defer Reset()
+	errPath := filepath.Join(t.TempDir(), "errors.log")
+	console := &bytes.Buffer{}
+	err := Configure(Config{
+		Level:         slog.LevelDebug,
+		Console:       true,
+		ConsoleWriter: console,
+		ErrorFilePath: errPath,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := Describe().Outputs[1]; o.Target != errPath || o.MinLevel != "WARN" {
+		t.Fatalf("expected the error file to be described, got %+v", o)
+	}
+
+	Debug("cache miss")
+	Info("request served")
+	Warn("slow query")
+	Error("request failed")
+	Reset()
+
+	data, err := os.ReadFile(errPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	assertContains(t, out, `msg="slow query"`)
+	assertContains(t, out, `msg="request failed"`)
+	if strings.Contains(out, "cache miss") || strings.Contains(out, "request served") {
+		t.Fatalf("expected only WARN and ERROR records:\n%s", out)
+	}
+	assertContains(t, console.String(), `msg="cache miss"`)
+}
+
+func TestHandler_AppliesTheConfiguredChain(t *testing.T) {
+	Reset()
+	defer Reset()
+	defer ClearRedactedKeys()
+	var buf bytes.Buffer
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: nopWriteCloser{&buf}}); err != nil {
+		t.Fatal(err)
+	}
+	SetRedactedKeys("password")
+
+	l := slog.New(Handler()).With("component", "billing")
+	l.Info("login", "password", "hunter2")
+	l.Debug("below level")
+
+	out := buf.String()
+	assertContains(t, out, "msg=login component=billing password=REDACTED")
+	if strings.Contains(out, "below level") {
+		t.Fatalf("expected Level to apply:\n%s", out)
+	}
+}
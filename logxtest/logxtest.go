@@ -0,0 +1,249 @@
+// Package logxtest provides helpers for testing code that logs through logx
+// or the slog default logger.
+package logxtest
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rannday/logx"
+)
+
+// Record is a captured log record with its attributes flattened. Attrs
+// inside groups are keyed by their dotted path, e.g. "req.method".
+type Record struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   map[string]any
+}
+
+// Recorder captures log records in memory. It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []entry
+}
+
+type entry struct {
+	ctx  context.Context
+	r    slog.Record
+	goas []groupOrAttrs
+}
+
+// groupOrAttrs is one WithGroup or WithAttrs call on a handler.
+type groupOrAttrs struct {
+	group string
+	attrs []slog.Attr
+}
+
+type recorderKey struct{}
+
+var (
+	swapMu  sync.Mutex
+	active  []*Recorder
+	restore func()
+)
+
+// Swap installs a recorder as the global logx logger and slog default for
+// the duration of t, restoring the previous logger, including any open
+// outputs, in t.Cleanup.
+//
+// Swap may be called by parallel tests. The global logger is shared while
+// several swaps are active: records logged with a context returned by the
+// recorder's Context method go to that recorder only, all other records
+// go to every active recorder.
+func Swap(t testing.TB) *Recorder {
+	t.Helper()
+	rec := &Recorder{}
+
+	swapMu.Lock()
+	if len(active) == 0 {
+		restore = logx.SwapLogger(slog.New(&handler{}))
+	}
+	active = append(active, rec)
+	swapMu.Unlock()
+
+	t.Cleanup(func() {
+		swapMu.Lock()
+		defer swapMu.Unlock()
+		active = slices.DeleteFunc(active, func(r *Recorder) bool { return r == rec })
+		if len(active) == 0 {
+			restore()
+			restore = nil
+		}
+	})
+	return rec
+}
+
+// NewRecorder installs a recorder like Swap and is the usual way to start
+// a test:
+//
+//	rec := logxtest.NewRecorder(t)
+//	doWork()
+//	if !rec.Contains(slog.LevelWarn, "retrying") { ... }
+func NewRecorder(t testing.TB) *Recorder {
+	t.Helper()
+	return Swap(t)
+}
+
+// Logger returns a logger that writes to this recorder only.
+func (r *Recorder) Logger() *slog.Logger {
+	return slog.New(&handler{target: r})
+}
+
+// Context returns a copy of ctx that routes records to this recorder only,
+// both through the global logx functions and through logx.LoggerFromContext.
+func (r *Recorder) Context(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = context.WithValue(ctx, recorderKey{}, r)
+	return logx.WithLogger(ctx, r.Logger())
+}
+
+// Records returns the captured records in the order they were logged.
+func (r *Recorder) Records() []Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	out := make([]Record, 0, len(r.entries))
+	for _, e := range r.entries {
+		rec := Record{Time: e.r.Time, Level: e.r.Level, Message: e.r.Message, Attrs: map[string]any{}}
+		prefix := ""
+		for _, g := range e.goas {
+			if g.group != "" {
+				prefix += g.group + "."
+				continue
+			}
+			for _, a := range g.attrs {
+				flatten(rec.Attrs, prefix, a)
+			}
+		}
+		e.r.Attrs(func(a slog.Attr) bool {
+			flatten(rec.Attrs, prefix, a)
+			return true
+		})
+		out = append(out, rec)
+	}
+	return out
+}
+
+// Contains reports whether a record with level and message was captured.
+func (r *Recorder) Contains(level slog.Level, msg string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.ContainsFunc(r.entries, func(e entry) bool {
+		return e.r.Level == level && e.r.Message == msg
+	})
+}
+
+// AttrsOf returns the attrs of the first captured record with message msg,
+// keyed like Record.Attrs, or nil if there is none.
+func (r *Recorder) AttrsOf(msg string) map[string]any {
+	for _, rec := range r.Records() {
+		if rec.Message == msg {
+			return rec.Attrs
+		}
+	}
+	return nil
+}
+
+// String renders the captured records in slog's text format.
+func (r *Recorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var buf bytes.Buffer
+	base := slog.NewTextHandler(&buf, nil)
+	for _, e := range r.entries {
+		var h slog.Handler = base
+		for _, g := range e.goas {
+			if g.group != "" {
+				h = h.WithGroup(g.group)
+			} else {
+				h = h.WithAttrs(g.attrs)
+			}
+		}
+		_ = h.Handle(e.ctx, e.r)
+	}
+	return buf.String()
+}
+
+func (r *Recorder) add(e entry) {
+	r.mu.Lock()
+	r.entries = append(r.entries, e)
+	r.mu.Unlock()
+}
+
+func flatten(m map[string]any, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup {
+		m[prefix+a.Key] = v.Any()
+		return
+	}
+	if a.Key != "" {
+		prefix += a.Key + "."
+	}
+	for _, ga := range v.Group() {
+		flatten(m, prefix, ga)
+	}
+}
+
+// handler records every level. With a nil target it routes records to the
+// recorders of active swaps.
+type handler struct {
+	target *Recorder
+	goas   []groupOrAttrs
+}
+
+func (h *handler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	e := entry{ctx: ctx, r: r.Clone(), goas: h.goas}
+	if h.target != nil {
+		h.target.add(e)
+		return nil
+	}
+	for _, rec := range targets(ctx) {
+		rec.add(e)
+	}
+	return nil
+}
+
+// targets returns the recorder bound to ctx if it is active, or else all
+// active recorders.
+func targets(ctx context.Context) []*Recorder {
+	swapMu.Lock()
+	defer swapMu.Unlock()
+	if ctx != nil {
+		if rec, ok := ctx.Value(recorderKey{}).(*Recorder); ok && slices.Contains(active, rec) {
+			return []*Recorder{rec}
+		}
+	}
+	return slices.Clone(active)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(groupOrAttrs{attrs: attrs})
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(groupOrAttrs{group: name})
+}
+
+func (h *handler) with(g groupOrAttrs) *handler {
+	c := *h
+	c.goas = append(slices.Clip(h.goas), g)
+	return &c
+}
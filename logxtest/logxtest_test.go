@@ -0,0 +1,84 @@
+package logxtest
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/rannday/logx"
+)
+
+func TestSwap_CapturesAndRestores(t *testing.T) {
+	prev := logx.Logger()
+	prevDefault := slog.Default()
+
+	t.Run("swapped", func(t *testing.T) {
+		rec := Swap(t)
+		logx.Info("hello", "k", "v")
+		slog.Debug("from slog")
+		logx.Logger().WithGroup("req").With("method", "GET").Warn("grouped", "status", 200)
+
+		got := rec.Records()
+		if len(got) != 3 {
+			t.Fatalf("expected 3 records, got %d", len(got))
+		}
+		if got[0].Message != "hello" || got[0].Attrs["k"] != "v" {
+			t.Fatalf("unexpected record: %+v", got[0])
+		}
+		if got[1].Level != slog.LevelDebug {
+			t.Fatalf("expected DEBUG record, got %v", got[1].Level)
+		}
+		if got[2].Attrs["req.method"] != "GET" || got[2].Attrs["req.status"] != int64(200) {
+			t.Fatalf("unexpected grouped attrs: %v", got[2].Attrs)
+		}
+		if !strings.Contains(rec.String(), "req.method=GET") {
+			t.Fatalf("unexpected text: %s", rec.String())
+		}
+	})
+
+	if logx.Logger() != prev || slog.Default() != prevDefault {
+		t.Fatalf("expected previous loggers to be restored")
+	}
+}
+
+func TestSwap_ParallelIsolation(t *testing.T) {
+	for _, name := range []string{"a", "b", "c"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			rec := Swap(t)
+			ctx := rec.Context(context.Background())
+
+			for range 50 {
+				logx.InfoContext(ctx, "global", "test", name)
+				logx.LoggerFromContext(ctx).Info("scoped", "test", name)
+			}
+
+			got := rec.Records()
+			if len(got) != 100 {
+				t.Fatalf("expected 100 records, got %d", len(got))
+			}
+			for _, r := range got {
+				if r.Attrs["test"] != name {
+					t.Fatalf("record from another test: %+v", r)
+				}
+			}
+		})
+	}
+}
+
+func TestNewRecorder_Assertions(t *testing.T) {
+	rec := NewRecorder(t)
+	logx.Warn("retrying", "attempt", 2)
+	logx.Logger().WithGroup("db").Error("query failed", "table", "users")
+
+	if !rec.Contains(slog.LevelWarn, "retrying") || rec.Contains(slog.LevelInfo, "retrying") {
+		t.Fatalf("unexpected Contains results: %s", rec.String())
+	}
+	if got := rec.AttrsOf("query failed"); got["db.table"] != "users" {
+		t.Fatalf("unexpected attrs: %v", got)
+	}
+	if got := rec.AttrsOf("missing"); got != nil {
+		t.Fatalf("expected nil attrs for a missing message, got %v", got)
+	}
+}
@@ -0,0 +1,278 @@
+// Package lokix pushes records to Grafana Loki's HTTP push API in batches,
+// so no promtail or file tailer is needed next to the process.
+//
+//	logx.RegisterSink("loki", lokix.Sink(lokix.Options{
+//		URL:        "http://loki:3100/loki/api/v1/push",
+//		Labels:     map[string]string{"app": "api"},
+//		LabelAttrs: []string{"component"},
+//	}))
+//	logx.Configure(logx.Config{Console: true, Sinks: []logx.SinkConfig{{Name: "loki"}}})
+package lokix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rannday/logx"
+	"github.com/rannday/logx/internal/shipper"
+)
+
+// Options configures a Loki handler.
+type Options struct {
+	// URL is the push endpoint, e.g. http://loki:3100/loki/api/v1/push.
+	URL string
+	// TenantID is sent as X-Scope-OrgID when set.
+	TenantID string
+	// Labels are added to every stream.
+	Labels map[string]string
+	// LabelAttrs lists top-level attr keys promoted to stream labels. The
+	// record level is always written as the "level" label.
+	LabelAttrs []string
+	// BatchSize pushes once this many records are buffered (default 500).
+	BatchSize int
+	// BatchWait pushes buffered records at least this often (default 1s).
+	BatchWait time.Duration
+	// MaxBuffer is the number of records waiting to be pushed; records
+	// logged while it is full are dropped (default 10000).
+	MaxBuffer int
+	// MaxRetries retries a failed push on network errors, 429 and 5xx
+	// responses, doubling the wait from MinBackoff up to MaxBackoff
+	// (defaults 5, 500ms, 30s).
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Client sends the pushes (nil = http.DefaultClient).
+	Client *http.Client
+}
+
+// ErrPush reports a batch Loki did not accept after all retries.
+var ErrPush = errors.New("lokix: push")
+
+// Handler is a slog.Handler that batches records for Loki. Records are
+// written as JSON lines. Close flushes buffered records.
+type Handler struct {
+	c     *core
+	json  slog.Handler
+	attrs map[string]string // label values from WithAttrs
+	group bool
+}
+
+// core is shared by a Handler and those derived with WithAttrs/WithGroup.
+type core struct {
+	opts    Options
+	labelOK map[string]bool
+	fmt     shipper.Formatter
+	ship    *shipper.Shipper[entry]
+}
+
+type entry struct {
+	labels map[string]string
+	ts     time.Time
+	line   string
+}
+
+// New starts a Loki handler. hopts controls the level and the JSON line
+// format (nil = INFO).
+func New(opts Options, hopts *slog.HandlerOptions) (*Handler, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("lokix: URL is required")
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.BatchWait <= 0 {
+		opts.BatchWait = time.Second
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	c := &core{opts: opts, labelOK: map[string]bool{}}
+	for _, k := range opts.LabelAttrs {
+		c.labelOK[k] = true
+	}
+	c.ship = shipper.New(shipper.Config[entry]{
+		Options: shipper.Options{
+			BatchSize:  opts.BatchSize,
+			BatchWait:  opts.BatchWait,
+			MaxBuffer:  opts.MaxBuffer,
+			MaxRetries: opts.MaxRetries,
+			MinBackoff: opts.MinBackoff,
+			MaxBackoff: opts.MaxBackoff,
+		},
+		Err:  ErrPush,
+		Send: c.push,
+	})
+
+	return &Handler{c: c, json: slog.NewJSONHandler(c.fmt.Buffer(), hopts)}, nil
+}
+
+// Sink returns a logx.SinkFactory for a Loki handler with opts; the
+// SinkConfig.Options map is not used.
+func Sink(opts Options) logx.SinkFactory {
+	return func(hopts *slog.HandlerOptions, _ map[string]any) (slog.Handler, io.Closer, error) {
+		h, err := New(opts, hopts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return h, h, nil
+	}
+}
+
+// Sent returns the number of records Loki accepted.
+func (h *Handler) Sent() uint64 { return h.c.ship.Sent() }
+
+// Dropped returns the number of records dropped because the buffer was full.
+func (h *Handler) Dropped() uint64 { return h.c.ship.Dropped() }
+
+// Failed returns the number of records in batches that could not be pushed.
+func (h *Handler) Failed() uint64 { return h.c.ship.Failed() }
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.json.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	labels := make(map[string]string, len(h.c.opts.Labels)+len(h.attrs)+1)
+	maps.Copy(labels, h.c.opts.Labels)
+	maps.Copy(labels, h.attrs)
+	if !h.group {
+		r.Attrs(func(a slog.Attr) bool {
+			if h.c.labelOK[a.Key] {
+				labels[a.Key] = a.Value.Resolve().String()
+			}
+			return true
+		})
+	}
+	labels["level"] = strings.ToLower(r.Level.String())
+
+	line, err := h.c.fmt.Format(ctx, h.json, r)
+	if err != nil {
+		return err
+	}
+	return h.c.ship.Enqueue(entry{labels: labels, ts: r.Time, line: string(line)})
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.json = h.json.WithAttrs(attrs)
+	if h.group {
+		return &c
+	}
+	cloned := false
+	for _, a := range attrs {
+		if !h.c.labelOK[a.Key] {
+			continue
+		}
+		if !cloned {
+			c.attrs = maps.Clone(h.attrs)
+			if c.attrs == nil {
+				c.attrs = map[string]string{}
+			}
+			cloned = true
+		}
+		c.attrs[a.Key] = a.Value.Resolve().String()
+	}
+	return &c
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.json = h.json.WithGroup(name)
+	c.group = true
+	return &c
+}
+
+// Close pushes buffered records and stops the handler. It returns the
+// error of the last failed push, if any. It is safe to call more than once.
+func (h *Handler) Close() error {
+	return h.c.ship.Close()
+}
+
+// push sends batch as one request.
+func (c *core) push(batch []entry) (rest []entry, retry bool, err error) {
+	body, err := encode(batch)
+	if err != nil {
+		return batch, false, err
+	}
+	if retry, err := c.send(body); err != nil {
+		return batch, retry, err
+	}
+	return nil, false, nil
+}
+
+// send posts body and reports whether a failure is worth retrying.
+func (c *core) send(body []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, c.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.opts.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", c.opts.TenantID)
+	}
+	resp, err := c.opts.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+}
+
+type pushRequest struct {
+	Streams []stream `json:"streams"`
+}
+
+type stream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+// encode groups batch into streams by label set, keeping record order
+// within each stream.
+func encode(batch []entry) ([]byte, error) {
+	var req pushRequest
+	index := map[string]int{}
+	for _, e := range batch {
+		key := labelKey(e.labels)
+		i, ok := index[key]
+		if !ok {
+			i = len(req.Streams)
+			index[key] = i
+			req.Streams = append(req.Streams, stream{Stream: e.labels})
+		}
+		ts := strconv.FormatInt(e.ts.UnixNano(), 10)
+		req.Streams[i].Values = append(req.Streams[i].Values, [2]string{ts, e.line})
+	}
+	return json.Marshal(req)
+}
+
+func labelKey(labels map[string]string) string {
+	var b strings.Builder
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		b.WriteString(k)
+		b.WriteByte(0)
+		b.WriteString(labels[k])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
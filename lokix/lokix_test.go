@@ -0,0 +1,168 @@
+package lokix
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rannday/logx"
+)
+
+type lokiServer struct {
+	mu     sync.Mutex
+	pushes []pushRequest
+	tenant string
+	fail   atomic.Int32 // respond 503 this many times
+}
+
+func newLokiServer(t *testing.T) (*lokiServer, *httptest.Server) {
+	ls := &lokiServer{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ls.fail.Add(-1) >= 0 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		var req pushRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ls.mu.Lock()
+		ls.pushes = append(ls.pushes, req)
+		ls.tenant = r.Header.Get("X-Scope-OrgID")
+		ls.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+	return ls, srv
+}
+
+func TestHandler_BatchesIntoLabeledStreams(t *testing.T) {
+	ls, srv := newLokiServer(t)
+	h, err := New(Options{
+		URL:        srv.URL,
+		TenantID:   "team-a",
+		Labels:     map[string]string{"app": "api"},
+		LabelAttrs: []string{"component"},
+		BatchWait:  time.Hour,
+	}, nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	l := slog.New(h)
+	l.With("component", "db").Info("query", "ms", 3)
+	l.Warn("slow", "component", "http")
+	l.WithGroup("req").Info("grouped", "component", "ignored")
+	l.Debug("below level")
+	if err := h.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if len(ls.pushes) != 1 {
+		t.Fatalf("expected one batch, got %d", len(ls.pushes))
+	}
+	if ls.tenant != "team-a" {
+		t.Fatalf("expected tenant header, got %q", ls.tenant)
+	}
+	streams := ls.pushes[0].Streams
+	if len(streams) != 3 {
+		t.Fatalf("expected 3 streams, got %+v", streams)
+	}
+	first := streams[0]
+	if first.Stream["app"] != "api" || first.Stream["component"] != "db" || first.Stream["level"] != "info" {
+		t.Fatalf("unexpected labels: %v", first.Stream)
+	}
+	if !strings.Contains(first.Values[0][1], `"msg":"query"`) || !strings.Contains(first.Values[0][1], `"component":"db"`) {
+		t.Fatalf("unexpected line: %s", first.Values[0][1])
+	}
+	if streams[1].Stream["component"] != "http" || streams[1].Stream["level"] != "warn" {
+		t.Fatalf("unexpected labels: %v", streams[1].Stream)
+	}
+	if _, ok := streams[2].Stream["component"]; ok {
+		t.Fatalf("grouped attr must not become a label: %v", streams[2].Stream)
+	}
+	if h.Sent() != 3 {
+		t.Fatalf("expected 3 sent, got %d", h.Sent())
+	}
+}
+
+func TestHandler_WithAttrsOverridesLabelOnce(t *testing.T) {
+	ls, srv := newLokiServer(t)
+	h, err := New(Options{URL: srv.URL, LabelAttrs: []string{"component", "region"}, BatchWait: time.Hour}, nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	parent := slog.New(h).With("component", "db")
+	parent.With("component", "http", "region", "eu").Info("child")
+	parent.Info("parent")
+	if err := h.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	streams := ls.pushes[0].Streams
+	if len(streams) != 2 {
+		t.Fatalf("expected 2 streams, got %+v", streams)
+	}
+	if l := streams[0].Stream; l["component"] != "http" || l["region"] != "eu" {
+		t.Fatalf("expected the overridden label kept, got %v", l)
+	}
+	if l := streams[1].Stream; l["component"] != "db" || l["region"] != "" {
+		t.Fatalf("expected the parent labels untouched, got %v", l)
+	}
+}
+
+func TestHandler_RetriesTransientFailures(t *testing.T) {
+	ls, srv := newLokiServer(t)
+	ls.fail.Store(2)
+	h, _ := New(Options{URL: srv.URL, BatchSize: 1, MinBackoff: time.Millisecond}, nil)
+
+	slog.New(h).Info("eventually")
+	if err := h.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if len(ls.pushes) != 1 || h.Failed() != 0 {
+		t.Fatalf("expected push after retries, pushes=%d failed=%d", len(ls.pushes), h.Failed())
+	}
+}
+
+func TestHandler_ReportsFailedPush(t *testing.T) {
+	ls, srv := newLokiServer(t)
+	ls.fail.Store(100)
+	h, _ := New(Options{URL: srv.URL, MaxRetries: 2, MinBackoff: time.Millisecond}, nil)
+
+	slog.New(h).Info("lost")
+	if err := h.Close(); !errors.Is(err, ErrPush) {
+		t.Fatalf("expected ErrPush, got %v", err)
+	}
+	if h.Failed() != 1 {
+		t.Fatalf("expected 1 failed record, got %d", h.Failed())
+	}
+}
+
+func TestSink_RegistersWithLogx(t *testing.T) {
+	logx.Reset()
+	defer logx.Reset()
+
+	ls, srv := newLokiServer(t)
+	name := "lokix-" + t.Name() + time.Now().Format("150405.000000000")
+	logx.RegisterSink(name, Sink(Options{URL: srv.URL}))
+	if err := logx.Configure(logx.Config{Sinks: []logx.SinkConfig{{Name: name}}}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	logx.Info("via logx", "k", "v")
+	if err := logx.Shutdown(); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if len(ls.pushes) != 1 || !strings.Contains(ls.pushes[0].Streams[0].Values[0][1], `"k":"v"`) {
+		t.Fatalf("unexpected pushes: %+v", ls.pushes)
+	}
+}
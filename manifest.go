@@ -0,0 +1,149 @@
+package logx
+
+// manifest.go keeps a JSON index of rotated log files with their time range,
+// size and SHA-256, so shipped logs can be checked for completeness and
+// tampering. The rotator updates it on every rotation and Configure verifies
+// it on startup.
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/rannday/logx/internal/backups"
+)
+
+// manifestSuffix is appended to Config.FilePath to name the manifest.
+const manifestSuffix = backups.ManifestSuffix
+
+// ErrManifestMismatch is wrapped by the errors of VerifyManifest for rotated
+// files that are missing or whose size or checksum changed.
+var ErrManifestMismatch = errors.New("logx: rotated file does not match manifest")
+
+// Manifest is the content of "<FilePath>.manifest".
+type Manifest struct {
+	Files []ManifestEntry `json:"files"`
+}
+
+// ManifestEntry describes one rotated file. From and To are when the rotator
+// started writing the file and when it rotated it.
+type ManifestEntry struct {
+	// Name is the file name, in the directory of the log file.
+	Name   string    `json:"name"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Size   int64     `json:"size"`
+	SHA256 string    `json:"sha256"`
+}
+
+// ReadManifest reads the manifest of the log file at path. It returns an
+// empty manifest when there is none.
+func ReadManifest(path string) (Manifest, error) {
+	var m Manifest
+	data, err := os.ReadFile(path + manifestSuffix)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, fmt.Errorf("logx: read manifest %s: %w", path+manifestSuffix, err)
+	}
+	return m, nil
+}
+
+// VerifyManifest checks every rotated file listed in the manifest of the log
+// file at path. Files that are missing or changed are reported together, each
+// wrapping ErrManifestMismatch. Backups removed by FileMaxBackups are dropped
+// from the manifest when they are removed and are not reported.
+func VerifyManifest(path string) error {
+	m, err := ReadManifest(path)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	var errs []error
+	for _, e := range m.Files {
+		size, sum, err := fileDigest(filepath.Join(dir, e.Name))
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			errs = append(errs, fmt.Errorf("%w: %s is missing", ErrManifestMismatch, e.Name))
+		case err != nil:
+			errs = append(errs, err)
+		case size != e.Size:
+			errs = append(errs, fmt.Errorf("%w: %s is %d bytes, want %d", ErrManifestMismatch, e.Name, size, e.Size))
+		case sum != e.SHA256:
+			errs = append(errs, fmt.Errorf("%w: %s checksum changed", ErrManifestMismatch, e.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reportManifest logs the problems VerifyManifest finds for path.
+func reportManifest(l *slog.Logger, path string) {
+	err := VerifyManifest(path)
+	if err == nil {
+		return
+	}
+	errs := []error{err}
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = j.Unwrap()
+	}
+	for _, e := range errs {
+		l.Warn("rotated log file failed verification", "manifest", path+manifestSuffix, "error", e)
+	}
+}
+
+// fileDigest returns the size and hex SHA-256 of the file at path.
+func fileDigest(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// recordRotation adds the file rotated to rotated, written since from, to
+// the manifest of path and drops the backups in removed. The manifest is
+// replaced atomically.
+func recordRotation(path, rotated string, from time.Time, removed []string) error {
+	m, err := ReadManifest(path)
+	if err != nil {
+		return err
+	}
+	size, sum, err := fileDigest(rotated)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(rotated)
+	m.Files = slices.DeleteFunc(m.Files, func(e ManifestEntry) bool {
+		return e.Name == name || slices.Contains(removed, e.Name)
+	})
+	m.Files = append(m.Files, ManifestEntry{Name: name, From: from, To: Now(), Size: size, SHA256: sum})
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + manifestSuffix + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path+manifestSuffix)
+}
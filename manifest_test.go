@@ -0,0 +1,129 @@
+package logx
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileRotator_ManifestRecordsRotatedFiles(t *testing.T) {
+	start := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return start })
+	defer SetClock(nil)
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := newFileRotator(path, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.manifest = true
+
+	_, _ = r.Write([]byte("first line\n"))
+	SetClock(func() time.Time { return start.Add(time.Minute) })
+	_, _ = r.Write([]byte("second line\n"))
+
+	m, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Files) != 1 {
+		t.Fatalf("expected one rotated file, got %+v", m.Files)
+	}
+	e := m.Files[0]
+	if !strings.HasPrefix(e.Name, "app.log.") || e.Size != 11 || len(e.SHA256) != 64 {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	if !e.From.Equal(start) || !e.To.Equal(start.Add(time.Minute)) {
+		t.Fatalf("unexpected time range: %v - %v", e.From, e.To)
+	}
+	if err := VerifyManifest(path); err != nil {
+		t.Fatalf("expected rotated file to verify: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), e.Name), []byte("forged line\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyManifest(path); !errors.Is(err, ErrManifestMismatch) || !strings.Contains(err.Error(), "is 12 bytes, want 11") {
+		t.Fatalf("expected size mismatch, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(filepath.Dir(path), e.Name), []byte("forged lin\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyManifest(path); !errors.Is(err, ErrManifestMismatch) || !strings.Contains(err.Error(), "checksum changed") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestFileRotator_ManifestDropsPrunedBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := newFileRotator(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.manifest = true
+
+	// distinct backup names need distinct seconds
+	for i := range 3 {
+		if i > 0 {
+			time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+		}
+		_, _ = r.Write([]byte("0123456789\n"))
+	}
+
+	m, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	backups, _ := filepath.Glob(path + ".2*")
+	if len(m.Files) != 1 || len(backups) != 1 || filepath.Base(backups[0]) != m.Files[0].Name {
+		t.Fatalf("expected the kept backup only, got %+v and %v", m.Files, backups)
+	}
+	if err := VerifyManifest(path); err != nil {
+		t.Fatalf("expected pruned backups not to be reported: %v", err)
+	}
+}
+
+func TestConfigure_ReportsManifestProblems(t *testing.T) {
+	Reset()
+	defer Reset()
+	path := filepath.Join(t.TempDir(), "app.log")
+	cfg := Config{Level: slog.LevelInfo, FilePath: path, FileMaxSizeBytes: 40, FileManifest: true}
+	if err := Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	Info("filling the file past its limit")
+	Info("rotated")
+	Reset()
+
+	m, err := ReadManifest(path)
+	if err != nil || len(m.Files) == 0 {
+		t.Fatalf("expected a manifest entry, got %+v, %v", m, err)
+	}
+	if err := os.Remove(filepath.Join(filepath.Dir(path), m.Files[0].Name)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	Reset()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, string(data), `msg="rotated log file failed verification"`)
+	assertContains(t, string(data), m.Files[0].Name+" is missing")
+}
+
+func TestValidate_FileManifestRequiresRotation(t *testing.T) {
+	err := Config{FilePath: filepath.Join(t.TempDir(), "app.log"), FileManifest: true}.Validate()
+	if err == nil || !strings.Contains(err.Error(), "FileManifest and FileCompress require") {
+		t.Fatalf("expected a FileManifest error, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package logx
+
+// named.go provides named component loggers with per-name levels. A named
+// logger forwards to whatever logger is installed, so it can be created once
+// at package init and survives Configure.
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// NamedKey is the attr key holding a named logger's name.
+const NamedKey = "logger"
+
+var (
+	namedMu sync.Mutex
+	named   = map[string]*namedState{}
+)
+
+// namedState is shared by every logger derived from Named(name).
+type namedState struct {
+	name   string
+	logger *slog.Logger
+	// level overrides the global level when set
+	level atomic.Pointer[slog.Level]
+}
+
+// namedStateFor returns the state for name, creating it. The caller holds
+// namedMu.
+func namedStateFor(name string) *namedState {
+	st := named[name]
+	if st == nil {
+		st = &namedState{name: name}
+		st.logger = slog.New(&namedHandler{st: st, cache: new(atomic.Pointer[namedCache])})
+		named[name] = st
+	}
+	return st
+}
+
+// Named returns the logger for a component, adding NamedKey=name to its
+// records. Calls with the same name return the same logger. It logs through
+// the current global logger, also after Configure replaces it, at the level
+// set with SetNamedLevel or Config.NamedLevels, or the global level.
+func Named(name string) *slog.Logger {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	return namedStateFor(name).logger
+}
+
+// SetNamedLevel sets the minimum level of the named logger, which may be
+// below the global level. A WithLevel context override still takes
+// precedence.
+func SetNamedLevel(name string, level slog.Level) {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	namedStateFor(name).level.Store(&level)
+}
+
+// ClearNamedLevel makes the named logger follow the global level again.
+func ClearNamedLevel(name string) {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	if st := named[name]; st != nil {
+		st.level.Store(nil)
+	}
+}
+
+// setNamedLevels replaces all per-name levels with levels.
+func setNamedLevels(levels map[string]slog.Level) {
+	namedMu.Lock()
+	defer namedMu.Unlock()
+	for _, st := range named {
+		st.level.Store(nil)
+	}
+	for name, level := range levels {
+		namedStateFor(name).level.Store(&level)
+	}
+}
+
+// namedCache is the global logger's handler with the named logger's attrs
+// and groups applied, built for one global logger.
+type namedCache struct {
+	base *slog.Logger
+	h    slog.Handler
+}
+
+// namedHandler resolves the global logger on every call and applies the
+// name, attrs and groups to its handler, caching the result until the
+// global logger changes.
+type namedHandler struct {
+	st    *namedState
+	ops   []func(slog.Handler) slog.Handler
+	cache *atomic.Pointer[namedCache]
+}
+
+func (h *namedHandler) handler() slog.Handler {
+	base := Logger()
+	if c := h.cache.Load(); c != nil && c.base == base {
+		return c.h
+	}
+	next := base.Handler().WithAttrs([]slog.Attr{slog.String(NamedKey, h.st.name)})
+	for _, op := range h.ops {
+		next = op(next)
+	}
+	h.cache.Store(&namedCache{base: base, h: next})
+	return next
+}
+
+func (h *namedHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if _, ok := LevelFromContext(ctx); !ok {
+		if min := h.st.level.Load(); min != nil {
+			return !disabled.Load() && level >= *min
+		}
+	}
+	return h.handler().Enabled(ctx, level)
+}
+
+func (h *namedHandler) Handle(ctx context.Context, r slog.Record) error {
+	// carry the name's level, so a record below the global level is not
+	// taken for one only the recent ring sees
+	if _, ok := LevelFromContext(ctx); !ok {
+		if min := h.st.level.Load(); min != nil {
+			ctx = WithLevel(ctx, *min)
+		}
+	}
+	return h.handler().Handle(ctx, r)
+}
+
+func (h *namedHandler) with(op func(slog.Handler) slog.Handler) slog.Handler {
+	ops := append(h.ops[:len(h.ops):len(h.ops)], op)
+	return &namedHandler{st: h.st, ops: ops, cache: new(atomic.Pointer[namedCache])}
+}
+
+func (h *namedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *namedHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
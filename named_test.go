@@ -0,0 +1,78 @@
+package logx
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNamed_FollowsReconfigureAndAddsName(t *testing.T) {
+	defer Reset()
+
+	db := Named("db")
+	if Named("db") != db {
+		t.Fatal("expected Named to memoize loggers")
+	}
+	tx := db.With("tx", 7).WithGroup("q")
+
+	first := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: first}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	db.Info("connected")
+	second := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: second}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	tx.Info("slow", "ms", 12)
+	Shutdown()
+
+	for _, want := range []string{"logger=db", "connected"} {
+		assertContains(t, first.String(), want)
+	}
+	for _, want := range []string{"logger=db", "tx=7", "q.ms=12"} {
+		assertContains(t, second.String(), want)
+	}
+	if strings.Contains(first.String(), "slow") {
+		t.Fatalf("record went to the replaced logger: %q", first.String())
+	}
+}
+
+func TestNamed_PerNameLevels(t *testing.T) {
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w, NamedLevels: map[string]slog.Level{"db": slog.LevelDebug}}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	Named("db").Debug("db debug")
+	Named("http").Debug("http debug")
+
+	SetNamedLevel("http", slog.LevelDebug)
+	SetNamedLevel("db", slog.LevelError)
+	Named("http").Debug("http debug again")
+	Named("db").Warn("db warn")
+	Named("db").WarnContext(WithLevel(context.Background(), slog.LevelWarn), "db warn by context")
+
+	ClearNamedLevel("http")
+	Named("http").Debug("http cleared")
+	Shutdown()
+
+	out := w.String()
+	for _, want := range []string{"db debug", "http debug again", "db warn by context"} {
+		assertContains(t, out, want)
+	}
+	for _, unwanted := range []string{`msg="http debug"`, `msg="db warn"`, "http cleared"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("unexpected %q in %q", unwanted, out)
+		}
+	}
+
+	if err := Configure(Config{FileWriter: &trackingWriteCloser{}}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	if !Named("db").Enabled(context.Background(), slog.LevelWarn) || Named("db").Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("expected Configure to replace per-name levels")
+	}
+}
@@ -0,0 +1,581 @@
+// Package natsx publishes records to NATS subjects derived from the level
+// and component, optionally waiting for JetStream acknowledgements, so
+// services already on NATS need no separate log shipper. It speaks the
+// NATS client protocol directly and has no dependencies.
+//
+//	logx.RegisterSink("nats", natsx.Sink(natsx.Options{
+//		URL:     "nats://nats:4222",
+//		Subject: "logs.myservice.{level}",
+//	}))
+//	logx.Configure(logx.Config{Console: true, Sinks: []logx.SinkConfig{{Name: "nats"}}})
+package natsx
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rannday/logx"
+	"github.com/rannday/logx/internal/shipper"
+)
+
+// Options configures a NATS handler.
+type Options struct {
+	// URL is the server, e.g. nats://nats:4222 or tls://nats:4222. User
+	// and password, or a token, may be given as URL user info.
+	URL string
+	// Subject is the subject template; "{level}" is replaced by the
+	// lower-case level name and "{component}" by the component attr
+	// (default "logs.{level}").
+	Subject string
+	// ComponentAttr is the top-level attr key used for "{component}"
+	// (default "component"); DefaultComponent is used when it is absent
+	// (default "default").
+	ComponentAttr    string
+	DefaultComponent string
+	// Name is the client name shown in the server's connection list.
+	Name string
+	// Token, or User and Password, authenticate the connection.
+	Token    string
+	User     string
+	Password string
+	// TLSConfig is used for tls:// URLs and servers that require TLS
+	// (nil = system defaults).
+	TLSConfig *tls.Config
+	// JetStream waits for the stream's acknowledgement of every record
+	// and retries records that were not acknowledged within AckWait
+	// (default 5s). The subjects must be bound to a stream.
+	JetStream bool
+	AckWait   time.Duration
+	// MaxBuffer is the number of records waiting to be published; records
+	// logged while it is full are dropped (default 10000).
+	MaxBuffer int
+	// MaxRetries retries failed publishes after reconnecting, doubling the
+	// wait from MinBackoff up to MaxBackoff (defaults 5, 500ms, 30s).
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// ErrPublish reports records that could not be published after all retries.
+var ErrPublish = errors.New("natsx: publish")
+
+// maxBatch is the number of records written before flushing the connection.
+const maxBatch = 256
+
+// Handler is a slog.Handler that publishes records as JSON messages.
+// Close publishes buffered records.
+type Handler struct {
+	c         *core
+	json      slog.Handler
+	component string // from WithAttrs
+	group     bool
+}
+
+// core is shared by a Handler and those derived with WithAttrs/WithGroup.
+type core struct {
+	opts Options
+	fmt  shipper.Formatter
+	ship *shipper.Shipper[entry]
+	nc   *conn // used by the shipper goroutine only
+}
+
+type entry struct {
+	subject string
+	data    []byte
+}
+
+// New connects to NATS and starts the handler. hopts controls the level
+// and the JSON message format (nil = INFO). After a successful start, a
+// lost connection is re-established while records are buffered.
+func New(opts Options, hopts *slog.HandlerOptions) (*Handler, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("natsx: URL is required")
+	}
+	if opts.Subject == "" {
+		opts.Subject = "logs.{level}"
+	}
+	if opts.ComponentAttr == "" {
+		opts.ComponentAttr = "component"
+	}
+	if opts.DefaultComponent == "" {
+		opts.DefaultComponent = "default"
+	}
+	if opts.AckWait <= 0 {
+		opts.AckWait = 5 * time.Second
+	}
+
+	nc, err := dial(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPublish, err)
+	}
+	c := &core{opts: opts, nc: nc}
+	c.ship = shipper.New(shipper.Config[entry]{
+		Options: shipper.Options{
+			BatchSize:  maxBatch,
+			MaxBuffer:  opts.MaxBuffer,
+			MaxRetries: opts.MaxRetries,
+			MinBackoff: opts.MinBackoff,
+			MaxBackoff: opts.MaxBackoff,
+		},
+		Err:  ErrPublish,
+		Send: c.publish,
+		Stop: func() {
+			if c.nc != nil {
+				c.nc.close()
+			}
+		},
+	})
+
+	return &Handler{c: c, json: slog.NewJSONHandler(c.fmt.Buffer(), hopts)}, nil
+}
+
+// Sink returns a logx.SinkFactory for a NATS handler with opts; the
+// SinkConfig.Options map is not used.
+func Sink(opts Options) logx.SinkFactory {
+	return func(hopts *slog.HandlerOptions, _ map[string]any) (slog.Handler, io.Closer, error) {
+		h, err := New(opts, hopts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return h, h, nil
+	}
+}
+
+// Sent returns the number of records published (and acknowledged with
+// JetStream).
+func (h *Handler) Sent() uint64 { return h.c.ship.Sent() }
+
+// Dropped returns the number of records dropped because the buffer was full.
+func (h *Handler) Dropped() uint64 { return h.c.ship.Dropped() }
+
+// Failed returns the number of records that could not be published.
+func (h *Handler) Failed() uint64 { return h.c.ship.Failed() }
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.json.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	component := h.component
+	if !h.group {
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == h.c.opts.ComponentAttr {
+				component = a.Value.Resolve().String()
+			}
+			return true
+		})
+	}
+	if component == "" {
+		component = h.c.opts.DefaultComponent
+	}
+	subject := strings.NewReplacer(
+		"{level}", subjectToken(strings.ToLower(r.Level.String())),
+		"{component}", subjectToken(component),
+	).Replace(h.c.opts.Subject)
+
+	data, err := h.c.fmt.Format(ctx, h.json, r)
+	if err != nil {
+		return err
+	}
+	return h.c.ship.Enqueue(entry{subject: subject, data: data})
+}
+
+// subjectToken replaces characters that would split or wildcard a subject.
+func subjectToken(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.json = h.json.WithAttrs(attrs)
+	if !h.group {
+		for _, a := range attrs {
+			if a.Key == h.c.opts.ComponentAttr {
+				c.component = a.Value.Resolve().String()
+			}
+		}
+	}
+	return &c
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.json = h.json.WithGroup(name)
+	c.group = true
+	return &c
+}
+
+// Close publishes buffered records and closes the connection. It returns
+// the error of the last failed publish, if any. It is safe to call more
+// than once.
+func (h *Handler) Close() error {
+	return h.c.ship.Close()
+}
+
+// publish sends batch and returns the records that were not published.
+// After a failure the connection is dropped, so the retry reconnects.
+func (c *core) publish(batch []entry) (rest []entry, retry bool, err error) {
+	rest, err = c.send(batch)
+	if err != nil && c.nc != nil {
+		c.nc.close()
+		c.nc = nil
+	}
+	return rest, true, err
+}
+
+// send publishes batch and returns the entries that were not published
+// (or not acknowledged with JetStream).
+func (c *core) send(batch []entry) ([]entry, error) {
+	if c.nc == nil {
+		nc, err := dial(c.opts)
+		if err != nil {
+			return batch, err
+		}
+		c.nc = nc
+	}
+	if !c.opts.JetStream {
+		return c.nc.pub(batch)
+	}
+	return c.nc.pubAcked(batch, c.opts.AckWait)
+}
+
+// conn is a NATS client connection. A reader goroutine answers server
+// PINGs and delivers JetStream acknowledgements.
+type conn struct {
+	nc net.Conn
+
+	wmu sync.Mutex
+	w   *bufio.Writer
+
+	inbox  string
+	nextID uint64
+	acks   chan ack
+	pongs  chan struct{}
+
+	dead chan struct{}
+	err  error // set before dead is closed
+}
+
+type ack struct {
+	id  string
+	err error
+}
+
+// serverInfo holds the INFO fields used by the client.
+type serverInfo struct {
+	TLSRequired bool `json:"tls_required"`
+}
+
+func dial(opts Options) (*conn, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	nc, err := net.DialTimeout("tcp", host, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	_ = nc.SetDeadline(time.Now().Add(5 * time.Second))
+	c, err := handshake(nc, u, opts)
+	if err != nil {
+		_ = nc.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func handshake(nc net.Conn, u *url.URL, opts Options) (*conn, error) {
+	r := bufio.NewReader(nc)
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	op, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+	if op != "INFO" {
+		return nil, fmt.Errorf("unexpected greeting %q", line)
+	}
+	var info serverInfo
+	_ = json.Unmarshal([]byte(args), &info)
+
+	if u.Scheme == "tls" || opts.TLSConfig != nil || info.TLSRequired {
+		cfg := &tls.Config{}
+		if opts.TLSConfig != nil {
+			cfg = opts.TLSConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = u.Hostname()
+		}
+		tc := tls.Client(nc, cfg)
+		if err := tc.Handshake(); err != nil {
+			return nil, err
+		}
+		nc = tc
+		r = bufio.NewReader(tc)
+	}
+
+	connect := map[string]any{
+		"verbose":  false,
+		"pedantic": false,
+		"lang":     "go",
+		"version":  "logx",
+		"protocol": 1,
+	}
+	if opts.Name != "" {
+		connect["name"] = opts.Name
+	}
+	user, pass, token := opts.User, opts.Password, opts.Token
+	if u.User != nil {
+		if p, ok := u.User.Password(); ok {
+			user, pass = u.User.Username(), p
+		} else {
+			token = u.User.Username()
+		}
+	}
+	if token != "" {
+		connect["auth_token"] = token
+	}
+	if user != "" {
+		connect["user"], connect["pass"] = user, pass
+	}
+	b, err := json.Marshal(connect)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &conn{nc: nc, w: bufio.NewWriter(nc), pongs: make(chan struct{}, 1), dead: make(chan struct{})}
+	fmt.Fprintf(c.w, "CONNECT %s\r\nPING\r\n", b)
+	if opts.JetStream {
+		var id [8]byte
+		_, _ = rand.Read(id[:])
+		c.inbox = "_INBOX." + hex.EncodeToString(id[:])
+		c.acks = make(chan ack, maxBatch)
+		fmt.Fprintf(c.w, "SUB %s.* 1\r\n", c.inbox)
+	}
+	if err := c.w.Flush(); err != nil {
+		return nil, err
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "PONG" {
+			break
+		}
+		if strings.HasPrefix(line, "-ERR") {
+			return nil, errors.New(line)
+		}
+	}
+	_ = nc.SetDeadline(time.Time{})
+	go c.read(r)
+	return c, nil
+}
+
+// read handles server messages until the connection fails.
+func (c *conn) read(r *bufio.Reader) {
+	fail := func(err error) {
+		c.err = err
+		close(c.dead)
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			fail(err)
+			return
+		}
+		line = strings.TrimSpace(line)
+		op, args, _ := strings.Cut(line, " ")
+		switch op {
+		case "PING":
+			c.wmu.Lock()
+			_, _ = c.w.WriteString("PONG\r\n")
+			_ = c.w.Flush()
+			c.wmu.Unlock()
+		case "PONG":
+			select {
+			case c.pongs <- struct{}{}:
+			default:
+			}
+		case "-ERR":
+			fail(errors.New(line))
+			_ = c.nc.Close()
+			return
+		case "MSG":
+			// MSG <subject> <sid> [reply-to] <#bytes>
+			f := strings.Fields(args)
+			if len(f) < 3 {
+				fail(fmt.Errorf("bad MSG line %q", line))
+				return
+			}
+			n, err := strconv.Atoi(f[len(f)-1])
+			if err != nil {
+				fail(fmt.Errorf("bad MSG line %q", line))
+				return
+			}
+			payload := make([]byte, n+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				fail(err)
+				return
+			}
+			if c.acks != nil {
+				select {
+				case c.acks <- ack{id: strings.TrimPrefix(f[0], c.inbox+"."), err: ackError(payload[:n])}:
+				default:
+					// late ack nobody waits for
+				}
+			}
+		}
+	}
+}
+
+// ackError returns the error of a JetStream publish acknowledgement.
+func ackError(payload []byte) error {
+	var resp struct {
+		Error *struct {
+			Code        int    `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(payload, &resp); err != nil {
+		return fmt.Errorf("bad ack %q", payload)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("jetstream: %s (%d)", resp.Error.Description, resp.Error.Code)
+	}
+	return nil
+}
+
+// pub writes batch and flushes. On failure no entry is known to have been
+// published, so all are returned.
+func (c *conn) pub(batch []entry) ([]entry, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	for _, e := range batch {
+		c.writePub(e, "")
+	}
+	if err := c.w.Flush(); err != nil {
+		return batch, err
+	}
+	select {
+	case <-c.dead:
+		return batch, c.err
+	default:
+		return nil, nil
+	}
+}
+
+func (c *conn) writePub(e entry, reply string) {
+	c.w.WriteString("PUB ")
+	c.w.WriteString(e.subject)
+	if reply != "" {
+		c.w.WriteByte(' ')
+		c.w.WriteString(reply)
+	}
+	c.w.WriteByte(' ')
+	c.w.WriteString(strconv.Itoa(len(e.data)))
+	c.w.WriteString("\r\n")
+	c.w.Write(e.data)
+	c.w.WriteString("\r\n")
+}
+
+// pubAcked publishes batch with reply subjects and waits up to wait for
+// the acknowledgements; entries without a positive ack are returned.
+func (c *conn) pubAcked(batch []entry, wait time.Duration) ([]entry, error) {
+	pending := make(map[string]int, len(batch))
+	c.wmu.Lock()
+	for i, e := range batch {
+		c.nextID++
+		id := strconv.FormatUint(c.nextID, 10)
+		pending[id] = i
+		c.writePub(e, c.inbox+"."+id)
+	}
+	err := c.w.Flush()
+	c.wmu.Unlock()
+	if err != nil {
+		return batch, err
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	var failed []int
+	var ackErr error
+	for len(pending) > 0 {
+		select {
+		case a := <-c.acks:
+			i, ok := pending[a.id]
+			if !ok {
+				continue // ack of an earlier, timed out attempt
+			}
+			delete(pending, a.id)
+			if a.err != nil {
+				failed = append(failed, i)
+				ackErr = a.err
+			}
+		case <-c.dead:
+			err = c.err
+		case <-timer.C:
+			err = fmt.Errorf("no ack within %v", wait)
+		}
+		if err != nil {
+			for _, i := range pending {
+				failed = append(failed, i)
+			}
+			break
+		}
+	}
+	if err == nil {
+		err = ackErr
+	}
+	slices.Sort(failed)
+	rest := make([]entry, len(failed))
+	for j, i := range failed {
+		rest[j] = batch[i]
+	}
+	return rest, err
+}
+
+// close waits for the server to process everything written (a PING/PONG
+// round trip, at most 2s) and closes the connection.
+func (c *conn) close() {
+	c.wmu.Lock()
+	_, _ = c.w.WriteString("PING\r\n")
+	err := c.w.Flush()
+	c.wmu.Unlock()
+	if err == nil {
+		select {
+		case <-c.pongs:
+		case <-c.dead:
+		case <-time.After(2 * time.Second):
+		}
+	}
+	_ = c.nc.Close()
+}
@@ -0,0 +1,217 @@
+package natsx
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rannday/logx"
+)
+
+type pubMsg struct {
+	subject string
+	data    string
+}
+
+// natsServer speaks enough of the server protocol for the client: INFO,
+// CONNECT, PING/PONG, SUB and PUB, acking publishes with a reply subject.
+type natsServer struct {
+	ln      net.Listener
+	connect chan map[string]any
+
+	mu   sync.Mutex
+	msgs []pubMsg
+	// ack returns the JetStream ack payload for the nth publish
+	ack func(n int) string
+}
+
+func newNATSServer(t *testing.T) *natsServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &natsServer{ln: ln, connect: make(chan map[string]any, 4)}
+	t.Cleanup(func() { _ = ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+func (s *natsServer) url() string { return "nats://" + s.ln.Addr().String() }
+
+func (s *natsServer) serve(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	fmt.Fprintf(c, "INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")
+	var sid string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		op, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+		switch op {
+		case "CONNECT":
+			var m map[string]any
+			_ = json.Unmarshal([]byte(args), &m)
+			s.connect <- m
+		case "PING":
+			fmt.Fprintf(c, "PONG\r\n")
+		case "SUB":
+			sid = strings.Fields(args)[1]
+		case "PUB":
+			f := strings.Fields(args)
+			n, _ := strconv.Atoi(f[len(f)-1])
+			data := make([]byte, n+2)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.msgs = append(s.msgs, pubMsg{subject: f[0], data: string(data[:n])})
+			count := len(s.msgs)
+			s.mu.Unlock()
+			if len(f) == 3 && s.ack != nil {
+				payload := s.ack(count)
+				fmt.Fprintf(c, "MSG %s %s %d\r\n%s\r\n", f[1], sid, len(payload), payload)
+			}
+		}
+	}
+}
+
+func (s *natsServer) published() []pubMsg {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]pubMsg(nil), s.msgs...)
+}
+
+func TestHandler_PublishesToDerivedSubjects(t *testing.T) {
+	s := newNATSServer(t)
+	h, err := New(Options{
+		URL:     "nats://s3cret@" + s.ln.Addr().String(),
+		Subject: "logs.billing.{component}.{level}",
+		Name:    "billing",
+	}, nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	connect := <-s.connect
+	if connect["auth_token"] != "s3cret" || connect["name"] != "billing" {
+		t.Fatalf("unexpected CONNECT: %v", connect)
+	}
+
+	l := slog.New(h)
+	l.With("component", "db").Error("query failed", "table", "users")
+	l.Info("charged", "component", "api.v2")
+	l.WithGroup("req").Warn("slow", "component", "ignored")
+	l.Debug("below level")
+	if err := h.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	msgs := s.published()
+	want := []string{"logs.billing.db.error", "logs.billing.api_v2.info", "logs.billing.default.warn"}
+	if len(msgs) != len(want) {
+		t.Fatalf("expected %d messages, got %+v", len(want), msgs)
+	}
+	for i, m := range msgs {
+		if m.subject != want[i] {
+			t.Errorf("subject %d = %q, want %q", i, m.subject, want[i])
+		}
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(msgs[0].data), &rec); err != nil || rec["msg"] != "query failed" || rec["table"] != "users" {
+		t.Fatalf("unexpected payload %q: %v", msgs[0].data, err)
+	}
+	if h.Sent() != 3 || h.Failed() != 0 || h.Dropped() != 0 {
+		t.Fatalf("unexpected counters: sent %d failed %d dropped %d", h.Sent(), h.Failed(), h.Dropped())
+	}
+}
+
+func TestHandler_JetStreamRetriesNegativeAcks(t *testing.T) {
+	s := newNATSServer(t)
+	s.ack = func(n int) string {
+		if n == 2 {
+			return `{"error":{"code":503,"description":"stream offline"}}`
+		}
+		return `{"stream":"LOGS","seq":` + strconv.Itoa(n) + `}`
+	}
+	h, err := New(Options{URL: s.url(), JetStream: true, MinBackoff: time.Millisecond}, nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	l := slog.New(h)
+	l.Info("one")
+	l.Info("two")
+	l.Info("three")
+	if err := h.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// the nacked record is published again and acked
+	if h.Sent() != 3 || h.Failed() != 0 {
+		t.Fatalf("unexpected counters: sent %d failed %d", h.Sent(), h.Failed())
+	}
+	if n := len(s.published()); n < 4 {
+		t.Fatalf("expected the nacked record to be republished, got %d publishes", n)
+	}
+}
+
+func TestHandler_JetStreamAckTimeoutFails(t *testing.T) {
+	s := newNATSServer(t)
+	h, err := New(Options{
+		URL:        s.url(),
+		JetStream:  true,
+		AckWait:    20 * time.Millisecond,
+		MaxRetries: 1,
+		MinBackoff: time.Millisecond,
+	}, nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	slog.New(h).Info("unacked")
+	err = h.Close()
+	if !errors.Is(err, ErrPublish) || h.Failed() != 1 {
+		t.Fatalf("expected ErrPublish and one failed record, got %v (failed %d)", err, h.Failed())
+	}
+}
+
+func TestSink_ConfigureAndDialFailure(t *testing.T) {
+	defer logx.Reset()
+
+	s := newNATSServer(t)
+	name := "nats-" + strings.ReplaceAll(t.Name(), "/", "-") + strconv.FormatInt(time.Now().UnixNano(), 36)
+	logx.RegisterSink(name, Sink(Options{URL: s.url()}))
+	if err := logx.Configure(logx.Config{Sinks: []logx.SinkConfig{{Name: name}}}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	logx.Info("via logx", "component", "worker")
+	logx.Shutdown()
+	if msgs := s.published(); len(msgs) != 1 || msgs[0].subject != "logs.info" {
+		t.Fatalf("unexpected messages: %+v", msgs)
+	}
+
+	ln, _ := net.Listen("tcp", "127.0.0.1:0")
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	down := name + "-down"
+	logx.RegisterSink(down, Sink(Options{URL: "nats://" + addr, MaxRetries: 1, MinBackoff: time.Millisecond}))
+	if err := logx.Configure(logx.Config{Sinks: []logx.SinkConfig{{Name: down}}}); !errors.Is(err, ErrPublish) {
+		t.Fatalf("expected ErrPublish, got %v", err)
+	}
+}
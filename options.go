@@ -0,0 +1,164 @@
+package logx
+
+// options.go offers functional options as an alternative to filling in a
+// Config by hand. Each option sets the fields for one feature together, so
+// call sites do not depend on zero-value conventions such as
+// StacktraceLevel 0 meaning "disabled".
+
+import (
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Option sets fields of a Config. Options are applied in order, so a later
+// option overrides an earlier one touching the same fields.
+type Option func(*Config)
+
+// Format selects the encoding of a file or console output.
+type Format int
+
+const (
+	// Text writes slog text records (key=value).
+	Text Format = iota
+	// JSON writes one JSON object per record.
+	JSON
+)
+
+// Init configures the global logger from opts; it is Configure(NewConfig(opts...)).
+func Init(opts ...Option) error {
+	return Configure(NewConfig(opts...))
+}
+
+// NewConfig returns the Config built by applying opts to the zero Config.
+// Use it to combine options with fields that have no option, or to inspect
+// the result before calling Configure.
+func NewConfig(opts ...Option) Config {
+	var cfg Config
+	cfg.Apply(opts...)
+	return cfg
+}
+
+// Apply applies opts to cfg.
+func (cfg *Config) Apply(opts ...Option) {
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+}
+
+// WithMinLevel sets the minimum level logged (Config.Level). It is not named
+// WithLevel because that name is the per-context level override.
+func WithMinLevel(level slog.Level) Option {
+	return func(cfg *Config) { cfg.Level = level }
+}
+
+// WithNamedLevel sets the level of the logger returned by Named(name).
+func WithNamedLevel(name string, level slog.Level) Option {
+	return func(cfg *Config) {
+		if cfg.NamedLevels == nil {
+			cfg.NamedLevels = map[string]slog.Level{}
+		}
+		cfg.NamedLevels[name] = level
+	}
+}
+
+// WithConsole enables the console output, as text unless a format is given.
+func WithConsole(format ...Format) Option {
+	return func(cfg *Config) {
+		cfg.Console = true
+		cfg.ConsoleJSON = len(format) > 0 && format[0] == JSON
+	}
+}
+
+// WithConsoleWriter sends console output to w instead of stderr; it also
+// enables the console.
+func WithConsoleWriter(w io.Writer) Option {
+	return func(cfg *Config) {
+		cfg.Console = true
+		cfg.ConsoleWriter = w
+	}
+}
+
+// WithFile logs to the file at path in the given format, replacing any
+// writer set with WithFileWriter.
+func WithFile(path string, format Format) Option {
+	return func(cfg *Config) {
+		cfg.FilePath = path
+		cfg.FileWriter = nil
+		cfg.JSONFile = format == JSON
+	}
+}
+
+// WithFileWriter logs to w in the given format instead of a file path. w is
+// closed when the logger is replaced or shut down.
+func WithFileWriter(w io.WriteCloser, format Format) Option {
+	return func(cfg *Config) {
+		cfg.FileWriter = w
+		cfg.FilePath = ""
+		cfg.JSONFile = format == JSON
+	}
+}
+
+// WithRotation rotates the log file when it exceeds maxBytes, keeping
+// backups rotated files.
+func WithRotation(maxBytes, backups int) Option {
+	return func(cfg *Config) {
+		cfg.FileMaxSizeBytes = maxBytes
+		cfg.FileMaxBackups = backups
+	}
+}
+
+// WithMaxTotalSize bounds the disk usage of the log file and its backups
+// (see Config.FileMaxTotalBytes).
+func WithMaxTotalSize(totalBytes int) Option {
+	return func(cfg *Config) { cfg.FileMaxTotalBytes = totalBytes }
+}
+
+// WithManifest records rotated files in "<path>.manifest" and verifies them
+// on startup (see Config.FileManifest).
+func WithManifest() Option {
+	return func(cfg *Config) { cfg.FileManifest = true }
+}
+
+// WithSource adds the caller's source location to every record.
+func WithSource() Option {
+	return func(cfg *Config) { cfg.AddSource = true }
+}
+
+// WithStacktrace attaches stack traces to records at or above level,
+// including level 0 (INFO).
+func WithStacktrace(level slog.Level) Option {
+	return func(cfg *Config) {
+		cfg.StacktraceEnabled = true
+		cfg.StacktraceLevel = level
+	}
+}
+
+// WithDedup collapses consecutive identical records (see Config.DedupWindow).
+func WithDedup(window time.Duration) Option {
+	return func(cfg *Config) { cfg.DedupWindow = window }
+}
+
+// WithSink adds the registered sink name with its options.
+func WithSink(name string, options map[string]any) Option {
+	return func(cfg *Config) {
+		cfg.Sinks = append(cfg.Sinks, SinkConfig{Name: name, Options: options})
+	}
+}
+
+// WithHooks adds hooks run for every record.
+func WithHooks(hooks ...Hook) Option {
+	return func(cfg *Config) { cfg.Hooks = append(cfg.Hooks, hooks...) }
+}
+
+// WithSchema selects the output schema.
+func WithSchema(schema OutputSchema) Option {
+	return func(cfg *Config) { cfg.Schema = schema }
+}
+
+// WithConfig applies fn to the Config, for fields without a dedicated option.
+func WithConfig(fn func(*Config)) Option {
+	return fn
+}
@@ -0,0 +1,57 @@
+package logx
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewConfig_AppliesOptionsInOrder(t *testing.T) {
+	cfg := NewConfig(
+		WithMinLevel(slog.LevelDebug),
+		WithFile("first.log", Text),
+		WithFile("app.log", JSON),
+		WithRotation(1<<20, 3),
+		WithConsole(),
+		WithStacktrace(slog.LevelInfo),
+		nil,
+		WithConfig(func(c *Config) { c.TimeUTC = true }),
+	)
+	if cfg.Level != slog.LevelDebug || !cfg.Console || cfg.ConsoleJSON {
+		t.Fatalf("unexpected level/console: %+v", cfg)
+	}
+	if cfg.FilePath != "app.log" || !cfg.JSONFile || cfg.FileMaxSizeBytes != 1<<20 || cfg.FileMaxBackups != 3 {
+		t.Fatalf("unexpected file settings: %+v", cfg)
+	}
+	if !cfg.stacktraceEnabled() || cfg.StacktraceLevel != slog.LevelInfo {
+		t.Fatalf("expected stack traces from INFO, got %+v", cfg)
+	}
+	if !cfg.TimeUTC {
+		t.Fatal("expected WithConfig to apply")
+	}
+
+	cfg.Apply(WithFileWriter(&trackingWriteCloser{}, Text), WithConsole(JSON))
+	if cfg.FilePath != "" || cfg.FileWriter == nil || cfg.JSONFile || !cfg.ConsoleJSON {
+		t.Fatalf("expected writer to replace the path: %+v", cfg)
+	}
+}
+
+func TestInit_ConfiguresGlobalLogger(t *testing.T) {
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Init(WithMinLevel(slog.LevelDebug), WithFileWriter(w, JSON)); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	Debug("from options", "k", "v")
+	Shutdown()
+
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(w.String())), &rec); err != nil {
+		t.Fatalf("expected one JSON record, got %q: %v", w.String(), err)
+	}
+	if rec["msg"] != "from options" || rec["k"] != "v" {
+		t.Fatalf("unexpected record: %v", rec)
+	}
+}
@@ -0,0 +1,132 @@
+package logx
+
+// outschema.go renames built-in and well-known keys to the field names a
+// log backend expects, so its index mappings accept logx output as is.
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// OutputSchema selects the field names written by text and JSON outputs
+// (Config.Schema). Schemas are meant for JSON outputs.
+type OutputSchema int
+
+const (
+	// SchemaDefault writes slog's key names.
+	SchemaDefault OutputSchema = iota
+	// SchemaECS writes Elastic Common Schema fields: "@timestamp",
+	// "log.level", "message", "error.message", "http.request.method" and
+	// so on, plus "ecs.version".
+	SchemaECS
+	// SchemaGCP writes Google Cloud Logging fields: "severity", "message",
+	// "logging.googleapis.com/trace", "logging.googleapis.com/spanId",
+	// "logging.googleapis.com/sourceLocation" and an "httpRequest" object
+	// built from the middleware's request attrs.
+	SchemaGCP
+	// SchemaCloudWatch writes the AWS Lambda JSON log format ("timestamp",
+	// "level" as TRACE..FATAL, "message", "requestId") and Embedded Metric
+	// Format blocks for attrs created with Metric.
+	SchemaCloudWatch
+)
+
+// String returns "default", "ecs", "gcp" or "cloudwatch".
+func (s OutputSchema) String() string {
+	switch s {
+	case SchemaECS:
+		return "ecs"
+	case SchemaGCP:
+		return "gcp"
+	case SchemaCloudWatch:
+		return "cloudwatch"
+	default:
+		return "default"
+	}
+}
+
+// ecsVersion is the ECS version the mapping follows.
+const ecsVersion = "8.11.0"
+
+// ecsKeys maps top-level keys written by logx and its middleware to ECS fields.
+var ecsKeys = map[string]string{
+	slog.TimeKey:    "@timestamp",
+	slog.LevelKey:   "log.level",
+	slog.MessageKey: "message",
+	slog.SourceKey:  "log.origin",
+	"error":         "error.message",
+	"error_type":    "error.type",
+	"error_chain":   "error.chain",
+	"error_origin":  "error.origin",
+	"stack":         "error.stack_trace",
+	"request_id":    "http.request.id",
+	"method":        "http.request.method",
+	"referer":       "http.request.referrer",
+	"url":           "url.original",
+	"status":        "http.response.status_code",
+	"bytes":         "http.response.body.bytes",
+	"duration":      "event.duration",
+	"remote_addr":   "client.address",
+	"user_agent":    "user_agent.original",
+	"trace_id":      "trace.id",
+	"span_id":       "span.id",
+}
+
+// replacer returns the schema's ReplaceAttr function, or nil.
+func (s OutputSchema) replacer(cfg Config) func([]string, slog.Attr) slog.Attr {
+	switch s {
+	case SchemaECS:
+		return ecsReplacer
+	case SchemaGCP:
+		return gcpReplacer(cfg.gcpProject())
+	case SchemaCloudWatch:
+		return cloudWatchReplacer
+	default:
+		return nil
+	}
+}
+
+func ecsReplacer(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) != 0 {
+		return a
+	}
+	key, ok := ecsKeys[a.Key]
+	if !ok {
+		return a
+	}
+	switch a.Key {
+	case slog.LevelKey:
+		if l, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(strings.ToLower(l.String()))
+		}
+	case slog.SourceKey:
+		if src, ok := a.Value.Any().(*slog.Source); ok {
+			a.Value = slog.GroupValue(
+				slog.String("file.name", src.File),
+				slog.Int("file.line", src.Line),
+				slog.String("function", src.Function),
+			)
+		}
+	case "duration":
+		// ECS durations are nanoseconds
+		if a.Value.Kind() == slog.KindDuration {
+			a.Value = slog.Int64Value(a.Value.Duration().Nanoseconds())
+		}
+	}
+	a.Key = key
+	return a
+}
+
+// wrap adds the schema's constant fields and restructuring to an output
+// handler.
+func (s OutputSchema) wrap(h slog.Handler, cfg Config) slog.Handler {
+	switch s {
+	case SchemaECS:
+		return h.WithAttrs([]slog.Attr{slog.String("ecs.version", ecsVersion)})
+	case SchemaGCP:
+		return &gcpHandler{next: h}
+	case SchemaCloudWatch:
+		return newEMFHandler(h, cfg)
+	default:
+		return h
+	}
+}
@@ -0,0 +1,67 @@
+package logx
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSchemaECS_RenamesKnownKeys(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w, JSONFile: true, AddSource: true, Schema: SchemaECS}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	ErrorErr("charge failed", errors.New("declined"),
+		"method", "POST", "status", 502, "duration", 1500*time.Microsecond, "custom", "kept")
+	Logger().WithGroup("req").Info("grouped", "method", "GET")
+
+	lines := strings.Split(strings.TrimSpace(w.String()), "\n")
+	var m map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	want := map[string]any{
+		"log.level":                 "error",
+		"message":                   "charge failed",
+		"error.message":             "declined",
+		"error.type":                "*errors.errorString",
+		"http.request.method":       "POST",
+		"http.response.status_code": float64(502),
+		"event.duration":            float64(1_500_000),
+		"custom":                    "kept",
+		"ecs.version":               ecsVersion,
+	}
+	for k, v := range want {
+		if m[k] != v {
+			t.Errorf("%s = %v, want %v", k, m[k], v)
+		}
+	}
+	if _, ok := m["@timestamp"]; !ok {
+		t.Errorf("expected @timestamp in %s", lines[0])
+	}
+	origin, _ := m["log.origin"].(map[string]any)
+	if !strings.HasSuffix(origin["file.name"].(string), "outschema_test.go") {
+		t.Errorf("unexpected log.origin: %v", m["log.origin"])
+	}
+
+	assertContains(t, lines[1], `"req":{"method":"GET"}`)
+	if Describe().Schema != "ecs" {
+		t.Fatalf("expected schema in description, got %q", Describe().Schema)
+	}
+}
+
+func TestSchemaDefault_NoReplacer(t *testing.T) {
+	if SchemaDefault.replacer(Config{}) != nil {
+		t.Fatalf("expected no replacer")
+	}
+	h := slog.NewJSONHandler(&strings.Builder{}, nil)
+	if SchemaDefault.wrap(h, Config{}) != slog.Handler(h) {
+		t.Fatalf("expected handler unchanged")
+	}
+}
@@ -0,0 +1,60 @@
+package logx
+
+// panic.go holds the log-then-panic and recover-then-log helpers used at
+// goroutine boundaries.
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+)
+
+// Panic logs msg at error level with a "stack" attr and then panics with msg.
+// When the configured stack traces cover error records, theirs replaces it.
+// With Config.CrashMarkerOnPanic a crash marker file is written first.
+func Panic(msg string, args ...any) {
+	fields := make([]any, 0, len(args)+2)
+	fields = append(fields, args...)
+	fields = append(fields, "stack", panicStack())
+	logDepth(Logger(), context.Background(), 0, slog.LevelError, msg, fields...)
+	writeCrashMarker("panic", msg, args, 1)
+	panic(msg)
+}
+
+// RecoverAndLog recovers a panic, logs it at error level with "panic" and
+// "stack" attrs plus extra, and swallows it; as with Panic, a configured
+// stack trace replaces the "stack" attr. It must be deferred directly:
+//
+//	go func() {
+//		defer logx.RecoverAndLog(ctx, "worker", id)
+//		work()
+//	}()
+//
+// The context logger (see LoggerFromContext) is used when present.
+func RecoverAndLog(ctx context.Context, extra ...any) {
+	rec := recover()
+	if rec == nil {
+		return
+	}
+
+	fields := make([]any, 0, len(extra)+4)
+	fields = append(fields, extra...)
+	fields = append(fields,
+		"panic", rec,
+		"stack", panicStack(),
+	)
+	// attribute the record to the function that panicked
+	var pc uintptr
+	if frames := callSiteFrames(0); len(frames) > 0 {
+		pc = frames[0].PC
+	}
+	logPC(LoggerFromContext(ctx), ctx, pc, slog.LevelError, "panic recovered", fields...)
+}
+
+func panicStack() string {
+	stack := debug.Stack()
+	if len(stack) > maxStackBytes {
+		stack = stack[:maxStackBytes]
+	}
+	return string(stack)
+}
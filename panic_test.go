@@ -0,0 +1,85 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestPanic_LogsAndRepanics(t *testing.T) {
+	var rec any
+	out := capture(t, slog.LevelInfo, func() {
+		defer func() { rec = recover() }()
+		Panic("invariant broken", "id", 7)
+	})
+
+	if rec != "invariant broken" {
+		t.Fatalf("expected re-panic with msg, got %v", rec)
+	}
+	assertContains(t, out, "level=ERROR")
+	assertContains(t, out, "id=7")
+	assertContains(t, out, "stack=")
+}
+
+func TestRecoverAndLog_SwallowsAndLogs(t *testing.T) {
+	out := capture(t, slog.LevelInfo, func() {
+		func() {
+			defer RecoverAndLog(context.Background(), "worker", 3)
+			panic("boom")
+		}()
+	})
+
+	assertContains(t, out, "panic recovered")
+	assertContains(t, out, "worker=3")
+	assertContains(t, out, "panic=boom")
+	assertContains(t, out, "stack=")
+}
+
+func TestRecoverAndLog_NoPanicIsSilent(t *testing.T) {
+	out := capture(t, slog.LevelInfo, func() {
+		func() {
+			defer RecoverAndLog(context.Background())
+		}()
+	})
+
+	if out != "" {
+		t.Fatalf("expected no output, got %q", out)
+	}
+}
+
+func TestPanic_ConfiguredStackReplacesItsOwn(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	if err := Configure(Config{
+		Console:         true,
+		ConsoleWriter:   &buf,
+		ConsoleJSON:     true,
+		StacktraceLevel: slog.LevelError,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		Panic("invariant broken", "id", 7)
+	}()
+	func() {
+		defer RecoverAndLog(context.Background(), "worker", 3)
+		panic("boom")
+	}()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected two records, got:\n%s", buf.String())
+	}
+	for _, line := range lines {
+		if n := strings.Count(line, `"stack":`); n != 1 {
+			t.Fatalf("expected one stack key, got %d:\n%s", n, line)
+		}
+	}
+	assertContains(t, lines[1], "TestPanic_ConfiguredStackReplacesItsOwn")
+}
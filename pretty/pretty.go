@@ -0,0 +1,372 @@
+// Package pretty turns logx records, JSON or logfmt (slog text), into
+// human-readable lines: a short timestamp, a colored level, the message and
+// the attrs, or with Options.Multiline one attr per line with JSON values
+// indented and stack traces kept as they are.
+//
+//	io.Copy(os.Stdout, pretty.NewReader(f, pretty.Options{Color: true}))
+package pretty
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rannday/logx"
+)
+
+// maxLineBytes bounds a single log line (stack traces included).
+const maxLineBytes = 1024 * 1024
+
+// Field is one attr of a record, with groups flattened into dotted keys.
+type Field struct {
+	Key   string
+	Value string
+	// JSON is true for objects and arrays, whose Value is kept as JSON.
+	JSON bool
+}
+
+// Record is a parsed log line. A line that is neither JSON nor logfmt is
+// kept whole as the Message.
+type Record struct {
+	Time  string
+	Level string
+	// LevelValue is Level parsed with logx.ParseLevel when HasLevel is set.
+	LevelValue slog.Level
+	HasLevel   bool
+	Message    string
+	Attrs      []Field
+}
+
+// Attr returns the value of the attr key ("group.key" for grouped attrs).
+func (r Record) Attr(key string) (string, bool) {
+	for _, f := range r.Attrs {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// Options controls Format and NewReader.
+type Options struct {
+	// Color adds ANSI colors: the level by severity, keys and time dimmed.
+	Color bool
+	// Multiline prints each attr on its own indented line.
+	Multiline bool
+	// TimeFormat is the layout for RFC 3339 timestamps (default
+	// "15:04:05.000"); other timestamps are printed as they are.
+	TimeFormat string
+	// Location converts timestamps before formatting (default time.Local).
+	Location *time.Location
+	// Filter drops the records for which it returns false (NewReader only).
+	Filter func(Record) bool
+}
+
+// Keys recognized as the built-in time, level and message, including the
+// names used by logx's output schemas.
+var (
+	timeKeys  = []string{"time", "@timestamp", "timestamp"}
+	levelKeys = []string{"level", "log.level", "severity"}
+	msgKeys   = []string{"msg", "message"}
+)
+
+// Parse parses a JSON or logfmt line.
+func Parse(line string) Record {
+	trimmed := strings.TrimSpace(line)
+	var fields []Field
+	var ok bool
+	if strings.HasPrefix(trimmed, "{") {
+		fields, ok = parseJSON([]byte(trimmed))
+	} else {
+		fields, ok = parseText(trimmed)
+	}
+	if !ok {
+		return Record{Message: line}
+	}
+
+	var r Record
+	for _, f := range fields {
+		switch {
+		case r.Time == "" && contains(timeKeys, f.Key):
+			r.Time = f.Value
+		case r.Level == "" && contains(levelKeys, f.Key):
+			r.Level = f.Value
+			if l, err := logx.ParseLevel(f.Value); err == nil {
+				r.LevelValue, r.HasLevel = l, true
+			}
+		case r.Message == "" && contains(msgKeys, f.Key):
+			r.Message = f.Value
+		default:
+			r.Attrs = append(r.Attrs, f)
+		}
+	}
+	return r
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// parseJSON returns the fields of a JSON object in order, flattening nested
+// objects into dotted keys.
+func parseJSON(b []byte) ([]Field, bool) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var fields []Field
+	if !decodeObject(dec, "", &fields) {
+		return nil, false
+	}
+	return fields, true
+}
+
+func decodeObject(dec *json.Decoder, prefix string, fields *[]Field) bool {
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return false
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return false
+		}
+		key := prefix + tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return false
+		}
+		switch raw[0] {
+		case '{':
+			sub := json.NewDecoder(bytes.NewReader(raw))
+			sub.UseNumber()
+			if !decodeObject(sub, key+".", fields) {
+				return false
+			}
+		case '[':
+			*fields = append(*fields, Field{Key: key, Value: string(raw), JSON: true})
+		case '"':
+			var s string
+			_ = json.Unmarshal(raw, &s)
+			*fields = append(*fields, Field{Key: key, Value: s})
+		default:
+			*fields = append(*fields, Field{Key: key, Value: string(raw)})
+		}
+	}
+	_, err := dec.Token()
+	return err == nil
+}
+
+// parseText returns the fields of a logfmt line (key=value pairs with
+// quoted values where needed).
+func parseText(s string) ([]Field, bool) {
+	var fields []Field
+	for i := 0; i < len(s); {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i == len(s) {
+			break
+		}
+		start := i
+		i = textToken(s, i, '=')
+		if i >= len(s) || s[i] != '=' || i == start {
+			return nil, false
+		}
+		key := unquote(s[start:i])
+		vstart := i + 1
+		i = textToken(s, vstart, ' ')
+		fields = append(fields, Field{Key: key, Value: unquote(s[vstart:i])})
+	}
+	return fields, len(fields) > 0
+}
+
+// textToken returns the end of the token starting at i: a quoted string,
+// or the bytes up to stop or a space.
+func textToken(s string, i int, stop byte) int {
+	if i < len(s) && s[i] == '"' {
+		for i++; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return len(s)
+	}
+	for i < len(s) && s[i] != stop && s[i] != ' ' {
+		i++
+	}
+	return i
+}
+
+func unquote(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorYellow = "\033[33m"
+	colorGreen  = "\033[32m"
+	colorGray   = "\033[90m"
+	colorDim    = "\033[2m"
+	colorBold   = "\033[1m"
+)
+
+// LevelColor returns the ANSI color used for level.
+func LevelColor(level slog.Level) string {
+	switch {
+	case level >= slog.LevelError:
+		return colorRed
+	case level >= slog.LevelWarn:
+		return colorYellow
+	case level >= slog.LevelInfo:
+		return colorGreen
+	default:
+		return colorGray
+	}
+}
+
+// Format renders r as "15:04:05.000 LEVEL msg key=value ...", without a
+// trailing newline.
+func Format(r Record, opts Options) string {
+	paint := func(c, s string) string {
+		if !opts.Color || s == "" {
+			return s
+		}
+		return c + s + colorReset
+	}
+
+	var b strings.Builder
+	if r.Time != "" {
+		b.WriteString(paint(colorDim, opts.formatTime(r.Time)))
+		b.WriteByte(' ')
+	}
+	if r.Level != "" {
+		level := r.Level
+		if len(level) < 5 {
+			level += strings.Repeat(" ", 5-len(level))
+		}
+		c := colorBold
+		if r.HasLevel {
+			c = LevelColor(r.LevelValue)
+		}
+		b.WriteString(paint(c, level))
+		b.WriteByte(' ')
+	}
+	b.WriteString(r.Message)
+
+	for _, f := range r.Attrs {
+		if !opts.Multiline {
+			b.WriteByte(' ')
+			b.WriteString(paint(colorDim, f.Key+"="))
+			b.WriteString(textValue(f))
+			continue
+		}
+		b.WriteString("\n    ")
+		b.WriteString(paint(colorDim, f.Key+":"))
+		v := indentedValue(f)
+		if strings.Contains(v, "\n") {
+			b.WriteString("\n        ")
+			b.WriteString(strings.ReplaceAll(v, "\n", "\n        "))
+		} else {
+			b.WriteByte(' ')
+			b.WriteString(v)
+		}
+	}
+	return b.String()
+}
+
+func (opts Options) formatTime(s string) string {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return s
+	}
+	loc, layout := opts.Location, opts.TimeFormat
+	if loc == nil {
+		loc = time.Local
+	}
+	if layout == "" {
+		layout = "15:04:05.000"
+	}
+	return t.In(loc).Format(layout)
+}
+
+// textValue quotes values the way slog's text handler does.
+func textValue(f Field) string {
+	if f.JSON {
+		return f.Value
+	}
+	if f.Value == "" || strings.ContainsAny(f.Value, " \t\r\n\"=") {
+		return strconv.Quote(f.Value)
+	}
+	return f.Value
+}
+
+// indentedValue indents JSON values.
+func indentedValue(f Field) string {
+	if !f.JSON {
+		return f.Value
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(f.Value), "", "  "); err != nil {
+		return f.Value
+	}
+	return buf.String()
+}
+
+// Reader reformats a stream of records line by line.
+type Reader struct {
+	sc   *bufio.Scanner
+	opts Options
+	buf  []byte
+	err  error
+}
+
+// NewReader returns a Reader that reads records from r and yields them
+// formatted by Format, one per line. Blank lines are dropped.
+func NewReader(r io.Reader, opts Options) *Reader {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	return &Reader{sc: sc, opts: opts}
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if !r.sc.Scan() {
+			r.err = r.sc.Err()
+			if r.err == nil {
+				r.err = io.EOF
+			}
+			continue
+		}
+		line := r.sc.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		rec := Parse(line)
+		if r.opts.Filter != nil && !r.opts.Filter(rec) {
+			continue
+		}
+		r.buf = append(r.buf[:0], Format(rec, r.opts)...)
+		r.buf = append(r.buf, '\n')
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
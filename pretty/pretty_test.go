@@ -0,0 +1,59 @@
+package pretty
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse_JSONAndLogfmt(t *testing.T) {
+	j := Parse(`{"time":"2024-03-01T12:00:00Z","level":"WARN","msg":"slow","req":{"id":"a1","ms":12},"tags":["x"]}`)
+	if j.Level != "WARN" || j.LevelValue != slog.LevelWarn || j.Message != "slow" || len(j.Attrs) != 3 {
+		t.Fatalf("unexpected JSON record: %+v", j)
+	}
+	if v, _ := j.Attr("req.id"); v != "a1" {
+		t.Fatalf("expected flattened req.id, got %+v", j.Attrs)
+	}
+
+	x := Parse(`time=2024-03-01T12:00:00Z level=ERROR+2 msg="query failed" err="bad \"x\"" n=3`)
+	if x.LevelValue != slog.LevelError+2 || x.Message != "query failed" {
+		t.Fatalf("unexpected logfmt record: %+v", x)
+	}
+	if v, _ := x.Attr("err"); v != `bad "x"` {
+		t.Fatalf("expected unquoted err, got %q", v)
+	}
+
+	if p := Parse("panic: boom"); p.HasLevel || p.Message != "panic: boom" {
+		t.Fatalf("expected a plain line, got %+v", p)
+	}
+}
+
+func TestFormat_MultilineAndColor(t *testing.T) {
+	r := Parse(`{"level":"ERROR","msg":"failed","stack":"a\nb","req":{"id":"a1"},"tags":["x","y"]}`)
+	got := Format(r, Options{Multiline: true})
+	want := "ERROR failed\n    stack:\n        a\n        b\n    req.id: a1\n    tags:\n        [\n          \"x\",\n          \"y\"\n        ]"
+	if got != want {
+		t.Fatalf("unexpected multiline output:\n%s\nwant:\n%s", got, want)
+	}
+	if got := Format(Parse(`level=INFO msg=hi k="a b"`), Options{Color: true}); got != colorGreen+"INFO "+colorReset+" hi "+colorDim+"k="+colorReset+`"a b"` {
+		t.Fatalf("unexpected colored line: %q", got)
+	}
+}
+
+func TestNewReader_FormatsAndFilters(t *testing.T) {
+	in := strings.NewReader("{\"time\":\"2024-03-01T12:00:00.5Z\",\"level\":\"INFO\",\"msg\":\"a\"}\n\n" +
+		"time=2024-03-01T12:00:01Z level=DEBUG msg=b\nnot a record\n")
+	r := NewReader(in, Options{
+		Location: time.UTC,
+		Filter:   func(r Record) bool { return !r.HasLevel || r.LevelValue >= slog.LevelInfo },
+	})
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "12:00:00.500 INFO  a\nnot a record\n"; string(out) != want {
+		t.Fatalf("unexpected output:\n%q\nwant\n%q", out, want)
+	}
+}
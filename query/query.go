@@ -0,0 +1,242 @@
+// Package query reads a logx JSON log file and its rotated backups as one
+// time-ordered stream of records, filtered by time range, level and attr
+// values, for post-incident analysis without stitching files by hand.
+package query
+
+import (
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/rannday/logx"
+	"github.com/rannday/logx/logread"
+)
+
+// Filter selects the records of a query. The zero Filter matches every
+// record with a timestamp.
+type Filter struct {
+	// From and To bound record times to [From, To). A zero time leaves that
+	// side unbounded.
+	From, To time.Time
+	// Level, when set, drops records below it and records whose level
+	// cannot be parsed.
+	Level slog.Leveler
+	// Attrs requires attr values by flattened key ("http.status"). Values
+	// are compared as text: numbers as written, booleans as true or false.
+	Attrs map[string]string
+}
+
+// Match reports whether r passes f.
+func (f Filter) Match(r logread.Record) bool {
+	ts, ok := r.Time()
+	if !ok {
+		return false
+	}
+	if !f.From.IsZero() && ts.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !ts.Before(f.To) {
+		return false
+	}
+	if f.Level != nil {
+		l, err := logx.ParseLevel(r.Level())
+		if err != nil || l < f.Level.Level() {
+			return false
+		}
+	}
+	if len(f.Attrs) > 0 {
+		flat := logread.Flatten(r)
+		for k, want := range f.Attrs {
+			v, ok := flat[k]
+			if !ok || text(v) != want {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// text formats a decoded JSON value for Attrs comparisons.
+func text(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	case nil:
+		return "null"
+	default:
+		b, err := json.Marshal(x)
+		if err != nil {
+			return fmt.Sprint(x)
+		}
+		return string(b)
+	}
+}
+
+// Stream merges the records of a log file and its backups in time order.
+// Records with equal times keep file order, oldest backup first.
+type Stream struct {
+	filter Filter
+	files  []*source
+	heads  heads
+	err    error
+}
+
+// Open opens path and its rotated backups (see logread.LogFiles) for
+// reading the records that match f. Files whose span lies outside
+// [f.From, f.To) are not opened. The caller closes the stream.
+func Open(path string, f Filter) (*Stream, error) {
+	paths, err := logread.RangeFiles(path, f.From, f.To)
+	if err != nil {
+		return nil, err
+	}
+	s := &Stream{filter: f}
+	for _, p := range paths {
+		r, err := logread.OpenFile(p)
+		if err != nil {
+			_ = s.Close()
+			return nil, fmt.Errorf("query: %s: %w", p, err)
+		}
+		s.files = append(s.files, &source{path: p, order: len(s.files), r: r, rd: logread.NewReader(r)})
+	}
+	for _, src := range s.files {
+		if err := s.advance(src); err != nil {
+			_ = s.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Next returns the next matching record. It returns io.EOF when every file
+// is exhausted.
+func (s *Stream) Next() (logread.Record, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(s.heads) == 0 {
+		return nil, io.EOF
+	}
+	src := s.heads[0]
+	rec := src.rec
+	heap.Pop(&s.heads)
+	if err := s.advance(src); err != nil {
+		s.err = err
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Close closes the files of the stream.
+func (s *Stream) Close() error {
+	var errs []error
+	for _, src := range s.files {
+		errs = append(errs, src.close())
+	}
+	s.files, s.heads = nil, nil
+	return errors.Join(errs...)
+}
+
+// advance reads the next matching record of src and queues it.
+func (s *Stream) advance(src *source) error {
+	for {
+		rec, err := src.rd.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if src.rd.Err() != nil {
+				return fmt.Errorf("query: %s: %w", src.path, err)
+			}
+			// lines that are not JSON records are skipped, as in logread.QueryRange
+			continue
+		}
+		if !s.filter.Match(rec) {
+			continue
+		}
+		src.rec = rec
+		src.time, _ = rec.Time()
+		heap.Push(&s.heads, src)
+		return nil
+	}
+}
+
+// Run calls fn for each record of path and its backups that matches f, in
+// time order, until fn returns false.
+func Run(path string, f Filter, fn func(logread.Record) bool) error {
+	s, err := Open(path, f)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	for {
+		rec, err := s.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !fn(rec) {
+			return nil
+		}
+	}
+}
+
+// All returns every record of path and its backups that matches f, in time
+// order.
+func All(path string, f Filter) ([]logread.Record, error) {
+	var out []logread.Record
+	err := Run(path, f, func(r logread.Record) bool {
+		out = append(out, r)
+		return true
+	})
+	return out, err
+}
+
+// source is one open file of a stream and its next record.
+type source struct {
+	path  string
+	order int
+	r     io.ReadCloser
+	rd    *logread.Reader
+
+	rec  logread.Record
+	time time.Time
+}
+
+func (src *source) close() error {
+	return src.r.Close()
+}
+
+// heads is a min-heap of the sources with a queued record, by record time
+// and then file order.
+type heads []*source
+
+func (h heads) Len() int { return len(h) }
+
+func (h heads) Less(i, j int) bool {
+	if !h[i].time.Equal(h[j].time) {
+		return h[i].time.Before(h[j].time)
+	}
+	return h[i].order < h[j].order
+}
+
+func (h heads) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *heads) Push(x any) { *h = append(*h, x.(*source)) }
+
+func (h *heads) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,118 @@
+package query
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rannday/logx/logread"
+)
+
+func writeFile(t *testing.T, path string, gz bool, lines ...string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	defer f.Close()
+	var w io.Writer = f
+	if gz {
+		zw := gzip.NewWriter(f)
+		defer zw.Close()
+		w = zw
+	}
+	_, _ = io.WriteString(w, strings.Join(lines, "\n")+"\n")
+}
+
+func messages(recs []logread.Record) string {
+	var out []string
+	for _, r := range recs {
+		out = append(out, r.Message())
+	}
+	return strings.Join(out, ",")
+}
+
+func TestAll_MergesBackupsInTimeOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	// a second process appended to the backup after the active file began
+	writeFile(t, path+".20240102T140000.gz", true,
+		`{"time":"2024-01-02T14:00:00Z","level":"INFO","msg":"a"}`,
+		`{"time":"2024-01-02T14:00:03Z","level":"INFO","msg":"d"}`,
+	)
+	writeFile(t, path, false,
+		`{"time":"2024-01-02T14:00:01Z","level":"INFO","msg":"b"}`,
+		`not json`,
+		`{"time":"2024-01-02T14:00:02Z","level":"INFO","msg":"c"}`,
+		`{"time":"2024-01-02T14:00:03Z","level":"INFO","msg":"e"}`,
+	)
+
+	recs, err := All(path, Filter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := messages(recs); got != "a,b,c,d,e" {
+		t.Fatalf("unexpected order: %s", got)
+	}
+}
+
+func TestAll_FiltersTimeLevelAndAttrs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	writeFile(t, path+".20240102T130000", false,
+		`{"time":"2024-01-02T13:00:00Z","level":"ERROR","msg":"old","user":"42"}`,
+	)
+	writeFile(t, path, false,
+		`{"time":"2024-01-02T14:00:00Z","level":"ERROR","msg":"match","user":"42","http":{"status":500}}`,
+		`{"time":"2024-01-02T14:00:01Z","level":"INFO","msg":"quiet","user":"42","http":{"status":500}}`,
+		`{"time":"2024-01-02T14:00:02Z","level":"WARN+2","msg":"other user","user":"7","http":{"status":500}}`,
+		`{"time":"2024-01-02T14:00:03Z","level":"WARN+2","msg":"also","user":"42","http":{"status":500}}`,
+		`{"time":"2024-01-02T14:00:04Z","level":"??","msg":"unknown level","user":"42","http":{"status":500}}`,
+		`{"time":"2024-01-02T15:00:00Z","level":"ERROR","msg":"late","user":"42","http":{"status":500}}`,
+	)
+
+	recs, err := All(path, Filter{
+		From:  time.Date(2024, 1, 2, 13, 30, 0, 0, time.UTC),
+		To:    time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC),
+		Level: slog.LevelWarn,
+		Attrs: map[string]string{"user": "42", "http.status": "500"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := messages(recs); got != "match,also" {
+		t.Fatalf("unexpected records: %s", got)
+	}
+}
+
+func TestStream_NextAndStop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	writeFile(t, path, false,
+		`{"time":"2024-01-02T14:00:00Z","level":"INFO","msg":"a"}`,
+		`{"time":"2024-01-02T14:00:01Z","level":"INFO","msg":"b"}`,
+	)
+
+	s, err := Open(path, Filter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer s.Close()
+	for _, want := range []string{"a", "b"} {
+		rec, err := s.Next()
+		if err != nil || rec.Message() != want {
+			t.Fatalf("expected %q, got %v, %v", want, rec, err)
+		}
+	}
+	if _, err := s.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected io.EOF, got %v", err)
+	}
+
+	n := 0
+	if err := Run(path, Filter{}, func(logread.Record) bool { n++; return false }); err != nil || n != 1 {
+		t.Fatalf("expected Run to stop after one record, got %d, %v", n, err)
+	}
+}
@@ -0,0 +1,122 @@
+package logx
+
+// queue.go gives an output its own bounded queue and writer goroutine, so a
+// slow network sink adds no latency to logging calls or to the outputs
+// written after it.
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrQueueFull is reported (see SetErrorHandler and WriteErrors) for every
+// record dropped because the queue of an output was full (see
+// SinkConfig.QueueSize).
+var ErrQueueFull = errors.New("logx: output queue full")
+
+var queueDropped atomic.Uint64
+
+// QueueDropped returns the number of records dropped by full output queues
+// since the process started or the last Reset.
+func QueueDropped() uint64 {
+	return queueDropped.Load()
+}
+
+type queuedRecord struct {
+	ctx context.Context
+	h   slog.Handler
+	r   slog.Record
+}
+
+// outputQueue holds the records of one output until its goroutine writes
+// them. It is shared by the handlers derived with WithAttrs and WithGroup;
+// each record carries the handler to write it with.
+type outputQueue struct {
+	output  string
+	records chan queuedRecord
+	stopped chan struct{}
+	closing sync.Once
+
+	// mu guards closed so no record is sent on the closed channel
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newOutputQueue(size int, output string) *outputQueue {
+	q := &outputQueue{
+		output:  output,
+		records: make(chan queuedRecord, size),
+		stopped: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+func (q *outputQueue) run() {
+	defer close(q.stopped)
+	for rec := range q.records {
+		_ = rec.h.Handle(rec.ctx, rec.r)
+	}
+}
+
+// Close writes the queued records and stops the goroutine. Later records
+// are written by the logging call. buildLogger closes it before the
+// outputs.
+func (q *outputQueue) Close() error {
+	q.closing.Do(func() {
+		q.mu.Lock()
+		q.closed = true
+		close(q.records)
+		q.mu.Unlock()
+	})
+	<-q.stopped
+	return nil
+}
+
+// queueHandler hands records to an outputQueue instead of writing them. A
+// record that does not fit is dropped and reported as ErrQueueFull; next
+// reports the errors of queued writes itself (newSinkErrHandler).
+type queueHandler struct {
+	next slog.Handler
+	q    *outputQueue
+}
+
+func newQueueHandler(next slog.Handler, q *outputQueue) slog.Handler {
+	return &queueHandler{next: next, q: q}
+}
+
+func (h *queueHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *queueHandler) Handle(ctx context.Context, r slog.Record) error {
+	q := h.q
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		return h.next.Handle(ctx, r)
+	}
+	select {
+	case q.records <- queuedRecord{ctx: context.WithoutCancel(ctx), h: h.next, r: r.Clone()}:
+		q.mu.RUnlock()
+		return nil
+	default:
+	}
+	q.mu.RUnlock()
+
+	queueDropped.Add(1)
+	err := &OutputError{Output: q.output, Err: ErrQueueFull}
+	reportWriteError(err)
+	return err
+}
+
+func (h *queueHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return newQueueHandler(h.next.WithAttrs(attrs), h.q)
+}
+
+func (h *queueHandler) WithGroup(name string) slog.Handler {
+	return newQueueHandler(h.next.WithGroup(name), h.q)
+}
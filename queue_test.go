@@ -0,0 +1,90 @@
+package logx
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// enteredWriter is a blockingWriter that signals each Write it enters.
+type enteredWriter struct {
+	blockingWriter
+	entered chan struct{}
+}
+
+func (w *enteredWriter) Write(p []byte) (int, error) {
+	w.entered <- struct{}{}
+	return w.blockingWriter.Write(p)
+}
+
+func TestConfigure_SinkQueueKeepsConsoleFlowing(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	slow := &enteredWriter{blockingWriter: blockingWriter{release: make(chan struct{})}, entered: make(chan struct{}, 8)}
+	name := testSinkName("test-queued")
+	RegisterSink(name, func(opts *slog.HandlerOptions, _ map[string]any) (slog.Handler, io.Closer, error) {
+		return slog.NewTextHandler(slow, opts), slow, nil
+	})
+	var reported []error
+	SetErrorHandler(func(err error) { reported = append(reported, err) })
+
+	var console bytes.Buffer
+	err := Configure(Config{
+		Level:         slog.LevelInfo,
+		Console:       true,
+		ConsoleWriter: &console,
+		Sinks:         []SinkConfig{{Name: name, QueueSize: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := Describe(); d.Outputs[1].QueueSize != 1 {
+		t.Fatalf("expected the queue to be described, got %+v", d.Outputs[1])
+	}
+
+	db := With("component", "db")
+	db.Info("first")
+	<-slow.entered // the sink goroutine holds "first"
+	db.Info("second")
+	db.Info("third")
+
+	out := console.String()
+	for _, msg := range []string{"first", "second", "third"} {
+		assertContains(t, out, "msg="+msg)
+	}
+	if QueueDropped() != 1 || len(reported) != 1 || !errors.Is(reported[0], ErrQueueFull) {
+		t.Fatalf("expected one dropped record, got %d (%v)", QueueDropped(), reported)
+	}
+	var oerr *OutputError
+	if !errors.As(reported[0], &oerr) || oerr.Output != name {
+		t.Fatalf("expected the drop to name the sink, got %v", reported[0])
+	}
+
+	close(slow.release)
+	if err := Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	sink := slow.String()
+	assertContains(t, sink, "msg=first component=db")
+	assertContains(t, sink, "msg=second component=db")
+	if strings.Contains(sink, "third") {
+		t.Fatalf("expected the record over the queue to be dropped:\n%s", sink)
+	}
+}
+
+func TestQueueHandler_WritesDirectlyAfterClose(t *testing.T) {
+	var buf bytes.Buffer
+	q := newOutputQueue(4, "test")
+	l := slog.New(newQueueHandler(slog.NewTextHandler(&buf, nil), q))
+
+	l.Info("queued")
+	_ = q.Close()
+	assertContains(t, buf.String(), "msg=queued")
+
+	l.Info("direct")
+	assertContains(t, buf.String(), "msg=direct")
+}
@@ -0,0 +1,228 @@
+package logx
+
+// ratelimit.go caps how many records a message or an attr value may produce
+// per interval, so one misbehaving client or dependency cannot flood the
+// log pipeline. Unlike Sampled, records within the limit are always kept.
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateLimitDroppedKey is added to the first record let through after a
+// limit dropped records, with the number dropped for that key.
+const RateLimitDroppedKey = "rate_limit_dropped"
+
+// maxRateBuckets bounds the buckets of one attr limit; when it is reached,
+// buckets that have refilled completely are forgotten. If none has, new
+// values share a single overflow bucket until some are.
+const maxRateBuckets = 10000
+
+var (
+	rateLimitsMu     sync.Mutex
+	rateLimits       atomic.Pointer[rateLimitSet]
+	rateLimitDropped atomic.Uint64
+)
+
+type rateLimitSet struct {
+	byMsg  map[string]*rateLimit
+	byAttr map[string]*rateLimit
+}
+
+// SetRateLimit lets at most n records with message msg through per
+// interval, as a token bucket holding n tokens that refills over per.
+// Dropped records are counted by RateLimitDropped and reported on the next
+// record let through. n <= 0 removes the limit. Limits are kept across
+// Configure calls; Reset and ClearRateLimits remove them.
+func SetRateLimit(msg string, n int, per time.Duration) {
+	updateRateLimits(func(s *rateLimitSet) { setLimit(s.byMsg, msg, n, per) })
+}
+
+// SetAttrRateLimit is like SetRateLimit for records with a top-level attr
+// key, with a separate limit for every value: SetAttrRateLimit("client_ip",
+// 100, time.Minute) lets each client produce 100 records a minute.
+func SetAttrRateLimit(key string, n int, per time.Duration) {
+	updateRateLimits(func(s *rateLimitSet) { setLimit(s.byAttr, key, n, per) })
+}
+
+// ClearRateLimits removes all limits.
+func ClearRateLimits() {
+	rateLimitsMu.Lock()
+	rateLimits.Store(nil)
+	rateLimitsMu.Unlock()
+}
+
+// RateLimitDropped returns the number of records dropped by rate limits.
+func RateLimitDropped() uint64 {
+	return rateLimitDropped.Load()
+}
+
+func updateRateLimits(update func(*rateLimitSet)) {
+	rateLimitsMu.Lock()
+	defer rateLimitsMu.Unlock()
+	next := &rateLimitSet{byMsg: map[string]*rateLimit{}, byAttr: map[string]*rateLimit{}}
+	if p := rateLimits.Load(); p != nil {
+		next.byMsg = maps.Clone(p.byMsg)
+		next.byAttr = maps.Clone(p.byAttr)
+	}
+	update(next)
+	if len(next.byMsg) == 0 && len(next.byAttr) == 0 {
+		next = nil
+	}
+	rateLimits.Store(next)
+}
+
+func setLimit(m map[string]*rateLimit, key string, n int, per time.Duration) {
+	if n <= 0 || per <= 0 {
+		delete(m, key)
+		return
+	}
+	m[key] = &rateLimit{n: float64(n), per: per, buckets: map[string]*tokenBucket{}}
+}
+
+type rateLimit struct {
+	n   float64
+	per time.Duration
+
+	mu       sync.Mutex
+	buckets  map[string]*tokenBucket
+	overflow *tokenBucket
+	evicted  time.Time // last eviction sweep
+}
+
+type tokenBucket struct {
+	tokens  float64
+	last    time.Time
+	dropped uint64
+}
+
+// allow takes a token from the bucket for key. When it is let through,
+// dropped is the number of records dropped for key since the last one.
+func (l *rateLimit) allow(key string, now time.Time) (ok bool, dropped uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b := l.buckets[key]
+	if b == nil {
+		if len(l.buckets) >= maxRateBuckets {
+			l.evict(now)
+		}
+		if len(l.buckets) < maxRateBuckets {
+			b = &tokenBucket{tokens: l.n, last: now}
+			l.buckets[key] = b
+		} else {
+			if l.overflow == nil {
+				l.overflow = &tokenBucket{tokens: l.n, last: now}
+			}
+			b = l.overflow
+		}
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(l.n, b.tokens+l.n*float64(elapsed)/float64(l.per))
+		b.last = now
+	}
+	if b.tokens < 1 {
+		b.dropped++
+		return false, 0
+	}
+	b.tokens--
+	dropped, b.dropped = b.dropped, 0
+	return true, dropped
+}
+
+// evict forgets buckets that are full again. It sweeps at most once per
+// interval: a bucket one sweep misses is full by the next. The caller holds
+// l.mu.
+func (l *rateLimit) evict(now time.Time) {
+	if !l.evicted.IsZero() && now.Sub(l.evicted) < l.per {
+		return
+	}
+	l.evicted = now
+	for key, b := range l.buckets {
+		if b.dropped == 0 && now.Sub(b.last) >= l.per {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// rateLimitHandler drops records over a limit set with SetRateLimit or
+// SetAttrRateLimit.
+type rateLimitHandler struct {
+	next  slog.Handler
+	scope attrScope
+}
+
+func newRateLimitHandler(next slog.Handler) slog.Handler {
+	return &rateLimitHandler{next: next}
+}
+
+func (h *rateLimitHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *rateLimitHandler) Handle(ctx context.Context, r slog.Record) error {
+	// records only the recent ring sees must not take tokens
+	set := rateLimits.Load()
+	if set == nil || ringOnly(ctx) {
+		return h.next.Handle(ctx, r)
+	}
+	now := Now()
+	var dropped uint64
+	check := func(l *rateLimit, key string) bool {
+		ok, d := l.allow(key, now)
+		if !ok {
+			rateLimitDropped.Add(1)
+		}
+		dropped += d
+		return ok
+	}
+
+	if l := set.byMsg[r.Message]; l != nil && !check(l, "") {
+		return nil
+	}
+	if len(set.byAttr) > 0 {
+		ok := true
+		visit := func(a slog.Attr) bool {
+			if l := set.byAttr[a.Key]; l != nil {
+				ok = check(l, a.Value.Resolve().String())
+			}
+			return ok
+		}
+		for _, p := range h.scope.pre {
+			if p.depth == 0 && !visit(p.attr) {
+				return nil
+			}
+		}
+		if len(h.scope.groups) == 0 {
+			r.Attrs(visit)
+		}
+		if !ok {
+			return nil
+		}
+	}
+	if dropped > 0 {
+		r = r.Clone()
+		r.AddAttrs(slog.Uint64(RateLimitDroppedKey, dropped))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *rateLimitHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.next = h.next.WithAttrs(attrs)
+	c.scope = h.scope.withAttrs(attrs)
+	return &c
+}
+
+func (h *rateLimitHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.next = h.next.WithGroup(name)
+	c.scope = h.scope.withGroup(name)
+	return &c
+}
@@ -0,0 +1,147 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimit_PerMessage(t *testing.T) {
+	defer Reset()
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	SetClock(func() time.Time { return now })
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	SetRateLimit("connection failed", 2, time.Minute)
+	for range 5 {
+		Error("connection failed", "host", "db")
+	}
+	Info("unrelated")
+	now = now.Add(30 * time.Second) // one token back
+	Error("connection failed", "host", "db")
+	Error("connection failed", "host", "db")
+
+	out := w.String()
+	if n := strings.Count(out, "connection failed"); n != 3 {
+		t.Fatalf("expected 3 records through the limit, got %d: %q", n, out)
+	}
+	assertContains(t, out, "rate_limit_dropped=3")
+	assertContains(t, out, "unrelated")
+	if RateLimitDropped() != 4 {
+		t.Fatalf("expected 4 dropped records, got %d", RateLimitDropped())
+	}
+
+	SetRateLimit("connection failed", 0, 0)
+	Error("connection failed", "after", "removed")
+	assertContains(t, w.String(), "after=removed")
+}
+
+func TestRateLimit_PerAttrValue(t *testing.T) {
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	SetAttrRateLimit("client_ip", 1, time.Hour)
+	client := With("client_ip", "10.0.0.1")
+	client.Warn("bad request")
+	client.Warn("bad request")
+	Warn("bad request", "client_ip", "10.0.0.2")
+	Warn("bad request", "client_ip", "10.0.0.2")
+	Warn("no client")
+	Warn("no client")
+
+	out := w.String()
+	for _, want := range []string{"client_ip=10.0.0.1", "client_ip=10.0.0.2"} {
+		if n := strings.Count(out, want); n != 1 {
+			t.Errorf("expected one record for %s, got %d: %q", want, n, out)
+		}
+	}
+	if n := strings.Count(out, "no client"); n != 2 {
+		t.Fatalf("records without the attr must not be limited: %q", out)
+	}
+
+	ClearRateLimits()
+	client.Warn("bad request")
+	if n := strings.Count(w.String(), "client_ip=10.0.0.1"); n != 2 {
+		t.Fatalf("expected ClearRateLimits to lift the limit: %q", w.String())
+	}
+}
+
+func TestRateLimit_BucketsAreCapped(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	l := &rateLimit{n: 1, per: time.Minute, buckets: map[string]*tokenBucket{}}
+	for i := range maxRateBuckets {
+		l.allow(strconv.Itoa(i), now)
+	}
+	// every bucket is still empty, so new values share the overflow bucket
+	if ok, _ := l.allow("new-1", now); !ok {
+		t.Fatal("expected the overflow bucket to let the first record through")
+	}
+	if ok, _ := l.allow("new-2", now); ok {
+		t.Fatal("expected new values to share the overflow bucket")
+	}
+	if len(l.buckets) != maxRateBuckets {
+		t.Fatalf("expected %d buckets, got %d", maxRateBuckets, len(l.buckets))
+	}
+
+	now = now.Add(time.Minute)
+	if ok, _ := l.allow("new-3", now); !ok {
+		t.Fatal("expected a fresh bucket once old ones refilled")
+	}
+	if len(l.buckets) != 1 {
+		t.Fatalf("expected refilled buckets to be evicted, got %d", len(l.buckets))
+	}
+}
+
+func TestRateLimit_IgnoresRecordsOnlyTheRecentRingSees(t *testing.T) {
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: w, RecentRecords: 8}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	SetRateLimit("x", 1, time.Hour)
+	Debug("x")
+	Info("x")
+
+	if RateLimitDropped() != 0 {
+		t.Fatalf("expected no dropped records, got %d", RateLimitDropped())
+	}
+	assertContains(t, w.String(), "level=INFO msg=x")
+
+	var buf bytes.Buffer
+	_ = DumpRecent(&buf)
+	assertContains(t, buf.String(), "level=DEBUG msg=x")
+}
+
+func TestRateLimit_AppliesToRecordsEnabledByOverrides(t *testing.T) {
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: w}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	SetRateLimit("poll", 1, time.Hour)
+	SetNamedLevel("ratelimit-db", slog.LevelDebug)
+	ctx := WithLevel(context.Background(), slog.LevelDebug)
+	for range 3 {
+		DebugContext(ctx, "poll")
+		Named("ratelimit-db").Debug("poll")
+	}
+
+	if n := strings.Count(w.String(), "msg=poll"); n != 1 {
+		t.Fatalf("expected one record through the limit, got %d: %q", n, w.String())
+	}
+	if RateLimitDropped() != 5 {
+		t.Fatalf("expected 5 dropped records, got %d", RateLimitDropped())
+	}
+}
@@ -0,0 +1,127 @@
+package logx
+
+// recent.go keeps the last N records at every level in memory so that a
+// crash can be followed by a dump of the debug context leading up to it,
+// even when the configured level only sends INFO and above to the outputs.
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+)
+
+// recentBuf is the active ring, set by Configure when Config.RecentRecords > 0.
+var recentBuf atomic.Pointer[recentRing]
+
+// DumpRecent writes the buffered recent records (oldest first, text format)
+// to w. It is a no-op unless Config.RecentRecords is set. Typical use is
+// from Config.OnFatal or a recover block:
+//
+//	OnFatal: func() { logx.DumpRecent(os.Stderr) },
+func DumpRecent(w io.Writer) error {
+	r := recentBuf.Load()
+	if r == nil {
+		return nil
+	}
+	for _, line := range r.snapshot() {
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recentRing is a fixed-size ring of formatted records. slog handlers issue
+// one Write per record, so each Write is stored as one entry.
+type recentRing struct {
+	mu    sync.Mutex
+	lines [][]byte
+	next  int
+	full  bool
+}
+
+func newRecentRing(n int) *recentRing {
+	return &recentRing{lines: make([][]byte, n)}
+}
+
+func (r *recentRing) Write(p []byte) (int, error) {
+	line := append([]byte(nil), p...)
+
+	r.mu.Lock()
+	r.lines[r.next] = line
+	r.next++
+	if r.next == len(r.lines) {
+		r.next = 0
+		r.full = true
+	}
+	r.mu.Unlock()
+	return len(p), nil
+}
+
+func (r *recentRing) snapshot() [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([][]byte(nil), r.lines[:r.next]...)
+	}
+	out := make([][]byte, 0, len(r.lines))
+	out = append(out, r.lines[r.next:]...)
+	return append(out, r.lines[:r.next]...)
+}
+
+// allLevels enables every level for the ring's formatter.
+const allLevels = slog.Level(math.MinInt)
+
+// ringOnlyKey marks the context of a record only the ring takes: the
+// outermost ctxLevelHandler enables every level when the ring is installed,
+// and marks records that neither the outputs' level, a WithLevel override
+// nor a named level enabled.
+type ringOnlyKey struct{}
+
+// ringOnly reports whether the record handled with ctx goes to the ring only;
+// decorators above the recentHandler pass such records through untouched.
+func ringOnly(ctx context.Context) bool {
+	return ctx.Value(ringOnlyKey{}) != nil
+}
+
+// recentHandler records every record into the ring and forwards those not
+// marked ringOnly. Enabled reports whether the outputs accept a level, which
+// ctxLevelHandler uses to mark the others.
+type recentHandler struct {
+	next slog.Handler
+	ring slog.Handler
+}
+
+func newRecentHandler(next slog.Handler, ring *recentRing) slog.Handler {
+	return &recentHandler{
+		next: next,
+		ring: slog.NewTextHandler(ring, &slog.HandlerOptions{Level: allLevels}),
+	}
+}
+
+func (h *recentHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	// a context override has already been applied by ctxLevelHandler
+	if override, ok := LevelFromContext(ctx); ok {
+		return level >= override
+	}
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *recentHandler) Handle(ctx context.Context, r slog.Record) error {
+	_ = h.ring.Handle(ctx, r)
+	if ringOnly(ctx) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *recentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &recentHandler{next: h.next.WithAttrs(attrs), ring: h.ring.WithAttrs(attrs)}
+}
+
+func (h *recentHandler) WithGroup(name string) slog.Handler {
+	return &recentHandler{next: h.next.WithGroup(name), ring: h.ring.WithGroup(name)}
+}
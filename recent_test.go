@@ -0,0 +1,80 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecentRing_KeepsLastN(t *testing.T) {
+	r := newRecentRing(2)
+	for _, s := range []string{"a\n", "b\n", "c\n"} {
+		_, _ = r.Write([]byte(s))
+	}
+
+	var got []string
+	for _, l := range r.snapshot() {
+		got = append(got, string(l))
+	}
+	if strings.Join(got, "") != "b\nc\n" {
+		t.Fatalf("unexpected snapshot: %q", got)
+	}
+}
+
+func TestDumpRecent_IncludesBelowLevelRecords(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	err := Configure(Config{
+		Level:         slog.LevelInfo,
+		FilePath:      path,
+		RecentRecords: 10,
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure error: %v", err)
+	}
+
+	Debug("cache miss", "key", "k1")
+	With("req", 1).Info("served")
+
+	var buf bytes.Buffer
+	if err := DumpRecent(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	assertContains(t, out, "level=DEBUG msg=\"cache miss\" key=k1")
+	assertContains(t, out, "msg=served req=1")
+}
+
+func TestRecentHandler_ForwardsOnlyEnabledRecords(t *testing.T) {
+	var sink bytes.Buffer
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(slog.LevelInfo)
+	next := slog.NewTextHandler(&sink, &slog.HandlerOptions{Level: levelVar})
+	l := slog.New(newCtxLevelHandler(newRecentHandler(next, newRecentRing(4)), true))
+
+	l.Debug("hidden")
+	l.Info("shown")
+	l.DebugContext(WithLevel(context.Background(), slog.LevelDebug), "override")
+
+	out := sink.String()
+	if strings.Contains(out, "hidden") {
+		t.Fatalf("below-level record reached output: %s", out)
+	}
+	assertContains(t, out, "shown")
+	assertContains(t, out, "override")
+}
+
+func TestDumpRecent_DisabledIsNoop(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	if err := DumpRecent(&buf); err != nil || buf.Len() != 0 {
+		t.Fatalf("expected no output, got %q, %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,108 @@
+package logx
+
+// recordfilter.go drops whole records with application predicates (bot
+// traffic, noisy components) before they reach any output.
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// RecordFilter reports whether a record is logged. r carries the attrs
+// added with Logger.With, like the records passed to hooks.
+type RecordFilter func(ctx context.Context, r slog.Record) bool
+
+var (
+	filtersMu      sync.Mutex
+	runtimeFilters atomic.Pointer[[]*RecordFilter]
+)
+
+// AddFilter drops records for which f returns false until the returned
+// function is called. Like AddHook, filters are kept across Configure
+// calls; Reset and ClearFilters remove them.
+func AddFilter(f RecordFilter) (remove func()) {
+	fp := &f
+	filtersMu.Lock()
+	var filters []*RecordFilter
+	if p := runtimeFilters.Load(); p != nil {
+		filters = slices.Clone(*p)
+	}
+	filters = append(filters, fp)
+	runtimeFilters.Store(&filters)
+	filtersMu.Unlock()
+
+	return func() {
+		filtersMu.Lock()
+		defer filtersMu.Unlock()
+		p := runtimeFilters.Load()
+		if p == nil {
+			return
+		}
+		filters := slices.DeleteFunc(slices.Clone(*p), func(x *RecordFilter) bool { return x == fp })
+		runtimeFilters.Store(&filters)
+	}
+}
+
+// ClearFilters removes all filters added with AddFilter.
+func ClearFilters() {
+	filtersMu.Lock()
+	runtimeFilters.Store(nil)
+	filtersMu.Unlock()
+}
+
+// recordFilterHandler drops records rejected by Config.Filter or a runtime
+// filter.
+type recordFilterHandler struct {
+	next   slog.Handler
+	filter RecordFilter
+	scope  attrScope
+}
+
+func newRecordFilterHandler(next slog.Handler, filter RecordFilter) slog.Handler {
+	return &recordFilterHandler{next: next, filter: filter}
+}
+
+func (h *recordFilterHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *recordFilterHandler) Handle(ctx context.Context, r slog.Record) error {
+	var runtime []*RecordFilter
+	if p := runtimeFilters.Load(); p != nil {
+		runtime = *p
+	}
+	if h.filter == nil && len(runtime) == 0 {
+		return h.next.Handle(ctx, r)
+	}
+
+	full := h.scope.full(r)
+	if h.filter != nil && !h.filter(ctx, full) {
+		return nil
+	}
+	for _, f := range runtime {
+		if !(*f)(ctx, full) {
+			return nil
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *recordFilterHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	c := *h
+	c.next = h.next.WithAttrs(attrs)
+	c.scope = h.scope.withAttrs(attrs)
+	return &c
+}
+
+func (h *recordFilterHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.next = h.next.WithGroup(name)
+	c.scope = h.scope.withGroup(name)
+	return &c
+}
@@ -0,0 +1,70 @@
+package logx
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func attrValue(r slog.Record, key string) string {
+	var v string
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == key {
+			v = a.Value.String()
+			return false
+		}
+		return true
+	})
+	return v
+}
+
+func TestConfigFilter_DropsByWithAttr(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	err := Configure(Config{
+		FileWriter: w,
+		Filter: func(_ context.Context, r slog.Record) bool {
+			return !strings.Contains(attrValue(r, "user_agent"), "Googlebot")
+		},
+	})
+	if err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+
+	Logger().With("user_agent", "Googlebot/2.1").Info("bot request")
+	Logger().With("user_agent", "curl/8").WithGroup("req").Info("human request")
+
+	out := w.String()
+	if strings.Contains(out, "bot request") {
+		t.Fatalf("expected bot record to be dropped: %s", out)
+	}
+	assertContains(t, out, "human request")
+}
+
+func TestAddFilter_RuntimeAndRemove(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	remove := AddFilter(func(_ context.Context, r slog.Record) bool {
+		return r.Level > slog.LevelInfo || attrValue(r, "component") != "poller"
+	})
+
+	Info("poll tick", "component", "poller")
+	Warn("poll failed", "component", "poller")
+	remove()
+	Info("poll tick again", "component", "poller")
+
+	out := w.String()
+	if strings.Contains(out, `msg="poll tick"`) {
+		t.Fatalf("expected filtered INFO record to be dropped: %s", out)
+	}
+	assertContains(t, out, "poll failed")
+	assertContains(t, out, "poll tick again")
+}
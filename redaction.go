@@ -30,25 +30,53 @@ func SanitizeURL(u *url.URL) string {
 	return clone.String()
 }
 
-var (
-	redactedKeys         = map[string]struct{}{}
-	redactedKeysMu       sync.RWMutex
-	redactedKeysSnapshot atomic.Value // map[string]struct{}
-)
+// redactionSet holds redacted keys, normalized to lowercase, and value
+// patterns. Writers copy them so handlers read a snapshot without locking.
+type redactionSet struct {
+	mu       sync.Mutex
+	snapshot atomic.Pointer[map[string]struct{}]
+	patterns atomic.Pointer[[]valuePattern]
+}
+
+func (s *redactionSet) add(keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := cloneKeySet(s.load())
+	for _, k := range keys {
+		next[strings.ToLower(k)] = struct{}{}
+	}
+	s.snapshot.Store(&next)
+}
+
+func (s *redactionSet) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshot.Store(nil)
+}
 
-func init() {
-	redactedKeysSnapshot.Store(map[string]struct{}{})
+func (s *redactionSet) load() map[string]struct{} {
+	if p := s.snapshot.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+func (s *redactionSet) list() []string {
+	keys := s.load()
+	out := make([]string, 0, len(keys))
+	for k := range keys {
+		out = append(out, k)
+	}
+	return out
 }
 
+// redactedKeys is the global redaction set, with the global patterns.
+var redactedKeys redactionSet
+
 // SetRedactedKeys adds keys to the global redaction set.
 // Keys are normalized to lowercase.
 func SetRedactedKeys(keys ...string) {
-	redactedKeysMu.Lock()
-	defer redactedKeysMu.Unlock()
-	for _, k := range keys {
-		redactedKeys[strings.ToLower(k)] = struct{}{}
-	}
-	redactedKeysSnapshot.Store(cloneKeySet(redactedKeys))
+	redactedKeys.add(keys...)
 }
 
 // AddRedactedKeys appends keys to the redaction set (concurrency-safe).
@@ -58,71 +86,181 @@ func AddRedactedKeys(keys ...string) {
 
 // ClearRedactedKeys removes all configured redacted keys.
 func ClearRedactedKeys() {
-	redactedKeysMu.Lock()
-	defer redactedKeysMu.Unlock()
-	redactedKeys = map[string]struct{}{}
-	redactedKeysSnapshot.Store(map[string]struct{}{})
+	redactedKeys.clear()
 }
 
 // ListRedactedKeys returns a snapshot of configured redacted keys.
 func ListRedactedKeys() []string {
-	keys, _ := redactedKeysSnapshot.Load().(map[string]struct{})
-	out := make([]string, 0, len(keys))
-	for k := range keys {
-		out = append(out, k)
-	}
-	return out
+	return redactedKeys.list()
 }
 
 type redactionHandler struct {
 	next slog.Handler
+	// keys is the redaction set of an Instance; nil uses the global set
+	keys *redactionSet
 }
 
 func newRedactionHandler(next slog.Handler) slog.Handler {
 	return &redactionHandler{next: next}
 }
 
+// redactor returns the current keys and patterns of h's set.
+func (h *redactionHandler) redactor() redactor {
+	keys := &redactedKeys
+	if h.keys != nil {
+		keys = h.keys
+	}
+	return currentRedactor(keys)
+}
+
 func (h *redactionHandler) Enabled(ctx context.Context, level slog.Level) bool {
 	return h.next.Enabled(ctx, level)
 }
 
 func (h *redactionHandler) Handle(ctx context.Context, r slog.Record) error {
-	keys, _ := redactedKeysSnapshot.Load().(map[string]struct{})
-	if len(keys) == 0 {
+	rd := h.redactor()
+	if rd.empty() {
 		return h.next.Handle(ctx, r)
 	}
 
-	nr := r.Clone()
+	// scan first so records without sensitive keys pass through untouched
+	matched := false
+	r.Attrs(func(a slog.Attr) bool {
+		matched = rd.needsRedaction(a)
+		return !matched
+	})
+	if !matched {
+		return h.next.Handle(ctx, r)
+	}
 
-	var attrs []slog.Attr
+	// r is not retained or modified, so it is read without cloning
+	attrs := getAttrs()
+	defer putAttrs(attrs)
 
-	nr.Attrs(func(a slog.Attr) bool {
-		_, ok := keys[strings.ToLower(a.Key)]
-		if ok {
-			a.Value = slog.StringValue("REDACTED")
-		}
-		attrs = append(attrs, a)
+	r.Attrs(func(a slog.Attr) bool {
+		*attrs = append(*attrs, rd.redactAttr(a))
 		return true
 	})
 
 	newRec := slog.NewRecord(
-		nr.Time,
-		nr.Level,
-		nr.Message,
-		nr.PC,
+		r.Time,
+		r.Level,
+		r.Message,
+		r.PC,
 	)
 
-	newRec.AddAttrs(attrs...)
+	newRec.AddAttrs(*attrs...)
 
 	return h.next.Handle(ctx, newRec)
 }
 
+// WithAttrs redacts attrs with the keys configured at the time of the call
+// before handing them to the next handler.
 func (h *redactionHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return newRedactionHandler(h.next.WithAttrs(attrs))
+	if rd := h.redactor(); !rd.empty() {
+		out := make([]slog.Attr, len(attrs))
+		for i, a := range attrs {
+			out[i] = rd.redactAttr(a)
+		}
+		attrs = out
+	}
+	return &redactionHandler{next: h.next.WithAttrs(attrs), keys: h.keys}
 }
 
 func (h *redactionHandler) WithGroup(name string) slog.Handler {
-	return newRedactionHandler(h.next.WithGroup(name))
+	return &redactionHandler{next: h.next.WithGroup(name), keys: h.keys}
+}
+
+// redactor is a snapshot of the redacted keys and value patterns.
+type redactor struct {
+	keys map[string]struct{}
+	pats []valuePattern
+}
+
+func currentRedactor(set *redactionSet) redactor {
+	return redactor{keys: set.load(), pats: set.loadPatterns()}
+}
+
+func (rd redactor) empty() bool {
+	return len(rd.keys) == 0 && len(rd.pats) == 0
+}
+
+// needsRedaction reports whether a or any attr nested in its groups has a
+// redacted key or a string value matching a pattern.
+func (rd redactor) needsRedaction(a slog.Attr) bool {
+	if _, ok := rd.keys[strings.ToLower(a.Key)]; ok {
+		return true
+	}
+	v := a.Value
+	if v.Kind() == slog.KindLogValuer {
+		v = v.Resolve()
+	}
+	switch v.Kind() {
+	case slog.KindString:
+		return rd.matches(v.String())
+	case slog.KindGroup:
+		for _, ga := range v.Group() {
+			if rd.needsRedaction(ga) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactAttr masks redacted keys and pattern matches in a, keeping group
+// structure.
+func (rd redactor) redactAttr(a slog.Attr) slog.Attr {
+	if _, ok := rd.keys[strings.ToLower(a.Key)]; ok {
+		a.Value = slog.StringValue("REDACTED")
+		return a
+	}
+	v := a.Value
+	if v.Kind() == slog.KindLogValuer {
+		v = v.Resolve()
+	}
+	switch v.Kind() {
+	case slog.KindString:
+		if rd.matches(v.String()) {
+			a.Value = slog.StringValue(rd.replace(v.String()))
+		}
+		return a
+	case slog.KindGroup:
+		if !rd.needsRedaction(a) {
+			return a
+		}
+		group := v.Group()
+		out := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			out[i] = rd.redactAttr(ga)
+		}
+		a.Value = slog.GroupValue(out...)
+	}
+	return a
+}
+
+// attrPool recycles the attr slices handlers use to rebuild records;
+// Record.AddAttrs copies its arguments, so a slice can be reused as soon as
+// the rebuilt record has been handled.
+var attrPool = sync.Pool{
+	New: func() any {
+		s := make([]slog.Attr, 0, 16)
+		return &s
+	},
+}
+
+func getAttrs() *[]slog.Attr {
+	return attrPool.Get().(*[]slog.Attr)
+}
+
+func putAttrs(s *[]slog.Attr) {
+	// drop oversized slices so one huge record does not pin memory
+	if cap(*s) > 256 {
+		return
+	}
+	clear(*s)
+	*s = (*s)[:0]
+	attrPool.Put(s)
 }
 
 func cloneKeySet(src map[string]struct{}) map[string]struct{} {
@@ -1,6 +1,8 @@
 package logx
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"log/slog"
 	"net/url"
@@ -55,3 +57,66 @@ func TestSanitizeURL_RedactsQueryParams(t *testing.T) {
 		t.Fatalf("expected apikey=REDACTED, got: %s", s)
 	}
 }
+
+func TestRedactionHandler_WithAttrsAndGroups(t *testing.T) {
+	SetRedactedKeys("token")
+	defer ClearRedactedKeys()
+
+	var buf bytes.Buffer
+	l := slog.New(newRedactionHandler(slog.NewJSONHandler(&buf, nil)))
+
+	l.With("token", "abc", "svc", "api").
+		WithGroup("req").
+		Info("call", slog.Group("auth", "token", "def", "user", "bob"), "path", "/x")
+
+	out := buf.String()
+	if strings.Contains(out, "abc") || strings.Contains(out, "def") {
+		t.Fatalf("token leaked: %s", out)
+	}
+	assertContains(t, out, `"token":"REDACTED","svc":"api"`)
+	assertContains(t, out, `"req":{"auth":{"token":"REDACTED","user":"bob"},"path":"/x"}`)
+}
+
+func TestRedactionHandler_NoMatchDoesNotAllocate(t *testing.T) {
+	SetRedactedKeys("password")
+	defer ClearRedactedKeys()
+
+	l := slog.New(newRedactionHandler(discardHandler{}))
+	ctx := context.Background()
+	attrs := []slog.Attr{slog.String("user", "bob"), slog.Group("g", slog.Int("n", 1))}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		l.LogAttrs(ctx, slog.LevelInfo, "login", attrs...)
+	})
+	if allocs > 0 {
+		t.Fatalf("expected no allocations, got %.1f", allocs)
+	}
+}
+
+func TestRedactionHandler_DoesNotAllocatePerRecord(t *testing.T) {
+	SetRedactedKeys("password")
+	defer ClearRedactedKeys()
+
+	l := slog.New(newRedactionHandler(discardHandler{}))
+	ctx := context.Background()
+	attrs := []slog.Attr{slog.String("user", "bob"), slog.String("password", "x")}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		l.LogAttrs(ctx, slog.LevelInfo, "login", attrs...)
+	})
+	if allocs > 0 {
+		t.Fatalf("expected no allocations, got %.1f", allocs)
+	}
+}
+
+func BenchmarkRedactionHandler(b *testing.B) {
+	SetRedactedKeys("password")
+	defer ClearRedactedKeys()
+
+	l := slog.New(newRedactionHandler(discardHandler{}))
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		l.LogAttrs(ctx, slog.LevelInfo, "login", slog.String("user", "bob"), slog.String("password", "x"))
+	}
+}
@@ -0,0 +1,183 @@
+package logx
+
+// redactpattern.go masks secrets by the shape of the value rather than the
+// attr key, for data such as card numbers that can appear under any key,
+// and ships preset key and pattern bundles for common secret families.
+
+import "regexp"
+
+// valuePattern matches secrets in string values; valid, if set, confirms
+// a match (e.g. a Luhn check) to avoid masking lookalikes.
+type valuePattern struct {
+	re    *regexp.Regexp
+	valid func(string) bool
+}
+
+// AddRedactedPatterns masks every match of the patterns in string attr
+// values with "REDACTED", whatever the key. Messages are not rewritten.
+func AddRedactedPatterns(patterns ...*regexp.Regexp) {
+	addValuePatterns(toValuePatterns(patterns))
+}
+
+// ClearRedactedPatterns removes all value patterns.
+func ClearRedactedPatterns() {
+	redactedKeys.clearPatterns()
+}
+
+func addValuePatterns(vps []valuePattern) {
+	redactedKeys.addPatterns(vps)
+}
+
+func toValuePatterns(patterns []*regexp.Regexp) []valuePattern {
+	vps := make([]valuePattern, len(patterns))
+	for i, re := range patterns {
+		vps[i] = valuePattern{re: re}
+	}
+	return vps
+}
+
+func (s *redactionSet) addPatterns(vps []valuePattern) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := append(append([]valuePattern(nil), s.loadPatterns()...), vps...)
+	s.patterns.Store(&next)
+}
+
+func (s *redactionSet) clearPatterns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.patterns.Store(nil)
+}
+
+func (s *redactionSet) loadPatterns() []valuePattern {
+	if p := s.patterns.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+func (rd redactor) matches(s string) bool {
+	for _, p := range rd.pats {
+		if p.valid == nil {
+			if p.re.MatchString(s) {
+				return true
+			}
+			continue
+		}
+		for _, m := range p.re.FindAllString(s, -1) {
+			if p.valid(m) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (rd redactor) replace(s string) string {
+	for _, p := range rd.pats {
+		s = p.re.ReplaceAllStringFunc(s, func(m string) string {
+			if p.valid != nil && !p.valid(m) {
+				return m
+			}
+			return "REDACTED"
+		})
+	}
+	return s
+}
+
+// RedactPreset is a bundle of redacted keys and value patterns; apply it
+// with UseRedactPreset.
+type RedactPreset struct {
+	// Keys are added with SetRedactedKeys.
+	Keys     []string
+	patterns []valuePattern
+}
+
+// UseRedactPreset adds the keys and value patterns of each preset:
+//
+//	logx.UseRedactPreset(logx.RedactPresetSecrets, logx.RedactPresetCloudCreds)
+func UseRedactPreset(presets ...RedactPreset) {
+	for _, p := range presets {
+		SetRedactedKeys(p.Keys...)
+		addValuePatterns(p.patterns)
+	}
+}
+
+// RedactPresetSecrets covers common password, token and session field
+// spellings.
+var RedactPresetSecrets = RedactPreset{
+	Keys: []string{
+		"password", "passwd", "pwd", "pass", "passphrase", "secret",
+		"token", "access_token", "refresh_token", "id_token", "auth_token",
+		"api_key", "apikey", "api-key", "x-api-key", "authorization",
+		"proxy-authorization", "cookie", "set-cookie", "session_id",
+		"sessionid", "csrf_token", "xsrf_token", "client_secret", "private_key",
+	},
+}
+
+// RedactPresetCloudCreds covers AWS, GCP and Azure credential names plus
+// AWS access key IDs, Google API keys, PEM private keys and Azure account
+// keys appearing in values.
+var RedactPresetCloudCreds = RedactPreset{
+	Keys: []string{
+		"aws_access_key_id", "aws_secret_access_key", "aws_session_token",
+		"x-amz-security-token", "secret_access_key", "session_token",
+		"google_application_credentials", "private_key_id", "private_key",
+		"client_secret", "azure_client_secret", "azure_storage_key",
+		"account_key", "sas_token", "connection_string",
+	},
+	patterns: []valuePattern{
+		{re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{re: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+		{re: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+		{re: regexp.MustCompile(`AccountKey=[A-Za-z0-9+/=]{20,}`)},
+	},
+}
+
+// RedactPresetPII covers personal data field names plus email addresses
+// and US social security numbers appearing in values.
+var RedactPresetPII = RedactPreset{
+	Keys: []string{
+		"email", "phone", "phone_number", "ssn", "social_security_number",
+		"date_of_birth", "dob", "passport_number", "national_id", "tax_id",
+		"drivers_license",
+	},
+	patterns: []valuePattern{
+		{re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+		{re: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+	},
+}
+
+// RedactPresetPayment covers card number and CVV field names plus card
+// numbers (13-19 digits, optionally separated, passing the Luhn check)
+// appearing in values.
+var RedactPresetPayment = RedactPreset{
+	Keys: []string{
+		"card_number", "cardnumber", "pan", "cc_number", "credit_card",
+		"cvv", "cvv2", "cvc", "security_code", "card_verification",
+	},
+	patterns: []valuePattern{
+		{re: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhn},
+	},
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum.
+func luhn(s string) bool {
+	sum, n := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
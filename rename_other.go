@@ -0,0 +1,9 @@
+//go:build !windows
+
+package logx
+
+// renameRetryable reports whether a failed rename may succeed later. Open
+// files can be renamed outside Windows, so failures are not transient.
+func renameRetryable(err error) bool {
+	return false
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package logx
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Windows errors for a file another process has open without
+// FILE_SHARE_DELETE.
+const (
+	errorAccessDenied     syscall.Errno = 5
+	errorSharingViolation syscall.Errno = 32
+	errorLockViolation    syscall.Errno = 33
+)
+
+// renameRetryable reports whether a failed rename may succeed once other
+// handles to the file are closed.
+func renameRetryable(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	return errno == errorAccessDenied || errno == errorSharingViolation || errno == errorLockViolation
+}
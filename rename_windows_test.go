@@ -0,0 +1,72 @@
+//go:build windows
+
+package logx
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMoveAside_RetriesSharingViolations(t *testing.T) {
+	calls := 0
+	renameFile = func(from, to string) error {
+		calls++
+		if calls < 3 {
+			return &os.LinkError{Op: "rename", Old: from, New: to, Err: errorSharingViolation}
+		}
+		return os.Rename(from, to)
+	}
+	renameBackoff = time.Millisecond
+	defer func() { renameFile, renameBackoff = os.Rename, 10*time.Millisecond }()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("line\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := moveAside(path, path+".1", 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 rename attempts, got %d", calls)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the file to be renamed, got %v", err)
+	}
+}
+
+func TestFileRotator_RotatesWhileFileIsOpenElsewhere(t *testing.T) {
+	renameBackoff = time.Millisecond
+	defer func() { renameBackoff = 10 * time.Millisecond }()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := newFileRotator(path, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	_, _ = r.Write([]byte("first line\n"))
+
+	// a reader such as a log shipper keeps the file open without
+	// FILE_SHARE_DELETE, so it cannot be renamed
+	reader, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	if _, err := r.Write([]byte("second\n")); err != nil {
+		t.Fatal(err)
+	}
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup, got %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != "first line\n" {
+		t.Fatalf("unexpected backup content: %q", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "second\n" {
+		t.Fatalf("unexpected current content: %q", data)
+	}
+}
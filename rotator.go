@@ -1,49 +1,192 @@
 package logx
 
-// rotator.go implements a minimal size-based file rotator used by the
+// rotator.go implements the size- and time-based file rotator used by the
 // package when file rotation is configured.
 
 import (
+	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/rannday/logx/internal/backups"
 )
 
-// fileRotator is a simple size-based log rotator.
+// Rotation is a rotation policy for a log file.
+type Rotation struct {
+	// MaxSizeBytes rotates the file before a write would make it larger
+	// (0 = no size limit).
+	MaxSizeBytes int
+	// Every rotates the file when a write falls in a later period of this
+	// length than the previous write, e.g. 24*time.Hour for daily files.
+	// Periods are aligned to UTC (0 = no time limit).
+	Every time.Duration
+	// MaxBackups is the number of rotated files to keep (0 = all).
+	MaxBackups int
+	// MaxTotalBytes bounds the disk usage of the file and its backups: the
+	// oldest backups are deleted until they fit in MaxTotalBytes minus
+	// MaxSizeBytes (0 = no quota).
+	MaxTotalBytes int
+	// Compress gzips rotated files to "<backup>.gz".
+	Compress bool
+}
+
+// active reports whether rot ever rotates the file.
+func (rot Rotation) active() bool {
+	return rot.MaxSizeBytes > 0 || rot.Every > 0
+}
+
+// validate reports the problems of rot for the file named name.
+func (rot Rotation) validate(name string, invalid func(format string, args ...any)) {
+	if rot.MaxSizeBytes < 0 || rot.Every < 0 || rot.MaxBackups < 0 || rot.MaxTotalBytes < 0 {
+		invalid("%s rotation settings must not be negative", name)
+	}
+	if !rot.active() && (rot.MaxBackups > 0 || rot.MaxTotalBytes > 0 || rot.Compress) {
+		invalid("%s: MaxBackups, MaxTotalBytes and Compress require MaxSizeBytes or Every", name)
+	}
+	if rot.MaxTotalBytes > 0 && rot.MaxTotalBytes < rot.MaxSizeBytes {
+		invalid("%s: MaxTotalBytes must be at least MaxSizeBytes", name)
+	}
+}
+
+// fileRotator is a size- and time-based log rotator.
 type fileRotator struct {
 	path    string
+	perm    os.FileMode
 	mu      sync.Mutex
 	f       *os.File
 	maxSize int
 	backups int
 	size    int64
+
+	// every rotates at period boundaries; last is the time of the latest
+	// write to the current file.
+	every time.Duration
+	last  time.Time
+	// compress gzips rotated files.
+	compress bool
+	// reopen checks at most this often whether path still names the open
+	// file, and reopens it if it was renamed or deleted (logrotate's
+	// default create mode); nextCheck is the time of the next check.
+	reopen    time.Duration
+	nextCheck time.Time
+
+	// lock is an advisory lock file shared with other processes writing to
+	// path. When set, every write and rotation happens under the lock and the
+	// current size is re-read from disk, since other processes also append.
+	lock *os.File
+
+	// manifest records each rotated file in "<path>.manifest"; opened is
+	// when the current file was started.
+	manifest bool
+	opened   time.Time
+
+	// maxTotal bounds the size of the active file and its backups together
+	// (0 = no quota).
+	maxTotal int
+}
+
+// lockSuffix names the sidecar lock file used in multi-process mode.
+const lockSuffix = backups.LockSuffix
+
+// OpenRotatingFile opens path for appending with the size-based rotation
+// Configure uses for FilePath (maxSizeBytes 0 = no rotation), for files
+// logx does not manage itself such as a separate access log. The caller
+// closes it.
+func OpenRotatingFile(path string, maxSizeBytes, maxBackups int) (io.WriteCloser, error) {
+	return newFileRotator(path, maxSizeBytes, maxBackups)
 }
 
 func newFileRotator(path string, maxSize int, backups int) (*fileRotator, error) {
+	return openRotator(path, Rotation{MaxSizeBytes: maxSize, MaxBackups: backups}, 0o644)
+}
+
+// openRotator opens path for appending with the rotation policy rot. New
+// files, the log file and its backups alike, are created with perm.
+func openRotator(path string, rot Rotation, perm os.FileMode) (*fileRotator, error) {
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return nil, err
 	}
 
-	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, perm)
 	if err != nil {
 		return nil, err
 	}
 
 	info, _ := f.Stat()
-	r := &fileRotator{path: path, f: f, maxSize: maxSize, backups: backups, size: info.Size()}
+	r := &fileRotator{
+		path:     path,
+		perm:     perm,
+		f:        f,
+		maxSize:  rot.MaxSizeBytes,
+		backups:  rot.MaxBackups,
+		maxTotal: rot.MaxTotalBytes,
+		every:    rot.Every,
+		compress: rot.Compress,
+		size:     info.Size(),
+		last:     info.ModTime(),
+		opened:   Now(),
+	}
 	return r, nil
 }
 
+// addLock makes r coordinate with other processes through "<path>.lock".
+// On failure r is closed.
+func (r *fileRotator) addLock() error {
+	lock, err := os.OpenFile(r.path+lockSuffix, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		r.f.Close()
+		return err
+	}
+	// fail at construction rather than on every write if locking is unavailable
+	if err := lockFile(lock); err != nil {
+		lock.Close()
+		r.f.Close()
+		return err
+	}
+	_ = unlockFile(lock)
+
+	r.lock = lock
+	return nil
+}
+
 func (r *fileRotator) Write(p []byte) (int, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if r.maxSize > 0 && r.size+int64(len(p)) > int64(r.maxSize) {
+	if r.lock != nil {
+		if err := lockFile(r.lock); err != nil {
+			return 0, err
+		}
+		defer unlockFile(r.lock)
+
+		if err := r.reopenIfMoved(); err != nil {
+			return 0, err
+		}
+	} else if r.reopen > 0 {
+		if now := time.Now(); !now.Before(r.nextCheck) {
+			r.nextCheck = now.Add(r.reopen)
+			if err := r.reopenIfMoved(); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	var now time.Time
+	if r.every > 0 {
+		now = Now()
+	}
+	// a record larger than maxSize goes to a fresh file rather than
+	// leaving an empty backup behind
+	if r.size > 0 && (r.maxSize > 0 && r.size+int64(len(p)) > int64(r.maxSize) ||
+		r.every > 0 && !now.Truncate(r.every).Equal(r.last.Truncate(r.every))) {
 		if err := r.rotate(); err != nil {
 			// if rotation fails, still attempt to write to current file
 		}
@@ -51,12 +194,60 @@ func (r *fileRotator) Write(p []byte) (int, error) {
 
 	n, err := r.f.Write(p)
 	r.size += int64(n)
+	if r.every > 0 {
+		r.last = now
+	}
 	return n, err
 }
 
+// Sync commits the current file's contents to stable storage.
+func (r *fileRotator) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return nil
+	}
+	return r.f.Sync()
+}
+
+// reopenIfMoved reopens path if another process rotated it away or it was
+// deleted, and refreshes the size from disk. With a lock it must be called
+// with the advisory lock held.
+func (r *fileRotator) reopenIfMoved() error {
+	// a file left closed by a failed rotation or reopen fails Stat; open
+	// path again so writes resume once it is writable
+	if cur, err := r.f.Stat(); err == nil {
+		onDisk, err := os.Stat(r.path)
+		if err == nil && os.SameFile(cur, onDisk) {
+			r.size = cur.Size()
+			return nil
+		}
+	}
+
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, r.perm)
+	if err != nil {
+		return err
+	}
+	r.f.Close()
+	r.f = f
+	r.opened = Now()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	r.size = info.Size()
+	r.last = info.ModTime()
+	return nil
+}
+
 func (r *fileRotator) Close() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if r.lock != nil {
+		r.lock.Close()
+		r.lock = nil
+	}
 	if r.f != nil {
 		err := r.f.Close()
 		r.f = nil
@@ -70,11 +261,10 @@ func (r *fileRotator) rotate() error {
 		r.f.Close()
 	}
 
-	ts := time.Now().Format("20060102T150405")
-	rotated := fmt.Sprintf("%s.%s", r.path, ts)
-	if err := os.Rename(r.path, rotated); err != nil {
+	rotated := backupName(r.path, Now())
+	if err := moveAside(r.path, rotated, r.perm); err != nil {
 		// if rename fails, try to reopen existing file
-		f, err2 := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		f, err2 := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, r.perm)
 		if err2 != nil {
 			return err2
 		}
@@ -84,29 +274,176 @@ func (r *fileRotator) rotate() error {
 		return err
 	}
 
-	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, r.perm)
 	if err != nil {
 		return err
 	}
 	r.f = f
 	r.size = 0
+	from := r.opened
+	r.opened = Now()
 
-	if r.backups > 0 {
-		// remove older backups
-		dir := filepath.Dir(r.path)
-		base := filepath.Base(r.path)
-		entries, _ := filepath.Glob(filepath.Join(dir, base+".*"))
-		sort.Strings(entries)
-		if len(entries) > r.backups {
-			remove := entries[:len(entries)-r.backups]
-			for _, p := range remove {
-				_ = os.Remove(p)
-			}
+	// an uncompressed backup is kept if compression fails
+	var zerr error
+	if r.compress {
+		if zerr = gzipFile(rotated, r.perm); zerr == nil {
+			rotated += ".gz"
+		}
+	}
+
+	removed := r.prune()
+	if r.manifest {
+		return errors.Join(zerr, recordRotation(r.path, rotated, from, removed))
+	}
+	return zerr
+}
+
+// gzipFile compresses the file at path to "<path>.gz" and removes path.
+func gzipFile(path string, perm os.FileMode) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	err = errors.Join(err, zw.Close(), dst.Close())
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
+
+// backupName returns a name for a backup of path rotated at t that sorts
+// after the existing ones: "<path>.<timestamp>", or "<path>.<timestamp>.001"
+// and up for further rotations within the same second. Sequence numbers
+// keep growing when older backups of that second were pruned.
+func backupName(path string, t time.Time) string {
+	base := path + "." + t.Format(backups.TimeFormat)
+	matches, _ := filepath.Glob(base + "*")
+	last := -1
+	for _, m := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(m, base), ".gz")
+		if suffix == "" {
+			last = max(last, 0)
+		} else if n, err := strconv.Atoi(strings.TrimPrefix(suffix, ".")); err == nil && suffix[0] == '.' {
+			last = max(last, n)
+		}
+	}
+	if last < 0 {
+		return base
+	}
+	return fmt.Sprintf("%s.%03d", base, last+1)
+}
+
+// renameFile is os.Rename, replaced in tests.
+var renameFile = os.Rename
+
+// renameAttempts and renameBackoff bound the retries of a rename that fails
+// because another process holds the file open, as virus scanners, indexers
+// and log shippers do on Windows. The backoff doubles after each attempt.
+var (
+	renameAttempts = 5
+	renameBackoff  = 10 * time.Millisecond
+)
+
+// moveAside moves the closed log file at path to rotated. It retries
+// renames that fail while the file is briefly held open elsewhere and, when
+// the file stays busy, copies it to rotated and truncates it instead.
+func moveAside(path, rotated string, perm os.FileMode) error {
+	backoff := renameBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = renameFile(path, rotated)
+		if err == nil || !renameRetryable(err) || attempt >= renameAttempts {
+			break
 		}
+		time.Sleep(backoff)
+		backoff *= 2
 	}
+	if err == nil {
+		return nil
+	}
+	if cerr := copyTruncate(path, rotated, perm); cerr != nil {
+		return errors.Join(err, cerr)
+	}
+	return nil
+}
+
+// copyTruncate copies the file at path to rotated and empties path. Records
+// appended by other writers between the copy and the truncation are lost,
+// so it is only a fallback for files that cannot be renamed.
+func copyTruncate(path, rotated string, perm os.FileMode) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
 
+	dst, err := os.OpenFile(rotated, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(rotated)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(rotated)
+		return err
+	}
+	if err := os.Truncate(path, 0); err != nil {
+		// keep a single copy of the records
+		os.Remove(rotated)
+		return err
+	}
 	return nil
 }
 
+// prune removes the oldest backups beyond maxBackups and, with maxTotal,
+// those that leave less than maxSize of the quota for the active file. It
+// returns the names of the removed backups.
+func (r *fileRotator) prune() []string {
+	if r.backups <= 0 && r.maxTotal <= 0 {
+		return nil
+	}
+	entries, _ := backups.List(r.path)
+
+	keep := 0 // index of the oldest kept backup
+	if r.backups > 0 && len(entries) > r.backups {
+		keep = len(entries) - r.backups
+	}
+	if r.maxTotal > 0 {
+		var total int64
+		sizes := make([]int64, len(entries))
+		for i, p := range entries[keep:] {
+			if info, err := os.Stat(p); err == nil {
+				sizes[keep+i] = info.Size()
+				total += info.Size()
+			}
+		}
+		budget := int64(r.maxTotal) - int64(r.maxSize)
+		for ; keep < len(entries) && total > budget; keep++ {
+			total -= sizes[keep]
+		}
+	}
+
+	var removed []string
+	for _, p := range entries[:keep] {
+		if os.Remove(p) == nil {
+			removed = append(removed, filepath.Base(p))
+		}
+	}
+	return removed
+}
+
 // Ensure fileRotator implements io.WriteCloser
 var _ io.WriteCloser = (*fileRotator)(nil)
@@ -1,11 +1,20 @@
 package logx
 
 import (
+	"compress/gzip"
+	"errors"
+	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/rannday/logx/internal/backups"
 )
 
 func TestFileRotator_RotatesAndKeepsBackups(t *testing.T) {
@@ -58,3 +67,386 @@ func TestFileRotator_RotatesAndKeepsBackups(t *testing.T) {
 	_, _ = io.ReadAll(f)
 	f.Close()
 }
+
+// newLockedFileRotator opens path the way Configure does with FileLock. A
+// maxSize of 0 disables rotation but still serializes appends.
+func newLockedFileRotator(path string, maxSize int, backups int) (*fileRotator, error) {
+	r, err := newFileRotator(path, maxSize, backups)
+	if err != nil {
+		return nil, err
+	}
+	return r, r.addLock()
+}
+
+func TestLockedFileRotator_SerializesWritersAcrossHandles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	a, err := newLockedFileRotator(path, 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create rotator: %v", err)
+	}
+	defer a.Close()
+	b, err := newLockedFileRotator(path, 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create rotator: %v", err)
+	}
+	defer b.Close()
+
+	line := strings.Repeat("z", 512) + "\n"
+	var wg sync.WaitGroup
+	for _, r := range []*fileRotator{a, b} {
+		wg.Add(1)
+		go func(r *fileRotator) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				_, _ = r.Write([]byte(line))
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 400 {
+		t.Fatalf("expected 400 lines, got %d", len(lines))
+	}
+	for _, l := range lines {
+		if l != line[:len(line)-1] {
+			t.Fatalf("found interleaved line of length %d", len(l))
+		}
+	}
+}
+
+func TestLockedFileRotator_FollowsRotationByOtherWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	a, err := newLockedFileRotator(path, 50, 5)
+	if err != nil {
+		t.Fatalf("failed to create rotator: %v", err)
+	}
+	defer a.Close()
+	b, err := newLockedFileRotator(path, 50, 5)
+	if err != nil {
+		t.Fatalf("failed to create rotator: %v", err)
+	}
+	defer b.Close()
+
+	_, _ = a.Write([]byte(strings.Repeat("a", 40) + "\n"))
+	// b sees a's bytes on disk and rotates before writing
+	_, _ = b.Write([]byte(strings.Repeat("b", 40) + "\n"))
+	// a must follow the rotation and append to the new file
+	_, _ = a.Write([]byte("after\n"))
+
+	data, _ := os.ReadFile(path)
+	if got := string(data); !strings.HasPrefix(got, "bbbb") || !strings.HasSuffix(got, "after\n") {
+		t.Fatalf("expected both writers in the new file, got %q", got)
+	}
+	if _, err := os.Stat(path + lockSuffix); err != nil {
+		t.Fatalf("expected lock file: %v", err)
+	}
+}
+
+func TestOpenRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access", "access.log")
+	w, err := OpenRotatingFile(path, 16, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		if _, err := io.WriteString(w, "0123456789\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, _ := filepath.Glob(path + "*")
+	if len(matches) != 2 {
+		t.Fatalf("expected current file and one backup, got %v", matches)
+	}
+}
+
+func TestFileRotator_FallsBackToCopyTruncate(t *testing.T) {
+	renameFile = func(string, string) error { return errors.New("file is busy") }
+	defer func() { renameFile = os.Rename }()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := newFileRotator(path, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	_, _ = r.Write([]byte("first line\n"))
+	_, _ = r.Write([]byte("second\n"))
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 1 {
+		t.Fatalf("expected one backup, got %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != "first line\n" {
+		t.Fatalf("unexpected backup content: %q", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "second\n" {
+		t.Fatalf("unexpected current content: %q", data)
+	}
+}
+
+func TestFileRotator_SameSecondRotationsKeepEveryBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := newFileRotator(path, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for range 5 {
+		if _, err := r.Write([]byte("0123456789\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 4 {
+		t.Fatalf("expected 4 distinct backups, got %v", backups)
+	}
+}
+
+func TestBackupName_AddsSequenceOnCollision(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	first := backupName(path, at)
+	if first != path+".20240101T120000" {
+		t.Fatalf("unexpected name: %s", first)
+	}
+	_ = os.WriteFile(first, nil, 0o644)
+	_ = os.WriteFile(first+".001.gz", nil, 0o644)
+	if got := backupName(path, at); got != first+".002" {
+		t.Fatalf("expected sequence 002, got %s", got)
+	}
+}
+
+func TestFileRotator_MaxTotalRemovesOldestBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := newFileRotator(path, 20, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.maxTotal = 50
+
+	for i := range 10 {
+		if _, err := fmt.Fprintf(r, "record %06d\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, _ := backups.List(path)
+	if len(files) != 2 {
+		t.Fatalf("expected 2 backups within the quota, got %v", files)
+	}
+	if data, _ := os.ReadFile(files[0]); string(data) != "record 000007\n" {
+		t.Fatalf("expected the newest backups to be kept, oldest is %q", data)
+	}
+	var total int64
+	for _, p := range append(files, path) {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total += info.Size()
+	}
+	if total > 50 {
+		t.Fatalf("expected at most 50 bytes on disk, got %d", total)
+	}
+}
+
+func TestFileRotator_PruneKeepsBackupsOverCrashMarker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	marker := path + crashMarkerSuffix
+	if err := os.WriteFile(marker, []byte(`{"reason":"fatal"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := newFileRotator(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.maxTotal = 30
+
+	for i := range 3 {
+		if _, err := fmt.Fprintf(r, "record %03d\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, _ := filepath.Glob(path + ".2*")
+	if len(backups) != 1 {
+		t.Fatalf("expected the newest backup to be kept, got %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != "record 001\n" {
+		t.Fatalf("expected the newest backup, got %q", data)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Fatalf("expected the crash marker to be left alone: %v", err)
+	}
+}
+
+func TestFileRotator_PruneKeepsNeighbouringErrorFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	errPath := path + ".err"
+	if err := os.WriteFile(errPath, []byte("error record\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := newFileRotator(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	for i := range 3 {
+		if _, err := fmt.Fprintf(r, "record %03d\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, _ := backups.List(path)
+	if len(files) != 1 {
+		t.Fatalf("expected one backup, got %v", files)
+	}
+	if data, _ := os.ReadFile(files[0]); string(data) != "record 001\n" {
+		t.Fatalf("expected the newest backup, got %q", data)
+	}
+	if data, _ := os.ReadFile(errPath); string(data) != "error record\n" {
+		t.Fatalf("expected the error file to be left alone, got %q", data)
+	}
+}
+
+func TestFileRotator_RotatesEveryPeriodAndCompresses(t *testing.T) {
+	day := time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+	now := day
+	SetClock(func() time.Time { return now })
+	defer SetClock(nil)
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	r, err := openRotator(path, Rotation{Every: 24 * time.Hour, MaxBackups: 365, Compress: true}, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	_, _ = r.Write([]byte("morning\n"))
+	now = day.Add(14 * time.Hour)
+	_, _ = r.Write([]byte("late evening\n"))
+	if backups, _ := filepath.Glob(path + ".*"); len(backups) != 0 {
+		t.Fatalf("expected no rotation within the day, got %v", backups)
+	}
+
+	now = day.Add(15 * time.Hour) // 00:00 the next day
+	_, _ = r.Write([]byte("next day\n"))
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 1 || !strings.HasSuffix(backups[0], ".gz") {
+		t.Fatalf("expected one compressed backup, got %v", backups)
+	}
+	f, err := os.Open(backups[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(zr); string(data) != "morning\nlate evening\n" {
+		t.Fatalf("unexpected backup content: %q", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "next day\n" {
+		t.Fatalf("unexpected current content: %q", data)
+	}
+	if info, _ := os.Stat(backups[0]); runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected backups to keep the file mode, got %v", info.Mode())
+	}
+}
+
+func TestFileRotator_ReopensAfterExternalRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	r, err := openRotator(path, Rotation{}, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.reopen = time.Nanosecond
+
+	_, _ = r.Write([]byte("before\n"))
+	// logrotate's create mode: rename, then the writer recreates the file
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = r.Write([]byte("after rename\n"))
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = r.Write([]byte("after delete\n"))
+
+	if data, _ := os.ReadFile(path + ".1"); string(data) != "before\n" {
+		t.Fatalf("unexpected rotated content: %q", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "after delete\n" {
+		t.Fatalf("unexpected current content: %q", data)
+	}
+}
+
+func TestFileRotator_ReopenRecoversAfterFailedRotation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("an open log file keeps its directory on Windows")
+	}
+	dir := filepath.Join(t.TempDir(), "logs")
+	path := filepath.Join(dir, "app.log")
+	r, err := openRotator(path, Rotation{MaxSizeBytes: 10}, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.reopen = time.Hour
+
+	_, _ = r.Write([]byte("before\n"))
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	// the rotation closes the file and cannot reopen it
+	if _, err := r.Write([]byte("lost record\n")); err == nil {
+		t.Fatal("expected the write to fail without the directory")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	r.nextCheck = time.Time{}
+	if _, err := r.Write([]byte("after\n")); err != nil {
+		t.Fatalf("expected writes to resume, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "after\n" {
+		t.Fatalf("unexpected current content: %q", data)
+	}
+}
+
+func TestConfigure_FileReopenInterval(t *testing.T) {
+	Reset()
+	defer Reset()
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := Configure(Config{Level: slog.LevelInfo, FilePath: path, FileReopenInterval: time.Nanosecond}); err != nil {
+		t.Fatal(err)
+	}
+	if o := Describe().Outputs[0]; o.ReopenInterval != "1ns" || o.Rotation != nil {
+		t.Fatalf("unexpected output: %+v", o)
+	}
+	Info("one")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	Info("two")
+	Reset()
+
+	data, _ := os.ReadFile(path)
+	assertContains(t, string(data), "msg=two")
+}
@@ -0,0 +1,67 @@
+package logx
+
+// sampled.go offers call-site sampling for very hot log statements, as a
+// lighter alternative to sampling in the logging pipeline.
+
+import (
+	"context"
+	"log/slog"
+)
+
+// sampleRand returns values in [0, 1); tests replace it.
+var sampleRand = RandFloat64
+
+// Sampler logs a random fraction of the records passed to it. Kept records
+// carry a "sample_rate" attr so counts can be scaled back up.
+type Sampler struct {
+	rate float64
+}
+
+// Sampled returns a Sampler that keeps a fraction rate (0..1) of records:
+//
+//	logx.Sampled(0.01).Info("cache hit", "key", k)
+//
+// A rate of 1 or more logs everything without the attr; 0 or less logs nothing.
+func Sampled(rate float64) Sampler {
+	return Sampler{rate: rate}
+}
+
+// Debug logs a sampled message at debug level.
+func (s Sampler) Debug(msg string, args ...any) {
+	s.log(context.Background(), slog.LevelDebug, msg, args)
+}
+
+// Info logs a sampled message at info level.
+func (s Sampler) Info(msg string, args ...any) {
+	s.log(context.Background(), slog.LevelInfo, msg, args)
+}
+
+// Warn logs a sampled message at warn level.
+func (s Sampler) Warn(msg string, args ...any) {
+	s.log(context.Background(), slog.LevelWarn, msg, args)
+}
+
+// Error logs a sampled message at error level.
+func (s Sampler) Error(msg string, args ...any) {
+	s.log(context.Background(), slog.LevelError, msg, args)
+}
+
+// Log logs a sampled message at level with ctx.
+func (s Sampler) Log(ctx context.Context, level slog.Level, msg string, args ...any) {
+	s.log(ctx, level, msg, args)
+}
+
+// log must be called directly from the exported methods so the record
+// source is their caller.
+func (s Sampler) log(ctx context.Context, level slog.Level, msg string, args []any) {
+	if s.rate <= 0 {
+		return
+	}
+	if s.rate < 1 {
+		if sampleRand() >= s.rate {
+			return
+		}
+		args = append(args[:len(args):len(args)], "sample_rate", s.rate)
+	}
+	logDepth(Logger(), ctx, 1, level, msg, args...)
+}
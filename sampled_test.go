@@ -0,0 +1,43 @@
+package logx
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSampled_KeepsFractionWithRate(t *testing.T) {
+	defer func(f func() float64) { sampleRand = f }(sampleRand)
+	rolls := []float64{0.05, 0.5, 0.09, 0.99}
+	sampleRand = func() float64 {
+		r := rolls[0]
+		rolls = rolls[1:]
+		return r
+	}
+
+	out := capture(t, slog.LevelInfo, func() {
+		for range 4 {
+			Sampled(0.1).Info("hot", "k", "v")
+		}
+	})
+
+	if n := strings.Count(out, "msg=hot"); n != 2 {
+		t.Fatalf("expected 2 sampled records, got %d: %s", n, out)
+	}
+	assertContains(t, out, "k=v sample_rate=0.1")
+}
+
+func TestSampled_Bounds(t *testing.T) {
+	out := capture(t, slog.LevelInfo, func() {
+		Sampled(0).Error("never")
+		Sampled(1).Warn("always")
+	})
+
+	if strings.Contains(out, "never") {
+		t.Fatalf("expected rate 0 to drop: %s", out)
+	}
+	assertContains(t, out, "msg=always")
+	if strings.Contains(out, "sample_rate") {
+		t.Fatalf("expected no sample_rate at rate 1: %s", out)
+	}
+}
@@ -0,0 +1,127 @@
+package logx
+
+// sanitize.go replaces invalid UTF-8 and control characters in messages and
+// string attr values, so binary data from external systems cannot corrupt
+// text output or confuse downstream parsers.
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type sanitizeHandler struct {
+	next         slog.Handler
+	keepNewlines bool
+}
+
+func newSanitizeHandler(next slog.Handler, keepNewlines bool) slog.Handler {
+	return &sanitizeHandler{next: next, keepNewlines: keepNewlines}
+}
+
+func (h *sanitizeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *sanitizeHandler) Handle(ctx context.Context, r slog.Record) error {
+	msg, changed := h.sanitize(r.Message)
+
+	attrs := getAttrs()
+	defer putAttrs(attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		a, ok := h.sanitizeAttr(a)
+		changed = changed || ok
+		*attrs = append(*attrs, a)
+		return true
+	})
+	if !changed {
+		return h.next.Handle(ctx, r)
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, msg, r.PC)
+	nr.AddAttrs(*attrs...)
+	return h.next.Handle(ctx, nr)
+}
+
+func (h *sanitizeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i], _ = h.sanitizeAttr(a)
+	}
+	return newSanitizeHandler(h.next.WithAttrs(out), h.keepNewlines)
+}
+
+func (h *sanitizeHandler) WithGroup(name string) slog.Handler {
+	return newSanitizeHandler(h.next.WithGroup(name), h.keepNewlines)
+}
+
+func (h *sanitizeHandler) sanitizeAttr(a slog.Attr) (slog.Attr, bool) {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		s, ok := h.sanitize(v.String())
+		if ok {
+			a.Value = slog.StringValue(s)
+		}
+		return a, ok
+	case slog.KindGroup:
+		group := v.Group()
+		out := make([]slog.Attr, len(group))
+		changed := false
+		for i, ga := range group {
+			var ok bool
+			out[i], ok = h.sanitizeAttr(ga)
+			changed = changed || ok
+		}
+		if changed {
+			a.Value = slog.GroupValue(out...)
+		}
+		return a, changed
+	}
+	return a, false
+}
+
+// sanitize replaces invalid UTF-8 sequences and control characters (other
+// than tab, and newline when keepNewlines is set) with U+FFFD.
+func (h *sanitizeHandler) sanitize(s string) (string, bool) {
+	if h.clean(s) {
+		return s, false
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 || !h.allowed(r) {
+			b.WriteRune(utf8.RuneError)
+		} else {
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String(), true
+}
+
+func (h *sanitizeHandler) clean(s string) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 || !h.allowed(r) {
+			return false
+		}
+		i += size
+	}
+	return true
+}
+
+func (h *sanitizeHandler) allowed(r rune) bool {
+	switch {
+	case r == '\t':
+		return true
+	case r == '\n':
+		return h.keepNewlines
+	default:
+		return !unicode.IsControl(r)
+	}
+}
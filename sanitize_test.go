@@ -0,0 +1,51 @@
+package logx
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestSanitizeHandler_ReplacesInvalidAndControl(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(newSanitizeHandler(slog.NewJSONHandler(&buf, nil), false))
+
+	l.Info("bad\x00msg", "bin", "a\xffb", "ok", "tab\there", slog.Group("g", "nl", "x\ny"))
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if rec["msg"] != "bad�msg" {
+		t.Fatalf("unexpected msg: %q", rec["msg"])
+	}
+	if rec["bin"] != "a�b" {
+		t.Fatalf("unexpected bin: %q", rec["bin"])
+	}
+	if rec["ok"] != "tab\there" {
+		t.Fatalf("tab should be kept: %q", rec["ok"])
+	}
+	if g := rec["g"].(map[string]any); g["nl"] != "x�y" {
+		t.Fatalf("unexpected nl: %q", g["nl"])
+	}
+}
+
+func TestSanitizeHandler_KeepNewlines(t *testing.T) {
+	h := &sanitizeHandler{keepNewlines: true}
+	if s, changed := h.sanitize("line1\nline2"); changed || s != "line1\nline2" {
+		t.Fatalf("newline should be kept, got %q", s)
+	}
+	if s, _ := h.sanitize("a\rb"); s != "a�b" {
+		t.Fatalf("carriage return should be replaced, got %q", s)
+	}
+}
+
+func TestSanitizeHandler_WithAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(newSanitizeHandler(slog.NewTextHandler(&buf, nil), false))
+
+	l.With("peer", "\x1b[31mred").Info("msg")
+
+	assertContains(t, buf.String(), `peer="�[31mred"`)
+}
@@ -0,0 +1,260 @@
+package logx
+
+// schedule.go implements planned level changes: one-off windows and
+// recurring daily windows that switch a level and revert it automatically.
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Leveler is a settable level such as *slog.LevelVar. Components with their
+// own LevelVar can be scheduled with ScheduleLevelVar. Implementations must
+// be comparable, such as pointers, since overlapping windows on the same
+// Leveler are tracked together.
+type Leveler interface {
+	Level() slog.Level
+	Set(slog.Level)
+}
+
+// globalLevel adapts the package level (SetLevel); as a comparable value
+// it tracks overlapping windows on the global level together.
+type globalLevel struct{}
+
+func (globalLevel) Level() slog.Level  { return levelVar.Level() }
+func (globalLevel) Set(lvl slog.Level) { SetLevel(lvl) }
+
+// ScheduleLevel sets the global level to level from from until to and then
+// restores the level that was active when the window opened. A from in the
+// past opens the window immediately. The returned cancel func stops the
+// schedule and reverts the level if the window is open.
+//
+// Windows may overlap, including those of ElevateLevel and
+// ScheduleRecurringLevel: the most recently opened one applies, and the
+// level is restored when the last one closes. A level set with SetLevel
+// while a window is open is kept when the windows close.
+func ScheduleLevel(level slog.Level, from, to time.Time) (cancel func()) {
+	return ScheduleLevelVar(globalLevel{}, level, from, to)
+}
+
+// ScheduleLevelVar is like ScheduleLevel for a component-specific level.
+func ScheduleLevelVar(v Leveler, level slog.Level, from, to time.Time) (cancel func()) {
+	w := &levelWindow{target: v, level: level}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.openTimer = time.AfterFunc(time.Until(from), w.open)
+	w.closeTimer = time.AfterFunc(time.Until(to), w.close)
+
+	return func() {
+		w.mu.Lock()
+		w.openTimer.Stop()
+		w.closeTimer.Stop()
+		w.mu.Unlock()
+		w.close()
+	}
+}
+
+// ElevateLevel lowers the global level to level for d, for example to log
+// DEBUG for five minutes while investigating, and then restores the level
+// that was active before. It takes effect before returning and never raises
+// the level. The returned cancel func restores the level early.
+func ElevateLevel(level slog.Level, d time.Duration) (cancel func()) {
+	return ElevateLevelVar(globalLevel{}, level, d)
+}
+
+// ElevateLevelVar is like ElevateLevel for a component-specific level.
+func ElevateLevelVar(v Leveler, level slog.Level, d time.Duration) (cancel func()) {
+	w := &levelWindow{target: v, level: min(level, v.Level())}
+	w.open()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeTimer = time.AfterFunc(d, w.close)
+	return func() {
+		w.mu.Lock()
+		w.closeTimer.Stop()
+		w.mu.Unlock()
+		w.close()
+	}
+}
+
+type levelWindow struct {
+	target Leveler
+	level  slog.Level
+
+	mu         sync.Mutex
+	openTimer  *time.Timer
+	closeTimer *time.Timer
+	active     bool
+	closed     bool
+}
+
+func (w *levelWindow) open() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.active || w.closed {
+		return
+	}
+	openOverride(w)
+	w.active = true
+}
+
+func (w *levelWindow) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	if !w.active {
+		return
+	}
+	closeOverride(w)
+	w.active = false
+}
+
+// levelOverride tracks the open windows of one target. Overlapping windows
+// apply the most recently opened one and restore base only when the last
+// one closes. A level set by anyone else while windows are open (detected
+// as a level other than applied) is kept: closing windows no longer touch
+// the target, and it becomes the level restored.
+type levelOverride struct {
+	base    slog.Level
+	applied slog.Level
+	open    []*levelWindow
+}
+
+var (
+	overridesMu sync.Mutex
+	overrides   = map[Leveler]*levelOverride{}
+)
+
+func openOverride(w *levelWindow) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	o := overrides[w.target]
+	if o == nil {
+		o = &levelOverride{base: w.target.Level()}
+		overrides[w.target] = o
+	} else if cur := w.target.Level(); cur != o.applied {
+		o.base = cur
+	}
+	o.open = append(o.open, w)
+	o.applied = w.level
+	w.target.Set(w.level)
+}
+
+func closeOverride(w *levelWindow) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	o := overrides[w.target]
+	if o == nil {
+		return
+	}
+	o.open = slices.DeleteFunc(o.open, func(x *levelWindow) bool { return x == w })
+	external := w.target.Level() != o.applied
+	if len(o.open) == 0 {
+		delete(overrides, w.target)
+		if !external {
+			w.target.Set(o.base)
+		}
+		return
+	}
+	if !external {
+		o.applied = o.open[len(o.open)-1].level
+		w.target.Set(o.applied)
+	}
+}
+
+// RecurringWindow is a daily window such as "02:00-04:00 on weekdays".
+type RecurringWindow struct {
+	// Start and End are offsets from midnight. End before Start spans midnight.
+	Start time.Duration
+	End   time.Duration
+	// Weekdays restricts the days on which the window opens (nil = every day).
+	Weekdays []time.Weekday
+	// Location is the time zone for Start and End (nil = time.Local).
+	Location *time.Location
+}
+
+// next returns the next window containing or following now.
+func (rw RecurringWindow) next(now time.Time) (time.Time, time.Time) {
+	loc := rw.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	now = now.In(loc)
+
+	length := rw.End - rw.Start
+	if length <= 0 {
+		length += 24 * time.Hour
+	}
+
+	// start one day back so a window spanning midnight is still found
+	y, m, d := now.AddDate(0, 0, -1).Date()
+	for i := 0; i < 9; i++ {
+		start := time.Date(y, m, d+i, 0, 0, 0, 0, loc).Add(rw.Start)
+		end := start.Add(length)
+		if !end.After(now) {
+			continue
+		}
+		if len(rw.Weekdays) > 0 && !slices.Contains(rw.Weekdays, start.Weekday()) {
+			continue
+		}
+		return start, end
+	}
+	return time.Time{}, time.Time{}
+}
+
+// ScheduleRecurringLevel sets the global level to level during every
+// occurrence of window and reverts it after each one. The returned cancel
+// func stops the schedule and reverts the level if a window is open.
+func ScheduleRecurringLevel(level slog.Level, window RecurringWindow) (cancel func()) {
+	return ScheduleRecurringLevelVar(globalLevel{}, level, window)
+}
+
+// ScheduleRecurringLevelVar is like ScheduleRecurringLevel for a
+// component-specific level.
+func ScheduleRecurringLevelVar(v Leveler, level slog.Level, window RecurringWindow) (cancel func()) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for {
+			start, end := window.next(time.Now())
+			if start.IsZero() {
+				return
+			}
+			if !sleepUntil(start, stop) {
+				return
+			}
+
+			w := &levelWindow{target: v, level: level}
+			w.open()
+			ok := sleepUntil(end, stop)
+			w.close()
+			if !ok {
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(stop) })
+		<-done
+	}
+}
+
+// sleepUntil waits until t and reports false if stop was closed first.
+func sleepUntil(t time.Time, stop <-chan struct{}) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-stop:
+		return false
+	}
+}
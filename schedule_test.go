@@ -0,0 +1,174 @@
+package logx
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func waitForLevel(t *testing.T, v Leveler, want slog.Level) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for v.Level() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected level %s, got %s", want, v.Level())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestScheduleLevel_AppliesAndReverts(t *testing.T) {
+	Reset()
+	defer Reset()
+	SetLevel(slog.LevelInfo)
+
+	now := time.Now()
+	cancel := ScheduleLevel(slog.LevelDebug, now.Add(10*time.Millisecond), now.Add(40*time.Millisecond))
+	defer cancel()
+
+	if levelVar.Level() != slog.LevelInfo {
+		t.Fatalf("expected level unchanged before window")
+	}
+	waitForLevel(t, globalLevel{}, slog.LevelDebug)
+	waitForLevel(t, globalLevel{}, slog.LevelInfo)
+}
+
+func TestScheduleLevelVar_CancelReverts(t *testing.T) {
+	var v slog.LevelVar
+	v.Set(slog.LevelWarn)
+
+	cancel := ScheduleLevelVar(&v, slog.LevelDebug, time.Now(), time.Now().Add(time.Hour))
+	waitForLevel(t, &v, slog.LevelDebug)
+
+	cancel()
+	if v.Level() != slog.LevelWarn {
+		t.Fatalf("expected cancel to revert level, got %s", v.Level())
+	}
+}
+
+func TestRecurringWindow_Next(t *testing.T) {
+	loc := time.UTC
+	w := RecurringWindow{
+		Start:    23 * time.Hour,
+		End:      1 * time.Hour,
+		Weekdays: []time.Weekday{time.Saturday},
+		Location: loc,
+	}
+
+	// Sunday 00:30 is inside Saturday's window that spans midnight.
+	now := time.Date(2024, 1, 7, 0, 30, 0, 0, loc)
+	start, end := w.next(now)
+	if !start.Equal(time.Date(2024, 1, 6, 23, 0, 0, 0, loc)) || !end.Equal(time.Date(2024, 1, 7, 1, 0, 0, 0, loc)) {
+		t.Fatalf("unexpected window: %v - %v", start, end)
+	}
+
+	// After it closes, the next one is the following Saturday.
+	start, _ = w.next(time.Date(2024, 1, 7, 2, 0, 0, 0, loc))
+	if !start.Equal(time.Date(2024, 1, 13, 23, 0, 0, 0, loc)) {
+		t.Fatalf("unexpected next start: %v", start)
+	}
+}
+
+func TestScheduleRecurringLevelVar_CancelRevertsOpenWindow(t *testing.T) {
+	var v slog.LevelVar
+	v.Set(slog.LevelInfo)
+
+	cancel := ScheduleRecurringLevelVar(&v, slog.LevelDebug, RecurringWindow{
+		Start: 0,
+		End:   24*time.Hour - time.Nanosecond,
+	})
+	waitForLevel(t, &v, slog.LevelDebug)
+
+	cancel()
+	if v.Level() != slog.LevelInfo {
+		t.Fatalf("expected cancel to revert level, got %s", v.Level())
+	}
+}
+
+func TestElevateLevel_AppliesImmediatelyAndRestores(t *testing.T) {
+	Reset()
+	defer Reset()
+	SetLevel(slog.LevelWarn)
+
+	ElevateLevel(slog.LevelDebug, 20*time.Millisecond)
+	if levelVar.Level() != slog.LevelDebug {
+		t.Fatalf("expected DEBUG right away, got %s", levelVar.Level())
+	}
+	waitForLevel(t, globalLevel{}, slog.LevelWarn)
+}
+
+func TestElevateLevelVar_CancelAndNeverRaises(t *testing.T) {
+	var v slog.LevelVar
+	v.Set(slog.LevelInfo)
+
+	cancel := ElevateLevelVar(&v, slog.LevelDebug, time.Hour)
+	if v.Level() != slog.LevelDebug {
+		t.Fatalf("expected DEBUG, got %s", v.Level())
+	}
+	cancel()
+	cancel()
+	if v.Level() != slog.LevelInfo {
+		t.Fatalf("expected INFO after cancel, got %s", v.Level())
+	}
+
+	v.Set(slog.LevelDebug)
+	cancel = ElevateLevelVar(&v, slog.LevelWarn, time.Hour)
+	defer cancel()
+	if v.Level() != slog.LevelDebug {
+		t.Fatalf("expected elevation to WARN not to raise DEBUG, got %s", v.Level())
+	}
+}
+
+func TestElevateLevelVar_OverlappingWindowsRestoreOnce(t *testing.T) {
+	var v slog.LevelVar
+	v.Set(slog.LevelInfo)
+
+	cancelA := ElevateLevelVar(&v, slog.LevelDebug, time.Hour)
+	cancelB := ElevateLevelVar(&v, slog.LevelDebug, time.Hour)
+	cancelA()
+	if v.Level() != slog.LevelDebug {
+		t.Fatalf("expected B to keep DEBUG after A closed, got %s", v.Level())
+	}
+	cancelB()
+	if v.Level() != slog.LevelInfo {
+		t.Fatalf("expected INFO after the last window closed, got %s", v.Level())
+	}
+}
+
+func TestScheduleLevelVar_OverlappingWindowsApplyNewest(t *testing.T) {
+	var v slog.LevelVar
+	v.Set(slog.LevelWarn)
+
+	cancelA := ScheduleLevelVar(&v, slog.LevelInfo, time.Now(), time.Now().Add(time.Hour))
+	waitForLevel(t, &v, slog.LevelInfo)
+	cancelB := ScheduleLevelVar(&v, slog.LevelDebug, time.Now(), time.Now().Add(time.Hour))
+	waitForLevel(t, &v, slog.LevelDebug)
+
+	cancelB()
+	if v.Level() != slog.LevelInfo {
+		t.Fatalf("expected A's level once B closed, got %s", v.Level())
+	}
+	cancelA()
+	if v.Level() != slog.LevelWarn {
+		t.Fatalf("expected WARN after both windows, got %s", v.Level())
+	}
+}
+
+func TestElevateLevel_KeepsLevelSetDuringWindow(t *testing.T) {
+	Reset()
+	defer Reset()
+	SetLevel(slog.LevelInfo)
+
+	cancel := ElevateLevel(slog.LevelDebug, time.Hour)
+	SetLevel(slog.LevelError)
+	cancel()
+	if levelVar.Level() != slog.LevelError {
+		t.Fatalf("expected the operator's level to be kept, got %s", levelVar.Level())
+	}
+
+	cancel = ElevateLevel(slog.LevelDebug, time.Hour)
+	cancel()
+	if levelVar.Level() != slog.LevelError {
+		t.Fatalf("expected the operator's level to be restored, got %s", levelVar.Level())
+	}
+}
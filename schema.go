@@ -0,0 +1,122 @@
+package logx
+
+// schema.go describes the keys, levels and application events of a
+// program's logs in a machine-readable form, so pipelines and dashboards
+// can be generated from code instead of reverse-engineered from output.
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+)
+
+// Schema is the result of ExportSchema. It marshals to JSON as is.
+type Schema struct {
+	Keys   []KeySchema   `json:"keys"`
+	Levels []LevelSchema `json:"levels"`
+	Events []EventSchema `json:"events,omitempty"`
+}
+
+// KeySchema describes a well-known attr key.
+type KeySchema struct {
+	// Key is the dotted attr path, e.g. "error_chain" or "http.status".
+	Key string `json:"key"`
+	// Type is a JSON type name: "string", "number", "boolean", "object",
+	// "array" or "time".
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// LevelSchema describes a level name and its numeric slog value.
+type LevelSchema struct {
+	Name  string `json:"name"`
+	Value int    `json:"value"`
+}
+
+// EventSchema describes an application log message and the attrs it is
+// expected to carry.
+type EventSchema struct {
+	// Message is the record message, which identifies the event.
+	Message string `json:"message"`
+	// Code is an optional stable identifier for alerting and dashboards.
+	Code string `json:"code,omitempty"`
+	// Level is the level the event is logged at.
+	Level    slog.Level `json:"level"`
+	Required []string   `json:"required,omitempty"`
+}
+
+// builtinKeys are the keys logx itself may add to records.
+var builtinKeys = []KeySchema{
+	{slog.TimeKey, "time", "record time"},
+	{slog.LevelKey, "string", "record level"},
+	{slog.MessageKey, "string", "record message"},
+	{slog.SourceKey, "object", "caller function, file and line (Config.AddSource)"},
+	{"error", "string", "error message (ErrorErr and variants)"},
+	{"error_type", "string", "Go type of the error"},
+	{"error_chain", "array", "wrapped errors as {message, type}"},
+	{"error_origin", "string", "function and line where a stack-carrying error was created"},
+	{"stack", "string", "stack trace (Config.StacktraceLevel; an array with StacktraceFrames)"},
+	{"panic", "string", "recovered panic value (RecoverAndLog)"},
+	{"duration", "string", "elapsed time (Timed)"},
+	{"sample_rate", "number", "fraction of records kept (Sampled)"},
+	{"truncated_keys", "array", "keys whose values were truncated (Config.MaxAttrValueBytes)"},
+	{"request_id", "string", "request identifier (WithRequestID, httpx middleware)"},
+}
+
+var (
+	schemaMu     sync.RWMutex
+	schemaKeys   = map[string]KeySchema{}
+	schemaEvents = map[string]EventSchema{}
+)
+
+// RegisterKey adds an application key to ExportSchema. Registering a key
+// again replaces its description.
+func RegisterKey(k KeySchema) {
+	schemaMu.Lock()
+	schemaKeys[k.Key] = k
+	schemaMu.Unlock()
+}
+
+// RegisterEvent adds an application event to ExportSchema. Like
+// RegisterSink it panics if the message is already registered.
+func RegisterEvent(e EventSchema) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	if _, dup := schemaEvents[e.Message]; dup {
+		panic("logx: RegisterEvent called twice for message " + e.Message)
+	}
+	schemaEvents[e.Message] = e
+}
+
+// ExportSchema returns logx's built-in keys, the levels, and the keys and
+// events registered with RegisterKey and RegisterEvent (sorted by key and
+// message):
+//
+//	json.NewEncoder(os.Stdout).Encode(logx.ExportSchema())
+func ExportSchema() Schema {
+	s := Schema{
+		Keys: append([]KeySchema(nil), builtinKeys...),
+		Levels: []LevelSchema{
+			{slog.LevelDebug.String(), int(slog.LevelDebug)},
+			{slog.LevelInfo.String(), int(slog.LevelInfo)},
+			{slog.LevelWarn.String(), int(slog.LevelWarn)},
+			{slog.LevelError.String(), int(slog.LevelError)},
+		},
+	}
+
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+
+	registered := make([]KeySchema, 0, len(schemaKeys))
+	for _, k := range schemaKeys {
+		registered = append(registered, k)
+	}
+	sort.Slice(registered, func(i, j int) bool { return registered[i].Key < registered[j].Key })
+	s.Keys = append(s.Keys, registered...)
+
+	for _, e := range schemaEvents {
+		s.Events = append(s.Events, e)
+	}
+	sort.Slice(s.Events, func(i, j int) bool { return s.Events[i].Message < s.Events[j].Message })
+	return s
+}
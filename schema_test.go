@@ -0,0 +1,37 @@
+package logx
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestExportSchema_BuiltinAndRegistered(t *testing.T) {
+	RegisterKey(KeySchema{Key: "tenant_id", Type: "string", Description: "customer tenant"})
+	msg := testSinkName("order placed")
+	RegisterEvent(EventSchema{Message: msg, Code: "ORD001", Level: slog.LevelInfo, Required: []string{"order_id"}})
+
+	data, err := json.Marshal(ExportSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+
+	assertContains(t, out, `{"key":"error_chain","type":"array"`)
+	assertContains(t, out, `{"key":"tenant_id","type":"string","description":"customer tenant"}`)
+	assertContains(t, out, `{"name":"WARN","value":4}`)
+	assertContains(t, out, `"code":"ORD001","level":"INFO","required":["order_id"]`)
+}
+
+func TestRegisterEvent_DuplicatePanics(t *testing.T) {
+	msg := testSinkName("dup event")
+	RegisterEvent(EventSchema{Message: msg})
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), msg) {
+			t.Fatalf("expected duplicate panic, got %v", r)
+		}
+	}()
+	RegisterEvent(EventSchema{Message: msg})
+}
@@ -0,0 +1,378 @@
+// Package sentryx forwards error records to Sentry as a logx.Hook, so an
+// error is logged and reported with a single call:
+//
+//	hook, err := sentryx.New(sentryx.Options{DSN: os.Getenv("SENTRY_DSN")})
+//	logx.Configure(logx.Config{Console: true, Hooks: []logx.Hook{hook}})
+//	defer hook.Close()
+//
+// Events are sent with Sentry's envelope HTTP API from a background
+// goroutine; no Sentry SDK is needed.
+package sentryx
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	mrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rannday/logx"
+)
+
+// Options configures a Sentry hook.
+type Options struct {
+	// DSN is the project's client key, e.g. https://key@o1.ingest.sentry.io/42.
+	DSN string
+	// Level is the minimum level forwarded (default slog.LevelError).
+	Level slog.Leveler
+	// SampleRate forwards this fraction (0 < rate < 1) of records; 0 or
+	// >= 1 forwards all of them.
+	SampleRate float64
+	// Rand returns values in [0, 1) for SampleRate (nil = math/rand/v2).
+	Rand func() float64
+	// Environment and Release are set on every event.
+	Environment string
+	Release     string
+	// BufferSize is the number of events waiting to be sent; events
+	// reported while it is full are dropped (default 100).
+	BufferSize int
+	// Client sends the events (nil = http.DefaultClient).
+	Client *http.Client
+}
+
+// ErrInvalidDSN reports a DSN that cannot be parsed.
+var ErrInvalidDSN = errors.New("sentryx: invalid DSN")
+
+// Hook is a logx.Hook reporting records to Sentry. Events are fingerprinted
+// by message and error type, so the same failure groups into one issue
+// regardless of the error text.
+type Hook struct {
+	opts     Options
+	endpoint string
+	auth     string
+
+	events  chan queued
+	done    chan struct{}
+	stopped chan struct{}
+	closing sync.Once
+
+	sent    atomic.Uint64
+	dropped atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// New starts a hook for opts.
+func New(opts Options) (*Hook, error) {
+	endpoint, key, err := parseDSN(opts.DSN)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Level == nil {
+		opts.Level = slog.LevelError
+	}
+	if opts.Rand == nil {
+		opts.Rand = mrand.Float64
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+	h := &Hook{
+		opts:     opts,
+		endpoint: endpoint,
+		auth:     "Sentry sentry_version=7, sentry_client=logx-sentryx/1.0, sentry_key=" + key,
+		events:   make(chan queued, opts.BufferSize),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go h.run()
+	return h, nil
+}
+
+// parseDSN returns the envelope endpoint and public key for dsn.
+func parseDSN(dsn string) (endpoint, key string, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrInvalidDSN, err)
+	}
+	project := path.Base(u.Path)
+	if u.User == nil || u.User.Username() == "" || u.Host == "" || project == "." || project == "/" {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidDSN, dsn)
+	}
+	prefix := strings.TrimSuffix(path.Dir(u.Path), "/")
+	endpoint = fmt.Sprintf("%s://%s%s/api/%s/envelope/", u.Scheme, u.Host, prefix, project)
+	return endpoint, u.User.Username(), nil
+}
+
+// Sent returns the number of events Sentry accepted.
+func (h *Hook) Sent() uint64 { return h.sent.Load() }
+
+// Dropped returns the number of events dropped because the buffer was full.
+func (h *Hook) Dropped() uint64 { return h.dropped.Load() }
+
+// Failed returns the number of events Sentry did not accept.
+func (h *Hook) Failed() uint64 { return h.failed.Load() }
+
+// Fire implements logx.Hook.
+func (h *Hook) Fire(ctx context.Context, r slog.Record) {
+	if r.Level < h.opts.Level.Level() {
+		return
+	}
+	if rate := h.opts.SampleRate; rate > 0 && rate < 1 && h.opts.Rand() >= rate {
+		return
+	}
+	ev := h.event(ctx, r)
+	b, err := json.Marshal(ev)
+	if err != nil {
+		h.failed.Add(1)
+		return
+	}
+	select {
+	case <-h.done:
+		h.dropped.Add(1)
+		return
+	default:
+	}
+	select {
+	case h.events <- queued{id: ev.EventID, body: b}:
+	default:
+		h.dropped.Add(1)
+	}
+}
+
+// Close sends queued events and stops the hook. It is safe to call more
+// than once.
+func (h *Hook) Close() error {
+	h.closing.Do(func() { close(h.done) })
+	<-h.stopped
+	return nil
+}
+
+type queued struct {
+	id   string
+	body []byte
+}
+
+type event struct {
+	EventID     string             `json:"event_id"`
+	Timestamp   string             `json:"timestamp"`
+	Platform    string             `json:"platform"`
+	Level       string             `json:"level"`
+	Logger      string             `json:"logger"`
+	Message     message            `json:"message"`
+	Environment string             `json:"environment,omitempty"`
+	Release     string             `json:"release,omitempty"`
+	Fingerprint []string           `json:"fingerprint"`
+	Tags        map[string]string  `json:"tags,omitempty"`
+	Extra       map[string]any     `json:"extra,omitempty"`
+	Exception   *values[exception] `json:"exception,omitempty"`
+	Threads     *values[thread]    `json:"threads,omitempty"`
+}
+
+type message struct {
+	Formatted string `json:"formatted"`
+}
+
+type values[T any] struct {
+	Values []T `json:"values"`
+}
+
+type exception struct {
+	Type       string      `json:"type"`
+	Value      string      `json:"value"`
+	Stacktrace *stacktrace `json:"stacktrace,omitempty"`
+}
+
+type thread struct {
+	Current    bool        `json:"current"`
+	Stacktrace *stacktrace `json:"stacktrace"`
+}
+
+type stacktrace struct {
+	Frames []frame `json:"frames"`
+}
+
+type frame struct {
+	Function string `json:"function"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+}
+
+// event converts r. Attrs are flattened into extra with dotted keys; error
+// attrs from logx.ErrorErr or logx.Err become the exception, structured
+// stacks (Config.StacktraceFrames) its stack trace, and request_id a tag.
+func (h *Hook) event(ctx context.Context, r slog.Record) event {
+	extra := map[string]any{}
+	r.Attrs(func(a slog.Attr) bool {
+		flatten(extra, "", a)
+		return true
+	})
+
+	ev := event{
+		EventID:     newEventID(),
+		Timestamp:   r.Time.UTC().Format(time.RFC3339Nano),
+		Platform:    "go",
+		Level:       "error",
+		Logger:      "logx",
+		Message:     message{Formatted: r.Message},
+		Environment: h.opts.Environment,
+		Release:     h.opts.Release,
+	}
+	switch {
+	case r.Level >= slog.LevelError+4:
+		ev.Level = "fatal"
+	case r.Level < slog.LevelWarn:
+		ev.Level = "info"
+	case r.Level < slog.LevelError:
+		ev.Level = "warning"
+	}
+
+	if id, ok := logx.RequestID(ctx); ok {
+		extra["request_id"] = id
+	}
+	if id, ok := take(extra, "request_id"); ok {
+		ev.Tags = map[string]string{"request_id": fmt.Sprint(id)}
+	}
+
+	var st *stacktrace
+	if frames, ok := extra["stack"].([]logx.StackFrame); ok {
+		delete(extra, "stack")
+		st = &stacktrace{}
+		// Sentry lists the outermost frame first
+		for _, f := range slices.Backward(frames) {
+			st.Frames = append(st.Frames, frame{Function: f.Function, AbsPath: f.File, Lineno: f.Line})
+		}
+	}
+
+	errType, _ := firstOf(extra, "error_type", "error.type")
+	errMsg, hasErr := firstOf(extra, "error", "error.message")
+	typ := ""
+	if errType != nil {
+		typ = fmt.Sprint(errType)
+	}
+	ev.Fingerprint = []string{r.Message, typ}
+	if hasErr {
+		if typ == "" {
+			typ = "error"
+		}
+		ev.Exception = &values[exception]{Values: []exception{{
+			Type:       typ,
+			Value:      fmt.Sprint(errMsg),
+			Stacktrace: st,
+		}}}
+	} else if st != nil {
+		ev.Threads = &values[thread]{Values: []thread{{Current: true, Stacktrace: st}}}
+	}
+	if len(extra) > 0 {
+		ev.Extra = extra
+	}
+	return ev
+}
+
+func take(m map[string]any, key string) (any, bool) {
+	v, ok := m[key]
+	delete(m, key)
+	return v, ok
+}
+
+func firstOf(m map[string]any, keys ...string) (any, bool) {
+	for _, k := range keys {
+		if v, ok := take(m, k); ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func flatten(m map[string]any, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			flatten(m, prefix, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	val := v.Any()
+	switch x := val.(type) {
+	case error:
+		val = x.Error()
+	case time.Duration:
+		val = x.String()
+	}
+	m[prefix+a.Key] = val
+}
+
+func newEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func (h *Hook) run() {
+	defer close(h.stopped)
+	for {
+		select {
+		case q := <-h.events:
+			h.send(q)
+		case <-h.done:
+			for {
+				select {
+				case q := <-h.events:
+					h.send(q)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// send posts one event envelope.
+func (h *Hook) send(q queued) {
+	var body bytes.Buffer
+	fmt.Fprintf(&body, `{"event_id":%q,"sent_at":%q}`+"\n", q.id, time.Now().UTC().Format(time.RFC3339))
+	fmt.Fprintf(&body, `{"type":"event","length":%d}`+"\n", len(q.body))
+	body.Write(q.body)
+	body.WriteByte('\n')
+
+	req, err := http.NewRequest(http.MethodPost, h.endpoint, &body)
+	if err != nil {
+		h.failed.Add(1)
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", h.auth)
+	resp, err := h.opts.Client.Do(req)
+	if err != nil {
+		h.failed.Add(1)
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		h.failed.Add(1)
+		return
+	}
+	h.sent.Add(1)
+}
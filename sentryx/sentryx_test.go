@@ -0,0 +1,136 @@
+package sentryx
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rannday/logx"
+)
+
+type sentryServer struct {
+	mu     sync.Mutex
+	events []map[string]any
+	auth   string
+	path   string
+}
+
+func newSentry(t *testing.T) (*sentryServer, string) {
+	ss := &sentryServer{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sc := bufio.NewScanner(r.Body)
+		sc.Buffer(nil, 1<<20)
+		var lines []string
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+		}
+		var ev map[string]any
+		if len(lines) != 3 || json.Unmarshal([]byte(lines[2]), &ev) != nil {
+			http.Error(w, "bad envelope", http.StatusBadRequest)
+			return
+		}
+		ss.mu.Lock()
+		ss.events = append(ss.events, ev)
+		ss.auth = r.Header.Get("X-Sentry-Auth")
+		ss.path = r.URL.Path
+		ss.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return ss, strings.Replace(srv.URL, "http://", "http://pubkey@", 1) + "/42"
+}
+
+func TestParseDSN(t *testing.T) {
+	endpoint, key, err := parseDSN("https://abc@o1.ingest.sentry.io/sub/42")
+	if err != nil || key != "abc" || endpoint != "https://o1.ingest.sentry.io/sub/api/42/envelope/" {
+		t.Fatalf("got %q %q %v", endpoint, key, err)
+	}
+	if _, _, err := parseDSN("https://o1.ingest.sentry.io/42"); !errors.Is(err, ErrInvalidDSN) {
+		t.Fatalf("expected ErrInvalidDSN without key, got %v", err)
+	}
+}
+
+func TestHook_ForwardsErrorRecords(t *testing.T) {
+	logx.Reset()
+	defer logx.Reset()
+
+	ss, dsn := newSentry(t)
+	hook, err := New(Options{DSN: dsn, Environment: "test"})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	err = logx.Configure(logx.Config{
+		FileWriter:       nopWriteCloser{},
+		Hooks:            []logx.Hook{hook},
+		StacktraceLevel:  slog.LevelError,
+		StacktraceFrames: true,
+	})
+	if err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+
+	logx.Logger().With("request_id", "r1").Error("charge failed", "error", errors.New("card declined"), "error_type", "*payments.Error", "amount", 12)
+	logx.Warn("not forwarded")
+	_ = hook.Close()
+
+	if len(ss.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(ss.events))
+	}
+	ev := ss.events[0]
+	if ss.path != "/api/42/envelope/" || !strings.Contains(ss.auth, "sentry_key=pubkey") {
+		t.Fatalf("unexpected request: %s %s", ss.path, ss.auth)
+	}
+	if ev["level"] != "error" || ev["environment"] != "test" {
+		t.Fatalf("unexpected event: %v", ev)
+	}
+	if fp := ev["fingerprint"].([]any); fp[0] != "charge failed" || fp[1] != "*payments.Error" {
+		t.Fatalf("unexpected fingerprint: %v", fp)
+	}
+	if tags := ev["tags"].(map[string]any); tags["request_id"] != "r1" {
+		t.Fatalf("expected request_id tag, got %v", tags)
+	}
+	exc := ev["exception"].(map[string]any)["values"].([]any)[0].(map[string]any)
+	if exc["value"] != "card declined" || exc["type"] != "*payments.Error" {
+		t.Fatalf("unexpected exception: %v", exc)
+	}
+	frames := exc["stacktrace"].(map[string]any)["frames"].([]any)
+	last := frames[len(frames)-1].(map[string]any)
+	if !strings.Contains(last["function"].(string), "TestHook_ForwardsErrorRecords") {
+		t.Fatalf("expected call site as innermost frame, got %v", last)
+	}
+	if extra := ev["extra"].(map[string]any); extra["amount"] != float64(12) {
+		t.Fatalf("unexpected extra: %v", extra)
+	}
+}
+
+func TestHook_Sampling(t *testing.T) {
+	ss, dsn := newSentry(t)
+	seq := []float64{0.1, 0.9, 0.2, 0.8}
+	hook, _ := New(Options{DSN: dsn, SampleRate: 0.5, Rand: func() float64 {
+		v := seq[0]
+		seq = seq[1:]
+		return v
+	}})
+
+	for range 4 {
+		var r slog.Record
+		r.Level = slog.LevelError
+		r.Message = "sampled"
+		hook.Fire(t.Context(), r)
+	}
+	_ = hook.Close()
+
+	if len(ss.events) != 2 || hook.Sent() != 2 {
+		t.Fatalf("expected 2 of 4 events sent, got %d", len(ss.events))
+	}
+}
+
+type nopWriteCloser struct{}
+
+func (nopWriteCloser) Write(p []byte) (int, error) { return len(p), nil }
+func (nopWriteCloser) Close() error                { return nil }
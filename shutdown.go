@@ -0,0 +1,130 @@
+package logx
+
+// shutdown.go provides an orderly Shutdown and detection of runs that ended
+// without one (killed, OOM, power loss), complementing crash markers.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rannday/logx/internal/backups"
+)
+
+// cleanSentinelSuffix is appended to Config.FilePath to name the sentinel
+// written by Shutdown.
+const cleanSentinelSuffix = backups.CleanSuffix
+
+// maxTailBytes bounds how much of the log file is read to find the last record.
+const maxTailBytes = 64 * 1024
+
+var cleanSentinelPath string // guarded by loggerMu
+
+// Shutdown flushes and closes the configured file writer, writes the
+// clean-shutdown sentinel when Config.DetectUncleanShutdown is set, and
+// uninstalls the logger. Logging after Shutdown is handled according to
+// the LateLogPolicy (by default a console logger is lazily installed).
+func Shutdown() error {
+	loggerMu.Lock()
+	closer := currentCloser
+	sentinel := cleanSentinelPath
+	logger.Store(nil)
+	lazyInit = new(sync.Once)
+	currentCloser = nil
+	currentDesc = Description{}
+	cleanSentinelPath = ""
+	crashMarkerPath = ""
+	recentBuf.Store(nil)
+	auditOut.Store(nil)
+	tornDownBy.Store(&byShutdown)
+	loggerMu.Unlock()
+
+	var err error
+	if closer != nil {
+		if s, ok := closer.(interface{ Sync() error }); ok {
+			err = s.Sync()
+		}
+		err = errors.Join(err, closer.Close())
+	}
+	if sentinel != "" {
+		stamp := []byte(time.Now().Format(time.RFC3339Nano) + "\n")
+		err = errors.Join(err, os.WriteFile(sentinel, stamp, 0o644))
+	}
+	return err
+}
+
+// checkCleanShutdown reports whether the previous run that wrote to logPath
+// ended without Shutdown and, if so, the time of its last record. The
+// sentinel is consumed so the current run must write its own.
+func checkCleanShutdown(logPath, sentinel string) (unclean bool, last time.Time) {
+	_, err := os.Stat(sentinel)
+	if err == nil {
+		_ = os.Remove(sentinel)
+		return false, time.Time{}
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return false, time.Time{}
+	}
+
+	// no previous run to judge
+	info, err := os.Stat(logPath)
+	if err != nil || info.Size() == 0 {
+		return false, time.Time{}
+	}
+	return true, lastRecordTime(logPath)
+}
+
+// lastRecordTime returns the timestamp of the last JSON or text record in
+// the file, or the zero time if it cannot be determined.
+func lastRecordTime(path string) time.Time {
+	f, err := os.Open(path)
+	if err != nil {
+		return time.Time{}
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return time.Time{}
+	}
+	off := max(info.Size()-maxTailBytes, 0)
+	tail := make([]byte, info.Size()-off)
+	if _, err := f.ReadAt(tail, off); err != nil && !errors.Is(err, io.EOF) {
+		return time.Time{}
+	}
+
+	lines := bytes.Split(bytes.TrimRight(tail, "\n"), []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		if t, ok := recordTime(lines[i]); ok {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func recordTime(line []byte) (time.Time, bool) {
+	if len(line) > 0 && line[0] == '{' {
+		var rec struct {
+			Time time.Time `json:"time"`
+		}
+		if json.Unmarshal(line, &rec) == nil && !rec.Time.IsZero() {
+			return rec.Time, true
+		}
+		return time.Time{}, false
+	}
+
+	s, ok := strings.CutPrefix(string(line), slog.TimeKey+"=")
+	if !ok {
+		return time.Time{}, false
+	}
+	s, _, _ = strings.Cut(s, " ")
+	t, err := time.Parse(time.RFC3339Nano, s)
+	return t, err == nil
+}
@@ -0,0 +1,96 @@
+package logx
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetectUncleanShutdown(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	cfg := Config{Level: slog.LevelInfo, FilePath: path, JSONFile: true, DetectUncleanShutdown: true}
+
+	// first run: no previous log, nothing to report
+	if err := Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	Info("serving")
+	// same-file reconfigure within a run is not a restart
+	if err := Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	Reset() // simulate a kill: no Shutdown, no sentinel
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "not clean") {
+		t.Fatalf("unexpected warning before restart: %s", data)
+	}
+
+	// second run sees the missing sentinel
+	if err := Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ = os.ReadFile(path)
+	assertContains(t, string(data), `"msg":"previous shutdown was not clean"`)
+	assertContains(t, string(data), `"last_record":`)
+	if _, err := os.Stat(path + cleanSentinelSuffix); err != nil {
+		t.Fatalf("expected sentinel after Shutdown: %v", err)
+	}
+
+	// third run after a clean Shutdown: no warning, sentinel consumed
+	if err := Configure(cfg); err != nil {
+		t.Fatal(err)
+	}
+	Reset()
+
+	data, _ = os.ReadFile(path)
+	if n := strings.Count(string(data), "not clean"); n != 1 {
+		t.Fatalf("expected exactly one warning, got %d", n)
+	}
+	if _, err := os.Stat(path + cleanSentinelSuffix); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected sentinel to be consumed, got %v", err)
+	}
+}
+
+func TestShutdown_UninstallsLogger(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	if err := Configure(Config{FilePath: filepath.Join(t.TempDir(), "app.log")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	if Describe().Configured {
+		t.Fatalf("expected logger to be uninstalled")
+	}
+}
+
+func TestRecordTime(t *testing.T) {
+	want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, line := range []string{
+		`{"time":"2026-01-02T03:04:05Z","level":"INFO","msg":"x"}`,
+		`time=2026-01-02T03:04:05Z level=INFO msg=x`,
+	} {
+		got, ok := recordTime([]byte(line))
+		if !ok || !got.Equal(want) {
+			t.Fatalf("recordTime(%q) = %v, %v", line, got, ok)
+		}
+	}
+	if _, ok := recordTime([]byte("garbage")); ok {
+		t.Fatalf("expected no time for garbage line")
+	}
+}
@@ -0,0 +1,144 @@
+package logx
+
+// sink.go lets applications plug their own destinations into Configure by
+// name, so declarative configuration can reference them without changes to
+// buildLogger.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SinkFactory builds a handler for a registered sink. opts carries the
+// HandlerOptions logx uses for its own outputs (level, source); options is
+// the SinkConfig.Options map. The returned closer, if non-nil, is closed
+// when the logger is replaced or shut down.
+type SinkFactory func(opts *slog.HandlerOptions, options map[string]any) (slog.Handler, io.Closer, error)
+
+// SinkConfig references a registered sink from Config.Sinks.
+type SinkConfig struct {
+	// Name is the name passed to RegisterSink.
+	Name string
+	// Options is passed to the factory as is.
+	Options map[string]any
+	// Attrs selects the attr keys written to this sink.
+	Attrs AttrFilter
+	// Keys flattens or nests attr keys for this sink.
+	Keys KeyLayout
+	// Levels is installed as opts.ReplaceAttr for the factory; it takes
+	// effect for sinks built on slog's handlers or that honor ReplaceAttr.
+	Levels LevelMapper
+	// WriteTimeout bounds how long the sink may block a record (see
+	// Config.ConsoleWriteTimeout).
+	WriteTimeout time.Duration
+	// QueueSize gives the sink a queue of this many records, written by its
+	// own goroutine, so logging calls and the other outputs never wait for
+	// it. Records that do not fit are dropped, reported as ErrQueueFull and
+	// counted by QueueDropped; Shutdown and Configure write the queued ones
+	// before closing the sink (0 = written by the logging call).
+	QueueSize int
+	// MinLevel drops records below this level from the sink (see
+	// Config.ConsoleMinLevel).
+	MinLevel slog.Leveler
+	// MaxAttrValueBytes overrides Config.MaxAttrValueBytes for this sink
+	// (0 = inherit, negative = unlimited).
+	MaxAttrValueBytes int
+}
+
+// ErrUnknownSink reports that Config.Sinks names a sink that was not registered.
+var ErrUnknownSink = errors.New("logx: unknown sink")
+
+// ErrSinkInit reports that a registered sink factory failed.
+var ErrSinkInit = errors.New("logx: init sink")
+
+var (
+	sinksMu sync.RWMutex
+	sinks   = map[string]SinkFactory{}
+)
+
+// RegisterSink makes a sink factory available to Config.Sinks under name.
+// Like database/sql.Register it panics if factory is nil or name is
+// already registered.
+func RegisterSink(name string, factory SinkFactory) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	if factory == nil {
+		panic("logx: RegisterSink factory is nil")
+	}
+	if _, dup := sinks[name]; dup {
+		panic("logx: RegisterSink called twice for sink " + name)
+	}
+	sinks[name] = factory
+}
+
+// RegisteredSinks returns the sorted names of registered sinks.
+func RegisteredSinks() []string {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	names := make([]string, 0, len(sinks))
+	for name := range sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// buildSink creates the handler for one SinkConfig.
+func buildSink(sc SinkConfig, opts *slog.HandlerOptions) (slog.Handler, io.Closer, error) {
+	sinksMu.RLock()
+	factory, ok := sinks[sc.Name]
+	sinksMu.RUnlock()
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnknownSink, sc.Name)
+	}
+
+	h, c, err := factory(opts, sc.Options)
+	if err != nil {
+		if c != nil {
+			_ = c.Close()
+		}
+		return nil, nil, fmt.Errorf("%w %q: %w", ErrSinkInit, sc.Name, err)
+	}
+	if h == nil {
+		return nil, c, fmt.Errorf("%w %q: factory returned nil handler", ErrSinkInit, sc.Name)
+	}
+	return h, c, nil
+}
+
+// joinClosers returns a closer for a and b; a may be nil.
+func joinClosers(a, b io.Closer) io.Closer {
+	switch m := a.(type) {
+	case nil:
+		return b
+	case multiCloser:
+		return append(m, b)
+	default:
+		return multiCloser{a, b}
+	}
+}
+
+// multiCloser closes (and syncs) several writers in order.
+type multiCloser []io.Closer
+
+func (m multiCloser) Sync() error {
+	var errs []error
+	for _, c := range m {
+		if s, ok := c.(interface{ Sync() error }); ok {
+			errs = append(errs, s.Sync())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m multiCloser) Close() error {
+	var errs []error
+	for _, c := range m {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,106 @@
+package logx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+// testSinkName returns a name unique within the process, since the sink
+// registry cannot be cleared (go test -count=N reruns in one process).
+func testSinkName(base string) string {
+	sinkSeq++
+	return fmt.Sprintf("%s-%d", base, sinkSeq)
+}
+
+var sinkSeq int
+
+type closeCounter struct{ closed int }
+
+func (c *closeCounter) Close() error { c.closed++; return nil }
+
+func TestRegisterSink_ConfigureByName(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	closer := &closeCounter{}
+	var gotPrefix any
+	name := testSinkName("test-buffer")
+	RegisterSink(name, func(opts *slog.HandlerOptions, options map[string]any) (slog.Handler, io.Closer, error) {
+		gotPrefix = options["prefix"]
+		return slog.NewTextHandler(&buf, opts), closer, nil
+	})
+
+	if !slices.Contains(RegisteredSinks(), name) {
+		t.Fatalf("expected sink to be listed, got %v", RegisteredSinks())
+	}
+
+	err := Configure(Config{
+		Level: slog.LevelInfo,
+		Sinks: []SinkConfig{{Name: name, Options: map[string]any{"prefix": "p"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Debug("hidden")
+	Info("hello", "k", "v")
+
+	if gotPrefix != "p" {
+		t.Fatalf("options not passed to factory: %v", gotPrefix)
+	}
+	assertContains(t, buf.String(), "msg=hello k=v")
+	if bytes.Contains(buf.Bytes(), []byte("hidden")) {
+		t.Fatalf("sink should honor the global level")
+	}
+
+	d := Describe()
+	if len(d.Outputs) != 1 || d.Outputs[0].Kind != "sink" || d.Outputs[0].Target != name {
+		t.Fatalf("unexpected outputs: %+v", d.Outputs)
+	}
+
+	Reset()
+	if closer.closed != 1 {
+		t.Fatalf("expected sink closer to be closed once, got %d", closer.closed)
+	}
+}
+
+func TestConfigure_UnknownAndFailingSinks(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	failing := testSinkName("test-failing")
+	RegisterSink(failing, func(*slog.HandlerOptions, map[string]any) (slog.Handler, io.Closer, error) {
+		return nil, nil, errors.New("endpoint unreachable")
+	})
+
+	res, err := ConfigureWithResult(Config{
+		Sinks: []SinkConfig{{Name: "test-missing"}, {Name: failing}},
+	})
+	if !errors.Is(err, ErrUnknownSink) || !errors.Is(err, ErrSinkInit) {
+		t.Fatalf("expected ErrUnknownSink and ErrSinkInit, got %v", err)
+	}
+	if !res.Degraded || len(res.Outputs) != 1 || !res.Outputs[0].Fallback {
+		t.Fatalf("expected stderr fallback, got %+v", res)
+	}
+}
+
+func TestRegisterSink_DuplicatePanics(t *testing.T) {
+	factory := func(*slog.HandlerOptions, map[string]any) (slog.Handler, io.Closer, error) {
+		return &passthroughHandler{}, nil, nil
+	}
+	name := testSinkName("test-dup")
+	RegisterSink(name, factory)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic on duplicate registration")
+		}
+	}()
+	RegisterSink(name, factory)
+}
@@ -0,0 +1,92 @@
+package logx
+
+// sinkerr.go surfaces output write failures. slog.Logger discards the error
+// returned by Handle, so without this a full disk or closed pipe silently
+// drops records.
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+)
+
+var (
+	errorHandler atomic.Pointer[func(error)]
+	writeErrors  atomic.Uint64
+)
+
+// OutputError is the error reported to SetErrorHandler, and returned by the
+// logger's Handle, when an output fails to write a record.
+type OutputError struct {
+	// Output names the output: "console", "file", "writer", a sink name or
+	// a Config.Files path.
+	Output string
+	Err    error
+}
+
+func (e *OutputError) Error() string {
+	return fmt.Sprintf("logx: %s output: %v", e.Output, e.Err)
+}
+
+func (e *OutputError) Unwrap() error {
+	return e.Err
+}
+
+// SetErrorHandler registers fn to be called whenever an output fails to
+// write a record, with an *OutputError naming the output. fn runs synchronously on the logging
+// goroutine, or on the output's goroutine for a queued write (see
+// SinkConfig.QueueSize), and must not log through logx. Pass nil to remove
+// the handler.
+func SetErrorHandler(fn func(error)) {
+	if fn == nil {
+		errorHandler.Store(nil)
+		return
+	}
+	errorHandler.Store(&fn)
+}
+
+// WriteErrors returns the number of failed output writes since the process
+// started or the last Reset.
+func WriteErrors() uint64 {
+	return writeErrors.Load()
+}
+
+func reportWriteError(err *OutputError) {
+	writeErrors.Add(1)
+	if fn := errorHandler.Load(); fn != nil {
+		(*fn)(err)
+	}
+}
+
+// sinkErrHandler reports Handle errors of a single output and returns them
+// as an *OutputError.
+type sinkErrHandler struct {
+	next   slog.Handler
+	output string
+}
+
+func newSinkErrHandler(next slog.Handler, output string) slog.Handler {
+	return &sinkErrHandler{next: next, output: output}
+}
+
+func (h *sinkErrHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *sinkErrHandler) Handle(ctx context.Context, r slog.Record) error {
+	if err := h.next.Handle(ctx, r); err != nil {
+		oerr := &OutputError{Output: h.output, Err: err}
+		reportWriteError(oerr)
+		return oerr
+	}
+	return nil
+}
+
+func (h *sinkErrHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return newSinkErrHandler(h.next.WithAttrs(attrs), h.output)
+}
+
+func (h *sinkErrHandler) WithGroup(name string) slog.Handler {
+	return newSinkErrHandler(h.next.WithGroup(name), h.output)
+}
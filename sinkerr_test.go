@@ -0,0 +1,103 @@
+package logx
+
+import (
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type failingWriteCloser struct{ err error }
+
+func (w failingWriteCloser) Write(p []byte) (int, error) { return 0, w.err }
+func (w failingWriteCloser) Close() error                { return nil }
+
+func TestSetErrorHandler_ReportsOutputFailures(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	diskFull := errors.New("no space left on device")
+	var got []error
+	SetErrorHandler(func(err error) { got = append(got, err) })
+
+	if err := Configure(Config{
+		Level:      slog.LevelInfo,
+		FileWriter: failingWriteCloser{err: diskFull},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	Info("one")
+	Info("two")
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 reported errors, got %d", len(got))
+	}
+	if !errors.Is(got[0], diskFull) {
+		t.Fatalf("expected wrapped write error, got %v", got[0])
+	}
+	var oerr *OutputError
+	if !errors.As(got[0], &oerr) || oerr.Output != "writer" {
+		t.Fatalf("expected an OutputError for the writer, got %#v", got[0])
+	}
+	assertContains(t, got[0].Error(), "logx: writer output: no space left on device")
+	if n := WriteErrors(); n != 2 {
+		t.Fatalf("expected WriteErrors=2, got %d", n)
+	}
+}
+
+func TestWriteErrors_CountsWithoutHandler(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	h := newSinkErrHandler(&errHandler{err: errors.New("closed pipe")}, "console")
+	_ = slog.New(h).With("k", "v").Handler().Handle(t.Context(), slog.Record{})
+
+	if n := WriteErrors(); n != 1 {
+		t.Fatalf("expected WriteErrors=1, got %d", n)
+	}
+
+	Reset()
+	if n := WriteErrors(); n != 0 {
+		t.Fatalf("expected Reset to clear counter, got %d", n)
+	}
+}
+
+func TestHandle_IdentifiesEveryFailingOutput(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var got []error
+	SetErrorHandler(func(err error) { got = append(got, err) })
+	defer SetErrorHandler(nil)
+
+	path := filepath.Join(t.TempDir(), "extra.log")
+	console := failingWriteCloser{err: errors.New("broken pipe")}
+	if err := Configure(Config{
+		Level:         slog.LevelInfo,
+		Console:       true,
+		ConsoleWriter: console,
+		FileWriter:    failingWriteCloser{err: errors.New("no space left on device")},
+		Files:         []FileConfig{{Path: path}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := Logger().Handler().Handle(t.Context(), slog.NewRecord(time.Now(), slog.LevelInfo, "m", 0))
+	var outputs []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var oerr *OutputError
+		if !errors.As(e, &oerr) {
+			t.Fatalf("expected an OutputError, got %v", e)
+		}
+		outputs = append(outputs, oerr.Output)
+	}
+	if strings.Join(outputs, ",") != "console,writer" {
+		t.Fatalf("expected the console and writer to fail, got %v", outputs)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 reported errors, got %v", got)
+	}
+}
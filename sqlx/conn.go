@@ -0,0 +1,183 @@
+package sqlx
+
+// conn.go wraps driver connections and statements. Optional driver
+// interfaces are always implemented by the wrappers and fall back to
+// driver.ErrSkip (or a no-op) when the wrapped driver lacks them, so
+// database/sql keeps using its generic code paths.
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/rannday/logx"
+)
+
+var (
+	errNamedUnsupported  = errors.New("sqlx: driver does not support named parameters")
+	errTxOptsUnsupported = errors.New("sqlx: driver does not support non-default transaction options")
+)
+
+type conn struct {
+	driver.Conn
+	opts Options
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := logx.Now()
+	res, err := execer.ExecContext(ctx, query, args)
+	c.opts.logQuery(ctx, "sql exec", query, args, start, res, err)
+	return res, err
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	start := logx.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	c.opts.logQuery(ctx, "sql query", query, args, start, nil, err)
+	return rows, err
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var (
+		s   driver.Stmt
+		err error
+	)
+	if p, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		s, err = p.PrepareContext(ctx, query)
+	} else {
+		s, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{Stmt: s, conn: c.Conn, query: query, opts: c.opts}, nil
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if b, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return b.BeginTx(ctx, opts)
+	}
+	// same restrictions database/sql applies to legacy drivers
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errTxOptsUnsupported
+	}
+	return c.Conn.Begin()
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type stmt struct {
+	driver.Stmt
+	conn  driver.Conn
+	query string
+	opts  Options
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	start := logx.Now()
+
+	var (
+		res driver.Result
+		err error
+	)
+	if e, ok := s.Stmt.(driver.StmtExecContext); ok {
+		res, err = e.ExecContext(ctx, args)
+	} else {
+		var vals []driver.Value
+		if vals, err = namedToValues(args); err == nil {
+			res, err = s.Stmt.Exec(vals)
+		}
+	}
+
+	s.opts.logQuery(ctx, "sql exec", s.query, args, start, res, err)
+	return res, err
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	start := logx.Now()
+
+	var (
+		rows driver.Rows
+		err  error
+	)
+	if q, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = q.QueryContext(ctx, args)
+	} else {
+		var vals []driver.Value
+		if vals, err = namedToValues(args); err == nil {
+			rows, err = s.Stmt.Query(vals)
+		}
+	}
+
+	s.opts.logQuery(ctx, "sql query", s.query, args, start, nil, err)
+	return rows, err
+}
+
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if nvc, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	if cc, ok := s.Stmt.(driver.ColumnConverter); ok {
+		v, err := cc.ColumnConverter(nv.Ordinal - 1).ConvertValue(nv.Value)
+		if err != nil {
+			return err
+		}
+		nv.Value = v
+		return nil
+	}
+	if nvc, ok := s.conn.(driver.NamedValueChecker); ok {
+		return nvc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func namedToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	vals := make([]driver.Value, len(args))
+	for i, a := range args {
+		if a.Name != "" {
+			return nil, errNamedUnsupported
+		}
+		vals[i] = a.Value
+	}
+	return vals, nil
+}
@@ -0,0 +1,168 @@
+// Package sqlx provides a database/sql driver wrapper that logs queries
+// through github.com/rannday/logx.
+package sqlx
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/rannday/logx"
+)
+
+// Options controls query logging.
+type Options struct {
+	// Logger is used for query records. If nil, the context logger or the
+	// package global logger is used (see logx.LoggerFromContext).
+	Logger *slog.Logger
+	// Level is the level for successful, fast queries (zero value = Info).
+	Level slog.Level
+	// SlowThreshold logs queries that take at least this long at warn level
+	// with slow=true (0 = disabled).
+	SlowThreshold time.Duration
+	// RedactArgs lists named parameters (sql.Named) whose values are masked,
+	// in addition to the keys configured with logx.SetRedactedKeys.
+	RedactArgs []string
+	// HideArgs omits query arguments from records entirely.
+	HideArgs bool
+}
+
+// WrapDriver wraps d so that connections opened through it log queries.
+// Register the result under a new name with sql.Register.
+func WrapDriver(d driver.Driver, opts Options) driver.Driver {
+	return &loggingDriver{d: d, opts: opts}
+}
+
+// WrapConnector wraps c so that connections it opens log queries.
+// Use the result with sql.OpenDB.
+func WrapConnector(c driver.Connector, opts Options) driver.Connector {
+	return &loggingConnector{c: c, drv: WrapDriver(c.Driver(), opts), opts: opts}
+}
+
+type loggingDriver struct {
+	d    driver.Driver
+	opts Options
+}
+
+func (d *loggingDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.d.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: c, opts: d.opts}, nil
+}
+
+func (d *loggingDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.d.(driver.DriverContext); ok {
+		c, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &loggingConnector{c: c, drv: d, opts: d.opts}, nil
+	}
+	return &dsnConnector{name: name, drv: d}, nil
+}
+
+type loggingConnector struct {
+	c    driver.Connector
+	drv  driver.Driver
+	opts Options
+}
+
+func (c *loggingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	cn, err := c.c.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: cn, opts: c.opts}, nil
+}
+
+func (c *loggingConnector) Driver() driver.Driver {
+	return c.drv
+}
+
+// dsnConnector adapts drivers without DriverContext, mirroring database/sql.
+type dsnConnector struct {
+	name string
+	drv  *loggingDriver
+}
+
+func (c *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.drv.Open(c.name)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.drv
+}
+
+// logQuery emits one record for a completed exec or query.
+func (o Options) logQuery(ctx context.Context, msg, query string, args []driver.NamedValue, start time.Time, result driver.Result, err error) {
+	if errors.Is(err, driver.ErrSkip) {
+		return
+	}
+
+	l := o.Logger
+	if l == nil {
+		l = logx.LoggerFromContext(ctx)
+	}
+
+	duration := logx.Since(start)
+	slow := o.SlowThreshold > 0 && duration >= o.SlowThreshold
+
+	level := o.Level
+	switch {
+	case err != nil:
+		level = slog.LevelError
+	case slow:
+		level = max(level, slog.LevelWarn)
+	}
+	if !l.Enabled(ctx, level) {
+		return
+	}
+
+	fields := []any{
+		"query", query,
+		"duration", duration,
+	}
+	if !o.HideArgs && len(args) > 0 {
+		fields = append(fields, "args", o.redactArgs(args))
+	}
+	if result != nil {
+		if n, rerr := result.RowsAffected(); rerr == nil {
+			fields = append(fields, "rows_affected", n)
+		}
+	}
+	if slow {
+		fields = append(fields, "slow", true)
+	}
+	if err != nil {
+		fields = append(fields, "error", err)
+	}
+
+	l.Log(ctx, level, msg, fields...)
+}
+
+func (o Options) redactArgs(args []driver.NamedValue) []any {
+	keys := make(map[string]struct{}, len(o.RedactArgs))
+	for _, k := range o.RedactArgs {
+		keys[strings.ToLower(k)] = struct{}{}
+	}
+	for _, k := range logx.ListRedactedKeys() {
+		keys[k] = struct{}{}
+	}
+
+	out := make([]any, len(args))
+	for i, a := range args {
+		out[i] = a.Value
+		if a.Name == "" {
+			continue
+		}
+		if _, ok := keys[strings.ToLower(a.Name)]; ok {
+			out[i] = "REDACTED"
+		}
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package sqlx
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rannday/logx"
+)
+
+type fakeDriver struct{ delay time.Duration }
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{delay: d.delay}, nil }
+
+type fakeConn struct{ delay time.Duration }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	time.Sleep(c.delay)
+	if strings.Contains(query, "FAIL") {
+		return nil, errors.New("syntax error")
+	}
+	return driver.RowsAffected(3), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct{}
+
+func (r *fakeRows) Columns() []string              { return []string{"n"} }
+func (r *fakeRows) Close() error                   { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+func openDB(t *testing.T, d driver.Driver, opts Options) (*sql.DB, *bytes.Buffer) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	opts.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+
+	c, err := WrapDriver(d, opts).(driver.DriverContext).OpenConnector("")
+	if err != nil {
+		t.Fatalf("open connector failed: %v", err)
+	}
+	db := sql.OpenDB(c)
+	t.Cleanup(func() { db.Close() })
+	return db, &buf
+}
+
+func TestWrapDriver_LogsExecWithRowsAndRedactedArgs(t *testing.T) {
+	logx.ClearRedactedKeys()
+	defer logx.ClearRedactedKeys()
+	logx.SetRedactedKeys("token")
+
+	db, buf := openDB(t, &fakeDriver{}, Options{RedactArgs: []string{"password"}})
+
+	_, err := db.Exec("UPDATE users SET password = @password, token = @token WHERE id = @id",
+		sql.Named("password", "hunter2"),
+		sql.Named("token", "abc"),
+		sql.Named("id", 42),
+	)
+	if err != nil {
+		t.Fatalf("exec failed: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "msg=\"sql exec\"") || !strings.Contains(out, "rows_affected=3") {
+		t.Fatalf("expected exec record with rows affected, got: %s", out)
+	}
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "abc") {
+		t.Fatalf("expected named args to be redacted, got: %s", out)
+	}
+	if !strings.Contains(out, "42") {
+		t.Fatalf("expected non-sensitive args to be logged, got: %s", out)
+	}
+}
+
+func TestWrapDriver_SlowAndFailedQueries(t *testing.T) {
+	db, buf := openDB(t, &fakeDriver{delay: 2 * time.Millisecond}, Options{SlowThreshold: time.Millisecond})
+
+	_, _ = db.Exec("SELECT 1")
+	_, _ = db.Exec("FAIL")
+	rows, err := db.Query("SELECT n FROM t")
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	rows.Close()
+
+	out := buf.String()
+	if !strings.Contains(out, "level=WARN") || !strings.Contains(out, "slow=true") {
+		t.Fatalf("expected slow query warning, got: %s", out)
+	}
+	if !strings.Contains(out, "level=ERROR") || !strings.Contains(out, "syntax error") {
+		t.Fatalf("expected failed query error, got: %s", out)
+	}
+	if !strings.Contains(out, "msg=\"sql query\"") {
+		t.Fatalf("expected query record, got: %s", out)
+	}
+}
@@ -2,16 +2,158 @@ package logx
 
 // stack.go provides a slog.Handler that appends truncated stack traces
 // to log records when the record level is at or above the configured level.
+// Stacks start at the logging call site; frames of logx, log/slog and the
+// runtime above it are dropped.
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
-	"runtime/debug"
+	"reflect"
+	"runtime"
+	"strings"
 )
 
 type stackHandler struct {
 	next  slog.Handler
 	level slog.Level
+	// base is next without open groups, so that "stack" is written at the
+	// top level of grouped records; groups and the attrs added inside them
+	// are replayed onto each such record.
+	base   slog.Handler
+	groups []string
+	pre    []depthAttr
+	// frames, if set, switches from a raw stack string to structured frames
+	frames *frameOptions
+	// skip drops this many frames after logx's own (Config.StacktraceSkipFrames)
+	skip int
+}
+
+// depthAttr is an attr added with WithAttrs inside depth open groups.
+type depthAttr struct {
+	depth int
+	attr  slog.Attr
+}
+
+// StackFrame is one frame of a structured stack trace.
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// defaultMaxFrames is used when Config.StacktraceMaxFrames is zero.
+const defaultMaxFrames = 32
+
+// defaultSkipPackages are dropped from structured stacks unless
+// Config.StacktraceSkipPackages is set.
+var defaultSkipPackages = []string{"runtime.", "log/slog."}
+
+// logxPkg is this package's import path, used to recognize its frames.
+var logxPkg = reflect.TypeFor[stackHandler]().PkgPath()
+
+type frameOptions struct {
+	max  int
+	skip []string
+}
+
+func newFrameOptions(cfg Config) *frameOptions {
+	if !cfg.StacktraceFrames {
+		return nil
+	}
+	o := &frameOptions{max: cfg.StacktraceMaxFrames, skip: cfg.StacktraceSkipPackages}
+	if o.max <= 0 {
+		o.max = defaultMaxFrames
+	}
+	if o.skip == nil {
+		o.skip = defaultSkipPackages
+	}
+	return o
+}
+
+// callSiteFrames returns the current stack starting at the first frame
+// outside logx, log/slog and the runtime, i.e. the code that logged,
+// after dropping skip further frames.
+func callSiteFrames(skip int) []runtime.Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var out []runtime.Frame
+	leading := true
+	for {
+		f, more := frames.Next()
+		if leading && isInternalFrame(f) {
+			if !more {
+				break
+			}
+			continue
+		}
+		leading = false
+		if skip > 0 {
+			skip--
+		} else {
+			out = append(out, f)
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+// isInternalFrame reports frames of the logging machinery itself. Test
+// files of this package count as user code.
+func isInternalFrame(f runtime.Frame) bool {
+	switch {
+	case strings.HasPrefix(f.Function, "runtime."), strings.HasPrefix(f.Function, "log/slog."):
+		return true
+	case strings.HasPrefix(f.Function, logxPkg+"."):
+		return !strings.HasSuffix(f.File, "_test.go")
+	default:
+		return false
+	}
+}
+
+// structured converts frames, dropping skipped packages and capping the count.
+func (o *frameOptions) structured(frames []runtime.Frame) []StackFrame {
+	out := make([]StackFrame, 0, min(len(frames), o.max))
+	for _, f := range frames {
+		if len(out) == o.max {
+			break
+		}
+		if o.skipped(f.Function) {
+			continue
+		}
+		out = append(out, StackFrame{Function: f.Function, File: f.File, Line: f.Line})
+	}
+	return out
+}
+
+func (o *frameOptions) skipped(fn string) bool {
+	for _, p := range o.skip {
+		if strings.HasPrefix(fn, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatStack renders frames like runtime/debug.Stack without arguments,
+// truncated to maxStackBytes.
+func formatStack(frames []runtime.Frame) string {
+	var b strings.Builder
+	for _, f := range frames {
+		fmt.Fprintf(&b, "%s()\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if b.Len() >= maxStackBytes {
+			break
+		}
+	}
+	s := b.String()
+	if len(s) > maxStackBytes {
+		s = s[:maxStackBytes]
+	}
+	return s
 }
 
 // maxStackBytes limits the size of attached stack traces. Default to 64KB.
@@ -25,6 +167,8 @@ func SetStackMaxBytes(n int) {
 	maxStackBytes = n
 }
 
+// newStackHandler treats level 0 as disabled; Configure constructs the
+// handler directly when Config.StacktraceEnabled asks for INFO stacks.
 func newStackHandler(next slog.Handler, level slog.Level) slog.Handler {
 	// If no stack level configured, return original handler
 	if level == 0 {
@@ -36,30 +180,106 @@ func newStackHandler(next slog.Handler, level slog.Level) slog.Handler {
 	}
 }
 
+// ungrouped returns the handler stack records are written to.
+func (h *stackHandler) ungrouped() slog.Handler {
+	if h.base != nil {
+		return h.base
+	}
+	return h.next
+}
+
 func (h *stackHandler) Enabled(ctx context.Context, level slog.Level) bool {
 	return h.next.Enabled(ctx, level)
 }
 
 func (h *stackHandler) Handle(ctx context.Context, r slog.Record) error {
-	if r.Level >= h.level {
-		nr := r.Clone()
-		stack := debug.Stack()
-		if len(stack) > maxStackBytes {
-			stack = stack[:maxStackBytes]
+	if r.Level < h.level {
+		return h.next.Handle(ctx, r)
+	}
+
+	frames := callSiteFrames(h.skip)
+	var stack slog.Attr
+	if h.frames != nil {
+		stack = slog.Any("stack", h.frames.structured(frames))
+	} else {
+		stack = slog.String("stack", formatStack(frames))
+	}
+
+	// a "stack" the record already carries (Panic, RecoverAndLog) gives
+	// way to ours, so the output has a single stack key
+	var attrs []slog.Attr
+	replaced := false
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "stack" {
+			replaced = true
+			return true
+		}
+		attrs = append(attrs, a)
+		return true
+	})
+
+	if len(h.groups) == 0 {
+		if !replaced {
+			nr := r.Clone()
+			nr.AddAttrs(stack)
+			return h.next.Handle(ctx, nr)
 		}
-		nr.AddAttrs(
-			slog.String("stack", string(stack)),
-		)
+		nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		nr.AddAttrs(attrs...)
+		nr.AddAttrs(stack)
 		return h.next.Handle(ctx, nr)
 	}
 
-	return h.next.Handle(ctx, r)
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(h.nest(1, attrs)...)
+	nr.AddAttrs(stack)
+	return h.ungrouped().Handle(ctx, nr)
+}
+
+// nest rebuilds the attrs inside groups[depth-1:], placing the record's
+// own attrs in the innermost group.
+func (h *stackHandler) nest(depth int, attrs []slog.Attr) []slog.Attr {
+	return nestAttrs(h.groups, h.pre, depth, attrs)
+}
+
+// nestAttrs rebuilds groups[depth-1:] with the attrs added at each depth,
+// placing attrs in the innermost group.
+func nestAttrs(groups []string, pre []depthAttr, depth int, attrs []slog.Attr) []slog.Attr {
+	var members []slog.Attr
+	for _, p := range pre {
+		if p.depth == depth {
+			members = append(members, p.attr)
+		}
+	}
+	if depth == len(groups) {
+		members = append(members, attrs...)
+	} else {
+		members = append(members, nestAttrs(groups, pre, depth+1, attrs)...)
+	}
+	return []slog.Attr{{Key: groups[depth-1], Value: slog.GroupValue(members...)}}
 }
 
 func (h *stackHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return newStackHandler(h.next.WithAttrs(attrs), h.level)
+	c := *h
+	c.next = h.next.WithAttrs(attrs)
+	if len(h.groups) == 0 {
+		c.base = nil
+		return &c
+	}
+	c.pre = append([]depthAttr(nil), h.pre...)
+	for _, a := range attrs {
+		c.pre = append(c.pre, depthAttr{depth: len(h.groups), attr: a})
+	}
+	return &c
 }
 
 func (h *stackHandler) WithGroup(name string) slog.Handler {
-	return newStackHandler(h.next.WithGroup(name), h.level)
+	if name == "" {
+		return h
+	}
+	c := *h
+	c.base = h.ungrouped()
+	c.next = h.next.WithGroup(name)
+	c.groups = append(append([]string(nil), h.groups...), name)
+	return &c
 }
@@ -3,15 +3,17 @@ package logx
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"log/slog"
+	"strings"
 	"testing"
+	"testing/slogtest"
 )
 
 func TestStacktraceLevel_AddsStack(t *testing.T) {
 	Reset()
 
 	var buf bytes.Buffer
-	useColor = false
 
 	err := Configure(Config{
 		Level:           slog.LevelInfo,
@@ -27,7 +29,7 @@ func TestStacktraceLevel_AddsStack(t *testing.T) {
 		Level:     levelVar,
 		AddSource: false,
 	})
-	logger = slog.New(newStackHandler(handler, slog.LevelError))
+	logger.Store(slog.New(newStackHandler(handler, slog.LevelError)))
 
 	Error("boom")
 
@@ -59,3 +61,190 @@ func TestNewStackHandler_WithDelegation(t *testing.T) {
 		t.Fatalf("WithGroup returned nil")
 	}
 }
+
+func TestStacktraceEnabled_AtInfo(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	err := Configure(Config{
+		Level:             slog.LevelInfo,
+		FileWriter:        nopWriteCloser{&buf},
+		StacktraceLevel:   slog.LevelInfo,
+		StacktraceEnabled: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure error: %v", err)
+	}
+
+	With("k", "v").Info("hello")
+
+	assertContains(t, buf.String(), "stack=")
+	if got := Describe().StacktraceLevel; got != "INFO" {
+		t.Fatalf("expected stacktrace level INFO, got %q", got)
+	}
+}
+
+func TestStacktraceLevel_ZeroWithoutEnabledIsDisabled(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: nopWriteCloser{&buf}}); err != nil {
+		t.Fatalf("unexpected configure error: %v", err)
+	}
+
+	Error("boom")
+
+	if bytes.Contains(buf.Bytes(), []byte("stack=")) {
+		t.Fatalf("expected no stack, got %s", buf.String())
+	}
+}
+
+func TestStacktraceFrames_JSONArray(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	err := Configure(Config{
+		Level:               slog.LevelInfo,
+		FileWriter:          nopWriteCloser{&buf},
+		JSONFile:            true,
+		StacktraceLevel:     slog.LevelError,
+		StacktraceFrames:    true,
+		StacktraceMaxFrames: 5,
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure error: %v", err)
+	}
+
+	Error("boom")
+
+	var rec struct {
+		Stack []StackFrame `json:"stack"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("invalid JSON: %v: %s", err, buf.String())
+	}
+	if len(rec.Stack) == 0 || len(rec.Stack) > 5 {
+		t.Fatalf("expected 1..5 frames, got %d", len(rec.Stack))
+	}
+	for _, f := range rec.Stack {
+		if strings.HasPrefix(f.Function, "runtime.") || strings.HasPrefix(f.Function, "log/slog.") {
+			t.Fatalf("expected runtime/slog frames to be skipped, got %s", f.Function)
+		}
+		if f.File == "" || f.Line == 0 {
+			t.Fatalf("incomplete frame: %+v", f)
+		}
+	}
+}
+
+func TestFrameOptions_SkipPrefixes(t *testing.T) {
+	o := &frameOptions{max: 50, skip: []string{"testing."}}
+	for _, f := range o.structured(callSiteFrames(0)) {
+		if strings.HasPrefix(f.Function, "testing.") {
+			t.Fatalf("expected testing frames to be skipped, got %s", f.Function)
+		}
+	}
+}
+
+func TestStacktrace_StartsAtCallSite(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	err := Configure(Config{
+		Level:            slog.LevelInfo,
+		FileWriter:       nopWriteCloser{&buf},
+		JSONFile:         true,
+		StacktraceLevel:  slog.LevelError,
+		StacktraceFrames: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure error: %v", err)
+	}
+
+	Error("boom")
+
+	var rec struct {
+		Stack []StackFrame `json:"stack"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("invalid JSON: %v: %s", err, buf.String())
+	}
+	if len(rec.Stack) == 0 || !strings.HasSuffix(rec.Stack[0].Function, ".TestStacktrace_StartsAtCallSite") {
+		t.Fatalf("expected first frame to be the test, got %+v", rec.Stack)
+	}
+}
+
+func logViaWrapper(msg string) {
+	Error(msg)
+}
+
+func TestStacktrace_SkipFrames(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var buf bytes.Buffer
+	err := Configure(Config{
+		Level:                slog.LevelInfo,
+		FileWriter:           nopWriteCloser{&buf},
+		StacktraceLevel:      slog.LevelError,
+		StacktraceSkipFrames: 1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure error: %v", err)
+	}
+
+	logViaWrapper("boom")
+
+	out := buf.String()
+	if strings.Contains(out, "logViaWrapper") {
+		t.Fatalf("expected wrapper frame to be skipped: %s", out)
+	}
+	if strings.Contains(out, "stackHandler") {
+		t.Fatalf("expected no logx frames: %s", out)
+	}
+	assertContains(t, out, "TestStacktrace_SkipFrames")
+}
+
+func TestStackHandler_StackAtTopLevelOfGroups(t *testing.T) {
+	var buf bytes.Buffer
+	h := newStackHandler(slog.NewJSONHandler(&buf, nil), slog.LevelError)
+	l := slog.New(h).With("svc", "api").WithGroup("req").With("id", 7).WithGroup("db")
+
+	l.Error("boom", "table", "users")
+	l.Info("fine", "table", "orders")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("invalid JSON: %v: %s", err, lines[0])
+	}
+	if _, ok := rec["stack"].(string); !ok {
+		t.Fatalf("expected top-level stack: %s", lines[0])
+	}
+	assertContains(t, lines[0], `"svc":"api","req":{"id":7,"db":{"table":"users"}},"stack":`)
+	assertContains(t, lines[1], `"svc":"api","req":{"id":7,"db":{"table":"orders"}}}`)
+}
+
+func TestStackHandler_Slogtest(t *testing.T) {
+	var buf bytes.Buffer
+	newHandler := func(*testing.T) slog.Handler {
+		buf.Reset()
+		// every record gets a stack
+		return &stackHandler{next: slog.NewJSONHandler(&buf, nil), level: slog.LevelDebug}
+	}
+	result := func(t *testing.T) map[string]any {
+		var m map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+			t.Fatalf("invalid JSON: %v: %s", err, buf.String())
+		}
+		if _, ok := m["stack"]; !ok {
+			t.Fatalf("expected top-level stack: %s", buf.String())
+		}
+		delete(m, "stack")
+		return m
+	}
+	slogtest.Run(t, newHandler, result)
+}
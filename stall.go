@@ -0,0 +1,88 @@
+package logx
+
+// stall.go bounds how long an output may block a record, so a hung NFS
+// mount or a pipe nobody reads stalls only that output instead of every
+// logging call.
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSinkStalled is reported (see SetErrorHandler and WriteErrors) when an
+// output with a write timeout does not finish a record in time, and for
+// every record the output skips until the blocked write returns.
+var ErrSinkStalled = errors.New("logx: output stalled")
+
+var stalledWrites atomic.Uint64
+
+// StalledWrites returns the number of records that timed out or were
+// skipped by stalled outputs since the process started or the last Reset.
+func StalledWrites() uint64 {
+	return stalledWrites.Load()
+}
+
+// stallHandler gives each record timeout to be written by next. After a
+// timeout the output is degraded: records are skipped until every blocked
+// write has returned.
+type stallHandler struct {
+	next    slog.Handler
+	timeout time.Duration
+	// blocked counts writes that timed out and have not returned yet; it is
+	// shared by the handlers derived with WithAttrs and WithGroup
+	blocked *atomic.Int64
+}
+
+// newStallHandler returns next unchanged when timeout is not positive.
+// Each record is written on its own goroutine, which costs an allocation
+// and a timer per record.
+func newStallHandler(next slog.Handler, timeout time.Duration) slog.Handler {
+	if timeout <= 0 {
+		return next
+	}
+	return &stallHandler{next: next, timeout: timeout, blocked: new(atomic.Int64)}
+}
+
+func (h *stallHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *stallHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.blocked.Load() > 0 {
+		stalledWrites.Add(1)
+		return fmt.Errorf("%w: skipped while a write is blocked", ErrSinkStalled)
+	}
+
+	done := make(chan error, 1)
+	ctx = context.WithoutCancel(ctx)
+	r = r.Clone()
+	go func() { done <- h.next.Handle(ctx, r) }()
+
+	timer := time.NewTimer(h.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+	}
+
+	stalledWrites.Add(1)
+	h.blocked.Add(1)
+	go func() {
+		<-done
+		h.blocked.Add(-1)
+	}()
+	return fmt.Errorf("%w: write blocked for %s", ErrSinkStalled, h.timeout)
+}
+
+func (h *stallHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &stallHandler{next: h.next.WithAttrs(attrs), timeout: h.timeout, blocked: h.blocked}
+}
+
+func (h *stallHandler) WithGroup(name string) slog.Handler {
+	return &stallHandler{next: h.next.WithGroup(name), timeout: h.timeout, blocked: h.blocked}
+}
@@ -0,0 +1,117 @@
+package logx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter blocks every Write until release is closed.
+type blockingWriter struct {
+	release chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *blockingWriter) Close() error { return nil }
+
+func (w *blockingWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestStallHandler_SkipsUntilBlockedWriteReturns(t *testing.T) {
+	stalledWrites.Store(0)
+	w := &blockingWriter{release: make(chan struct{})}
+	h := newStallHandler(slog.NewTextHandler(w, nil), 20*time.Millisecond).(*stallHandler)
+	l := slog.New(h).With("component", "db")
+	ctx := context.Background()
+
+	rec := func(msg string) slog.Record { return slog.NewRecord(time.Now(), slog.LevelInfo, msg, 0) }
+	if err := l.Handler().Handle(ctx, rec("blocked")); !errors.Is(err, ErrSinkStalled) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	start := time.Now()
+	if err := l.Handler().Handle(ctx, rec("skipped")); !errors.Is(err, ErrSinkStalled) {
+		t.Fatalf("expected a skip, got %v", err)
+	}
+	if time.Since(start) > 10*time.Millisecond {
+		t.Fatalf("expected skipped records not to wait")
+	}
+	if StalledWrites() != 2 {
+		t.Fatalf("expected 2 stalled writes, got %d", StalledWrites())
+	}
+
+	close(w.release)
+	deadline := time.Now().Add(time.Second)
+	for h.blocked.Load() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := l.Handler().Handle(ctx, rec("recovered")); err != nil {
+		t.Fatalf("expected the output to recover, got %v", err)
+	}
+	out := w.String()
+	assertContains(t, out, "msg=blocked component=db")
+	assertContains(t, out, "msg=recovered")
+	if bytes.Contains([]byte(out), []byte("skipped")) {
+		t.Fatalf("expected the skipped record to be dropped:\n%s", out)
+	}
+}
+
+func TestConfigure_FileWriteTimeoutKeepsConsoleFlowing(t *testing.T) {
+	Reset()
+	defer Reset()
+	var errs []error
+	var mu sync.Mutex
+	SetErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	})
+	defer SetErrorHandler(nil)
+
+	console := &bytes.Buffer{}
+	file := &blockingWriter{release: make(chan struct{})}
+	defer close(file.release)
+	err := Configure(Config{
+		Level:            slog.LevelInfo,
+		Console:          true,
+		ConsoleWriter:    console,
+		FileWriter:       file,
+		FileWriteTimeout: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := Describe().Outputs[1]; o.WriteTimeout != "10ms" {
+		t.Fatalf("expected the timeout to be described, got %+v", o)
+	}
+
+	start := time.Now()
+	Info("one")
+	Info("two")
+	if time.Since(start) > time.Second {
+		t.Fatalf("expected the stalled file not to block logging")
+	}
+	assertContains(t, console.String(), "msg=two")
+	if WriteErrors() != 2 || StalledWrites() != 2 {
+		t.Fatalf("expected 2 write errors and stalled writes, got %d and %d", WriteErrors(), StalledWrites())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 2 || !errors.Is(errs[0], ErrSinkStalled) {
+		t.Fatalf("expected ErrSinkStalled errors, got %v", errs)
+	}
+}
@@ -0,0 +1,111 @@
+package logx
+
+// stdlog.go bridges the standard library log package into logx so that
+// third-party output passes through the configured handlers (redaction,
+// file sinks, stack traces).
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// stdRedirected is set while RedirectStdLog is active so that Configure and
+// SetLogger (which call slog.SetDefault and thereby reset log's output)
+// reinstall the bridge.
+var stdRedirected atomic.Bool
+
+// stdLevelPrefixes maps common level prefixes emitted by libraries using
+// the log package. Matching is case-insensitive; the prefix is stripped.
+var stdLevelPrefixes = []struct {
+	prefix string
+	level  slog.Level
+}{
+	{"[error]", slog.LevelError},
+	{"error:", slog.LevelError},
+	{"[err]", slog.LevelError},
+	{"[fatal]", slog.LevelError},
+	{"fatal:", slog.LevelError},
+	{"[warning]", slog.LevelWarn},
+	{"warning:", slog.LevelWarn},
+	{"[warn]", slog.LevelWarn},
+	{"warn:", slog.LevelWarn},
+	{"[info]", slog.LevelInfo},
+	{"info:", slog.LevelInfo},
+	{"[debug]", slog.LevelDebug},
+	{"debug:", slog.LevelDebug},
+	{"[trace]", slog.LevelDebug},
+}
+
+// StdLogger returns a *log.Logger that writes through the package logger.
+// Lines without a recognized level prefix ("[ERROR]", "WARN:", ...) are
+// logged at level.
+func StdLogger(level slog.Level) *log.Logger {
+	return log.New(&stdWriter{level: level}, "", 0)
+}
+
+// RedirectStdLog routes the standard library's default logger through the
+// package logger with level detection, defaulting to info. It stays in
+// effect across Configure calls. The returned func restores the previous
+// output, flags and prefix.
+func RedirectStdLog() (restore func()) {
+	prevOut := log.Writer()
+	prevFlags := log.Flags()
+	prevPrefix := log.Prefix()
+
+	stdRedirected.Store(true)
+	installStdRedirect()
+
+	return func() {
+		stdRedirected.Store(false)
+		log.SetOutput(prevOut)
+		log.SetFlags(prevFlags)
+		log.SetPrefix(prevPrefix)
+	}
+}
+
+func installStdRedirect() {
+	log.SetOutput(&stdWriter{level: slog.LevelInfo})
+	log.SetFlags(0)
+	log.SetPrefix("")
+}
+
+type stdWriter struct {
+	level slog.Level
+}
+
+func (w *stdWriter) Write(p []byte) (int, error) {
+	msg := string(bytes.TrimRight(p, "\r\n"))
+	level, msg := detectStdLevel(msg, w.level)
+
+	ctx := context.Background()
+	l := Logger()
+	if !l.Enabled(ctx, level) {
+		return len(p), nil
+	}
+
+	// skip [Callers, Write, log.(*Logger).output, log.Printf]
+	var pcs [1]uintptr
+	runtime.Callers(4, pcs[:])
+
+	r := slog.NewRecord(Now(), level, msg, pcs[0])
+	if err := l.Handler().Handle(ctx, r); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func detectStdLevel(msg string, fallback slog.Level) (slog.Level, string) {
+	trimmed := strings.TrimLeft(msg, " ")
+	lower := strings.ToLower(trimmed)
+	for _, lp := range stdLevelPrefixes {
+		if strings.HasPrefix(lower, lp.prefix) {
+			return lp.level, strings.TrimLeft(trimmed[len(lp.prefix):], " ")
+		}
+	}
+	return fallback, msg
+}
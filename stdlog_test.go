@@ -0,0 +1,48 @@
+package logx
+
+import (
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestStdLogger_DetectsLevelPrefixes(t *testing.T) {
+	out := capture(t, slog.LevelDebug, func() {
+		l := StdLogger(slog.LevelInfo)
+		l.Print("[ERROR] connection refused")
+		l.Print("WARNING: retrying")
+		l.Print("plain line")
+	})
+
+	assertContains(t, out, `level=ERROR msg="connection refused"`)
+	assertContains(t, out, `level=WARN msg=retrying`)
+	assertContains(t, out, `level=INFO msg="plain line"`)
+}
+
+func TestRedirectStdLog_SurvivesConfigureAndRestores(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: w}); err != nil {
+		t.Fatalf("configure failed: %v", err)
+	}
+
+	restore := RedirectStdLog()
+
+	w2 := &trackingWriteCloser{}
+	if err := Configure(Config{Level: slog.LevelInfo, FileWriter: w2}); err != nil {
+		t.Fatalf("reconfigure failed: %v", err)
+	}
+	log.Print("warn: disk almost full")
+
+	restore()
+	Reset()
+
+	out := w2.String()
+	assertContains(t, out, `level=WARN msg="disk almost full"`)
+	if strings.Contains(w.String(), "disk") {
+		t.Fatalf("expected output to follow reconfiguration")
+	}
+}
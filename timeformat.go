@@ -0,0 +1,58 @@
+package logx
+
+// timeformat.go renders the record time in the layout or epoch unit an
+// ingestion pipeline expects, in UTC if asked.
+
+import "log/slog"
+
+// Special Config.TimeFormat values; any other non-empty value is a time
+// layout for time.Format.
+const (
+	TimeEpochSeconds = "epoch_seconds"
+	TimeEpochMillis  = "epoch_millis"
+	TimeEpochNanos   = "epoch_nanos"
+)
+
+// timeReplacer returns a ReplaceAttr function rendering the record time
+// for format and utc, or nil when slog's default rendering is kept.
+func timeReplacer(format string, utc bool) func([]string, slog.Attr) slog.Attr {
+	if format == "" && !utc {
+		return nil
+	}
+	return func(groups []string, a slog.Attr) slog.Attr {
+		if len(groups) != 0 || a.Key != slog.TimeKey || a.Value.Kind() != slog.KindTime {
+			return a
+		}
+		t := a.Value.Time()
+		if utc {
+			t = t.UTC()
+		}
+		switch format {
+		case "":
+			a.Value = slog.TimeValue(t)
+		case TimeEpochSeconds:
+			a.Value = slog.Int64Value(t.Unix())
+		case TimeEpochMillis:
+			a.Value = slog.Int64Value(t.UnixMilli())
+		case TimeEpochNanos:
+			a.Value = slog.Int64Value(t.UnixNano())
+		default:
+			a.Value = slog.StringValue(t.Format(format))
+		}
+		return a
+	}
+}
+
+// chainReplaceAttr returns a function applying first, then next; either
+// may be nil.
+func chainReplaceAttr(first, next func([]string, slog.Attr) slog.Attr) func([]string, slog.Attr) slog.Attr {
+	switch {
+	case first == nil:
+		return next
+	case next == nil:
+		return first
+	}
+	return func(groups []string, a slog.Attr) slog.Attr {
+		return next(groups, first(groups, a))
+	}
+}
@@ -0,0 +1,64 @@
+package logx
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeReplacer(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 5_000_000, time.FixedZone("CET", 3600))
+	attr := slog.Time(slog.TimeKey, ts)
+
+	tests := []struct {
+		format string
+		utc    bool
+		want   any
+	}{
+		{TimeEpochMillis, false, ts.UnixMilli()},
+		{TimeEpochSeconds, false, ts.Unix()},
+		{TimeEpochNanos, false, ts.UnixNano()},
+		{time.RFC3339Nano, true, "2024-03-01T11:30:00.005Z"},
+		{"15:04", false, "12:30"},
+	}
+	for _, tt := range tests {
+		got := timeReplacer(tt.format, tt.utc)(nil, attr).Value.Any()
+		if got != tt.want {
+			t.Errorf("format %q utc %v: got %v, want %v", tt.format, tt.utc, got, tt.want)
+		}
+	}
+
+	if timeReplacer("", false) != nil {
+		t.Fatalf("expected no replacer for defaults")
+	}
+	if got := timeReplacer("", true)(nil, attr).Value.Time(); got.Location() != time.UTC {
+		t.Fatalf("expected UTC time, got %v", got)
+	}
+	nested := timeReplacer(TimeEpochMillis, false)([]string{"g"}, attr)
+	if nested.Value.Kind() != slog.KindTime {
+		t.Fatalf("expected nested time attrs to be left alone")
+	}
+}
+
+func TestConfigTimeFormat_EpochMillisJSON(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w, JSONFile: true, TimeFormat: TimeEpochMillis}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	before := time.Now().UnixMilli()
+	Info("stamped")
+
+	var m map[string]any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(w.String())), &m); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	ms, ok := m["time"].(float64)
+	if !ok || int64(ms) < before || int64(ms) > before+5000 {
+		t.Fatalf("expected epoch millis, got %v", m["time"])
+	}
+}
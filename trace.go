@@ -0,0 +1,68 @@
+package logx
+
+// trace.go connects the Timed helpers to a tracing system without making
+// logx depend on one: the application supplies a Tracer (typically a few
+// lines wrapping an OpenTelemetry tracer) and timing records line up with
+// spans.
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Tracer adapts a tracing library for TimedSpan and the Timed helpers.
+// Either field may be nil.
+type Tracer struct {
+	// Start starts a span named name and returns a context carrying it and
+	// a function that ends it, recording err when non-nil.
+	Start func(ctx context.Context, name string) (context.Context, func(err error))
+	// IDs returns the trace and span IDs of the span in ctx, or empty
+	// strings when there is none.
+	IDs func(ctx context.Context) (traceID, spanID string)
+}
+
+var tracer atomic.Pointer[Tracer]
+
+// SetTracer installs t for TimedSpan and adds "trace_id" and "span_id" to
+// Timed records whose context carries a span. Pass nil to remove it.
+func SetTracer(t *Tracer) {
+	tracer.Store(t)
+}
+
+// TimedSpan is Timed inside a new span: it starts a span named msg, logs
+// "<msg> started" with its IDs and returns the span's context and a done
+// closure that ends the span with the same outcome it logs:
+//
+//	ctx, done := logx.TimedSpan(ctx, "sync inventory", "store", id)
+//	err := syncInventory(ctx)
+//	done(err)
+//
+// Without a Tracer (or Tracer.Start) it behaves like Timed and returns ctx.
+func TimedSpan(ctx context.Context, msg string, args ...any) (context.Context, func(extra ...any)) {
+	var end func(error)
+	if t := tracer.Load(); t != nil && t.Start != nil {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctx, end = t.Start(ctx, msg)
+	}
+	return ctx, timed(Logger(), slog.LevelInfo, 0, ctx, msg, args, end)
+}
+
+// traceArgs appends the span IDs in ctx, if a Tracer can report them.
+func traceArgs(ctx context.Context, args []any) []any {
+	t := tracer.Load()
+	if t == nil || t.IDs == nil || ctx == nil {
+		return args
+	}
+	traceID, spanID := t.IDs(ctx)
+	if traceID == "" {
+		return args
+	}
+	out := append(args[:len(args):len(args)], "trace_id", traceID)
+	if spanID != "" {
+		out = append(out, "span_id", spanID)
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package logx
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type fakeSpanKey struct{}
+
+// fakeTracer records ended spans and their errors.
+func fakeTracer(ended *[]string) *Tracer {
+	return &Tracer{
+		Start: func(ctx context.Context, name string) (context.Context, func(error)) {
+			ctx = context.WithValue(ctx, fakeSpanKey{}, name)
+			return ctx, func(err error) {
+				*ended = append(*ended, name+":"+errors.Join(err).Error())
+			}
+		},
+		IDs: func(ctx context.Context) (string, string) {
+			name, _ := ctx.Value(fakeSpanKey{}).(string)
+			if name == "" {
+				return "", ""
+			}
+			return "trace-1", "span-" + name
+		},
+	}
+}
+
+func TestTimedSpan_StartsAndEndsSpan(t *testing.T) {
+	var ended []string
+	var spanCtx context.Context
+	out := capture(t, slog.LevelInfo, func() {
+		SetTracer(fakeTracer(&ended))
+		ctx, done := TimedSpan(context.Background(), "sync", "store", 7)
+		spanCtx = ctx
+		done(errors.New("offline"))
+	})
+
+	if spanCtx.Value(fakeSpanKey{}) != "sync" {
+		t.Fatalf("expected returned context to carry the span")
+	}
+	if len(ended) != 1 || ended[0] != "sync:offline" {
+		t.Fatalf("expected span ended with error, got %v", ended)
+	}
+	assertContains(t, out, `msg="sync started" store=7 trace_id=trace-1 span_id=span-sync`)
+	assertContains(t, out, `msg="sync failed" store=7 trace_id=trace-1 span_id=span-sync`)
+}
+
+func TestTimed_NoTracer(t *testing.T) {
+	out := capture(t, slog.LevelInfo, func() {
+		ctx, done := TimedSpan(context.Background(), "job")
+		done()
+		Timed(ctx, "other")()
+	})
+
+	assertContains(t, out, `msg="job completed"`)
+	if strings.Contains(out, "trace_id") {
+		t.Fatalf("expected no trace ids without a tracer: %s", out)
+	}
+}
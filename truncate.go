@@ -0,0 +1,133 @@
+package logx
+
+// truncate.go caps the length of string attr values per output so that an
+// accidentally logged payload cannot blow up file sizes or exceed the line
+// limits of downstream ingestion.
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"unicode/utf8"
+)
+
+// truncatedKeysKey lists the keys whose values were shortened in a record.
+const truncatedKeysKey = "truncated_keys"
+
+// attrLimit resolves a per-output limit against the global one:
+// 0 inherits global, a negative value disables truncation.
+func attrLimit(global, output int) int {
+	if output == 0 {
+		output = global
+	}
+	if output < 0 {
+		return 0
+	}
+	return output
+}
+
+type truncateHandler struct {
+	next  slog.Handler
+	limit int
+	// keys truncated in attrs added with WithAttrs, reported on every record
+	preKeys []string
+	prefix  string
+}
+
+func newTruncateHandler(next slog.Handler, limit int) slog.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return &truncateHandler{next: next, limit: limit}
+}
+
+func (h *truncateHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *truncateHandler) Handle(ctx context.Context, r slog.Record) error {
+	var (
+		keys    []string
+		changed bool
+	)
+	attrs := getAttrs()
+	defer putAttrs(attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		a, ok := h.truncateAttr(a, h.prefix, &keys)
+		changed = changed || ok
+		*attrs = append(*attrs, a)
+		return true
+	})
+	if !changed && len(h.preKeys) == 0 {
+		return h.next.Handle(ctx, r)
+	}
+
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	nr.AddAttrs(*attrs...)
+	keys = append(append([]string(nil), h.preKeys...), keys...)
+	nr.AddAttrs(slog.Any(truncatedKeysKey, keys))
+	return h.next.Handle(ctx, nr)
+}
+
+func (h *truncateHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	keys := append([]string(nil), h.preKeys...)
+	out := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		out[i], _ = h.truncateAttr(a, h.prefix, &keys)
+	}
+	return &truncateHandler{
+		next:    h.next.WithAttrs(out),
+		limit:   h.limit,
+		preKeys: keys,
+		prefix:  h.prefix,
+	}
+}
+
+func (h *truncateHandler) WithGroup(name string) slog.Handler {
+	return &truncateHandler{
+		next:    h.next.WithGroup(name),
+		limit:   h.limit,
+		preKeys: h.preKeys,
+		prefix:  h.prefix + name + ".",
+	}
+}
+
+// truncateAttr shortens string values (recursing into groups) and records
+// the dotted key of each truncated value.
+func (h *truncateHandler) truncateAttr(a slog.Attr, prefix string, keys *[]string) (slog.Attr, bool) {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		s := v.String()
+		if len(s) <= h.limit {
+			return a, false
+		}
+		a.Value = slog.StringValue(truncateString(s, h.limit))
+		*keys = append(*keys, prefix+a.Key)
+		return a, true
+	case slog.KindGroup:
+		group := v.Group()
+		out := make([]slog.Attr, len(group))
+		changed := false
+		for i, ga := range group {
+			var ok bool
+			out[i], ok = h.truncateAttr(ga, prefix+a.Key+".", keys)
+			changed = changed || ok
+		}
+		if changed {
+			a.Value = slog.GroupValue(out...)
+		}
+		return a, changed
+	}
+	return a, false
+}
+
+// truncateString cuts s to at most limit bytes on a rune boundary and
+// appends how many bytes were dropped.
+func truncateString(s string, limit int) string {
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…(+" + strconv.Itoa(len(s)-cut) + " bytes)"
+}
@@ -0,0 +1,106 @@
+package logx
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTruncateHandler_ShortensLongValues(t *testing.T) {
+	var buf bytes.Buffer
+	h := newTruncateHandler(slog.NewJSONHandler(&buf, nil), 4)
+	l := slog.New(h).With("pre", "abcdefgh")
+
+	l.Info("msg", "short", "abc", "long", "abcéfg", slog.Group("g", "inner", "0123456789"))
+
+	out := buf.String()
+	assertContains(t, out, `"pre":"abcd…(+4 bytes)"`)
+	assertContains(t, out, `"short":"abc"`)
+	// "é" straddles the limit, so the cut backs up to a rune boundary
+	assertContains(t, out, `"long":"abc…(+4 bytes)"`)
+	assertContains(t, out, `"inner":"0123…(+6 bytes)"`)
+	assertContains(t, out, `"truncated_keys":["pre","long","g.inner"]`)
+}
+
+func TestTruncateHandler_NoTruncationLeavesRecord(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(newTruncateHandler(slog.NewTextHandler(&buf, nil), 16))
+
+	l.Info("msg", "k", "v")
+
+	if strings.Contains(buf.String(), truncatedKeysKey) {
+		t.Fatalf("unexpected truncated_keys: %s", buf.String())
+	}
+}
+
+func TestAttrLimit(t *testing.T) {
+	cases := []struct{ global, output, want int }{
+		{0, 0, 0},
+		{100, 0, 100},
+		{100, 10, 10},
+		{100, -1, 0},
+	}
+	for _, c := range cases {
+		if got := attrLimit(c.global, c.output); got != c.want {
+			t.Fatalf("attrLimit(%d, %d) = %d, want %d", c.global, c.output, got, c.want)
+		}
+	}
+}
+
+func TestConfigure_PerOutputAttrLimit(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	err := Configure(Config{
+		Level:                 slog.LevelInfo,
+		FilePath:              path,
+		MaxAttrValueBytes:     4,
+		FileMaxAttrValueBytes: 8,
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure error: %v", err)
+	}
+
+	Info("msg", "payload", strings.Repeat("x", 20))
+	Reset()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, string(data), `payload="xxxxxxxx…(+12 bytes)"`)
+	assertContains(t, string(data), "truncated_keys=[payload]")
+}
+
+func TestConfigure_SinkAttrLimit(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	var inherit, own bytes.Buffer
+	a, b := testSinkName("truncate-inherit"), testSinkName("truncate-own")
+	for name, buf := range map[string]*bytes.Buffer{a: &inherit, b: &own} {
+		RegisterSink(name, func(opts *slog.HandlerOptions, _ map[string]any) (slog.Handler, io.Closer, error) {
+			return slog.NewTextHandler(buf, opts), nil, nil
+		})
+	}
+	err := Configure(Config{
+		Level:             slog.LevelInfo,
+		MaxAttrValueBytes: 4,
+		Sinks:             []SinkConfig{{Name: a}, {Name: b, MaxAttrValueBytes: -1}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected configure error: %v", err)
+	}
+
+	Info("msg", "payload", strings.Repeat("x", 20))
+	assertContains(t, inherit.String(), `payload="xxxx…(+16 bytes)" truncated_keys=[payload]`)
+	assertContains(t, own.String(), "payload="+strings.Repeat("x", 20))
+	if out := Describe().Outputs; out[0].MaxAttrValueBytes != 4 || out[1].MaxAttrValueBytes != 0 {
+		t.Fatalf("unexpected limits: %+v", out)
+	}
+}
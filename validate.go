@@ -0,0 +1,203 @@
+package logx
+
+// validate.go checks a Config without building or installing anything, so
+// deployment tooling can reject a bad configuration before it takes effect.
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrInvalidConfig is wrapped by every error returned by Config.Validate.
+var ErrInvalidConfig = errors.New("logx: invalid config")
+
+// Validate reports every problem it finds in cfg, joined, each wrapping
+// ErrInvalidConfig: files that cannot be written, incoherent rotation
+// settings, options that have no effect with the configured outputs, and
+// unknown or duplicate sinks. It only probes writability (creating and
+// removing a temporary file next to a log file that does not exist yet);
+// nothing is opened for logging and the active logger is not changed.
+func (cfg Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidConfig}, args...)...))
+	}
+
+	file := cfg.FilePath != "" || cfg.FileWriter != nil
+	if cfg.FilePath != "" && cfg.FileWriter != nil {
+		invalid("FilePath %q is ignored when FileWriter is set", cfg.FilePath)
+	}
+	if cfg.FilePath != "" && cfg.FileWriter == nil && !cfg.Discard {
+		if err := checkWritable(cfg.FilePath); err != nil {
+			invalid("FilePath: %v", err)
+		}
+	}
+	if cfg.FileMaxSizeBytes < 0 || cfg.FileMaxBackups < 0 || cfg.FileRotateEvery < 0 {
+		invalid("FileMaxSizeBytes, FileMaxBackups and FileRotateEvery must not be negative")
+	}
+	rotates := cfg.fileRotation().active()
+	if cfg.FileMaxBackups > 0 && !rotates {
+		invalid("FileMaxBackups requires FileMaxSizeBytes or FileRotateEvery")
+	}
+	if (rotates || cfg.FileLock) && cfg.FilePath == "" {
+		invalid("FileMaxSizeBytes, FileRotateEvery and FileLock require FilePath")
+	}
+	if cfg.FileWriter != nil && (rotates || cfg.FileLock) {
+		invalid("FileMaxSizeBytes, FileRotateEvery and FileLock do not apply to FileWriter")
+	}
+	if cfg.FileReopenInterval < 0 || cfg.ConsoleWriteTimeout < 0 || cfg.FileWriteTimeout < 0 {
+		invalid("FileReopenInterval, ConsoleWriteTimeout and FileWriteTimeout must not be negative")
+	}
+	if cfg.ParallelOutputs < 0 {
+		invalid("ParallelOutputs must not be negative")
+	}
+	if cfg.FileReopenInterval > 0 && cfg.FilePath == "" {
+		invalid("FileReopenInterval requires FilePath")
+	}
+	if cfg.FileMaxTotalBytes < 0 {
+		invalid("FileMaxTotalBytes must not be negative")
+	}
+	if cfg.FileMaxTotalBytes > 0 && cfg.FileMaxTotalBytes < cfg.FileMaxSizeBytes {
+		invalid("FileMaxTotalBytes must be at least FileMaxSizeBytes")
+	}
+	if cfg.FileMaxTotalBytes > 0 && !rotates {
+		invalid("FileMaxTotalBytes requires FileMaxSizeBytes or FileRotateEvery")
+	}
+	if (cfg.FileManifest || cfg.FileCompress) && !rotates {
+		invalid("FileManifest and FileCompress require FileMaxSizeBytes or FileRotateEvery")
+	}
+	paths := map[string]bool{cfg.FilePath: cfg.FilePath != "", cfg.AuditPath: cfg.AuditPath != ""}
+	for i, fc := range cfg.fileOutputs() {
+		name := fmt.Sprintf("Files[%d]", i)
+		if i == len(cfg.Files) {
+			name = "ErrorFile"
+		}
+		if fc.Path == "" {
+			invalid("%s.Path is required", name)
+			continue
+		}
+		if paths[fc.Path] {
+			invalid("%s.Path %q is already written to", name, fc.Path)
+		}
+		paths[fc.Path] = true
+		if err := checkWritable(fc.Path); err != nil {
+			invalid("%s.Path: %v", name, err)
+		}
+		fc.Rotation.validate(name, invalid)
+		if fc.ReopenInterval < 0 || fc.WriteTimeout < 0 {
+			invalid("%s.ReopenInterval and WriteTimeout must not be negative", name)
+		}
+	}
+	if cfg.ConsoleColor < ColorLevel || cfg.ConsoleColor > ColorLine {
+		invalid("unknown ConsoleColor %d", int(cfg.ConsoleColor))
+	}
+	if cfg.ConsoleWriter != nil && !cfg.Console {
+		invalid("ConsoleWriter requires Console")
+	}
+	if cfg.ConsoleMinLevel != nil && !cfg.Console {
+		invalid("ConsoleMinLevel requires Console")
+	}
+	if !file && (cfg.FileFallback || cfg.FileMinLevel != nil) {
+		invalid("FileFallback and FileMinLevel require FilePath or FileWriter")
+	}
+	if cfg.FileRetryInterval < 0 {
+		invalid("FileRetryInterval must not be negative")
+	}
+	if cfg.FilePath == "" && (cfg.CrashMarker || cfg.DetectUncleanShutdown) {
+		invalid("CrashMarker and DetectUncleanShutdown require FilePath")
+	}
+	if cfg.DedupWindow < 0 {
+		invalid("DedupWindow must not be negative")
+	}
+	if cfg.RecentRecords < 0 || cfg.StacktraceMaxFrames < 0 || cfg.StacktraceSkipFrames < 0 {
+		invalid("RecentRecords, StacktraceMaxFrames and StacktraceSkipFrames must not be negative")
+	}
+
+	if cfg.AuditPath != "" && cfg.AuditWriter != nil {
+		invalid("AuditPath %q is ignored when AuditWriter is set", cfg.AuditPath)
+	}
+	if cfg.AuditPath != "" && cfg.AuditWriter == nil {
+		if err := checkWritable(cfg.AuditPath); err != nil {
+			invalid("AuditPath: %v", err)
+		}
+	}
+	if cfg.AuditRotation != (Rotation{}) && cfg.AuditPath == "" {
+		invalid("AuditRotation requires AuditPath")
+	}
+	cfg.AuditRotation.validate("AuditRotation", invalid)
+	if cfg.AuditHashChain && cfg.AuditPath == "" && cfg.AuditWriter == nil {
+		invalid("AuditHashChain requires AuditPath or AuditWriter")
+	}
+
+	seen := map[string]bool{}
+	for _, sc := range cfg.Sinks {
+		sinksMu.RLock()
+		_, ok := sinks[sc.Name]
+		sinksMu.RUnlock()
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %w: %q", ErrInvalidConfig, ErrUnknownSink, sc.Name))
+		}
+		if seen[sc.Name] {
+			invalid("sink %q is listed twice", sc.Name)
+		}
+		if sc.WriteTimeout < 0 || sc.QueueSize < 0 {
+			invalid("sink %q: WriteTimeout and QueueSize must not be negative", sc.Name)
+		}
+		seen[sc.Name] = true
+	}
+	if cfg.GELF != nil {
+		if cfg.GELF.Addr == "" {
+			invalid("GELF.Addr is required")
+		}
+		switch cfg.GELF.Network {
+		case "", "udp", "tcp", "tls":
+		default:
+			invalid("GELF.Network %q is not udp, tcp or tls", cfg.GELF.Network)
+		}
+		if cfg.GELF.WriteTimeout < 0 || cfg.GELF.QueueSize < 0 {
+			invalid("GELF.WriteTimeout and GELF.QueueSize must not be negative")
+		}
+	}
+	if cfg.Journald != nil && (cfg.Journald.WriteTimeout < 0 || cfg.Journald.QueueSize < 0) {
+		invalid("Journald.WriteTimeout and Journald.QueueSize must not be negative")
+	}
+
+	if cfg.Schema != SchemaCloudWatch && (cfg.EMFNamespace != "" || len(cfg.EMFDimensions) > 0) {
+		invalid("EMFNamespace and EMFDimensions require SchemaCloudWatch")
+	}
+	if cfg.Schema != SchemaGCP && cfg.GCPProjectID != "" {
+		invalid("GCPProjectID requires SchemaGCP")
+	}
+	if cfg.Schema < SchemaDefault || cfg.Schema > SchemaCloudWatch {
+		invalid("unknown Schema %d", int(cfg.Schema))
+	}
+	return errors.Join(errs...)
+}
+
+// checkWritable reports whether path can be opened for appending without
+// writing to it. A missing file is probed with a temporary file in its
+// directory.
+func checkWritable(path string) error {
+	fi, err := os.Stat(path)
+	switch {
+	case err == nil && fi.IsDir():
+		return fmt.Errorf("%s is a directory", path)
+	case err == nil:
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".logx-validate-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,120 @@
+package logx
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{
+		FilePath:          filepath.Join(dir, "missing", "app.log"),
+		FileMaxBackups:    3,
+		FileMaxTotalBytes: 1 << 20,
+		CrashMarker:       true,
+		AuditHashChain:    true,
+		Sinks:             []SinkConfig{{Name: testSinkName("unregistered")}},
+		GELF:              &GELFConfig{Network: "sctp"},
+		EMFNamespace:      "app",
+	}
+	err := cfg.Validate()
+	if !errors.Is(err, ErrInvalidConfig) || !errors.Is(err, ErrUnknownSink) {
+		t.Fatalf("expected ErrInvalidConfig and ErrUnknownSink, got %v", err)
+	}
+	for _, want := range []string{"FilePath", "FileMaxBackups requires", "FileMaxTotalBytes requires", "AuditHashChain", "GELF.Addr", "sctp", "EMFNamespace"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "CrashMarker") {
+		t.Errorf("CrashMarker has a FilePath: %v", err)
+	}
+}
+
+func TestValidate_ValidConfigLeavesNoFiles(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{Console: true, FilePath: filepath.Join(dir, "app.log"), FileMaxSizeBytes: 1 << 20, FileMaxBackups: 2}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("expected Validate to leave the directory empty, got %v", entries)
+	}
+	if err := (Config{FilePath: dir}).Validate(); err == nil {
+		t.Fatalf("expected a directory FilePath to be rejected")
+	}
+}
+
+func TestConfigureStrict_KeepsActiveLogger(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	w := &trackingWriteCloser{}
+	if err := Configure(Config{FileWriter: w}); err != nil {
+		t.Fatalf("configure: %v", err)
+	}
+	prev := Logger()
+
+	bad := Config{Strict: true, FilePath: filepath.Join(t.TempDir(), "missing", "app.log")}
+	if err := Configure(bad); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	if Logger() != prev || w.closed {
+		t.Fatalf("expected the active logger and its writer to be kept")
+	}
+
+	// output failures caught only while building are also not installed
+	failing := testSinkName("failing")
+	RegisterSink(failing, func(*slog.HandlerOptions, map[string]any) (slog.Handler, io.Closer, error) {
+		return nil, nil, errors.New("broker down")
+	})
+	if err := Configure(Config{Strict: true, Console: true, Sinks: []SinkConfig{{Name: failing}}}); !errors.Is(err, ErrSinkInit) {
+		t.Fatalf("expected ErrSinkInit, got %v", err)
+	}
+	Info("still here")
+	if Logger() != prev {
+		t.Fatalf("expected the active logger to be kept")
+	}
+	assertContains(t, w.String(), "still here")
+}
+
+func TestValidate_FilesAndAuditRotation(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{
+		FilePath: filepath.Join(dir, "app.log"),
+		Files: []FileConfig{
+			{},
+			{Path: filepath.Join(dir, "app.log")},
+			{Path: filepath.Join(dir, "debug.log"), Rotation: Rotation{MaxBackups: 3}},
+		},
+		AuditRotation: Rotation{Every: 24 * time.Hour},
+		ErrorFilePath: filepath.Join(dir, "debug.log"),
+	}
+	err := cfg.Validate()
+	for _, want := range []string{
+		"Files[0].Path is required",
+		"Files[1].Path",
+		"Files[2]: MaxBackups, MaxTotalBytes and Compress require MaxSizeBytes or Every",
+		"AuditRotation requires AuditPath",
+		"ErrorFile.Path",
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+func TestValidate_MinLevelsRequireTheirOutput(t *testing.T) {
+	err := Config{ConsoleMinLevel: slog.LevelInfo, FileMinLevel: slog.LevelDebug}.Validate()
+	for _, want := range []string{"ConsoleMinLevel requires Console", "FileMinLevel require FilePath"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
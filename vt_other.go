@@ -0,0 +1,11 @@
+//go:build !windows
+
+package logx
+
+import "os"
+
+// enableVirtualTerminal is only needed on Windows; other terminals
+// interpret ANSI escapes already.
+func enableVirtualTerminal(*os.File) bool {
+	return true
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package logx
+
+import (
+	"os"
+	"syscall"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// enableVirtualTerminal turns on ANSI escape handling for the console f,
+// so colors work in cmd.exe and PowerShell. It reports false when f is not
+// a console or the console predates Windows 10.
+func enableVirtualTerminal(f *os.File) bool {
+	h := syscall.Handle(f.Fd())
+	var mode uint32
+	if err := syscall.GetConsoleMode(h, &mode); err != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	if err := procSetConsoleMode.Find(); err != nil {
+		return false
+	}
+	ok, _, _ := procSetConsoleMode.Call(uintptr(h), uintptr(mode|enableVirtualTerminalProcessing))
+	return ok != 0
+}
@@ -0,0 +1,96 @@
+package logx
+
+// writer.go adapts line-oriented output (child processes, libraries that
+// take an io.Writer) into log records.
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+// maxWriterLine bounds buffered partial lines; longer lines are split.
+const maxWriterLine = 64 * 1024
+
+// Writer returns an io.Writer that logs each written line as a record at
+// level through the package logger. If msgKey is empty the line is the
+// record message; otherwise the line is stored under the msgKey attribute
+// with an empty message. Trailing "\r" is trimmed and empty lines are dropped.
+//
+// A partial line is buffered until its newline arrives. The returned writer
+// also implements io.Closer; Close flushes any buffered partial line.
+//
+//	cmd.Stdout = logx.Writer(slog.LevelInfo, "")
+//	cmd.Stderr = logx.Writer(slog.LevelWarn, "")
+func Writer(level slog.Level, msgKey string) io.Writer {
+	return &lineWriter{level: level, msgKey: msgKey}
+}
+
+type lineWriter struct {
+	level  slog.Level
+	msgKey string
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+
+	for len(w.buf) >= maxWriterLine {
+		w.emit(w.buf[:maxWriterLine])
+		w.buf = w.buf[maxWriterLine:]
+	}
+
+	// compact so the backing array does not grow without bound
+	if len(w.buf) == 0 {
+		w.buf = w.buf[:0:0]
+	}
+	return len(p), nil
+}
+
+// Close flushes a buffered partial line.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.emit(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
+
+func (w *lineWriter) emit(line []byte) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	l := Logger()
+	if !l.Enabled(ctx, w.level) {
+		return
+	}
+
+	var r slog.Record
+	if w.msgKey == "" {
+		r = slog.NewRecord(Now(), w.level, string(line), 0)
+	} else {
+		r = slog.NewRecord(Now(), w.level, "", 0)
+		r.AddAttrs(slog.String(w.msgKey, string(line)))
+	}
+	_ = l.Handler().Handle(ctx, r)
+}
@@ -0,0 +1,49 @@
+package logx
+
+import (
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestWriter_SplitsLinesAndBuffersPartials(t *testing.T) {
+	out := capture(t, slog.LevelDebug, func() {
+		w := Writer(slog.LevelWarn, "")
+		io.WriteString(w, "first line\nsecond ")
+		io.WriteString(w, "line\r\n\npartial")
+		w.(io.Closer).Close()
+	})
+
+	assertContains(t, out, `level=WARN msg="first line"`)
+	assertContains(t, out, `msg="second line"`)
+	assertContains(t, out, `msg=partial`)
+	if got := strings.Count(out, "level=WARN"); got != 3 {
+		t.Fatalf("expected 3 records, got %d: %s", got, out)
+	}
+}
+
+func TestWriter_MsgKeyStoresLineAsAttr(t *testing.T) {
+	out := capture(t, slog.LevelInfo, func() {
+		io.WriteString(Writer(slog.LevelInfo, "output"), "hello child\n")
+	})
+
+	assertContains(t, out, `output="hello child"`)
+}
+
+func TestWriter_CapturesSubprocessOutput(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not available")
+	}
+
+	out := capture(t, slog.LevelInfo, func() {
+		cmd := exec.Command("echo", "from-child")
+		cmd.Stdout = Writer(slog.LevelInfo, "")
+		if err := cmd.Run(); err != nil {
+			t.Fatalf("run failed: %v", err)
+		}
+	})
+
+	assertContains(t, out, "msg=from-child")
+}